@@ -18,18 +18,27 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"contrib.go.opencensus.io/exporter/prometheus"
 	"github.com/julienschmidt/httprouter"
 	"github.com/oklog/run"
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
 	"gopkg.in/alecthomas/kingpin.v2"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	client "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/leaderelection"
@@ -50,17 +59,92 @@ func main() {
 	var (
 		app = kingpin.New(filepath.Base(os.Args[0]), "Automatically cordons and drains nodes that match the supplied conditions.").DefaultEnvars()
 
-		debug            = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
-		listen           = app.Flag("listen", "Address at which to expose /metrics and /healthz.").Default(":10002").String()
-		kubecfg          = app.Flag("kubeconfig", "Path to kubeconfig file. Leave unset to use in-cluster config.").String()
-		apiserver        = app.Flag("master", "Address of Kubernetes API server. Leave unset to use in-cluster config.").String()
-		dryRun           = app.Flag("dry-run", "Emit an event without cordoning or draining matching nodes.").Bool()
-		maxGracePeriod   = app.Flag("max-grace-period", "Maximum time evicted pods will be given to terminate gracefully.").Default(kubernetes.DefaultMaxGracePeriod.String()).Duration()
-		evictionHeadroom = app.Flag("eviction-headroom", "Additional time to wait after a pod's termination grace period for it to have been deleted.").Default(kubernetes.DefaultEvictionOverhead.String()).Duration()
-		drainBuffer      = app.Flag("drain-buffer", "Minimum time between starting each drain. Nodes are always cordoned immediately.").Default(kubernetes.DefaultDrainBuffer.String()).Duration()
-		nodeLabels       = app.Flag("node-label", "(Deprecated) Nodes with this label will be eligible for cordoning and draining. May be specified multiple times").Strings()
-		nodeLabelsExpr   = app.Flag("node-label-expr", "Nodes that match this expression will be eligible for cordoning and draining.").String()
-		namespace        = app.Flag("namespace", "Namespace used to create leader election lock object.").Default("kube-system").String()
+		debug              = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
+		listen             = app.Flag("listen", "Address at which to expose /metrics and /healthz.").Default(":10002").String()
+		scheduleAPIListen  = app.Flag("schedule-api-listen", "Address at which the leader exposes the drain schedule API under /schedules. Leave unset to disable it.").String()
+		scheduleAPIToken   = app.Flag("schedule-api-token", "Bearer token required to delete and expedite drain schedules through the schedule API. Leave unset to serve the API read only. Prefer --schedule-api-token-file or the DRAINO_SCHEDULE_API_TOKEN environment variable, which do not expose the token in the process arguments.").String()
+		scheduleTokenFile  = app.Flag("schedule-api-token-file", "File holding the bearer token of the schedule API, e.g. mounted from a Secret. Takes precedence over --schedule-api-token.").String()
+		kubecfg            = app.Flag("kubeconfig", "Path to kubeconfig file. Leave unset to use in-cluster config.").String()
+		apiserver          = app.Flag("master", "Address of Kubernetes API server. Leave unset to use in-cluster config.").String()
+		dryRun             = app.Flag("dry-run", "Emit an event without cordoning or draining matching nodes.").Bool()
+		maxGracePeriod     = app.Flag("max-grace-period", "Maximum time evicted pods will be given to terminate gracefully.").Default(kubernetes.DefaultMaxGracePeriod.String()).Duration()
+		evictionHeadroom   = app.Flag("eviction-headroom", "Additional time to wait after a pod's termination grace period for it to have been deleted.").Default(kubernetes.DefaultEvictionOverhead.String()).Duration()
+		drainBuffer        = app.Flag("drain-buffer", "Minimum time between starting each drain, no less than 10s. Nodes are always cordoned immediately.").Default(kubernetes.DefaultDrainBuffer.String()).Duration()
+		nodeLabels         = app.Flag("node-label", "(Deprecated) Nodes with this label will be eligible for cordoning and draining. May be specified multiple times").Strings()
+		nodeLabelsExpr     = app.Flag("node-label-expr", "Nodes that match this expression will be eligible for cordoning and draining.").String()
+		namespace          = app.Flag("namespace", "Namespace used to create leader election lock object.").Default("kube-system").String()
+		nodeGroupLabel     = app.Flag("node-group-label", "Label whose value identifies the group a node belongs to.").String()
+		instanceTypeLabel  = app.Flag("instance-type-label", "Label whose value identifies the instance type of a node, used to break down drain metrics.").Default(core.LabelInstanceTypeStable).String()
+		maxDeferral        = app.Flag("max-drain-deferral", "Maximum time a drain may be deferred by soft constraints such as drain dependencies. Zero means no limit.").Default("0s").Duration()
+		deferUnschedulable = app.Flag("defer-unschedulable-drains", "Defer the drains of nodes whose pods would not fit in the free capacity of the rest of the cluster, up to --max-drain-deferral.").Bool()
+		pushGateway        = app.Flag("metrics-pushgateway", "URL of a Prometheus Pushgateway to push metrics to every --metrics-push-interval and when Draino stops, for short lived runs that exit before being scraped. Leave unset to only serve /metrics.").PlaceHolder("URL").String()
+		pushInterval       = app.Flag("metrics-push-interval", "Interval between metrics pushes to --metrics-pushgateway. Zero only pushes metrics when Draino stops.").Default(kubernetes.DefaultPushInterval.String()).Duration()
+		pushJob            = app.Flag("metrics-push-job", "Job label of the metrics pushed to --metrics-pushgateway.").Default(kubernetes.Component).String()
+		nodeSnapshotTTL    = app.Flag("node-snapshot-ttl", "How long scheduling constraints that list nodes, such as --defer-unschedulable-drains, reuse the same snapshot of the node cache.").Default(kubernetes.DefaultNodeSnapshotTTL.String()).Duration()
+		safeMode           = app.Flag("safe-mode", "Check that the pods of nodes can be safely rescheduled when their drain fires, and either defer or fail the drains of nodes with bare pods or pods of single replica StatefulSets.").Enum("", string(kubernetes.SafeModeDefer), string(kubernetes.SafeModeFail))
+		quorumAware        = app.Flag("quorum-aware-drain", "Defer the drains of nodes whose pods of a quorum group, identified by --quorum-group-label, cannot be evicted without leaving fewer ready members than a quorum. Drains are failed instead with --safe-mode=fail.").Bool()
+		quorumLabel        = app.Flag("quorum-group-label", "Label of pods whose value identifies their quorum group within their namespace, with --quorum-aware-drain.").Default(kubernetes.DefaultQuorumGroupLabel).String()
+		terminatingTaints  = app.Flag("node-terminating-taint", "Skip the drains of nodes carrying a taint with this key, set by node termination handlers when the provider reclaims a node, e.g. aws-node-termination-handler/spot-itn. May be specified multiple times.").PlaceHolder("KEY").Strings()
+		terminatingKeys    = app.Flag("node-terminating-annotation", "Skip the drains of nodes carrying an annotation with this key, set by node termination handlers when the provider reclaims a node. May be specified multiple times.").PlaceHolder("KEY").Strings()
+		allowedReasons     = app.Flag("allowed-drain-reason", "Only schedule drains triggered by an offending condition of this type, refusing the others, as a guard against misconfigured node conditions. May be specified multiple times. Leave unset to allow all the supplied conditions.").PlaceHolder("CONDITION").Strings()
+		recheckBeforeDrain = app.Flag("recheck-before-drain", "Recheck the conditions of nodes when their drain fires, and skip the drain of nodes that recovered.").Bool()
+		maxZoneDrains      = app.Flag("max-zone-drains", "Maximum number of drains of the nodes of an availability zone per --zone-drain-window. Zero means no limit.").Default("0").Int()
+		zoneDrainWindow    = app.Flag("zone-drain-window", "Sliding window over which --max-zone-drains applies.").Default("1h").Duration()
+		maxPodEvictions    = app.Flag("max-pod-evictions", "Maximum number of pods evicted by drains per --pod-eviction-window. Drains that would exceed it are deferred. Zero means no limit.").Default("0").Int()
+		podEvictionWindow  = app.Flag("pod-eviction-window", "Sliding window over which --max-pod-evictions applies.").Default("1h").Duration()
+		cordonReasonKey    = app.Flag("cordon-reason-annotation", "Annotation recording why draino cordoned a node.").Default(kubernetes.DefaultCordonReasonAnnotation).String()
+		cordonOwnerKey     = app.Flag("cordon-owner-annotation", "Annotation recording that draino cordoned a node.").Default(kubernetes.DefaultCordonOwnerAnnotation).String()
+		stateConditions    = app.Flag("drain-state-conditions", "Set a DrainInProgress, DrainSucceeded or DrainFailed node condition, in addition to the DrainScheduled condition, reflecting the state of each drain.").Bool()
+		drainAttempts      = app.Flag("max-drain-attempts", "Number of times a drain is attempted before it is marked failed.").Default("1").Int()
+		drainRetryBackoff  = app.Flag("drain-retry-backoff", "Time to wait before retrying a failed drain. Doubles after each failed attempt.").Default("5m").Duration()
+		forceFinalAttempt  = app.Flag("force-on-final-attempt", "Delete pods without a grace period on the final attempt of drains retried per --max-drain-attempts.").Bool()
+		drainBudget        = app.Flag("drain-budget", "Maximum number of drains running at once. Zero means no limit.").Default("0").Int()
+		maxCordons         = app.Flag("max-concurrent-cordons", "Maximum number of drains cordoning their node at once, with --split-drain-stages. Zero means no limit.").Default("0").Int()
+		maxEvictions       = app.Flag("max-concurrent-evictions", "Maximum number of drains evicting pods at once, with --split-drain-stages. Zero means no limit.").Default("0").Int()
+		splitStages        = app.Flag("split-drain-stages", "Split drains into a cordon stage and an eviction stage, limited by --max-concurrent-cordons and --max-concurrent-evictions, so that cordons outrun evictions.").Bool()
+		drainPermits       = app.Flag("drain-permits", "Number of drains permitted per --drain-permit-interval, granted afresh at the start of each interval. Drains that fire once they are exhausted are deferred to the next interval. Zero means no limit.").Default("0").Int()
+		permitInterval     = app.Flag("drain-permit-interval", "Interval at which --drain-permits are granted.").Default("30m").Duration()
+		drainBudgetLease   = app.Flag("drain-budget-lease", "Prefix of the leases, in --namespace, sharing --drain-budget across draino instances. Leave unset to only limit the drains of this instance.").String()
+		drainBudgetTTL     = app.Flag("drain-budget-lease-duration", "How long a drain may hold a --drain-budget-lease. Should exceed the longest drain.").Default("1h").Duration()
+		optInKey           = app.Flag("opt-in-annotation", "Only schedule the drains of nodes whose annotation is true, e.g. "+kubernetes.DefaultOptInAnnotation+"=true, to roll out draining gradually. Leave unset to drain all eligible nodes.").PlaceHolder("KEY").String()
+		minNodeAge         = app.Flag("min-node-age", "Do not drain nodes younger than this, which may still be initializing.").Default("0s").Duration()
+		maxSchedules       = app.Flag("max-schedules", "Maximum number of drains pending or in progress at once. Further drains are refused until drains finish or schedules are deleted. Zero means no limit.").Default("0").Int()
+		maxFailedSchedules = app.Flag("max-failed-schedules", "Maximum number of failed drain schedules retained. The schedules that failed the longest ago are deleted beyond it, leaving their nodes marked failed. Zero means no limit.").Default("0").Int()
+		maxDailyDrains     = app.Flag("max-daily-drains", "Maximum number of drains scheduled for each node per day. Further drains of the node are refused until the next day. Zero means no limit.").Default("0").Int()
+		dailyDrainReset    = app.Flag("daily-drain-reset", "Time past midnight UTC at which the days counted by --max-daily-drains start.").Default("0s").Duration()
+		groupCooldown      = app.Flag("group-drain-cooldown", "Minimum time between starting the drains of nodes of the same node group.").Default("0s").Duration()
+		interleaveGroups   = app.Flag("interleave-node-groups", "Alternate drains across the node groups, per --node-group-label, with drains pending, rather than draining nodes in the order their drains were scheduled.").Bool()
+		scaleDownLease     = app.Flag("scale-down-lease", "Name of a Lease, in --namespace, whose --scale-down-annotation is true while the cluster autoscaler is scaling down. Drains are deferred meanwhile. Leave unset to ignore scale downs.").String()
+		scaleDownKey       = app.Flag("scale-down-annotation", "Annotation of the --scale-down-lease that is true while the cluster autoscaler is scaling down.").Default(kubernetes.DefaultScaleDownAnnotation).String()
+		drainSwitch        = app.Flag("drain-switch-configmap", "Name of a ConfigMap, in --namespace, whose --drain-switch-key disables all drains while false, without restarting Draino. Drains are still scheduled, and deferred when they fire. Leave unset to always enable drains.").String()
+		drainSwitchKey     = app.Flag("drain-switch-key", "Key of the --drain-switch-configmap that enables drains while true.").Default(kubernetes.DefaultDrainSwitchKey).String()
+		healthGateURL      = app.Flag("health-gate-url", "URL of an external health check, whose GET must succeed with a 2xx status for drains to proceed when they fire. Drains are deferred meanwhile. Leave unset to not check health.").String()
+		healthBackoff      = app.Flag("health-gate-backoff", "How long drains are first deferred while the --health-gate-url fails, doubling while it keeps failing.").Default(kubernetes.DefaultDrainDeferralPeriod.String()).Duration()
+		maxHealthBackoff   = app.Flag("max-health-gate-backoff", "Longest drains are deferred at once while the --health-gate-url fails.").Default("10m").Duration()
+		maxPendingPods     = app.Flag("max-pending-pods", "Defer drains while more pods of the cluster than this are waiting to be scheduled. Zero disables the check.").Default("0").Int()
+		requireApproval    = app.Flag("require-drain-approval", "Defer each drain until its node is approved by setting --drain-approval-annotation to true.").Bool()
+		confirmEndState    = app.Flag("confirm-drain-end-state", "Confirm that drained nodes reach this state within --confirm-drain-timeout, either removed, e.g. by the cluster autoscaler, or cordoned. Nodes that linger are reported but their drain does not fail. Leave unset to not confirm drains.").Enum("", string(kubernetes.DrainEndStateRemoved), string(kubernetes.DrainEndStateCordoned))
+		confirmTimeout     = app.Flag("confirm-drain-timeout", "How long drained nodes are polled for their --confirm-drain-end-state.").Default(kubernetes.DefaultDrainConfirmationTimeout.String()).Duration()
+		alreadyCordoned    = app.Flag("already-cordoned-policy", "What to do when a drain fires for a node draino had already cordoned, as recorded by --cordon-owner-annotation, e.g. by a prior drain that failed: proceed with the drain, or skip it and leave the node cordoned.").Default(string(kubernetes.AlreadyCordonedProceed)).Enum(string(kubernetes.AlreadyCordonedProceed), string(kubernetes.AlreadyCordonedSkip))
+		mergeReasons       = app.Flag("merge-drain-reasons", "Merge the conditions of nodes whose drain is already scheduled into their schedule, rather than discarding them, recording a DrainReasonsMerged event.").Bool()
+		unreachableBackoff = app.Flag("api-unreachable-backoff", "Defer, rather than fail, drains that fail because the API server is unreachable, first by this long, doubling while it remains unreachable. Zero fails such drains.").Default(kubernetes.DefaultAPIUnreachableBackoff.String()).Duration()
+		maxUnreachable     = app.Flag("max-api-unreachable-backoff", "Longest a drain is deferred at once while the API server is unreachable.").Default(kubernetes.DefaultMaxAPIUnreachableBackoff.String()).Duration()
+		approvalKey        = app.Flag("drain-approval-annotation", "Annotation of nodes that approves their drain when --require-drain-approval is set.").Default(kubernetes.DefaultApprovalAnnotation).String()
+		pdbAwareOrder      = app.Flag("pdb-aware-drain-order", "Defer drains while a drain in progress evicts pods of a PodDisruptionBudget they share, when their pods together exceed the disruptions it allows, up to --max-drain-deferral.").Bool()
+		pdbPrecheck        = app.Flag("pdb-precheck", "Do not schedule the drains of nodes with pods covered by a PodDisruptionBudget that currently allows no disruptions, until it does.").Bool()
+		breakerThreshold   = app.Flag("circuit-breaker-threshold", "Pause all drains once more than this fraction, between 0 and 1, of the drain attempts within --circuit-breaker-window failed. Zero disables the circuit breaker.").Default("0").Float64()
+		breakerMinAttempts = app.Flag("circuit-breaker-min-attempts", "Minimum number of drain attempts within --circuit-breaker-window before the circuit breaker may trip.").Default("5").Int()
+		breakerWindow      = app.Flag("circuit-breaker-window", "How far back the drain attempts considered by the circuit breaker go.").Default("30m").Duration()
+		breakerCooldown    = app.Flag("circuit-breaker-cooldown", "How long drains stay paused once the circuit breaker tripped, unless the failure rate recovers first. Zero waits for the failure rate to recover.").Default("30m").Duration()
+		maxDisruption      = app.Flag("max-cluster-disruption", "Maximum percentage of the pods of the cluster evicted by all the drains in progress at once. Zero means no limit.").Default("0").Float64()
+		legacyConditions   = app.Flag("legacy-drain-condition-type", "Condition type previous versions of draino recorded drains with, treated as the DrainScheduled condition when recovering pending drains on startup. May be specified multiple times.").PlaceHolder("TYPE").Strings()
+		stateConfigMap     = app.Flag("state-configmap", "Name of a ConfigMap, in --namespace, persisting drain cooldowns across restarts. Leave unset to disable persistence.").String()
+		conditionDelay     = app.Flag("drain-condition-delay", "How long to wait after scheduling the drain of a node before writing its drain condition, giving systems watching for the cordon time to prepare. Drains start no earlier.").Default("0s").Duration()
+		minPodsMoved       = app.Flag("min-pods-moved", "Minimum number of pods a drain must move to be recorded as succeeded. Drains that move fewer are recorded with result empty. Zero counts every drain.").Default("0").Int()
+		aggregateEvents    = app.Flag("aggregate-events-object", "Object, in --namespace, to which the events about drain schedules are also recorded, as KIND/NAME where KIND is lease or pod, e.g. lease/draino. Leave unset to only record them against nodes.").PlaceHolder("KIND/NAME").String()
+		pendingAgePeriod   = app.Flag("pending-schedule-age-interval", "How often to record the age of the oldest schedule whose drain has not started yet. Zero disables the metric.").Default(kubernetes.DefaultPendingAgeInterval.String()).Duration()
+		latencyThreshold   = app.Flag("api-latency-threshold", "Back off the drain buffer while the average latency of API server calls exceeds this threshold. Zero disables throttling.").Default("0s").Duration()
+		maxDrainBuffer     = app.Flag("max-drain-buffer", "Maximum time between starting each drain when backing off due to API server latency.").Default("10m").Duration()
 
 		leaderElectionLeaseDuration = app.Flag("leader-election-lease-duration", "Lease duration for leader election.").Default(DefaultLeaderElectionLeaseDuration.String()).Duration()
 		leaderElectionRenewDeadline = app.Flag("leader-election-renew-deadline", "Leader election renew deadline.").Default(DefaultLeaderElectionRenewDeadline.String()).Duration()
@@ -69,11 +153,66 @@ func main() {
 
 		skipDrain             = app.Flag("skip-drain", "Whether to skip draining nodes after cordoning.").Default("false").Bool()
 		skipDelete            = app.Flag("skip-delete", "Whether to skip deleteing nodes after draining.").Default("false").Bool()
+		escalateEvictions     = app.Flag("escalate-evictions", "Force delete, without a grace period, pods whose eviction is still refused after --eviction-escalation-timeout.").Bool()
+		terminatingTimeout    = app.Flag("terminating-pod-timeout", "How long to wait for pods that are already terminating to be gone before evicting them. Zero waits for --max-grace-period plus --eviction-headroom.").Default("0s").Duration()
+		stuckTerminating      = app.Flag("force-delete-stuck-terminating-after", "Force delete, without a grace period, pods still terminating this long after their deletion started, e.g. due to finalizers or an unresponsive kubelet. Zero never force deletes them.").Default("0s").Duration()
+		jobPodPolicy          = app.Flag("job-pod-policy", "How to drain pods owned by Jobs: evict them like any other pod, or wait for up to --job-pod-timeout for them to complete before evicting them.").Default(string(kubernetes.JobPodEvict)).Enum(string(kubernetes.JobPodEvict), string(kubernetes.JobPodWait))
+		jobPodTimeout         = app.Flag("job-pod-timeout", "How long to wait for pods owned by Jobs to complete before evicting them, with --job-pod-policy=wait.").Default(kubernetes.DefaultJobPodTimeout.String()).Duration()
+		replacementCheck      = app.Flag("check-replacement-room", "Defer the eviction of the last ready replica of a workload while its replacement would not fit in the free capacity of the cluster, for up to --replacement-room-timeout. Lists the nodes and pods of the cluster for each last replica.").Bool()
+		replacementTimeout    = app.Flag("replacement-room-timeout", "How long to defer the eviction of the last ready replica of a workload whose replacement would not fit, with --check-replacement-room.").Default(kubernetes.DefaultReplacementTimeout.String()).Duration()
+		spreadCheckDelay      = app.Flag("replacement-spread-check-delay", "How long after the pods of a drained node are gone to check whether their replacements landed on nodes also being drained, warning in the drain summary if so. Zero disables the check.").Default("0s").Duration()
+		admissionDenied       = app.Flag("admission-denied-policy", "What to do with pods whose eviction is denied by an admission webhook: fail the drain, or skip them and carry on with the drain.").Default(string(kubernetes.AdmissionDeniedFail)).Enum(string(kubernetes.AdmissionDeniedFail), string(kubernetes.AdmissionDeniedSkip))
+		escalationTimeout     = app.Flag("eviction-escalation-timeout", "How long refused evictions are retried before escalating to force deletion.").Default("5m").Duration()
+		evictionCallTimeout   = app.Flag("eviction-call-timeout", "How long each eviction API call may take before it is abandoned and retried. Zero means no limit.").Default("0s").Duration()
+		nodeGoneInterval      = app.Flag("node-gone-check-interval", "How often to check that a node being drained still exists, stopping its drain once it was deleted. Zero disables the check.").Default("10s").Duration()
+		propagationPolicy     = app.Flag("eviction-propagation-policy", "Deletion propagation policy of evicted pods, one of Orphan, Background or Foreground. Leave unset to use the API server default.").Enum(string(meta.DeletePropagationOrphan), string(meta.DeletePropagationBackground), string(meta.DeletePropagationForeground))
+		verifyEvictions       = app.Flag("verify-evictions-timeout", "Wait up to this long for evicted pods to be gone from a node before marking its drain succeeded. Zero disables verification.").Default("0s").Duration()
+		deterministicOrder    = app.Flag("deterministic-eviction-order", "Evict pods one at a time, sorted by namespace then name, rather than all at once.").Bool()
+		serverDryRun          = app.Flag("eviction-server-dry-run", "Issue evictions as server side dry runs, reporting the pods whose eviction the API server would reject without evicting any.").Bool()
+		annotateResults       = app.Flag("annotate-drain-results", "Record the result and completion time of the last drain of each node as node annotations.").Bool()
+		cordonRetryPeriod     = app.Flag("cordon-retry-period", "How long to wait before retrying a cordon that failed because of a conflict or another transient error.").Default(kubernetes.DefaultCordonRetryPeriod.String()).Duration()
+		cordonRetryTimeout    = app.Flag("cordon-retry-timeout", "How long to retry a cordon that keeps failing transiently before failing it. Zero disables retries.").Default(kubernetes.DefaultCordonRetryTimeout.String()).Duration()
+		cordonSettleDelay     = app.Flag("cordon-settle-delay", "How long to wait after cordoning a node before evicting its pods, so that pods being scheduled to it land first.").Default("0s").Duration()
+		ownerAwareOrder       = app.Flag("owner-aware-eviction-order", "Evict the pods of one controller at a time, waiting for them to be gone before evicting those of the next.").Bool()
+		extendedLast          = app.Flag("extended-resources-last", "Evict the pods requesting extended resources, such as GPUs, after all others, since they are often the hardest to reschedule.").Bool()
+		systemNamespaces      = app.Flag("system-pod-namespace", "Evict the pods of this namespace, which provide node local services such as networking or storage, in a final phase after all others. May be specified multiple times.").PlaceHolder("NAMESPACE").Strings()
+		systemLabels          = app.Flag("system-pod-label", "Evict the pods with this label, either KEY to match any value or KEY=VALUE, in a final phase after all others. May be specified multiple times.").PlaceHolder("KEY[=VALUE]").Strings()
+		topologySpreadKey     = app.Flag("topology-spread-eviction-order", "Evict pods one at a time, those whose controller is the most over represented in the topology domain of the node, as given by this node label, e.g. topology.kubernetes.io/zone, first. Takes precedence over --largest-first-eviction-order. Leave unset to disable.").PlaceHolder("LABEL").String()
+		largestFirstOrder     = app.Flag("largest-first-eviction-order", "Evict pods one at a time by decreasing memory then CPU requests, freeing the capacity of nodes as fast as possible. Takes precedence over --owner-aware-eviction-order and --deterministic-eviction-order.").Bool()
+		evictFirstKey         = app.Flag("evict-first-annotation", "Pods whose annotation is true are evicted one at a time before all others. Leave empty to evict all pods in the same order.").Default(kubernetes.DefaultEvictFirstAnnotation).String()
+		startupCostKey        = app.Flag("startup-cost-annotation", "Pods are evicted by increasing value of this annotation, the cost of starting them elsewhere, pods without one halfway through the others. Leave empty to ignore startup costs.").Default(kubernetes.DefaultStartupCostAnnotation).String()
+		protectedPodsKey      = app.Flag("protected-pods-annotation", "Annotation of nodes listing, comma separated, the names of pods never evicted from them, either namespaced or not. Set it empty to ignore it.").Default(kubernetes.DefaultProtectedPodsAnnotation).String()
+		progressKey           = app.Flag("drain-progress-annotation", "Annotation of nodes recording the progress of their drain, e.g. 37/120 pods evicted. Leave empty to not report progress.").Default(kubernetes.DefaultProgressAnnotation).String()
+		progressInterval      = app.Flag("drain-progress-interval", "Minimum interval between progress updates of a drain.").Default(kubernetes.DefaultProgressInterval.String()).Duration()
+		sidecarLabel          = app.Flag("sidecar-label", "Label of pods whose sidecars, such as a service mesh proxy, must be asked to exit once their eviction is accepted, by POSTing to --sidecar-quit-path on --sidecar-quit-port of the pod. Leave unset to not shut down sidecars.").PlaceHolder("KEY").String()
+		sidecarQuitPort       = app.Flag("sidecar-quit-port", "Port of pods labelled --sidecar-label whose sidecars exit when POSTed to --sidecar-quit-path.").Default(strconv.Itoa(kubernetes.DefaultSidecarQuitPort)).Int()
+		sidecarQuitPath       = app.Flag("sidecar-quit-path", "Path of the endpoint of pods labelled --sidecar-label that makes their sidecars exit.").Default(kubernetes.DefaultSidecarQuitPath).String()
+		unreadyFastPath       = app.Flag("unready-pod-fast-path", "Evict pods that are not ready at once, before those that are ready, with at most --unready-pod-grace-period to shut down.").Bool()
+		unreadyGracePeriod    = app.Flag("unready-pod-grace-period", "Maximum grace period of pods that are not ready with --unready-pod-fast-path.").Default("5s").Duration()
+		crashLoopFastPath     = app.Flag("crashloop-pod-fast-path", "Evict pods with a container in CrashLoopBackOff at once, before all others, with at most --crashloop-pod-grace-period to shut down.").Bool()
+		nsTerminatingGone     = app.Flag("namespace-terminating-pods-gone", "Treat pods whose eviction fails because their namespace is being deleted as already gone, rather than failing the drain.").Default("true").Bool()
+		crashLoopGracePeriod  = app.Flag("crashloop-pod-grace-period", "Maximum grace period of crashlooping pods with --crashloop-pod-fast-path.").Default("1s").Duration()
+		graceTierLabel        = app.Flag("grace-period-tier-label", "Label of pods whose value selects the grace period of their eviction among the --grace-period-tier values.").Default("tier").String()
+		maxRequestKey         = app.Flag("max-request-annotation", "Annotation of pods holding the duration, in seconds, of the longest requests they serve, to which the grace period of their eviction is extended, up to --max-long-request-grace.").Default(kubernetes.DefaultMaxRequestAnnotation).String()
+		maxRequestGrace       = app.Flag("max-long-request-grace", "Longest grace period the eviction of pods serving long requests is extended to, per their --max-request-annotation, even beyond --max-grace-period. Zero disables the extension.").Default("0s").Duration()
+		ageProfiles           = app.Flag("condition-age-profile", "Cap the grace period of the evictions of nodes whose offending condition has been true for at least this long, e.g. 1h=30s, or 24h=5s,expedite to also drain them at once rather than after --drain-buffer. The profile of the longest age reached applies. May be specified multiple times.").PlaceHolder("AGE=GRACE[,expedite]").Strings()
+		graceTiers            = app.Flag("grace-period-tier", "Grace period of the eviction of pods whose --grace-period-tier-label has this value, e.g. critical=5m or batch=0s. May be specified multiple times.").PlaceHolder("VALUE=DURATION").Strings()
+		evictionRate          = app.Flag("eviction-rate-per-node", "Maximum number of pods of a node removed per second during its drain. Zero means no limit.").Default("0").Float64()
+		minInterPodDelay      = app.Flag("min-inter-pod-eviction-delay", "Minimum time between the removal of two pods of a node, so that evicted pods are rescheduled one at a time. Zero means no delay.").Default("0s").Duration()
+		maxTerminatingPods    = app.Flag("max-terminating-pods", "Maximum number of pods of a node being removed at once. Further pods are evicted as others are gone. Zero means no limit.").Default("0").Int()
+		maxNamespaceEvictions = app.Flag("max-namespace-evictions", "Maximum number of pods of each namespace being removed at once, across all the nodes being drained. Zero means no limit.").Default("0").Int()
+		namespaceEvictions    = app.Flag("namespace-max-evictions", "Maximum number of pods of a namespace being removed at once, across all the nodes being drained, overriding --max-namespace-evictions, e.g. team-a=2. May be specified multiple times.").PlaceHolder("NAMESPACE=MAX").Strings()
+		pvAwareDrain          = app.Flag("pv-aware-drain", "Wait for the PersistentVolumes of evicted pods to be detached from the node, failing the drain if they are not, and never force delete these pods.").Bool()
+		volumeDetachTimeout   = app.Flag("volume-detach-timeout", "How long to wait for the PersistentVolumes of an evicted pod to be detached with --pv-aware-drain.").Default(kubernetes.DefaultVolumeDetachTimeout.String()).Duration()
+		emptyNodeFastPath     = app.Flag("empty-node-fast-path", "Complete drains immediately, with a noop result, when a node has no pods to evict.").Default("true").Bool()
 		evictDaemonSetPods    = app.Flag("evict-daemonset-pods", "Evict pods that were created by an extant DaemonSet.").Bool()
 		evictStatefulSetPods  = app.Flag("evict-statefulset-pods", "Evict pods that were created by an extant StatefulSet.").Bool()
 		evictLocalStoragePods = app.Flag("evict-emptydir-pods", "Evict pods with local storage, i.e. with emptyDir volumes.").Bool()
 		evictUnreplicatedPods = app.Flag("evict-unreplicated-pods", "Evict pods that were not created by a replication controller.").Bool()
 
+		postDrainActions        = app.Flag("post-drain-action", "What to do with nodes drained because of a node condition, either keepCordoned or uncordon. May be specified multiple times.").PlaceHolder("CONDITION=ACTION").Strings()
+		failedDrainAction       = app.Flag("failed-drain-action", "What to do with cordoned nodes whose drain failed: keepCordoned, rollbackCordon to uncordon them, or retainForRetry to retry their drain.").Default(string(kubernetes.FailedDrainKeepCordoned)).Enum(string(kubernetes.FailedDrainKeepCordoned), string(kubernetes.FailedDrainRollbackCordon), string(kubernetes.FailedDrainRetainForRetry))
+		groupDrainSchedules     = app.Flag("group-drain-schedule", "Only drain the nodes of a node group, per --node-group-label, at the occurrences of a cron schedule, e.g. batch=\"0 1 * * 0\" for Sundays at 01:00. May be specified multiple times.").PlaceHolder("GROUP=CRON").Strings()
 		protectedPodAnnotations = app.Flag("protected-pod-annotation", "Protect pods with this annotation from eviction. May be specified multiple times.").PlaceHolder("KEY[=VALUE]").Strings()
 
 		conditions = app.Arg("node-conditions", "Nodes for which any of these conditions are true will be cordoned and drained.").Required().Strings()
@@ -103,7 +242,7 @@ func main() {
 			Measure:     kubernetes.MeasureNodesDrained,
 			Description: "Number of nodes drained.",
 			Aggregation: view.Count(),
-			TagKeys:     []tag.Key{kubernetes.TagResult},
+			TagKeys:     []tag.Key{kubernetes.TagResult, kubernetes.TagInstanceType, kubernetes.TagKubeletVersion},
 		}
 		nodesDrainScheduled = &view.View{
 			Name:        "drain_scheduled_nodes_total",
@@ -112,25 +251,402 @@ func main() {
 			Aggregation: view.Count(),
 			TagKeys:     []tag.Key{kubernetes.TagResult},
 		}
+		drainDuration = &view.View{
+			Name:        "drain_duration_seconds",
+			Measure:     kubernetes.MeasureDrainDuration,
+			Description: "Time spent draining nodes.",
+			Aggregation: view.Distribution(1, 5, 15, 30, 60, 120, 300, 600, 1200, 3600),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
+		drainsDeferred = &view.View{
+			Name:        "drains_deferred_total",
+			Measure:     kubernetes.MeasureDrainsDeferred,
+			Description: "Number of drains deferred when they fired.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagReason},
+		}
+		drainsForceFired = &view.View{
+			Name:        "drains_force_fired_total",
+			Measure:     kubernetes.MeasureDrainsForceFired,
+			Description: "Number of drains fired after being deferred for too long.",
+			Aggregation: view.Count(),
+		}
+		effectiveDrainPeriod = &view.View{
+			Name:        "effective_drain_buffer_seconds",
+			Measure:     kubernetes.MeasureEffectiveDrainPeriod,
+			Description: "Minimum time between starting each drain, after throttling.",
+			Aggregation: view.LastValue(),
+		}
+		cpuFreed = &view.View{
+			Name:        "cpu_freed_cores_total",
+			Measure:     kubernetes.MeasureCPUFreed,
+			Description: "Allocatable CPU cores of the nodes whose drain completed.",
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
+		memoryFreed = &view.View{
+			Name:        "memory_freed_bytes_total",
+			Measure:     kubernetes.MeasureMemoryFreed,
+			Description: "Allocatable memory of the nodes whose drain completed.",
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
+		oldestPendingScheduleAge = &view.View{
+			Name:        "oldest_pending_schedule_age_seconds",
+			Measure:     kubernetes.MeasureOldestPendingScheduleAge,
+			Description: "Time since the oldest schedule whose drain has not started yet was created.",
+			Aggregation: view.LastValue(),
+		}
+		circuitBreakerOpen = &view.View{
+			Name:        "circuit_breaker_open",
+			Measure:     kubernetes.MeasureCircuitBreakerOpen,
+			Description: "Whether drains are paused because too many recent drain attempts failed.",
+			Aggregation: view.LastValue(),
+		}
+		drainThroughput = &view.View{
+			Name:        "drain_throughput_per_minute",
+			Measure:     kubernetes.MeasureDrainThroughput,
+			Description: "Number of drains completed per minute within the last hour.",
+			Aggregation: view.LastValue(),
+		}
+		blockedByConstraint = &view.View{
+			Name:        "drains_blocked_by_constraint",
+			Measure:     kubernetes.MeasureBlockedByConstraint,
+			Description: "Number of pending drains currently blocked on a constraint.",
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{kubernetes.TagConstraint},
+		}
+		pendingPods = &view.View{
+			Name:        "cluster_pending_pods",
+			Measure:     kubernetes.MeasurePendingPods,
+			Description: "Number of pods of the cluster waiting to be scheduled, when drains last fired.",
+			Aggregation: view.LastValue(),
+		}
+		drainsEnabled = &view.View{
+			Name:        "drains_enabled",
+			Measure:     kubernetes.MeasureDrainsEnabled,
+			Description: "Whether drains are enabled by the drain switch ConfigMap.",
+			Aggregation: view.LastValue(),
+		}
+		nodesNotOptedIn = &view.View{
+			Name:        "nodes_not_opted_in_total",
+			Measure:     kubernetes.MeasureNodesNotOptedIn,
+			Description: "Number of nodes not scheduled for drain because they are not opted in to draining.",
+			Aggregation: view.Count(),
+		}
+		nodesTooYoung = &view.View{
+			Name:        "too_young_nodes_total",
+			Measure:     kubernetes.MeasureNodesTooYoung,
+			Description: "Number of nodes not scheduled for drain because they are too young.",
+			Aggregation: view.Count(),
+		}
+		failedDrainActions = &view.View{
+			Name:        "failed_drain_actions_total",
+			Measure:     kubernetes.MeasureFailedDrainActions,
+			Description: "Number of failed drain policy actions applied to nodes whose drain failed.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagAction},
+		}
+		cordonRetries = &view.View{
+			Name:        "cordon_retries_total",
+			Measure:     kubernetes.MeasureCordonRetries,
+			Description: "Number of cordons retried after failing transiently.",
+			Aggregation: view.Count(),
+		}
+		podsByOwnerKind = &view.View{
+			Name:        "pods_by_owner_kind_total",
+			Measure:     kubernetes.MeasurePodsByOwnerKind,
+			Description: "Number of pods drained from nodes, by the kind of their controller.",
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{kubernetes.TagOwnerKind},
+		}
+		namespaceEvictionsInFlight = &view.View{
+			Name:        "namespace_evictions_in_flight",
+			Measure:     kubernetes.MeasureNamespaceEvictions,
+			Description: "Number of pods of a namespace being removed at once, across drains.",
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{kubernetes.TagNamespace},
+		}
+		drainConfirmations = &view.View{
+			Name:        "drain_confirmations_total",
+			Measure:     kubernetes.MeasureDrainConfirmations,
+			Description: "Number of drained nodes checked for their expected end state, by result.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
+		reasonsMerged = &view.View{
+			Name:        "drain_reasons_merged_total",
+			Measure:     kubernetes.MeasureReasonsMerged,
+			Description: "Number of reasons merged into the existing drain schedules of nodes.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagReason},
+		}
+		reentrantDrains = &view.View{
+			Name:        "reentrant_drains_total",
+			Measure:     kubernetes.MeasureReentrantDrains,
+			Description: "Number of drains that fired for nodes draino had already cordoned.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
+		evictionsDeferred = &view.View{
+			Name:        "evictions_deferred_total",
+			Measure:     kubernetes.MeasureEvictionsDeferred,
+			Description: "Number of evictions of the last ready replica of a workload deferred because its replacement would not fit in the cluster.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagNodeName},
+		}
+		disallowedReasons = &view.View{
+			Name:        "disallowed_reasons_total",
+			Measure:     kubernetes.MeasureDisallowedReasons,
+			Description: "Number of drains not scheduled because the reason that triggered them is not allowed.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagReason},
+		}
+		dailyLimitRefusals = &view.View{
+			Name:        "daily_limit_refusals_total",
+			Measure:     kubernetes.MeasureDailyLimitRefusals,
+			Description: "Number of drains not scheduled because their node reached the daily drain limit.",
+			Aggregation: view.Count(),
+		}
+		schedulesEvicted = &view.View{
+			Name:        "failed_schedules_evicted_total",
+			Measure:     kubernetes.MeasureSchedulesEvicted,
+			Description: "Number of failed schedules deleted because too many failed schedules were retained.",
+			Aggregation: view.Count(),
+		}
+		schedulesRejected = &view.View{
+			Name:        "schedules_rejected_total",
+			Measure:     kubernetes.MeasureSchedulesRejected,
+			Description: "Number of drains not scheduled because the scheduler holds the maximum number of schedules.",
+			Aggregation: view.Count(),
+		}
+		pdbBlocks = &view.View{
+			Name:        "pdb_blocks_total",
+			Measure:     kubernetes.MeasurePDBBlocks,
+			Description: "Number of evictions refused by each PodDisruptionBudget.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagNamespace, kubernetes.TagPDB},
+		}
+		pdbConflictsAvoided = &view.View{
+			Name:        "pdb_conflicts_avoided_total",
+			Measure:     kubernetes.MeasurePDBConflictsAvoided,
+			Description: "Number of drains deferred because a drain in progress evicts pods of the same PodDisruptionBudget.",
+			Aggregation: view.Count(),
+		}
+		staleEvents = &view.View{
+			Name:        "stale_events_total",
+			Measure:     kubernetes.MeasureStaleEvents,
+			Description: "Number of node events whose condition transitioned after the drain they scheduled.",
+			Aggregation: view.Count(),
+		}
+		drainsAborted = &view.View{
+			Name:        "drains_aborted_total",
+			Measure:     kubernetes.MeasureDrainsAborted,
+			Description: "Number of drains aborted because their schedule was deleted.",
+			Aggregation: view.Count(),
+		}
+		drainsEscalated = &view.View{
+			Name:        "drains_escalated_total",
+			Measure:     kubernetes.MeasureDrainsEscalated,
+			Description: "Number of drains escalated to a force drain on their final attempt.",
+			Aggregation: view.Count(),
+		}
+		evictionBackoffs = &view.View{
+			Name:        "eviction_backoffs_total",
+			Measure:     kubernetes.MeasureEvictionBackoffs,
+			Description: "Number of evictions refused with 429 Too Many Requests and retried.",
+			Aggregation: view.Count(),
+		}
+		evictionTimeouts = &view.View{
+			Name:        "eviction_call_timeouts_total",
+			Measure:     kubernetes.MeasureEvictionTimeouts,
+			Description: "Number of eviction API calls that timed out and were retried.",
+			Aggregation: view.Count(),
+		}
+		podsRemoved = &view.View{
+			Name:        "removed_pods_total",
+			Measure:     kubernetes.MeasurePodsRemoved,
+			Description: "Number of pods removed from drained nodes.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagPhase},
+		}
+		misplacedReplicas = &view.View{
+			Name:        "replacements_on_draining_nodes_total",
+			Measure:     kubernetes.MeasureMisplacedReplicas,
+			Description: "Number of replacements of evicted pods that landed on nodes also being drained.",
+			Aggregation: view.Count(),
+		}
+		crashLoopsCleared = &view.View{
+			Name:        "crashloop_pods_cleared_total",
+			Measure:     kubernetes.MeasureCrashLoopsCleared,
+			Description: "Number of crashlooping pods fast pathed out of drained nodes.",
+			Aggregation: view.Count(),
+		}
+		podsNamespaceTerminating = &view.View{
+			Name:        "pods_namespace_terminating_total",
+			Measure:     kubernetes.MeasurePodsNamespaceGone,
+			Description: "Number of pods assumed gone because their namespace was being deleted while they were evicted.",
+			Aggregation: view.Count(),
+		}
+		podsSkipped = &view.View{
+			Name:        "skipped_pods_total",
+			Measure:     kubernetes.MeasurePodsSkipped,
+			Description: "Number of pods skipped by the eviction filter.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagReason},
+		}
+		clusterDisruption = &view.View{
+			Name:        "cluster_disruption_percent",
+			Measure:     kubernetes.MeasureClusterDisruption,
+			Description: "Percentage of the pods of the cluster being evicted by the drains in progress.",
+			Aggregation: view.LastValue(),
+		}
+		nodeEvictionRate = &view.View{
+			Name:        "node_eviction_rate",
+			Measure:     kubernetes.MeasureNodeEvictionRate,
+			Description: "Number of pods of a node removed per second during the last drain.",
+			Aggregation: view.LastValue(),
+		}
+		interPodDelay = &view.View{
+			Name:        "inter_pod_delay_seconds",
+			Measure:     kubernetes.MeasureInterPodDelay,
+			Description: "Total time the pod removals of the last drain of a node waited for the minimum delay between them.",
+			Aggregation: view.LastValue(),
+		}
+		peakTerminatingPods = &view.View{
+			Name:        "peak_terminating_pods",
+			Measure:     kubernetes.MeasurePeakTerminatingPods,
+			Description: "Largest number of pods being removed at once during the last drain.",
+			Aggregation: view.LastValue(),
+		}
+		windowPodEvictions = &view.View{
+			Name:        "window_pod_evictions",
+			Measure:     kubernetes.MeasureWindowPodEvictions,
+			Description: "Number of pods evicted by recent drains, within the pod eviction budget window.",
+			Aggregation: view.LastValue(),
+		}
+		nodeSnapshotLookups = &view.View{
+			Name:        "node_snapshot_lookups_total",
+			Measure:     kubernetes.MeasureNodeSnapshotLookups,
+			Description: "Number of lookups of the node snapshot shared by scheduling constraints, by hit or miss.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
+		drainPermitsLeft = &view.View{
+			Name:        "drain_permits_remaining",
+			Measure:     kubernetes.MeasureDrainPermits,
+			Description: "Number of drain permits left in the current replenishment interval.",
+			Aggregation: view.LastValue(),
+		}
+		zoneWindowDrains = &view.View{
+			Name:        "zone_window_drains",
+			Measure:     kubernetes.MeasureZoneWindowDrains,
+			Description: "Number of recent drains of the nodes of a zone, within the zone drain window.",
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{kubernetes.TagZone},
+		}
+		scheduleLatency = &view.View{
+			Name:        "schedule_latency_seconds",
+			Measure:     kubernetes.MeasureScheduleLatency,
+			Description: "Time between the transition of the offending condition of a node and the scheduling of its drain.",
+			Aggregation: view.Distribution(1, 5, 15, 30, 60, 120, 300, 600, 1200, 3600),
+		}
+		preDrainCapacityWait = &view.View{
+			Name:        "pre_drain_capacity_wait_seconds",
+			Measure:     kubernetes.MeasurePreDrainCapacityWait,
+			Description: "Time spent waiting for replacement capacity before draining.",
+			Aggregation: view.Distribution(1, 5, 15, 30, 60, 120, 300, 600, 1200),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
 	)
 
-	kingpin.FatalIfError(view.Register(nodesCordoned, nodesUncordoned, nodesDrained, nodesDrainScheduled), "cannot create metrics")
-	p, err := prometheus.NewExporter(prometheus.Options{Namespace: kubernetes.Component})
+	kingpin.FatalIfError(view.Register(
+		nodesCordoned,
+		nodesUncordoned,
+		nodesDrained,
+		nodesDrainScheduled,
+		drainDuration,
+		drainsForceFired,
+		drainsDeferred,
+		nodesTooYoung,
+		nodesNotOptedIn,
+		drainsEnabled,
+		pendingPods,
+		blockedByConstraint,
+		staleEvents,
+		pdbConflictsAvoided,
+		pdbBlocks,
+		schedulesRejected,
+		schedulesEvicted,
+		dailyLimitRefusals,
+		disallowedReasons,
+		drainConfirmations,
+		reentrantDrains,
+		reasonsMerged,
+		podsByOwnerKind,
+		cordonRetries,
+		failedDrainActions,
+		drainsAborted,
+		drainsEscalated,
+		evictionBackoffs,
+		crashLoopsCleared,
+		podsNamespaceTerminating,
+		misplacedReplicas,
+		evictionTimeouts,
+		evictionsDeferred,
+		podsRemoved,
+		podsSkipped,
+		preDrainCapacityWait,
+		scheduleLatency,
+		effectiveDrainPeriod,
+		drainThroughput,
+		circuitBreakerOpen,
+		oldestPendingScheduleAge,
+		cpuFreed,
+		memoryFreed,
+		zoneWindowDrains,
+		windowPodEvictions,
+		drainPermitsLeft,
+		nodeSnapshotLookups,
+		peakTerminatingPods,
+		namespaceEvictionsInFlight,
+		nodeEvictionRate,
+		interPodDelay,
+		clusterDisruption,
+	), "cannot create metrics")
+	registry := promclient.NewRegistry()
+	p, err := prometheus.NewExporter(prometheus.Options{Namespace: kubernetes.Component, Registry: registry})
 	kingpin.FatalIfError(err, "cannot export metrics")
 	view.RegisterExporter(p)
 
-	web := &httpRunner{l: *listen, h: map[string]http.Handler{
-		"/metrics": p,
-		"/healthz": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { r.Body.Close() }), // nolint:errcheck
-	}}
-
 	log, err := zap.NewProduction()
 	if *debug {
 		log, err = zap.NewDevelopment()
 	}
 	kingpin.FatalIfError(err, "cannot create log")
+
+	web := &httpRunner{l: *listen, log: log, h: map[string]http.Handler{
+		"/metrics": p,
+		"/healthz": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { r.Body.Close() }), // nolint:errcheck
+	}}
 	defer log.Sync() // nolint:errcheck
 
+	pusher := kubernetes.NewMetricsPusher(*pushGateway, *pushJob, registry, *pushInterval, log)
+	pusher.Start()
+	if pusher != nil {
+		// Push the final metrics of runs terminated rather than stepping down.
+		go func() {
+			sig := make(chan os.Signal, 1)
+			signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+			<-sig
+			if err := pusher.Stop(); err != nil {
+				log.Info("Cannot push final metrics", zap.Error(err))
+			}
+			log.Sync() // nolint:errcheck
+			os.Exit(0)
+		}()
+	}
+
 	go func() {
 		log.Info("web server is running", zap.String("listen", *listen))
 		kingpin.FatalIfError(await(web), "error serving")
@@ -159,29 +675,244 @@ func main() {
 		"cluster-autoscaler.kubernetes.io/safe-to-evict=false", // https://github.com/kubernetes/autoscaler/blob/master/cluster-autoscaler/FAQ.md#what-types-of-pods-can-prevent-ca-from-removing-a-node
 	}
 	pf = append(pf, kubernetes.UnprotectedPodFilter(append(systemKnownAnnotations, *protectedPodAnnotations...)...))
-	var h cache.ResourceEventHandler = kubernetes.NewDrainingResourceEventHandler(
-		kubernetes.NewAPICordonDrainer(cs,
-			kubernetes.MaxGracePeriod(*maxGracePeriod),
-			kubernetes.EvictionHeadroom(*evictionHeadroom),
-			kubernetes.WithSkipDrain(*skipDrain),
-			kubernetes.WithSkipDelete(*skipDelete),
-			kubernetes.WithPodFilter(kubernetes.NewPodFilters(pf...)),
-			kubernetes.WithAPICordonDrainerLogger(log),
-		),
-		kubernetes.NewEventRecorder(cs),
+	scheduleOptions := []kubernetes.DrainSchedulesOption{
+		kubernetes.WithNodeGroupLabel(*nodeGroupLabel),
+		kubernetes.WithInstanceTypeLabel(*instanceTypeLabel),
+		kubernetes.WithMaxDeferral(*maxDeferral),
+		kubernetes.WithGroupCooldown(*groupCooldown),
+		kubernetes.WithMinNodeAge(*minNodeAge),
+		kubernetes.WithOptInAnnotation(*optInKey),
+		kubernetes.WithReasonAllowlist(*allowedReasons...),
+		kubernetes.WithMaxSchedules(*maxSchedules),
+		kubernetes.WithMaxFailedSchedules(*maxFailedSchedules),
+		kubernetes.WithDailyDrainLimit(*maxDailyDrains, *dailyDrainReset),
+		kubernetes.WithDrainPermits(*drainPermits, *permitInterval),
+		kubernetes.WithFailedDrainPolicy(kubernetes.FailedDrainAction(*failedDrainAction)),
+		kubernetes.WithAlreadyCordonedPolicy(*cordonOwnerKey, kubernetes.AlreadyCordonedPolicy(*alreadyCordoned)),
+		kubernetes.WithReasonMerging(*mergeReasons),
+		kubernetes.WithAPIUnreachableBackoff(*unreachableBackoff, *maxUnreachable),
+		kubernetes.WithZoneDrainLimit(*maxZoneDrains, *zoneDrainWindow),
+		kubernetes.WithPDBAwareOrder(*pdbAwareOrder),
+		kubernetes.WithPDBPrecheck(*pdbPrecheck),
+		kubernetes.WithPendingAgeInterval(*pendingAgePeriod),
+		kubernetes.WithLegacyConditionTypes(*legacyConditions),
+	}
+	if len(*postDrainActions) > 0 {
+		policy, err := parsePostDrainActions(*postDrainActions)
+		kingpin.FatalIfError(err, "cannot parse post drain actions")
+		scheduleOptions = append(scheduleOptions, kubernetes.WithPostDrainPolicy(policy))
+	}
+	if len(*groupDrainSchedules) > 0 {
+		schedules, err := parseGroupDrainSchedules(*groupDrainSchedules)
+		kingpin.FatalIfError(err, "cannot parse group drain schedules")
+		scheduleOptions = append(scheduleOptions, kubernetes.WithGroupDrainSchedules(schedules))
+	}
+	if *scheduleTokenFile != "" {
+		token, err := os.ReadFile(*scheduleTokenFile)
+		kingpin.FatalIfError(err, "cannot read schedule API token")
+		*scheduleAPIToken = strings.TrimSpace(string(token))
+	}
+	id, err := os.Hostname()
+	kingpin.FatalIfError(err, "cannot get hostname")
+	if *drainAttempts > 1 {
+		scheduleOptions = append(scheduleOptions,
+			kubernetes.WithDrainRetries(*drainAttempts, *drainRetryBackoff),
+			kubernetes.WithForceOnFinalAttempt(*forceFinalAttempt))
+	}
+	if *drainBudget > 0 {
+		var budget kubernetes.RateBudget = kubernetes.NewLocalRateBudget(*drainBudget)
+		if *drainBudgetLease != "" {
+			budget = kubernetes.NewLeaseRateBudget(cs, *namespace, *drainBudgetLease, id, *drainBudget, *drainBudgetTTL)
+		}
+		scheduleOptions = append(scheduleOptions, kubernetes.WithRateBudget(budget))
+	}
+	if *splitStages {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithStageConcurrency(*maxCordons, *maxEvictions))
+	}
+	var validators kubernetes.SafetyValidators
+	safeModePolicy := kubernetes.SafeModePolicy(*safeMode)
+	if *safeMode != "" {
+		validators = append(validators, kubernetes.NewControllerSafetyValidator(cs))
+	}
+	if *quorumAware {
+		validators = append(validators, kubernetes.NewQuorumSafetyValidator(cs, *quorumLabel))
+		if safeModePolicy == "" {
+			safeModePolicy = kubernetes.SafeModeDefer
+		}
+	}
+	if len(validators) > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithSafeMode(validators, safeModePolicy))
+	}
+	// Scheduling constraints that list nodes share a short lived snapshot of
+	// the cache of the node watch, to which the event handler is added below.
+	nodes := kubernetes.NewNodeWatch(cs)
+	scorer := kubernetes.NewClusterCapacityScorer(cs, kubernetes.WithScorerNodeLister(kubernetes.NewNodeSnapshot(nodes, *nodeSnapshotTTL)))
+	if *deferUnschedulable {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithFeasibilityScorer(scorer))
+	}
+	if *maxPodEvictions > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithPodEvictionBudget(*maxPodEvictions, *podEvictionWindow, scorer))
+	}
+	if *drainSwitch != "" {
+		sw := kubernetes.NewConfigMapDrainSwitch(cs, *namespace, *drainSwitch, *drainSwitchKey, log)
+		go sw.Run(wait.NeverStop)
+		scheduleOptions = append(scheduleOptions, kubernetes.WithDrainSwitch(sw.Enabled))
+	}
+	if *confirmEndState != "" {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithDrainConfirmation(nodes, kubernetes.DrainEndState(*confirmEndState), *confirmTimeout))
+	}
+	if *scaleDownLease != "" {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithScaleDownGate(kubernetes.NewLeaseScaleDownSignal(cs, *namespace, *scaleDownLease, *scaleDownKey, log)))
+	}
+	if *interleaveGroups {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithGroupInterleaving())
+	}
+	if len(*ageProfiles) > 0 {
+		profiles, err := parseConditionAgeProfiles(*ageProfiles)
+		kingpin.FatalIfError(err, "cannot parse condition age profiles")
+		scheduleOptions = append(scheduleOptions, kubernetes.WithConditionAgeProfiles(profiles...))
+	}
+	if *healthGateURL != "" {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithHealthGate(kubernetes.NewHTTPHealthGate(*healthGateURL), *healthBackoff, *maxHealthBackoff))
+	}
+	if *maxPendingPods > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithPendingPodsGate(kubernetes.NewAPIPendingPodCounter(cs), *maxPendingPods))
+	}
+	if *requireApproval {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithDrainApprover(kubernetes.NewAnnotationDrainApprover(kubernetes.NewAPINodeStore(cs), *approvalKey)))
+	}
+	if len(*terminatingTaints) > 0 || len(*terminatingKeys) > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithNodeTerminationCheck(kubernetes.NewAPINodeStore(cs), *terminatingTaints, *terminatingKeys))
+	}
+	if *breakerThreshold > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithCircuitBreaker(*breakerMinAttempts, *breakerThreshold, *breakerWindow, *breakerCooldown))
+	}
+	if *maxDisruption > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithDisruptionCeiling(*maxDisruption, scorer))
+	}
+	if *conditionDelay > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithConditionDelay(*conditionDelay))
+	}
+	if *minPodsMoved > 0 {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithMinPodsMoved(*minPodsMoved))
+	}
+	if *aggregateEvents != "" {
+		kind, name, ok := strings.Cut(*aggregateEvents, "/")
+		if !ok {
+			kingpin.Fatalf("cannot parse aggregate events object %q, want KIND/NAME", *aggregateEvents)
+		}
+		ref, err := kubernetes.ResolveObjectReference(cs, kind, *namespace, name)
+		kingpin.FatalIfError(err, "cannot resolve aggregate events object")
+		scheduleOptions = append(scheduleOptions, kubernetes.WithAggregateEvents(ref))
+	}
+	if *stateConfigMap != "" {
+		scheduleOptions = append(scheduleOptions, kubernetes.WithStateStore(kubernetes.NewConfigMapStateStore(cs, *namespace, *stateConfigMap)))
+	}
+	drainerOptions := []kubernetes.APICordonDrainerOption{
+		kubernetes.MaxGracePeriod(*maxGracePeriod),
+		kubernetes.EvictionHeadroom(*evictionHeadroom),
+		kubernetes.WithSkipDrain(*skipDrain),
+		kubernetes.WithSkipDelete(*skipDelete),
+		kubernetes.WithEmptyNodeFastPath(*emptyNodeFastPath),
+		kubernetes.WithNamespaceTerminatingPodsGone(*nsTerminatingGone),
+		kubernetes.WithDeterministicOrder(*deterministicOrder),
+		kubernetes.WithCordonRetry(*cordonRetryPeriod, *cordonRetryTimeout),
+		kubernetes.WithOwnerAwareOrder(*ownerAwareOrder),
+		kubernetes.WithLargestFirstOrder(*largestFirstOrder),
+		kubernetes.WithTopologySpreadOrder(*topologySpreadKey),
+		kubernetes.WithExtendedResourcesLast(*extendedLast),
+		kubernetes.WithSystemPodsLast(*systemNamespaces, *systemLabels),
+		kubernetes.WithEvictFirstAnnotation(*evictFirstKey),
+		kubernetes.WithStartupCostAnnotation(*startupCostKey),
+		kubernetes.WithProtectedPodsAnnotation(*protectedPodsKey),
+		kubernetes.WithMaxTerminatingPods(*maxTerminatingPods),
+		kubernetes.WithEvictionRatePerNode(*evictionRate),
+		kubernetes.WithMinInterPodDelay(*minInterPodDelay),
+		kubernetes.WithPVAwareDrain(*pvAwareDrain, *volumeDetachTimeout),
+		kubernetes.WithServerDryRun(*serverDryRun),
+		kubernetes.WithCordonSettleDelay(*cordonSettleDelay),
+		kubernetes.WithEvictionVerification(*verifyEvictions),
+		kubernetes.WithCordonAnnotations(*cordonReasonKey, *cordonOwnerKey),
+		kubernetes.WithDrainStateConditions(*stateConditions),
+		kubernetes.WithTerminatingPodTimeout(*terminatingTimeout),
+		kubernetes.WithLongRequestGrace(*maxRequestKey, *maxRequestGrace),
+		kubernetes.WithStuckTerminatingForceDelete(*stuckTerminating),
+		kubernetes.WithJobPodPolicy(kubernetes.JobPodPolicy(*jobPodPolicy), *jobPodTimeout),
+		kubernetes.WithAdmissionDeniedPolicy(kubernetes.AdmissionDeniedPolicy(*admissionDenied)),
+		kubernetes.WithEvictionCallTimeout(*evictionCallTimeout),
+		kubernetes.WithNodeGoneCheck(*nodeGoneInterval),
+		kubernetes.WithPodFilter(kubernetes.NewPodFilters(pf...)),
+		kubernetes.WithAPICordonDrainerLogger(log),
+	}
+	if *propagationPolicy != "" {
+		drainerOptions = append(drainerOptions, kubernetes.WithPropagationPolicy(meta.DeletionPropagation(*propagationPolicy)))
+	}
+	if *sidecarLabel != "" {
+		drainerOptions = append(drainerOptions, kubernetes.WithSidecarShutdown(*sidecarLabel, kubernetes.NewHTTPSidecarShutdownHook(*sidecarQuitPort, *sidecarQuitPath)))
+	}
+	if *escalateEvictions {
+		drainerOptions = append(drainerOptions, kubernetes.WithEvictionEscalation(*escalationTimeout))
+	}
+	if *progressKey != "" {
+		drainerOptions = append(drainerOptions, kubernetes.WithProgressReporter(kubernetes.NewAnnotationProgressReporter(cs, *progressKey), *progressInterval))
+	}
+	if *maxNamespaceEvictions > 0 || len(*namespaceEvictions) > 0 {
+		caps, err := parseNamespaceEvictions(*namespaceEvictions)
+		kingpin.FatalIfError(err, "cannot parse namespace eviction caps")
+		drainerOptions = append(drainerOptions, kubernetes.WithNamespaceEvictionConcurrency(*maxNamespaceEvictions, caps))
+	}
+	if len(*graceTiers) > 0 {
+		tiers, err := parseGracePeriodTiers(*graceTiers)
+		kingpin.FatalIfError(err, "cannot parse grace period tiers")
+		drainerOptions = append(drainerOptions, kubernetes.WithGracePeriodTiers(*graceTierLabel, tiers))
+	}
+	if *replacementCheck {
+		drainerOptions = append(drainerOptions, kubernetes.WithReplacementRoomCheck(*replacementTimeout))
+	}
+	if *unreadyFastPath {
+		drainerOptions = append(drainerOptions, kubernetes.WithUnreadyPodFastPath(*unreadyGracePeriod))
+	}
+	if *spreadCheckDelay > 0 {
+		drainerOptions = append(drainerOptions, kubernetes.WithReplacementSpreadCheck(*spreadCheckDelay))
+	}
+	if *crashLoopFastPath {
+		drainerOptions = append(drainerOptions, kubernetes.WithCrashLoopFastPath(*crashLoopGracePeriod))
+	}
+	if *annotateResults {
+		drainerOptions = append(drainerOptions, kubernetes.WithDrainResultAnnotations(kubernetes.DefaultDrainResultAnnotation, kubernetes.DefaultDrainTimeAnnotation))
+	}
+	if *latencyThreshold > 0 {
+		t := kubernetes.NewLatencyThrottle(*drainBuffer, *latencyThreshold, *maxDrainBuffer)
+		scheduleOptions = append(scheduleOptions, kubernetes.WithLatencyThrottle(t))
+		drainerOptions = append(drainerOptions, kubernetes.WithLatencyObserver(t.Observe))
+	}
+
+	var recheckStore kubernetes.NodeStore
+	if *recheckBeforeDrain {
+		recheckStore = kubernetes.NewAPINodeStore(cs)
+	}
+	// Only one scheduler is created, so that periodic metrics such as the
+	// age of the oldest pending schedule are recorded once.
+	recorder := kubernetes.NewEventRecorder(cs)
+	drainerOptions = append(drainerOptions, kubernetes.WithDrainerEventRecorder(recorder))
+	var cordonDrainer kubernetes.CordonDrainer = kubernetes.NewAPICordonDrainer(cs, drainerOptions...)
+	if *dryRun {
+		cordonDrainer = &kubernetes.NoopCordonDrainer{}
+	}
+	drainingHandler := kubernetes.NewDrainingResourceEventHandler(
+		cordonDrainer,
+		recorder,
 		kubernetes.WithLogger(log),
 		kubernetes.WithDrainBuffer(*drainBuffer),
-		kubernetes.WithConditionsFilter(*conditions))
+		kubernetes.WithConditionsFilter(*conditions),
+		kubernetes.WithDrainSchedulesOptions(scheduleOptions...),
+		kubernetes.WithRecheckBeforeDrain(recheckStore))
+	var h cache.ResourceEventHandler = drainingHandler
 
 	if *dryRun {
 		h = cache.FilteringResourceEventHandler{
 			FilterFunc: kubernetes.NewNodeProcessed().Filter,
-			Handler: kubernetes.NewDrainingResourceEventHandler(
-				&kubernetes.NoopCordonDrainer{},
-				kubernetes.NewEventRecorder(cs),
-				kubernetes.WithLogger(log),
-				kubernetes.WithDrainBuffer(*drainBuffer),
-				kubernetes.WithConditionsFilter(*conditions)),
+			Handler:    drainingHandler,
 		}
 	}
 
@@ -205,10 +936,7 @@ func main() {
 
 	nodeLabelFilter = cache.FilteringResourceEventHandler{FilterFunc: nodeLabelFilterFunc, Handler: h}
 
-	nodes := kubernetes.NewNodeWatch(cs, nodeLabelFilter)
-
-	id, err := os.Hostname()
-	kingpin.FatalIfError(err, "cannot get hostname")
+	nodes.AddEventHandler(nodeLabelFilter)
 
 	// use a Go context so we can tell the leaderelection code when we
 	// want to step down
@@ -235,10 +963,34 @@ func main() {
 		RetryPeriod:   *leaderElectionRetryPeriod,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
+				// Recover the drains scheduled before a restart from the
+				// conditions of the nodes, before the watch reschedules them.
+				list, err := cs.CoreV1().Nodes().List(ctx, meta.ListOptions{})
+				kingpin.FatalIfError(err, "cannot list nodes")
+				var scheduled []*core.Node
+				for i := range list.Items {
+					if nodeLabelFilterFunc(&list.Items[i]) {
+						scheduled = append(scheduled, &list.Items[i])
+					}
+				}
+				drainingHandler.ReconcileFromNodes(scheduled)
+
+				// Only the leader holds drain schedules, so only it
+				// serves the schedule API.
+				if api, ok := drainingHandler.ScheduleAPI(); ok && *scheduleAPIListen != "" {
+					go func() {
+						log.Info("schedule API is running", zap.String("listen", *scheduleAPIListen), zap.Bool("readOnly", *scheduleAPIToken == ""))
+						kingpin.FatalIfError(await(&httpRunner{l: *scheduleAPIListen, log: log, api: kubernetes.NewScheduleAPIHandler(api, *scheduleAPIToken)}), "error serving schedule API")
+					}()
+				}
+
 				log.Info("node watcher is running")
 				kingpin.FatalIfError(await(nodes), "error watching")
 			},
 			OnStoppedLeading: func() {
+				if err := pusher.Stop(); err != nil {
+					log.Info("Cannot push final metrics", zap.Error(err))
+				}
 				kingpin.Fatalf("lost leader election")
 			},
 		},
@@ -260,8 +1012,11 @@ func await(rs ...runner) error {
 }
 
 type httpRunner struct {
-	l string
-	h map[string]http.Handler
+	l   string
+	log *zap.Logger
+	h   map[string]http.Handler
+	// api serves the drain schedule API, if set.
+	api http.Handler
 }
 
 func (r *httpRunner) Run(stop <-chan struct{}) {
@@ -269,6 +1024,12 @@ func (r *httpRunner) Run(stop <-chan struct{}) {
 	for path, handler := range r.h {
 		rt.Handler("GET", path, handler)
 	}
+	if r.api != nil {
+		for _, method := range []string{"GET", "POST", "DELETE"} {
+			rt.Handler(method, kubernetes.ScheduleAPIPath, r.api)
+			rt.Handler(method, kubernetes.ScheduleAPIPath+"/*path", r.api)
+		}
+	}
 
 	s := &http.Server{Addr: r.l, Handler: rt}
 	ctx, cancel := context.WithTimeout(context.Background(), 0*time.Second)
@@ -276,6 +1037,103 @@ func (r *httpRunner) Run(stop <-chan struct{}) {
 		<-stop
 		s.Shutdown(ctx) // nolint:errcheck
 	}()
-	s.ListenAndServe() // nolint:errcheck
+	if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		r.log.Error("cannot serve HTTP", zap.String("listen", r.l), zap.Error(err))
+	}
 	cancel()
 }
+
+func parsePostDrainActions(actions []string) (map[string]kubernetes.PostDrainAction, error) {
+	policy := map[string]kubernetes.PostDrainAction{}
+	for _, a := range actions {
+		parts := strings.SplitN(a, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected CONDITION=ACTION, got %q", a)
+		}
+		switch action := kubernetes.PostDrainAction(parts[1]); action {
+		case kubernetes.PostDrainKeepCordoned, kubernetes.PostDrainUncordon:
+			policy[parts[0]] = action
+		default:
+			return nil, fmt.Errorf("unknown post drain action %q", parts[1])
+		}
+	}
+	return policy, nil
+}
+
+func parseGracePeriodTiers(tiers []string) (map[string]time.Duration, error) {
+	parsed := map[string]time.Duration{}
+	for _, t := range tiers {
+		parts := strings.SplitN(t, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected VALUE=DURATION, got %q", t)
+		}
+		grace, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid grace period of tier %q: %v", parts[0], err)
+		}
+		if grace < 0 {
+			return nil, fmt.Errorf("negative grace period of tier %q", parts[0])
+		}
+		parsed[parts[0]] = grace
+	}
+	return parsed, nil
+}
+
+func parseConditionAgeProfiles(profiles []string) ([]kubernetes.ConditionAgeProfile, error) {
+	parsed := make([]kubernetes.ConditionAgeProfile, 0, len(profiles))
+	for _, p := range profiles {
+		spec, expedite := strings.CutSuffix(p, ",expedite")
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected AGE=GRACE[,expedite], got %q", p)
+		}
+		age, err := time.ParseDuration(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition age %q: %v", parts[0], err)
+		}
+		grace, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid grace period of condition age %q: %v", parts[0], err)
+		}
+		if grace < 0 {
+			return nil, fmt.Errorf("negative grace period of condition age %q", parts[0])
+		}
+		parsed = append(parsed, kubernetes.ConditionAgeProfile{MinAge: age, MaxGracePeriod: grace, Expedite: expedite})
+	}
+	return parsed, nil
+}
+
+func parseNamespaceEvictions(caps []string) (map[string]int, error) {
+	parsed := map[string]int{}
+	for _, c := range caps {
+		parts := strings.SplitN(c, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected NAMESPACE=MAX, got %q", c)
+		}
+		max, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid eviction cap of namespace %q: %v", parts[0], err)
+		}
+		if max < 0 {
+			return nil, fmt.Errorf("negative eviction cap of namespace %q", parts[0])
+		}
+		parsed[parts[0]] = max
+	}
+	return parsed, nil
+}
+
+func parseGroupDrainSchedules(schedules []string) (map[string]*kubernetes.CronSchedule, error) {
+	parsed := map[string]*kubernetes.CronSchedule{}
+	for _, s := range schedules {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected GROUP=CRON, got %q", s)
+		}
+		c, err := kubernetes.ParseCronSchedule(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		parsed[parts[0]] = c
+	}
+	return parsed, nil
+}
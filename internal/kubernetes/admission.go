@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// An AdmissionDeniedPolicy is what Drain does with the pods whose eviction is
+// denied by an admission webhook.
+type AdmissionDeniedPolicy string
+
+// Admission denied policies.
+const (
+	// AdmissionDeniedFail fails the drain with an AdmissionDeniedError. This
+	// is the default.
+	AdmissionDeniedFail AdmissionDeniedPolicy = "fail"
+	// AdmissionDeniedSkip leaves the pod on the node and carries on with the
+	// drain, noting the denial in the drain summary.
+	AdmissionDeniedSkip AdmissionDeniedPolicy = "skip"
+)
+
+// WithAdmissionDeniedPolicy configures what Drain does with the pods whose
+// eviction is denied by an admission webhook, e.g. a webhook protecting
+// certain pods.
+func WithAdmissionDeniedPolicy(p AdmissionDeniedPolicy) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.admissionDenied = p
+	}
+}
+
+// isAdmissionDenied returns true if the supplied eviction error is a denial
+// from an admission webhook. The API server reports denials as 403 Forbidden,
+// with a message naming the webhook.
+func isAdmissionDenied(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(statusMessage(err), "admission webhook")
+}
+
+// statusMessage returns the message of the API status of the supplied error,
+// or else the error itself.
+func statusMessage(err error) string {
+	if status, ok := err.(apierrors.APIStatus); ok && status.Status().Message != "" {
+		return status.Status().Message
+	}
+	return err.Error()
+}
+
+// admissionDeniedEviction handles the supplied pod, whose eviction was denied
+// by an admission webhook with the supplied error, per the admission denied
+// policy. Skipped pods are counted as skipped, and their denial noted in the
+// drain summary.
+func (d *APICordonDrainer) admissionDeniedEviction(ctx context.Context, p core.Pod, err error) error {
+	pod := p.GetNamespace() + "/" + p.GetName()
+	msg := statusMessage(err)
+	d.l.Info("Eviction denied by admission webhook", zap.String("pod", pod), zap.String("policy", string(d.admissionDeniedPolicy())), zap.String("message", msg))
+	if d.admissionDeniedPolicy() != AdmissionDeniedSkip {
+		return NewAdmissionDeniedError(pod, msg)
+	}
+	s := drainSummaryFrom(ctx)
+	s.update(func(s *DrainSummary) {
+		s.Skipped++
+		s.Denied = append(s.Denied, pod)
+	})
+	s.warn("%s skipped, eviction denied: %s", pod, msg)
+	return nil
+}
+
+func (d *APICordonDrainer) admissionDeniedPolicy() AdmissionDeniedPolicy {
+	if d.admissionDenied == "" {
+		return AdmissionDeniedFail
+	}
+	return d.admissionDenied
+}
+
+// AdmissionDeniedError is returned by Drain when an admission webhook denied
+// the eviction of a pod.
+type AdmissionDeniedError struct {
+	error
+	// Pod is the namespaced name of the pod whose eviction was denied.
+	Pod string
+}
+
+func NewAdmissionDeniedError(pod, message string) error {
+	return &AdmissionDeniedError{
+		error: fmt.Errorf("eviction of pod %s denied: %s", pod, message),
+		Pod:   pod,
+	}
+}
+
+func IsAdmissionDeniedError(err error) bool {
+	_, ok := errors.Cause(err).(*AdmissionDeniedError)
+	return ok
+}
@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+const webhookDenial = `admission webhook "protect.example.com" denied the request: pod is protected`
+
+// newAdmissionDeniedClientset returns a clientset on which an admission
+// webhook denies the eviction of the protected pod.
+func newAdmissionDeniedClientset() *fake.Clientset {
+	c := fake.NewSimpleClientset(
+		&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+		&core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "protected"}},
+		&core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "web"}},
+	)
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		name := a.(clienttesting.CreateAction).GetObject().(meta.Object).GetName()
+		if name == "protected" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, name, apierrors.NewBadRequest(webhookDenial))
+		}
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+	})
+	return c
+}
+
+func TestDrainAdmissionDenied(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  AdmissionDeniedPolicy
+		wantErr bool
+	}{
+		{name: "Fail", policy: AdmissionDeniedFail, wantErr: true},
+		{name: "Default", wantErr: true},
+		{name: "Skip", policy: AdmissionDeniedSkip},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newAdmissionDeniedClientset()
+			var opts []APICordonDrainerOption
+			if tc.policy != "" {
+				opts = append(opts, WithAdmissionDeniedPolicy(tc.policy))
+			}
+			d := NewAPICordonDrainer(c, opts...)
+			err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if tc.wantErr {
+				if !IsAdmissionDeniedError(err) {
+					t.Fatalf("d.Drain(%v): want AdmissionDeniedError, got %v", nodeName, err)
+				}
+				if !strings.Contains(err.Error(), webhookDenial) {
+					t.Errorf("d.Drain(%v): want the webhook message in %q", nodeName, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+			summary, _ := d.DrainSummary(nodeName)
+			if summary.Evicted != 1 || summary.Skipped != 1 {
+				t.Errorf("summary: want 1 pod evicted and 1 skipped, got %+v", summary)
+			}
+			if len(summary.Warnings) != 1 || !strings.Contains(summary.Warnings[0], webhookDenial) {
+				t.Errorf("summary warnings: want the webhook message, got %v", summary.Warnings)
+			}
+		})
+	}
+}
+
+func TestDrainAdmissionDeniedVerification(t *testing.T) {
+	// The protected pod stays on the node, and in pod listings, but does not
+	// fail the verification of the evictions once skipped.
+	c := newAdmissionDeniedClientset()
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: []core.Pod{{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "protected"}}}}, nil
+	})
+	d := NewAPICordonDrainer(c, WithAdmissionDeniedPolicy(AdmissionDeniedSkip), WithEvictionVerification(100*time.Millisecond))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Errorf("d.Drain(%v): %v", nodeName, err)
+	}
+}
+
+func TestDrainSchedules_AdmissionDeniedResult(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	m := &recordingMetrics{}
+	recorder := record.NewFakeRecorder(100)
+	scheduler := NewDrainSchedules(NewAPICordonDrainer(newAdmissionDeniedClientset()), recorder, 0, zap.NewNop(), WithMetricsRecorder(m)).(*DrainSchedules)
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	if want := []string{nodeName + "=" + tagResultAdmissionDenied}; len(m.drained) != 1 || m.drained[0] != want[0] {
+		t.Errorf("NodeDrained(): want %v, got %v", want, m.drained)
+	}
+	var failed string
+	for len(recorder.Events) > 0 {
+		if e := <-recorder.Events; strings.HasPrefix(e, "Warning DrainFailed ") {
+			failed = e
+		}
+	}
+	if !strings.Contains(failed, webhookDenial) {
+		t.Errorf("DrainFailed event: want the webhook message, got %q", failed)
+	}
+}
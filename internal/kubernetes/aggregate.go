@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/reference"
+)
+
+// An aggregateRecorder mirrors the events recorded against any object to a
+// single aggregate object, prefixing their messages with the object they
+// concern, so that the events of the aggregate object form a timeline of all
+// drains.
+type aggregateRecorder struct {
+	record.EventRecorder
+	aggregate *core.ObjectReference
+}
+
+// WithAggregateEvents mirrors the events recorded about schedules, which are
+// recorded against their nodes, to the supplied object, e.g. a Lease or the
+// pod of draino.
+func WithAggregateEvents(aggregate *core.ObjectReference) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if aggregate == nil {
+			return
+		}
+		d.eventRecorder = &aggregateRecorder{EventRecorder: d.eventRecorder, aggregate: aggregate}
+	}
+}
+
+func (r *aggregateRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, message)
+	r.EventRecorder.Event(r.aggregate, eventtype, reason, r.prefix(object)+message)
+}
+
+func (r *aggregateRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *aggregateRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+	r.EventRecorder.AnnotatedEventf(r.aggregate, annotations, eventtype, reason, "%s", r.prefix(object)+message)
+}
+
+// prefix returns the prefix of the messages mirrored from the events of the
+// supplied object, e.g. "Node a: ".
+func (r *aggregateRecorder) prefix(object runtime.Object) string {
+	if ref, ok := object.(*core.ObjectReference); ok {
+		return fmt.Sprintf("%s %s: ", ref.Kind, ref.Name)
+	}
+	ref, err := reference.GetReference(scheme.Scheme, object)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s: ", ref.Kind, ref.Name)
+}
+
+// ResolveObjectReference returns a reference to the named object of the
+// supplied kind, either Lease or Pod, in the supplied namespace.
+func ResolveObjectReference(c kubernetes.Interface, kind, namespace, name string) (*core.ObjectReference, error) {
+	var (
+		object runtime.Object
+		err    error
+	)
+	switch strings.ToLower(kind) {
+	case "lease":
+		object, err = c.CoordinationV1().Leases(namespace).Get(context.Background(), name, meta.GetOptions{})
+	case "pod":
+		object, err = c.CoreV1().Pods(namespace).Get(context.Background(), name, meta.GetOptions{})
+	default:
+		return nil, errors.Errorf("unsupported kind %q, want Lease or Pod", kind)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s %s/%s", kind, namespace, name)
+	}
+	return reference.GetReference(scheme.Scheme, object)
+}
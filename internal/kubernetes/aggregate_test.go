@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	coordination "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+type recordedEvent struct {
+	object  string
+	reason  string
+	message string
+}
+
+// objectRecorder records the object each event is recorded against.
+type objectRecorder struct {
+	record.FakeRecorder
+	sync.Mutex
+	events []recordedEvent
+}
+
+func (r *objectRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.Lock()
+	defer r.Unlock()
+	ref := object.(*v1.ObjectReference)
+	r.events = append(r.events, recordedEvent{object: ref.Kind + "/" + ref.Name, reason: reason, message: message})
+}
+
+func (r *objectRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *objectRecorder) recorded(object string) []recordedEvent {
+	r.Lock()
+	defer r.Unlock()
+	var events []recordedEvent
+	for _, e := range r.events {
+		if e.object == object {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestDrainSchedules_AggregateEvents(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder := &objectRecorder{}
+	aggregate := &v1.ObjectReference{Kind: "Lease", Namespace: "kube-system", Name: "draino"}
+	scheduler := NewDrainSchedules(newRecordingDrainer(), recorder, time.Minute, zap.NewNop(),
+		WithDispatcher(NewManualDispatcher(start)),
+		WithAggregateEvents(aggregate),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(a) error = %v", err)
+	}
+	scheduler.runDrain(node, scheduler.schedules["a"])
+
+	nodeEvents := recorder.recorded("Node/a")
+	mirrored := recorder.recorded("Lease/draino")
+	if len(nodeEvents) == 0 {
+		t.Fatal("node events: want some, got none")
+	}
+	if len(mirrored) != len(nodeEvents) {
+		t.Fatalf("mirrored events: want %d, got %d", len(nodeEvents), len(mirrored))
+	}
+	for i, e := range nodeEvents {
+		if want := "Node a: " + e.message; mirrored[i].message != want || mirrored[i].reason != e.reason {
+			t.Errorf("mirrored event %d: want %s %q, got %s %q", i, e.reason, want, mirrored[i].reason, mirrored[i].message)
+		}
+	}
+}
+
+func TestResolveObjectReference(t *testing.T) {
+	c := fake.NewSimpleClientset(&coordination.Lease{ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "draino", UID: "uid"}})
+	ref, err := ResolveObjectReference(c, "lease", "kube-system", "draino")
+	if err != nil {
+		t.Fatalf("ResolveObjectReference() error = %v", err)
+	}
+	if ref.Kind != "Lease" || ref.Namespace != "kube-system" || ref.Name != "draino" || ref.UID != "uid" {
+		t.Errorf("ResolveObjectReference(): got %+v", ref)
+	}
+	if _, err := ResolveObjectReference(c, "pod", "kube-system", "draino"); err == nil {
+		t.Error("ResolveObjectReference(pod): want error, got nil")
+	}
+	if _, err := ResolveObjectReference(c, "configmap", "kube-system", "draino"); err == nil {
+		t.Error("ResolveObjectReference(configmap): want error, got nil")
+	}
+}
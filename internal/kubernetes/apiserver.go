@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// ScheduleAPIPath is the path under which NewScheduleAPIHandler serves the
+// drain schedules.
+const ScheduleAPIPath = "/schedules"
+
+// A ScheduleAPI exposes the drain schedules of nodes.
+type ScheduleAPI interface {
+	// ListSchedules describes the drain schedules of all nodes.
+	ListSchedules() []ScheduleInfo
+	// ScheduleInfo describes the drain schedule of the named node, if any.
+	ScheduleInfo(name string) (ScheduleInfo, bool)
+	DeleteSchedule(name string)
+	// Expedite fires the pending drain of the named node now.
+	Expedite(name string) error
+}
+
+// NewScheduleAPIHandler returns an HTTP handler serving the drain schedules of
+// the supplied ScheduleAPI as JSON:
+//
+//   - GET /schedules lists the schedules of all nodes.
+//   - GET /schedules/{node} describes the schedule of a node.
+//   - DELETE /schedules/{node} deletes the schedule of a node.
+//   - POST /schedules/{node}/expedite fires the pending drain of a node now.
+//
+// Requests for nodes without a schedule fail with 404 Not Found, and requests
+// to expedite drains that are not pending with 409 Conflict. Reads are not
+// authenticated. Deleting and expediting schedules are only served if the
+// supplied token is not empty, to requests bearing it as an Authorization
+// bearer token; others fail with 401 Unauthorized.
+func NewScheduleAPIHandler(api ScheduleAPI, token string) http.Handler {
+	rt := httprouter.New()
+	rt.GET(ScheduleAPIPath, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		writeJSON(w, http.StatusOK, api.ListSchedules())
+	})
+	rt.GET(ScheduleAPIPath+"/:node", func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		info, ok := api.ScheduleInfo(p.ByName("node"))
+		if !ok {
+			http.Error(w, "node has no drain schedule", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	})
+	if token == "" {
+		return rt
+	}
+	rt.DELETE(ScheduleAPIPath+"/:node", authenticated(token, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if _, ok := api.ScheduleInfo(p.ByName("node")); !ok {
+			http.Error(w, "node has no drain schedule", http.StatusNotFound)
+			return
+		}
+		api.DeleteSchedule(p.ByName("node"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rt.POST(ScheduleAPIPath+"/:node/expedite", authenticated(token, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if _, ok := api.ScheduleInfo(p.ByName("node")); !ok {
+			http.Error(w, "node has no drain schedule", http.StatusNotFound)
+			return
+		}
+		if err := api.Expedite(p.ByName("node")); err != nil {
+			code := http.StatusInternalServerError
+			if IsNotPendingError(err) {
+				code = http.StatusConflict
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		info, _ := api.ScheduleInfo(p.ByName("node"))
+		writeJSON(w, http.StatusOK, info)
+	}))
+	return rt
+}
+
+// authenticated wraps the supplied handle so that it only serves requests
+// bearing the supplied token.
+func authenticated(token string, h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h(w, r, p)
+	}
+}
+
+// writeJSON writes the supplied value as the JSON body of a response with the
+// supplied status code.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v) // nolint:errcheck,gosec
+}
@@ -0,0 +1,211 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// fakeScheduleAPI is a ScheduleAPI over a fixed set of schedules.
+type fakeScheduleAPI struct {
+	schedules map[string]ScheduleInfo
+	expedited []string
+	deleted   []string
+}
+
+func (f *fakeScheduleAPI) ListSchedules() []ScheduleInfo {
+	infos := make([]ScheduleInfo, 0, len(f.schedules))
+	for _, info := range f.schedules {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Node < infos[j].Node })
+	return infos
+}
+
+func (f *fakeScheduleAPI) ScheduleInfo(name string) (ScheduleInfo, bool) {
+	info, ok := f.schedules[name]
+	return info, ok
+}
+
+func (f *fakeScheduleAPI) DeleteSchedule(name string) {
+	f.deleted = append(f.deleted, name)
+	delete(f.schedules, name)
+}
+
+func (f *fakeScheduleAPI) Expedite(name string) error {
+	switch f.schedules[name].State {
+	case DrainStateScheduled:
+	case DrainStateFailed:
+		return NewNotPendingError(name)
+	default:
+		return errors.New("myerr")
+	}
+	f.expedited = append(f.expedited, name)
+	return nil
+}
+
+func TestScheduleAPIHandler(t *testing.T) {
+	cases := []struct {
+		name          string
+		method        string
+		path          string
+		wantCode      int
+		wantNodes     []string
+		wantDeleted   []string
+		token         string
+		wantExpedited []string
+	}{
+		{
+			name:      "List",
+			method:    http.MethodGet,
+			path:      "/schedules",
+			wantCode:  http.StatusOK,
+			wantNodes: []string{"a", "b", "c"},
+		},
+		{
+			name:      "Get",
+			method:    http.MethodGet,
+			path:      "/schedules/b",
+			wantCode:  http.StatusOK,
+			wantNodes: []string{"b"},
+		},
+		{
+			name:     "GetMissing",
+			method:   http.MethodGet,
+			path:     "/schedules/missing",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:        "Delete",
+			token:       "mytoken",
+			method:      http.MethodDelete,
+			path:        "/schedules/a",
+			wantCode:    http.StatusNoContent,
+			wantDeleted: []string{"a"},
+		},
+		{
+			name:     "DeleteMissing",
+			token:    "mytoken",
+			method:   http.MethodDelete,
+			path:     "/schedules/missing",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:          "Expedite",
+			token:         "mytoken",
+			method:        http.MethodPost,
+			path:          "/schedules/a/expedite",
+			wantCode:      http.StatusOK,
+			wantNodes:     []string{"a"},
+			wantExpedited: []string{"a"},
+		},
+		{
+			name:     "ExpediteMissing",
+			token:    "mytoken",
+			method:   http.MethodPost,
+			path:     "/schedules/missing/expedite",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "ExpediteNotPending",
+			token:    "mytoken",
+			method:   http.MethodPost,
+			path:     "/schedules/b/expedite",
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "ExpediteError",
+			token:    "mytoken",
+			method:   http.MethodPost,
+			path:     "/schedules/c/expedite",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "DeleteUnauthenticated",
+			method:   http.MethodDelete,
+			path:     "/schedules/a",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "ExpediteWrongToken",
+			method:   http.MethodPost,
+			path:     "/schedules/a/expedite",
+			token:    "othertoken",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "MethodNotAllowed",
+			method:   http.MethodPut,
+			path:     "/schedules/a",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			api := &fakeScheduleAPI{schedules: map[string]ScheduleInfo{
+				"a": {Node: "a", State: DrainStateScheduled},
+				"b": {Node: "b", State: DrainStateFailed, FailureReason: "myerr"},
+				"c": {Node: "c", State: DrainStateInProgress},
+			}}
+			rsp := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			NewScheduleAPIHandler(api, "mytoken").ServeHTTP(rsp, req)
+
+			if rsp.Code != tc.wantCode {
+				t.Fatalf("%s %s: want status %d, got %d: %s", tc.method, tc.path, tc.wantCode, rsp.Code, rsp.Body)
+			}
+			if tc.wantNodes != nil {
+				var infos []ScheduleInfo
+				if len(tc.wantNodes) == 1 && tc.path != ScheduleAPIPath {
+					var info ScheduleInfo
+					if err := json.NewDecoder(rsp.Body).Decode(&info); err != nil {
+						t.Fatalf("cannot decode schedule: %v", err)
+					}
+					infos = append(infos, info)
+				} else if err := json.NewDecoder(rsp.Body).Decode(&infos); err != nil {
+					t.Fatalf("cannot decode schedules: %v", err)
+				}
+				var nodes []string
+				for _, info := range infos {
+					nodes = append(nodes, info.Node)
+				}
+				if !reflect.DeepEqual(nodes, tc.wantNodes) {
+					t.Errorf("nodes: want %v, got %v", tc.wantNodes, nodes)
+				}
+			}
+			if !reflect.DeepEqual(api.deleted, tc.wantDeleted) {
+				t.Errorf("DeleteSchedule(): want %v, got %v", tc.wantDeleted, api.deleted)
+			}
+			if !reflect.DeepEqual(api.expedited, tc.wantExpedited) {
+				t.Errorf("Expedite(): want %v, got %v", tc.wantExpedited, api.expedited)
+			}
+		})
+	}
+}
+
+func TestScheduleAPIHandlerReadOnly(t *testing.T) {
+	for _, method := range []string{http.MethodDelete, http.MethodPost} {
+		api := &fakeScheduleAPI{schedules: map[string]ScheduleInfo{"a": {Node: "a", State: DrainStateScheduled}}}
+		path := "/schedules/a"
+		if method == http.MethodPost {
+			path += "/expedite"
+		}
+		rsp := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer ")
+		NewScheduleAPIHandler(api, "").ServeHTTP(rsp, req)
+		if rsp.Code != http.StatusMethodNotAllowed && rsp.Code != http.StatusNotFound {
+			t.Errorf("%s %s: want the request refused, got status %d", method, path, rsp.Code)
+		}
+		if len(api.deleted) > 0 || len(api.expedited) > 0 {
+			t.Errorf("%s %s: read only API mutated schedules", method, path)
+		}
+	}
+}
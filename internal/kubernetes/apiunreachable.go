@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+const (
+	// DefaultAPIUnreachableBackoff is how long a drain is first deferred
+	// when the API server is unreachable.
+	DefaultAPIUnreachableBackoff = 10 * time.Second
+	// DefaultMaxAPIUnreachableBackoff is the longest a drain is deferred at
+	// once while the API server is unreachable.
+	DefaultMaxAPIUnreachableBackoff = 5 * time.Minute
+
+	deferralReasonAPIUnreachable = "api-unreachable"
+)
+
+// WithAPIUnreachableBackoff defers, rather than fails, the drains that fail
+// because the API server cannot be reached, e.g. during a control plane
+// outage, so that a blip does not fail every drain that fires meanwhile. Such
+// drains do not count as attempts, and are deferred by the supplied backoff,
+// which doubles each time the API server is still unreachable, up to the
+// supplied maximum, DefaultMaxAPIUnreachableBackoff if not positive, until
+// connectivity returns. Zero backoff disables the deferral.
+func WithAPIUnreachableBackoff(backoff, max time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if max <= 0 {
+			max = DefaultMaxAPIUnreachableBackoff
+		}
+		d.unreachableBackoff = backoff
+		d.maxUnreachableBackoff = max
+	}
+}
+
+// isAPIUnreachable returns true if the supplied error is a failure to reach
+// the API server, as opposed to an error returned by the API server.
+func isAPIUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// deferUnreachable returns true if the drain of the supplied schedule is
+// deferred because it failed with the supplied error as the API server is
+// unreachable. The attempt is not counted.
+func (d *DrainSchedules) deferUnreachable(node *core.Node, sched *schedule, err error) bool {
+	if d.unreachableBackoff <= 0 || !isAPIUnreachable(err) {
+		d.Lock()
+		sched.unreachable = 0
+		d.Unlock()
+		return false
+	}
+	d.Lock()
+	backoff := d.unreachableBackoff << uint(sched.unreachable)
+	if backoff >= d.maxUnreachableBackoff {
+		backoff = d.maxUnreachableBackoff
+	} else {
+		sched.unreachable++
+	}
+	sched.attempt--
+	sched.timer.Reset(backoff)
+	d.Unlock()
+
+	d.logger.Info("Deferring drain, the API server is unreachable", zap.String("node", node.GetName()), zap.String("drainID", sched.drainID), zap.Duration("backoff", backoff), zap.Error(err))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "The API server is unreachable, retrying in %s: %v", backoff, err)
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonAPIUnreachable))
+	d.recordDeferral(node.GetName(), deferralReasonAPIUnreachable)
+	if err := d.markDrain(node, DrainStateScheduled, sched.when, time.Time{}, ""); err != nil {
+		d.logger.Info("Failed to mark drain scheduled while the API server is unreachable", zap.String("node", node.GetName()), zap.Error(err))
+	}
+	d.setDrainState(node, DrainStateScheduled, d.now().Add(backoff), time.Time{}, "")
+	return true
+}
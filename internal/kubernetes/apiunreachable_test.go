@@ -0,0 +1,119 @@
+package kubernetes
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+// unreachableDrainer fails to drain while the API server is unreachable.
+type unreachableDrainer struct {
+	*recordingDrainer
+	failures int
+}
+
+func (d *unreachableDrainer) Drain(n *v1.Node) error {
+	if d.failures > 0 {
+		d.failures--
+		err := &url.Error{Op: "Get", URL: "https://10.0.0.1/api/v1/pods", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+		return pkgerrors.Wrap(err, "cannot list pods")
+	}
+	return d.recordingDrainer.Drain(n)
+}
+
+func TestIsAPIUnreachable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Nil"},
+		{name: "Logical", err: errors.New("myerr")},
+		{name: "Forbidden", err: apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "a", errors.New("denied"))},
+		{name: "ConnectionRefused", err: pkgerrors.Wrap(syscall.ECONNREFUSED, "cannot evict pod"), want: true},
+		{name: "ConnectionReset", err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, want: true},
+		{name: "Transport", err: &url.Error{Op: "Get", URL: "https://10.0.0.1", Err: errors.New("i/o timeout")}, want: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isAPIUnreachable(tc.err); got != tc.want {
+				t.Errorf("isAPIUnreachable(%v): want %v, got %v", tc.err, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_APIUnreachable(t *testing.T) {
+	drainer := &unreachableDrainer{recordingDrainer: newRecordingDrainer(), failures: 2}
+	m := &deferralMetrics{}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithMetricsRecorder(m),
+		WithDrainRetries(1, time.Minute),
+		WithAPIUnreachableBackoff(time.Minute, time.Hour),
+	).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+
+	// Drains failing while the API server is unreachable are deferred, not
+	// failed, however many attempts are allowed.
+	for i := 0; i < 2; i++ {
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+		if _, failed := scheduler.HasSchedule(nodeName); failed {
+			t.Fatalf("attempt %d: want drain deferred, got failed", i+1)
+		}
+		if sched.attempt != 0 {
+			t.Errorf("attempt %d: want no attempt counted, got %d", i+1, sched.attempt)
+		}
+	}
+	if want := []string{nodeName + "=" + deferralReasonAPIUnreachable, nodeName + "=" + deferralReasonAPIUnreachable}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+	if sched.unreachable != 2 {
+		t.Errorf("unreachable: want 2, got %d", sched.unreachable)
+	}
+
+	// The drain completes once connectivity returns.
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+	sched.timer.Stop()
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{nodeName}) {
+		t.Fatalf("drained: want %v, got %v", []string{nodeName}, got)
+	}
+	if _, failed := scheduler.HasSchedule(nodeName); failed {
+		t.Error("HasSchedule(): want drain succeeded, got failed")
+	}
+	if sched.unreachable != 0 {
+		t.Errorf("unreachable: want reset, got %d", sched.unreachable)
+	}
+}
+
+func TestDrainSchedules_APIUnreachableDisabled(t *testing.T) {
+	drainer := &unreachableDrainer{recordingDrainer: newRecordingDrainer(), failures: 1}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+	if _, failed := scheduler.HasSchedule(nodeName); !failed {
+		t.Error("HasSchedule(): want drain failed, got not failed")
+	}
+}
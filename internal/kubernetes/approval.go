@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultApprovalAnnotation is the annotation of the nodes checked by
+	// an AnnotationDrainApprover. The drain of a node is approved once it is
+	// true.
+	DefaultApprovalAnnotation = "draino.kubernetes.io/approved"
+
+	deferralReasonApproval = "awaiting-approval"
+)
+
+// A DrainApprover approves the drains of nodes when they fire.
+type DrainApprover interface {
+	// Approve returns true if the drain of the supplied node may proceed.
+	Approve(ctx context.Context, n *core.Node) (approved bool, err error)
+}
+
+// An AnnotationDrainApprover approves the drains of nodes whose annotation is
+// true, as returned by its NodeStore.
+type AnnotationDrainApprover struct {
+	store      NodeStore
+	annotation string
+}
+
+// NewAnnotationDrainApprover returns a DrainApprover that approves the drains of
+// nodes whose supplied annotation is true. The current state of nodes is read
+// from the supplied store, so that approvals granted after a drain was
+// scheduled are honoured.
+func NewAnnotationDrainApprover(s NodeStore, annotation string) *AnnotationDrainApprover {
+	return &AnnotationDrainApprover{store: s, annotation: annotation}
+}
+
+// Approve returns true if the annotation of the supplied node is true.
+func (a *AnnotationDrainApprover) Approve(_ context.Context, n *core.Node) (bool, error) {
+	fresh, err := a.store.Get(n.GetName())
+	if err != nil {
+		return false, err
+	}
+	approved, _ := strconv.ParseBool(fresh.GetAnnotations()[a.annotation])
+	return approved, nil
+}
+
+// WithDrainApprover requires the drains of nodes to be approved by the supplied
+// DrainApprover when they fire. Drains that are not approved, including those
+// whose approval cannot be determined, are deferred by
+// DefaultDrainDeferralPeriod and checked again. Unlike other deferrals they are
+// never force fired once the maximum deferral elapses.
+func WithDrainApprover(a DrainApprover) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.approver = a
+	}
+}
+
+// deferApproval returns true if the drain of the supplied schedule is deferred
+// because it is not approved.
+func (d *DrainSchedules) deferApproval(node *core.Node, sched *schedule) bool {
+	if d.approver == nil {
+		return false
+	}
+	log := d.logger.With(zap.String("node", node.GetName()))
+	approved, err := d.approver.Approve(sched.spanContext(), node)
+	if err != nil {
+		log.Info("Cannot determine drain approval, assuming not approved", zap.Error(err))
+	}
+	if approved {
+		return false
+	}
+	log.Info("Deferring drain, awaiting approval")
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Drain is awaiting approval")
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonApproval))
+	d.recordDeferral(node.GetName(), deferralReasonApproval)
+	d.Lock()
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	d.Unlock()
+	return true
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_DrainApprover(t *testing.T) {
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	store := nodeStore{nodeName: node}
+	m := &deferralMetrics{}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDrainApprover(NewAnnotationDrainApprover(store, DefaultApprovalAnnotation)), WithMetricsRecorder(m)).(*DrainSchedules)
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+
+	scheduler.runDrain(node, sched)
+	sched.timer.Stop()
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("drained %v before the drain was approved", got)
+	}
+	if want := []string{nodeName + "=" + deferralReasonApproval}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+
+	// The approval is read from the current state of the node.
+	store[nodeName] = &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        nodeName,
+		Annotations: map[string]string{DefaultApprovalAnnotation: "true"},
+	}}
+	scheduler.runDrain(node, sched)
+	if got, want := drainer.nodes(), []string{nodeName}; !reflect.DeepEqual(got, want) {
+		t.Errorf("drained nodes once approved: want %v, got %v", want, got)
+	}
+}
+
+func TestAnnotationDrainApprover(t *testing.T) {
+	annotated := func(v string) *v1.Node {
+		return &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{DefaultApprovalAnnotation: v}}}
+	}
+	cases := []struct {
+		name    string
+		node    *v1.Node
+		want    bool
+		wantErr bool
+	}{
+		{name: "Approved", node: annotated("true"), want: true},
+		{name: "Rejected", node: annotated("false")},
+		{name: "Invalid", node: annotated("soon")},
+		{name: "NotAnnotated", node: &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}},
+		{name: "NoNode", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := nodeStore{}
+			if tc.node != nil {
+				store[nodeName] = tc.node
+			}
+			a := NewAnnotationDrainApprover(store, DefaultApprovalAnnotation)
+			got, err := a.Approve(context.Background(), &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Approve(): want error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Approve(): want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultScaleDownAnnotation is the annotation of the Lease watched by
+	// NewLeaseScaleDownSignal. The cluster autoscaler is scaling down while
+	// it is true.
+	DefaultScaleDownAnnotation = "draino.kubernetes.io/scale-down-active"
+
+	deferralReasonScaleDown = "autoscaler-scale-down"
+)
+
+// A ScaleDownSignal returns true while the cluster autoscaler is scaling down.
+type ScaleDownSignal func() bool
+
+// NewLeaseScaleDownSignal returns a ScaleDownSignal that is true while the
+// supplied annotation of the supplied Lease is true. It is false if the Lease
+// cannot be read, so that an unavailable signal never blocks drains.
+func NewLeaseScaleDownSignal(c kubernetes.Interface, namespace, name, annotation string, l *zap.Logger) ScaleDownSignal {
+	return func() bool {
+		lease, err := c.CoordinationV1().Leases(namespace).Get(context.Background(), name, meta.GetOptions{})
+		if err != nil {
+			l.Info("Cannot get scale down Lease, assuming no scale down", zap.String("lease", namespace+"/"+name), zap.Error(err))
+			return false
+		}
+		active, _ := strconv.ParseBool(lease.GetAnnotations()[annotation])
+		return active
+	}
+}
+
+// WithScaleDownGate defers drains by DefaultDrainDeferralPeriod while the
+// supplied signal reports that the cluster autoscaler is scaling down, so that
+// both do not evict pods at the same time. Deferred drains fire regardless
+// once the maximum deferral elapses.
+func WithScaleDownGate(s ScaleDownSignal) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.scaleDownActive = s
+	}
+}
+
+// deferScaleDown returns true if the drain of the supplied schedule is deferred
+// because the cluster autoscaler is scaling down.
+func (d *DrainSchedules) deferScaleDown(node *core.Node, sched *schedule) bool {
+	if d.scaleDownActive == nil || !d.scaleDownActive() {
+		return false
+	}
+	log := d.logger.With(zap.String("node", node.GetName()))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.Lock()
+	defer d.Unlock()
+	if d.maxDeferral > 0 && !d.now().Before(sched.created.Add(d.maxDeferral)) {
+		log.Info("Force firing drain deferred for too long", zap.String("reason", deferralReasonScaleDown))
+		d.metrics.DrainForceFired(node.GetName())
+		sched.addSpanEvent("force fired", attribute.String("reason", deferralReasonScaleDown))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for the cluster autoscaler scale down", sched.created.Format(time.RFC3339))
+		return false
+	}
+	log.Info("Deferring drain, cluster autoscaler is scaling down")
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Cluster autoscaler is scaling down")
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonScaleDown))
+	d.recordDeferral(node.GetName(), deferralReasonScaleDown)
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	return true
+}
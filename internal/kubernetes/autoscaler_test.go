@@ -0,0 +1,83 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	coordination "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+type deferralMetrics struct {
+	OpenCensusMetricsRecorder
+	deferred []string
+}
+
+func (m *deferralMetrics) DrainDeferred(node, reason string) {
+	m.deferred = append(m.deferred, node+"="+reason)
+}
+
+func TestDrainSchedules_ScaleDownGate(t *testing.T) {
+	scalingDown := true
+	m := &deferralMetrics{}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithScaleDownGate(func() bool { return scalingDown }), WithMetricsRecorder(m)).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+
+	scheduler.runDrain(node, sched)
+	sched.timer.Stop()
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("drained %v while the cluster autoscaler was scaling down", got)
+	}
+	if want := []string{nodeName + "=" + deferralReasonScaleDown}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+
+	scalingDown = false
+	scheduler.runDrain(node, sched)
+	if got, want := drainer.nodes(), []string{nodeName}; !reflect.DeepEqual(got, want) {
+		t.Errorf("drained nodes once the scale down ended: want %v, got %v", want, got)
+	}
+}
+
+func TestLeaseScaleDownSignal(t *testing.T) {
+	lease := func(active string) *coordination.Lease {
+		return &coordination.Lease{ObjectMeta: meta.ObjectMeta{
+			Namespace:   "kube-system",
+			Name:        "cluster-autoscaler",
+			Annotations: map[string]string{DefaultScaleDownAnnotation: active},
+		}}
+	}
+	cases := []struct {
+		name  string
+		lease *coordination.Lease
+		want  bool
+	}{
+		{name: "Active", lease: lease("true"), want: true},
+		{name: "Inactive", lease: lease("false")},
+		{name: "Invalid", lease: lease("maybe")},
+		{name: "NoLease"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset()
+			if tc.lease != nil {
+				c = fake.NewSimpleClientset(tc.lease)
+			}
+			active := NewLeaseScaleDownSignal(c, "kube-system", "cluster-autoscaler", DefaultScaleDownAnnotation, zap.NewNop())
+			if got := active(); got != tc.want {
+				t.Errorf("ScaleDownSignal(): want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
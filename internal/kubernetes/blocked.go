@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"sync"
+)
+
+// The reasons drains are held back for that are not recorded by
+// MetricsRecorder.DrainDeferred, only reported as the constraint drains are
+// blocked on. They share the taxonomy of the deferral reasons declared with the
+// gates that defer drains.
+const (
+	deferralReasonDependency          = "dependency"
+	deferralReasonGroupPaused         = "group-paused"
+	deferralReasonGroupCooldown       = "group-cooldown"
+	deferralReasonGroupSchedule       = "group-schedule"
+	deferralReasonZoneLimit           = "zone-limit"
+	deferralReasonInfeasible          = "infeasible"
+	deferralReasonPodBudget           = "pod-budget"
+	deferralReasonReplacementCapacity = "replacement-capacity"
+	deferralReasonRateBudget          = "rate-budget"
+	deferralReasonStageLimit          = "stage-limit"
+	deferralReasonUnsafePods          = "unsafe-pods"
+)
+
+// blockedDrains tracks the constraint each pending drain is currently blocked
+// on, by node name, so that the number of drains blocked on each constraint can
+// be reported. The constraint a drain no longer is blocked on is reported again
+// as it changes.
+type blockedDrains struct {
+	sync.Mutex
+	by map[string]string
+}
+
+// recordDeferral records the drain of the named node deferred for the supplied
+// reason, which is the constraint it is now blocked on.
+func (d *DrainSchedules) recordDeferral(node, reason string) {
+	d.metrics.DrainDeferred(node, reason)
+	d.blockDrain(node, reason)
+}
+
+// blockDrain records the drain of the named node blocked on the supplied
+// constraint, replacing the constraint it was blocked on, if any.
+func (d *DrainSchedules) blockDrain(node, constraint string) {
+	b := d.blocked
+	b.Lock()
+	defer b.Unlock()
+	if b.by[node] == constraint {
+		return
+	}
+	previous, ok := b.by[node]
+	b.by[node] = constraint
+	if ok {
+		d.publishBlockedLocked(previous)
+	}
+	d.publishBlockedLocked(constraint)
+}
+
+// unblockDrain records the drain of the named node no longer blocked, because
+// it proceeds or its schedule was deleted.
+func (d *DrainSchedules) unblockDrain(node string) {
+	b := d.blocked
+	b.Lock()
+	defer b.Unlock()
+	constraint, ok := b.by[node]
+	if !ok {
+		return
+	}
+	delete(b.by, node)
+	d.publishBlockedLocked(constraint)
+}
+
+// publishBlockedLocked reports the number of drains blocked on the supplied
+// constraint. It must be called with the lock of the blocked drains held.
+func (d *DrainSchedules) publishBlockedLocked(constraint string) {
+	n := 0
+	for _, c := range d.blocked.by {
+		if c == constraint {
+			n++
+		}
+	}
+	d.metrics.DrainsBlocked(constraint, n)
+}
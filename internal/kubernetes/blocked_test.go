@@ -0,0 +1,145 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type blockedMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	blocked map[string]int
+}
+
+func (m *blockedMetrics) DrainsBlocked(constraint string, count int) {
+	m.Lock()
+	defer m.Unlock()
+	m.blocked[constraint] = count
+}
+
+func (m *blockedMetrics) get() map[string]int {
+	m.Lock()
+	defer m.Unlock()
+	got := map[string]int{}
+	for c, n := range m.blocked {
+		got[c] = n
+	}
+	return got
+}
+
+func TestDrainSchedules_DrainsBlocked(t *testing.T) {
+	healthy := false
+	m := &blockedMetrics{blocked: map[string]int{}}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithHealthGate(func(context.Context) (bool, error) { return healthy, nil }, 0, 0),
+		WithMetricsRecorder(m),
+	).(*DrainSchedules)
+	a := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	b := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "b"}}
+	for _, n := range []*v1.Node{a, b} {
+		if _, err := scheduler.Schedule(n); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", n.GetName(), err)
+		}
+		scheduler.schedules[n.GetName()].timer.Stop()
+	}
+
+	// Deferring drains for a constraint counts them as blocked on it.
+	scheduler.runDrain(a, scheduler.schedules["a"])
+	scheduler.runDrain(b, scheduler.schedules["b"])
+	if want := map[string]int{deferralReasonUnhealthy: 2}; !reflect.DeepEqual(m.get(), want) {
+		t.Errorf("DrainsBlocked: want %v, got %v", want, m.get())
+	}
+
+	// Drains are no longer blocked once their schedule is deleted, or they
+	// proceed.
+	scheduler.DeleteSchedule("b")
+	if want := map[string]int{deferralReasonUnhealthy: 1}; !reflect.DeepEqual(m.get(), want) {
+		t.Errorf("DrainsBlocked after deleting a schedule: want %v, got %v", want, m.get())
+	}
+	healthy = true
+	sched := scheduler.schedules["a"]
+	sched.timer.Stop()
+	scheduler.runDrain(a, sched)
+	if want := map[string]int{deferralReasonUnhealthy: 0}; !reflect.DeepEqual(m.get(), want) {
+		t.Errorf("DrainsBlocked once the drain proceeded: want %v, got %v", want, m.get())
+	}
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("drained nodes: want [a], got %v", got)
+	}
+}
+
+func TestDrainSchedules_DrainsBlockedByZoneLimit(t *testing.T) {
+	m := &blockedMetrics{blocked: map[string]int{}}
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithZoneDrainLimit(1, time.Hour),
+		WithMetricsRecorder(m),
+	).(*DrainSchedules)
+	for _, name := range []string{"a", "b"} {
+		n := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: "z1"}}}
+		if _, err := scheduler.Schedule(n); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		scheduler.schedules[name].timer.Stop()
+	}
+
+	// The second drain of the zone waits for the zone drain limit.
+	if want := map[string]int{deferralReasonZoneLimit: 1}; !reflect.DeepEqual(m.get(), want) {
+		t.Errorf("DrainsBlocked: want %v, got %v", want, m.get())
+	}
+	scheduler.DeleteSchedule("b")
+	if want := map[string]int{deferralReasonZoneLimit: 0}; !reflect.DeepEqual(m.get(), want) {
+		t.Errorf("DrainsBlocked after deleting a schedule: want %v, got %v", want, m.get())
+	}
+	scheduler.DeleteSchedule("a")
+}
+
+func TestDrainSchedules_DrainsBlockedByStageLimit(t *testing.T) {
+	m := &blockedMetrics{blocked: map[string]int{}}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithStageConcurrency(0, 1),
+		WithMetricsRecorder(m),
+	).(*DrainSchedules)
+	a := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	if _, err := scheduler.Schedule(a); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(a) error = %v", err)
+	}
+	sched := scheduler.schedules["a"]
+	sched.timer.Stop()
+
+	// Another drain holds the only eviction slot.
+	if err := scheduler.evictionSlots.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.runDrain(a, sched)
+	}()
+	want := map[string]int{deferralReasonStageLimit: 1}
+	deadline := time.Now().Add(5 * time.Second)
+	for !reflect.DeepEqual(m.get(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("DrainsBlocked: want %v, got %v", want, m.get())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	scheduler.evictionSlots.Release()
+	<-done
+	if want := map[string]int{deferralReasonStageLimit: 0}; !reflect.DeepEqual(m.get(), want) {
+		t.Errorf("DrainsBlocked once the drain proceeded: want %v, got %v", want, m.get())
+	}
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("drained nodes: want [a], got %v", got)
+	}
+}
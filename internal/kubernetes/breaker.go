@@ -0,0 +1,135 @@
+package kubernetes
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const deferralReasonCircuitOpen = "circuit-open"
+
+// A circuitBreaker pauses all drains while too many recent drain attempts
+// failed.
+type circuitBreaker struct {
+	minAttempts int
+	threshold   float64
+	window      time.Duration
+	cooldown    time.Duration
+
+	// attempts are the outcomes of the drain attempts that finished within
+	// the window, oldest first.
+	attempts []breakerAttempt
+	// openedAt is the time the breaker tripped, or zero while it is closed.
+	openedAt time.Time
+}
+
+type breakerAttempt struct {
+	finished time.Time
+	failed   bool
+}
+
+// WithCircuitBreaker pauses all drains once more than the supplied fraction,
+// between 0 and 1, of the drain attempts that finished within the supplied
+// window failed, provided there were at least minAttempts of them. Drains that
+// fire while the breaker is open are deferred by DefaultDrainDeferralPeriod,
+// and never force fired. The breaker closes once the failure rate recovers,
+// typically because the failed attempts left the window, or once the supplied
+// cooldown elapsed since it tripped. The attempts seen so far are then
+// forgotten, so that only new failures may trip it again.
+func WithCircuitBreaker(minAttempts int, threshold float64, window, cooldown time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if minAttempts <= 0 || window <= 0 {
+			d.breaker = nil
+			return
+		}
+		d.breaker = &circuitBreaker{minAttempts: minAttempts, threshold: threshold, window: window, cooldown: cooldown}
+	}
+}
+
+// forgetLocked drops the attempts that finished before the window preceding
+// the supplied time.
+func (b *circuitBreaker) forgetLocked(now time.Time) {
+	i := 0
+	for i < len(b.attempts) && !b.attempts[i].finished.After(now.Add(-b.window)) {
+		i++
+	}
+	b.attempts = b.attempts[i:]
+}
+
+// failingLocked returns the number of attempts within the window, and the
+// number that failed, if the failure rate exceeds the threshold.
+func (b *circuitBreaker) failingLocked(now time.Time) (attempts, failed int, failing bool) {
+	b.forgetLocked(now)
+	for _, a := range b.attempts {
+		if a.failed {
+			failed++
+		}
+	}
+	attempts = len(b.attempts)
+	return attempts, failed, attempts >= b.minAttempts && float64(failed) > b.threshold*float64(attempts)
+}
+
+// recordAttempt feeds the outcome of a drain attempt of the supplied node to
+// the circuit breaker, if any. A failed attempt trips it if too many attempts
+// failed.
+func (d *DrainSchedules) recordAttempt(node *core.Node, err error) {
+	if d.breaker == nil {
+		return
+	}
+	d.Lock()
+	b := d.breaker
+	now := d.now()
+	b.attempts = append(b.attempts, breakerAttempt{finished: now, failed: err != nil})
+	attempts, failed, failing := b.failingLocked(now)
+	if err == nil || !b.openedAt.IsZero() || !failing {
+		d.Unlock()
+		return
+	}
+	b.openedAt = now
+	d.Unlock()
+
+	d.logger.Warn("Too many drains failed, pausing all drains", zap.String("node", node.GetName()), zap.Int("failed", failed), zap.Int("attempts", attempts))
+	d.metrics.CircuitBreaker(true)
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainCircuitOpen, "%d of the last %d drain attempts failed, pausing all drains", failed, attempts)
+}
+
+// deferCircuitOpen returns true if the drain of the supplied schedule is
+// deferred because the circuit breaker is open. It closes the breaker first if
+// the failure rate recovered or its cooldown elapsed.
+func (d *DrainSchedules) deferCircuitOpen(node *core.Node, sched *schedule) bool {
+	if d.breaker == nil {
+		return false
+	}
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.Lock()
+	b := d.breaker
+	if b.openedAt.IsZero() {
+		d.Unlock()
+		return false
+	}
+	now := d.now()
+	_, _, failing := b.failingLocked(now)
+	cooled := b.cooldown > 0 && !now.Before(b.openedAt.Add(b.cooldown))
+	if !failing || cooled {
+		since := b.openedAt
+		b.openedAt = time.Time{}
+		b.attempts = nil
+		d.Unlock()
+		d.logger.Info("Resuming drains", zap.String("node", node.GetName()), zap.Time("pausedSince", since))
+		d.metrics.CircuitBreaker(false)
+		d.eventRecorder.Eventf(nr, core.EventTypeNormal, d.eventReasons.DrainCircuitClosed, "Resuming drains paused since %s", since.Format(time.RFC3339))
+		return false
+	}
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	d.Unlock()
+
+	d.logger.Info("Deferring drain, too many drains failed recently", zap.String("node", node.GetName()))
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Drains are paused because too many drains failed recently")
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonCircuitOpen))
+	d.recordDeferral(node.GetName(), deferralReasonCircuitOpen)
+	return true
+}
@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type failingNodesDrainer struct {
+	*recordingDrainer
+	failing map[string]bool
+}
+
+func (d *failingNodesDrainer) Drain(n *v1.Node) error {
+	if d.failing[n.GetName()] {
+		return errors.New("api server unavailable")
+	}
+	return d.recordingDrainer.Drain(n)
+}
+
+type breakerMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	states []bool
+}
+
+func (m *breakerMetrics) CircuitBreaker(open bool) {
+	m.Lock()
+	defer m.Unlock()
+	m.states = append(m.states, open)
+}
+
+func TestDrainSchedules_CircuitBreaker(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := &failingNodesDrainer{
+		recordingDrainer: newRecordingDrainer(),
+		failing:          map[string]bool{"bad-0": true, "bad-1": true, "bad-2": true},
+	}
+	recorder := record.NewFakeRecorder(100)
+	m := &breakerMetrics{}
+	scheduler := NewDrainSchedules(drainer, recorder, time.Second, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithMetricsRecorder(m),
+		WithCircuitBreaker(3, 0.5, time.Hour, 10*time.Minute),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	run := func(name string) {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		sched := scheduler.schedules[name]
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+	}
+
+	// Two failures out of two attempts are too few to trip the breaker, and
+	// successes never trip it.
+	run("bad-0")
+	run("bad-1")
+	run("good-0")
+	if got := drainer.nodes(); len(got) != 1 || got[0] != "good-0" {
+		t.Fatalf("drained nodes: want [good-0], got %v", got)
+	}
+	// Three failures out of four attempts trip it.
+	run("bad-2")
+	if len(m.states) != 1 || !m.states[0] {
+		t.Fatalf("CircuitBreaker(): want [true], got %v", m.states)
+	}
+	run("good-1")
+	if got := drainer.nodes(); len(got) != 1 {
+		t.Errorf("drained nodes while the breaker is open: want [good-0], got %v", got)
+	}
+
+	// The deferred drain fires again, and resumes drains, once the cooldown
+	// elapsed.
+	dispatcher.ProcessDue(start.Add(10 * time.Minute))
+	if got := drainer.nodes(); len(got) != 2 || got[1] != "good-1" {
+		t.Errorf("drained nodes after the cooldown: want [good-0 good-1], got %v", got)
+	}
+	if len(m.states) != 2 || m.states[1] {
+		t.Errorf("CircuitBreaker(): want [true false], got %v", m.states)
+	}
+
+	var opened, closed bool
+	for len(recorder.Events) > 0 {
+		e := <-recorder.Events
+		opened = opened || strings.HasPrefix(e, "Warning DrainCircuitOpen 3 of the last 4 drain attempts failed")
+		closed = closed || strings.HasPrefix(e, "Normal DrainCircuitClosed Resuming drains")
+	}
+	if !opened || !closed {
+		t.Errorf("events: want the breaker opened and closed, got opened %v, closed %v", opened, closed)
+	}
+}
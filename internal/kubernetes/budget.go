@@ -0,0 +1,194 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	coordination "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultRateBudgetPollPeriod is how often a LeaseRateBudget looks for a free
+// lease while all of them are held.
+const DefaultRateBudgetPollPeriod = 5 * time.Second
+
+// A RateBudget bounds the number of drains running at once, possibly across
+// several independent scheduler instances.
+type RateBudget interface {
+	// Acquire blocks until the budget allows another drain, or the supplied
+	// context is done.
+	Acquire(ctx context.Context) error
+	// Release returns a previously acquired drain to the budget.
+	Release()
+}
+
+// A LocalRateBudget is a RateBudget shared by the drains of a single process.
+type LocalRateBudget struct {
+	slots chan struct{}
+}
+
+// NewLocalRateBudget returns a RateBudget allowing the supplied number of
+// drains at once.
+func NewLocalRateBudget(size int) *LocalRateBudget {
+	return &LocalRateBudget{slots: make(chan struct{}, size)}
+}
+
+// Acquire blocks until fewer than the budget size drains are running, or the
+// supplied context is done.
+func (b *LocalRateBudget) Acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tryAcquire acquires a slot without blocking, if fewer than the budget size
+// drains are running. It reports whether it did.
+func (b *LocalRateBudget) tryAcquire() bool {
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release returns a drain to the budget.
+func (b *LocalRateBudget) Release() {
+	select {
+	case <-b.slots:
+	default:
+	}
+}
+
+// A LeaseRateBudget is a RateBudget shared by all the scheduler instances that
+// use the same set of Kubernetes leases. Each running drain holds one of the
+// leases. Leases are not renewed, so their duration should exceed the longest
+// drain; the lease of a drain whose instance died expires after that duration.
+type LeaseRateBudget struct {
+	c         kubernetes.Interface
+	namespace string
+	name      string
+	identity  string
+	size      int
+	duration  time.Duration
+	poll      time.Duration
+
+	mu   sync.Mutex
+	held []string
+}
+
+// NewLeaseRateBudget returns a RateBudget allowing, across all instances, the
+// supplied number of drains at once. Drains hold the leases named after the
+// supplied name, in the supplied namespace, on behalf of the supplied identity
+// for at most the supplied duration.
+func NewLeaseRateBudget(c kubernetes.Interface, namespace, name, identity string, size int, duration time.Duration) *LeaseRateBudget {
+	return &LeaseRateBudget{
+		c:         c,
+		namespace: namespace,
+		name:      name,
+		identity:  identity,
+		size:      size,
+		duration:  duration,
+		poll:      DefaultRateBudgetPollPeriod,
+	}
+}
+
+// Acquire blocks until one of the leases of the budget is free and acquired,
+// or the supplied context is done.
+func (b *LeaseRateBudget) Acquire(ctx context.Context) error {
+	for {
+		for i := 0; i < b.size; i++ {
+			name := fmt.Sprintf("%s-%d", b.name, i)
+			if b.tryAcquire(ctx, name) {
+				b.mu.Lock()
+				b.held = append(b.held, name)
+				b.mu.Unlock()
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(b.poll):
+		}
+	}
+}
+
+// tryAcquire returns true if it acquired the named lease.
+func (b *LeaseRateBudget) tryAcquire(ctx context.Context, name string) bool {
+	now := meta.NowMicro()
+	seconds := int32(b.duration.Seconds())
+	leases := b.c.CoordinationV1().Leases(b.namespace)
+	l, err := leases.Get(ctx, name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err := leases.Create(ctx, &coordination.Lease{
+			ObjectMeta: meta.ObjectMeta{Namespace: b.namespace, Name: name},
+			Spec: coordination.LeaseSpec{
+				HolderIdentity:       &b.identity,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, meta.CreateOptions{})
+		return err == nil
+	}
+	if err != nil || leaseHeld(l, now.Time) {
+		return false
+	}
+	l.Spec.HolderIdentity = &b.identity
+	l.Spec.LeaseDurationSeconds = &seconds
+	l.Spec.AcquireTime = &now
+	l.Spec.RenewTime = &now
+	// A conflict means another instance acquired the lease first.
+	_, err = leases.Update(ctx, l, meta.UpdateOptions{})
+	return err == nil
+}
+
+// leaseHeld returns true if the supplied lease is held and not expired at the
+// supplied time.
+func leaseHeld(l *coordination.Lease, now time.Time) bool {
+	if l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity == "" {
+		return false
+	}
+	if l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := l.Spec.RenewTime.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second)
+	return now.Before(expiry)
+}
+
+// Release releases the most recently acquired lease of the budget. Release is
+// best effort: a lease that cannot be released expires after its duration.
+func (b *LeaseRateBudget) Release() {
+	b.mu.Lock()
+	if len(b.held) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	name := b.held[len(b.held)-1]
+	b.held = b.held[:len(b.held)-1]
+	b.mu.Unlock()
+
+	leases := b.c.CoordinationV1().Leases(b.namespace)
+	l, err := leases.Get(context.Background(), name, meta.GetOptions{})
+	if err != nil || l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity != b.identity {
+		return
+	}
+	l.Spec.HolderIdentity = nil
+	_, _ = leases.Update(context.Background(), l, meta.UpdateOptions{})
+}
+
+// WithRateBudget configures a budget bounding the number of drains running at
+// once. Drains wait for the budget before evicting pods.
+func WithRateBudget(b RateBudget) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.rateBudget = b
+	}
+}
@@ -0,0 +1,143 @@
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestLocalRateBudget(t *testing.T) {
+	const size, drains = 2, 10
+	b := NewLocalRateBudget(size)
+
+	var running, max int32
+	var wg sync.WaitGroup
+	for i := 0; i < drains; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.Acquire(context.Background()); err != nil {
+				t.Errorf("b.Acquire(): %v", err)
+				return
+			}
+			defer b.Release()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	if max > size {
+		t.Errorf("want at most %d drains at once, got %d", size, max)
+	}
+
+	// Acquire gives up once its context is done.
+	for i := 0; i < size; i++ {
+		if err := b.Acquire(context.Background()); err != nil {
+			t.Fatalf("b.Acquire(): %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("b.Acquire() with exhausted budget: want %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestLeaseRateBudget(t *testing.T) {
+	c := fake.NewSimpleClientset()
+	a := NewLeaseRateBudget(c, "kube-system", "draino-budget", "a", 2, time.Hour)
+	b := NewLeaseRateBudget(c, "kube-system", "draino-budget", "b", 2, time.Hour)
+	a.poll, b.poll = time.Millisecond, time.Millisecond
+
+	for _, budget := range []*LeaseRateBudget{a, b} {
+		if err := budget.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire(): %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Acquire() with all leases held: want %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	b.Release()
+	if err := a.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() after release: %v", err)
+	}
+	leases, err := c.CoordinationV1().Leases("kube-system").List(context.Background(), meta.ListOptions{})
+	if err != nil {
+		t.Fatalf("leases.List(): %v", err)
+	}
+	for _, l := range leases.Items {
+		if l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity != "a" {
+			t.Errorf("lease %s: want held by a, got %v", l.GetName(), l.Spec.HolderIdentity)
+		}
+	}
+}
+
+// countingBudget is a RateBudget that refuses drains when err is set, and
+// counts the drains it allowed and the ones returned.
+type countingBudget struct {
+	err                error
+	acquired, released int32
+}
+
+func (b *countingBudget) Acquire(_ context.Context) error {
+	if b.err != nil {
+		return b.err
+	}
+	atomic.AddInt32(&b.acquired, 1)
+	return nil
+}
+
+func (b *countingBudget) Release() {
+	atomic.AddInt32(&b.released, 1)
+}
+
+func TestDrainSchedules_RateBudget(t *testing.T) {
+	cases := []struct {
+		name      string
+		budget    *countingBudget
+		wantDrain bool
+	}{
+		{name: "Available", budget: &countingBudget{}, wantDrain: true},
+		{name: "Unavailable", budget: &countingBudget{err: context.DeadlineExceeded}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := newRecordingDrainer()
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithRateBudget(tc.budget)).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+
+			scheduler.runDrain(node, sched)
+			sched.timer.Stop()
+			if got := len(drainer.nodes()) == 1; got != tc.wantDrain {
+				t.Errorf("drained: want %v, got %v", tc.wantDrain, got)
+			}
+			if tc.budget.acquired != tc.budget.released {
+				t.Errorf("budget: acquired %d drains, released %d", tc.budget.acquired, tc.budget.released)
+			}
+		})
+	}
+}
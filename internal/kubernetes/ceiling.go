@@ -0,0 +1,132 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const deferralReasonDisruptionCeiling = "disruption-ceiling"
+
+// A ClusterPodCounter counts the pods of the cluster.
+type ClusterPodCounter interface {
+	CountClusterPods(ctx context.Context) (int, error)
+}
+
+// A DisruptionCounter counts the pods of the cluster, and those a drain of a
+// node would evict.
+type DisruptionCounter interface {
+	PodCounter
+	ClusterPodCounter
+}
+
+// CountClusterPods returns the number of pods bound to a node that have not
+// terminated. Mirror and DaemonSet pods are not counted, since drains never
+// disrupt them.
+func (s *ClusterCapacityScorer) CountClusterPods(ctx context.Context) (int, error) {
+	pods, err := s.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot list pods")
+	}
+	count := 0
+	for _, p := range pods.Items {
+		if p.Spec.NodeName == "" || !rescheduled(p) {
+			continue
+		}
+		if p.Status.Phase == core.PodSucceeded || p.Status.Phase == core.PodFailed {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// WithDisruptionCeiling caps the pods evicted by all the drains in progress at
+// once to the supplied percentage of the pods of the cluster, counted with the
+// supplied counter when each drain is about to evict. Drains that would push
+// the disruption past the ceiling are deferred by DefaultDrainDeferralPeriod,
+// or until the maximum deferral elapses. The drain of a node with more pods
+// than the ceiling proceeds once no other drain is in progress. Unlike
+// PodDisruptionBudgets, the ceiling applies across all workloads. Zero
+// disables the ceiling.
+func WithDisruptionCeiling(percent float64, c DisruptionCounter) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.disruptionCeiling = percent
+		d.disruptionCounter = c
+	}
+}
+
+// disruptionPercent returns the supplied number of pods as a percentage of the
+// supplied total.
+func disruptionPercent(pods, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(pods) / float64(total)
+}
+
+// disruptedPodsLocked returns the number of pods being evicted by the drains
+// in progress. It must be called with the lock held.
+func (d *DrainSchedules) disruptedPodsLocked() int {
+	pods := 0
+	for _, n := range d.disruptedPods {
+		pods += n
+	}
+	return pods
+}
+
+// admitDisruption returns true if the drain of the supplied schedule may evict
+// its pods according to the disruption ceiling, if any, along with a function
+// to call once it is done evicting. Otherwise the drain is deferred.
+func (d *DrainSchedules) admitDisruption(node *core.Node, sched *schedule) (func(), bool) {
+	if d.disruptionCeiling <= 0 || d.disruptionCounter == nil {
+		return func() {}, true
+	}
+	log := d.logger.With(zap.String("node", node.GetName()))
+	ctx := context.Background()
+	pods, err := d.disruptionCounter.CountPods(ctx, node)
+	if err != nil {
+		log.Info("Cannot count pods to evict, draining anyway", zap.Error(err))
+		return func() {}, true
+	}
+	total, err := d.disruptionCounter.CountClusterPods(ctx)
+	if err != nil {
+		log.Info("Cannot count the pods of the cluster, draining anyway", zap.Error(err))
+		return func() {}, true
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	d.disruptionTotal = total
+	current := d.disruptedPodsLocked()
+	if current > 0 && disruptionPercent(current+pods, total) > d.disruptionCeiling {
+		nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+		if d.maxDeferral <= 0 || d.now().Before(sched.created.Add(d.maxDeferral)) {
+			log.Info("Deferring drain, cluster disruption ceiling reached", zap.Int("pods", pods), zap.Int("disruptedPods", current), zap.Int("clusterPods", total))
+			d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Evicting %d pods would disrupt more than %g%% of the %d pods of the cluster, %d already being evicted", pods, d.disruptionCeiling, total, current)
+			sched.addSpanEvent("deferred", attribute.Int("pods", pods), attribute.Int("disruptedPods", current))
+			d.recordDeferral(node.GetName(), deferralReasonDisruptionCeiling)
+			d.metrics.ClusterDisruption(disruptionPercent(current, total))
+			sched.timer.Reset(DefaultDrainDeferralPeriod)
+			return nil, false
+		}
+		log.Info("Force firing drain deferred for too long", zap.Int("pods", pods), zap.Int("disruptedPods", current))
+		d.metrics.DrainForceFired(node.GetName())
+		sched.addSpanEvent("force fired", attribute.Int("pods", pods), attribute.Int("disruptedPods", current))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for the cluster disruption ceiling", sched.created.Format(time.RFC3339))
+	}
+	d.disruptedPods[node.GetName()] = pods
+	d.metrics.ClusterDisruption(disruptionPercent(current+pods, total))
+	return func() {
+		d.Lock()
+		defer d.Unlock()
+		delete(d.disruptedPods, node.GetName())
+		d.metrics.ClusterDisruption(disruptionPercent(d.disruptedPodsLocked(), d.disruptionTotal))
+	}, true
+}
@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type fixedDisruptionCounter struct {
+	pods  map[string]int
+	total int
+}
+
+func (c fixedDisruptionCounter) CountPods(_ context.Context, n *v1.Node) (int, error) {
+	return c.pods[n.GetName()], nil
+}
+
+func (c fixedDisruptionCounter) CountClusterPods(_ context.Context) (int, error) {
+	return c.total, nil
+}
+
+type disruptionMetrics struct {
+	deferralMetrics
+	sync.Mutex
+	percents []float64
+}
+
+func (m *disruptionMetrics) ClusterDisruption(percent float64) {
+	m.Lock()
+	defer m.Unlock()
+	m.percents = append(m.percents, percent)
+}
+
+func TestDrainSchedules_DisruptionCeiling(t *testing.T) {
+	drainer := &gatedDrainer{release: make(chan struct{}), drained: make(chan string, 2)}
+	m := &disruptionMetrics{}
+	counter := fixedDisruptionCounter{pods: map[string]int{"a": 4, "b": 3}, total: 100}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDisruptionCeiling(5, counter), WithMetricsRecorder(m)).(*DrainSchedules)
+
+	scheds := map[string]*schedule{}
+	nodes := map[string]*v1.Node{}
+	for _, name := range []string{"a", "b"} {
+		nodes[name] = &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+		if _, err := scheduler.Schedule(nodes[name]); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		scheds[name] = scheduler.schedules[name]
+		scheds[name].timer.Stop()
+	}
+	disrupted := func() int {
+		scheduler.Lock()
+		defer scheduler.Unlock()
+		return scheduler.disruptedPodsLocked()
+	}
+
+	// a disrupts 4% of the pods of the cluster while it drains.
+	go scheduler.runDrain(nodes["a"], scheds["a"])
+	deadline := time.Now().Add(5 * time.Second)
+	for disrupted() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := disrupted(); got != 4 {
+		t.Fatalf("disrupted pods: want 4, got %d", got)
+	}
+
+	// b would push the disruption to 7%, past the 5% ceiling.
+	scheduler.runDrain(nodes["b"], scheds["b"])
+	scheds["b"].timer.Stop()
+	if want := []string{"b=" + deferralReasonDisruptionCeiling}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+
+	close(drainer.release)
+	if got := <-drainer.drained; got != "a" {
+		t.Fatalf("drained %s, want a", got)
+	}
+	for disrupted() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// b proceeds once a is done.
+	scheduler.runDrain(nodes["b"], scheds["b"])
+	if got := <-drainer.drained; got != "b" {
+		t.Fatalf("drained %s, want b", got)
+	}
+	m.Lock()
+	defer m.Unlock()
+	if want := []float64{4, 4, 0, 3, 0}; !reflect.DeepEqual(m.percents, want) {
+		t.Errorf("ClusterDisruption: want %v, got %v", want, m.percents)
+	}
+}
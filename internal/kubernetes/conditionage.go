@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// A ConditionAgeProfile determines how aggressively the nodes whose offending
+// condition has been true for at least MinAge are drained.
+type ConditionAgeProfile struct {
+	// MinAge is how long the condition must have been true.
+	MinAge time.Duration
+	// MaxGracePeriod caps the grace period of the pods evicted. Zero leaves
+	// the grace period of the drainer unchanged.
+	MaxGracePeriod time.Duration
+	// Expedite fires the drain at once, rather than after the period between
+	// drains.
+	Expedite bool
+}
+
+// WithConditionAgeProfiles derives how aggressively nodes are drained from how
+// long their offending condition has been true when their drain is scheduled
+// with ScheduleWithTransition, so that nodes unhealthy for long are drained
+// sooner and faster while recently degraded nodes are drained gently. The
+// profile with the longest MinAge the condition reached applies; nodes whose
+// condition is younger than every profile are drained as usual.
+func WithConditionAgeProfiles(profiles ...ConditionAgeProfile) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.ageProfiles = profiles
+	}
+}
+
+// conditionAgeProfile returns the profile for a condition of the supplied age,
+// if any.
+func (d *DrainSchedules) conditionAgeProfile(age time.Duration) (ConditionAgeProfile, bool) {
+	var match ConditionAgeProfile
+	found := false
+	for _, p := range d.ageProfiles {
+		if age >= p.MinAge && (!found || p.MinAge > match.MinAge) {
+			match, found = p, true
+		}
+	}
+	return match, found
+}
+
+// applyConditionAge applies the profile for the age of the offending condition
+// of the supplied node, which transitioned at the supplied time, to its newly
+// scheduled drain.
+func (d *DrainSchedules) applyConditionAge(node *v1.Node, since time.Time) {
+	if len(d.ageProfiles) == 0 || since.IsZero() {
+		return
+	}
+	age := d.now().Sub(since)
+	p, ok := d.conditionAgeProfile(age)
+	if !ok {
+		return
+	}
+	d.Lock()
+	sched, ok := d.schedules[node.GetName()]
+	if ok {
+		sched.maxGracePeriod = p.MaxGracePeriod
+	}
+	d.Unlock()
+	if !ok {
+		return
+	}
+	d.logger.Info("Applying condition age profile", zap.String("node", node.GetName()), zap.Duration("age", age), zap.Duration("maxGracePeriod", p.MaxGracePeriod), zap.Bool("expedite", p.Expedite))
+	sched.addSpanEvent("condition age profile", attribute.String("age", age.String()))
+	if p.Expedite {
+		if err := d.Expedite(node.GetName()); err != nil {
+			d.logger.Info("Cannot expedite drain of long unhealthy node", zap.String("node", node.GetName()), zap.Error(err))
+		}
+	}
+}
+
+type maxGracePeriodKey struct{}
+
+// withMaxGracePeriod returns a context capping the grace period of the pods
+// evicted with it to the supplied duration, unless zero.
+func withMaxGracePeriod(ctx context.Context, max time.Duration) context.Context {
+	if max <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, maxGracePeriodKey{}, max)
+}
+
+// capGracePeriod returns the supplied grace period, in seconds, capped to the
+// grace period carried by the supplied context, if any.
+func capGracePeriod(ctx context.Context, gracePeriod int64) int64 {
+	max, ok := ctx.Value(maxGracePeriodKey{}).(time.Duration)
+	if ok && int64(max.Seconds()) < gracePeriod {
+		return int64(max.Seconds())
+	}
+	return gracePeriod
+}
@@ -0,0 +1,75 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_ConditionAgeProfiles(t *testing.T) {
+	profiles := []ConditionAgeProfile{
+		{MinAge: time.Hour, MaxGracePeriod: 30 * time.Second},
+		{MinAge: 24 * time.Hour, MaxGracePeriod: 5 * time.Second, Expedite: true},
+	}
+	cases := []struct {
+		name      string
+		age       time.Duration
+		wantGrace int64
+	}{
+		{name: "Fresh", age: time.Minute, wantGrace: 120},
+		{name: "Old", age: 2 * time.Hour, wantGrace: 30},
+		{name: "Ancient", age: 48 * time.Hour, wantGrace: 5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			grace := int64(120)
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			c := fake.NewSimpleClientset(node, &core.Pod{
+				ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName},
+				Spec:       core.PodSpec{TerminationGracePeriodSeconds: &grace},
+			})
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return a.GetSubresource() == "eviction", nil, nil
+			})
+			c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+			})
+
+			start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+			dispatcher := NewManualDispatcher(start)
+			scheduler := NewDrainSchedules(NewAPICordonDrainer(c, MaxGracePeriod(time.Hour)), record.NewFakeRecorder(100), time.Hour, zap.NewNop(),
+				WithDispatcher(dispatcher),
+				WithConditionAgeProfiles(profiles...),
+			).(*DrainSchedules)
+			scheduler.lastDrainScheduledFor = start
+			if _, err := scheduler.ScheduleWithTransition(node, start.Add(-tc.age)); err != nil {
+				t.Fatalf("DrainSchedules.ScheduleWithTransition() error = %v", err)
+			}
+			// Only the drain of the ancient condition is expedited.
+			if fired := dispatcher.ProcessDue(start); (fired == 1) != (tc.age >= 24*time.Hour) {
+				t.Errorf("ProcessDue(): fired %d drains at once for a condition %s old", fired, tc.age)
+			}
+			dispatcher.ProcessDue(start.Add(time.Hour))
+
+			var got []int64
+			for _, a := range c.Actions() {
+				if a.GetSubresource() == "eviction" {
+					got = append(got, *a.(clienttesting.CreateAction).GetObject().(*policy.Eviction).DeleteOptions.GracePeriodSeconds)
+				}
+			}
+			if len(got) != 1 || got[0] != tc.wantGrace {
+				t.Errorf("eviction grace periods: want [%d], got %v", tc.wantGrace, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package kubernetes
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WithConditionDelay delays writing the condition stating that the drain of a
+// node is scheduled by the supplied duration, giving external systems watching
+// for the cordon time to prepare before the condition appears. Drains are
+// scheduled no earlier than the condition is written. If the condition cannot
+// be written, the schedule is deleted. Zero writes the condition as soon as
+// the drain is scheduled.
+func WithConditionDelay(delay time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.conditionDelay = delay
+	}
+}
+
+// afterConditionDelay returns the supplied drain time, or the time the drain
+// condition of a drain scheduled now is written, whichever is later.
+func (d *DrainSchedules) afterConditionDelay(when time.Time) time.Time {
+	if d.conditionDelay <= 0 {
+		return when
+	}
+	if written := d.now().Add(d.conditionDelay); when.Before(written) {
+		return written
+	}
+	return when
+}
+
+// markScheduledLate writes the drain condition of the supplied schedule once
+// the condition delay elapsed, unless the schedule was deleted or its drain
+// started meanwhile.
+func (d *DrainSchedules) markScheduledLate(node *v1.Node, sched *schedule) {
+	d.Lock()
+	current := d.schedules[node.GetName()] == sched
+	_, started := d.inProgress[node.GetName()]
+	finished := !sched.finish.IsZero()
+	d.Unlock()
+	if !current || started || finished {
+		return
+	}
+	err := d.markScheduled(node, sched)
+	if err == nil {
+		return
+	}
+	d.logger.Info("Cannot mark node, deleting schedule", zap.String("node", node.GetName()), zap.Error(err))
+	nr := &v1.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, v1.EventTypeWarning, d.eventReasons.DrainSchedulingFailed, "Drain scheduling failed: %v", err)
+	d.Lock()
+	if d.schedules[node.GetName()] == sched {
+		d.deleteScheduleLocked(node.GetName(), sched)
+	}
+	d.Unlock()
+}
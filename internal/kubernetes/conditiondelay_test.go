@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type markingDrainer struct {
+	NoopCordonDrainer
+	sync.Mutex
+	now    func() time.Time
+	marked []time.Time
+}
+
+func (d *markingDrainer) MarkDrain(n *v1.Node, when, finish time.Time, failed bool, reason string) error {
+	d.Lock()
+	defer d.Unlock()
+	d.marked = append(d.marked, d.now())
+	return nil
+}
+
+func (d *markingDrainer) marks() []time.Time {
+	d.Lock()
+	defer d.Unlock()
+	return append([]time.Time{}, d.marked...)
+}
+
+func TestDrainSchedules_ConditionDelay(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := &markingDrainer{now: dispatcher.Now}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithConditionDelay(30*time.Second),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	when, err := scheduler.Schedule(node)
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	if want := start.Add(30 * time.Second); when.Before(want) {
+		t.Errorf("DrainSchedules.Schedule(): want drain no earlier than %s, got %s", want, when)
+	}
+	if got := drainer.marks(); len(got) != 0 {
+		t.Fatalf("MarkDrain(): want no call before the delay, got %v", got)
+	}
+
+	dispatcher.ProcessDue(start.Add(29 * time.Second))
+	if got := drainer.marks(); len(got) != 0 {
+		t.Fatalf("MarkDrain(): want no call before the delay, got %v", got)
+	}
+	dispatcher.ProcessDue(start.Add(30 * time.Second))
+	got := drainer.marks()
+	if len(got) == 0 {
+		t.Fatal("MarkDrain(): want a call once the delay elapsed, got none")
+	}
+	if want := start.Add(30 * time.Second); !got[0].Equal(want) {
+		t.Errorf("MarkDrain(): want first call at %s, got %s", want, got[0])
+	}
+}
+
+func TestDrainSchedules_ConditionDelayDeleted(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := &markingDrainer{now: dispatcher.Now}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Hour, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithConditionDelay(30*time.Second),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	scheduler.DeleteSchedule("a")
+	dispatcher.ProcessDue(start.Add(time.Minute))
+	if got := drainer.marks(); len(got) != 0 {
+		t.Errorf("MarkDrain(): want no call once the schedule was deleted, got %v", got)
+	}
+}
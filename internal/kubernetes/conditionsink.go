@@ -0,0 +1,51 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// A DrainState is a step of the drain lifecycle reported to a ConditionSink,
+// and returned by DrainScheduler.State.
+type DrainState string
+
+// Drain states. Only Scheduled, InProgress, Succeeded and Failed are reported
+// to a ConditionSink.
+const (
+	DrainStateNone       DrainState = "None"
+	DrainStateScheduled  DrainState = "Scheduled"
+	DrainStateInProgress DrainState = "InProgress"
+	DrainStateSucceeded  DrainState = "Succeeded"
+	DrainStateFailed     DrainState = "Failed"
+	DrainStateDeferred   DrainState = "Deferred"
+	DrainStateCooldown   DrainState = "Cooldown"
+)
+
+// A ConditionSink mirrors the drain state of nodes to a secondary store, for
+// example the status of a custom resource. It is notified at the same points
+// the drain condition of the node is set. Failures are logged but do not fail
+// the drain.
+type ConditionSink interface {
+	SetDrainState(ctx context.Context, n *v1.Node, state DrainState, when, finish time.Time, reason string) error
+}
+
+// WithConditionSink configures a sink notified of each change of drain state.
+func WithConditionSink(s ConditionSink) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.conditionSink = s
+	}
+}
+
+// setDrainState notifies the condition sink, if any, of the drain state of the
+// supplied node.
+func (d *DrainSchedules) setDrainState(n *v1.Node, state DrainState, when, finish time.Time, reason string) {
+	if d.conditionSink == nil {
+		return
+	}
+	if err := d.conditionSink.SetDrainState(context.Background(), n, state, when, finish, reason); err != nil {
+		d.logger.Warn("Failed to mirror drain state", zap.String("node", n.GetName()), zap.String("state", string(state)), zap.Error(err))
+	}
+}
@@ -0,0 +1,72 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type recordingSink struct {
+	sync.Mutex
+	states []DrainState
+	err    error
+}
+
+func (s *recordingSink) SetDrainState(ctx context.Context, n *v1.Node, state DrainState, when, finish time.Time, reason string) error {
+	s.Lock()
+	defer s.Unlock()
+	s.states = append(s.states, state)
+	return s.err
+}
+
+func TestDrainSchedules_ConditionSink(t *testing.T) {
+	cases := []struct {
+		name    string
+		drainer Drainer
+		sinkErr error
+		want    []DrainState
+	}{
+		{
+			name:    "Succeeded",
+			drainer: &NoopCordonDrainer{},
+			want:    []DrainState{DrainStateScheduled, DrainStateInProgress, DrainStateSucceeded},
+		},
+		{
+			name:    "Failed",
+			drainer: &failDrainer{},
+			want:    []DrainState{DrainStateScheduled, DrainStateInProgress, DrainStateFailed},
+		},
+		{
+			name:    "SinkErrorsIgnored",
+			drainer: &NoopCordonDrainer{},
+			sinkErr: errors.New("sink exploded"),
+			want:    []DrainState{DrainStateScheduled, DrainStateInProgress, DrainStateSucceeded},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &recordingSink{err: tc.sinkErr}
+			scheduler := NewDrainSchedules(tc.drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithConditionSink(sink)).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			if !reflect.DeepEqual(sink.states, tc.want) {
+				t.Errorf("states: want %v, got %v", tc.want, sink.states)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package kubernetes
+
+import (
+	"sort"
+	"time"
+)
+
+// SchedulerConfig is a snapshot of the effective configuration of a
+// DrainSchedules, including the changes made at runtime.
+type SchedulerConfig struct {
+	// Period is the period between drains, before throttling, and
+	// EffectivePeriod the period drains are currently spaced by.
+	Period          time.Duration
+	EffectivePeriod time.Duration
+	// MinPeriod is the floor of Period.
+	MinPeriod time.Duration
+
+	MaxDeferral    time.Duration
+	MinNodeAge     time.Duration
+	ConditionDelay time.Duration
+	GroupLabel     string
+	GroupCooldown  time.Duration
+	PausedGroups   []string
+
+	// MaxSchedules, DailyLimit, ZoneLimit and PodBudget are zero when
+	// unlimited.
+	MaxSchedules    int
+	DailyLimit      int
+	DailyReset      time.Duration
+	ZoneLimit       int
+	ZoneWindow      time.Duration
+	PodBudget       int
+	PodBudgetWindow time.Duration
+	// MaxFailedSchedules is the number of failed schedules retained, or zero
+	// when unlimited.
+	MaxFailedSchedules int
+	// DrainPermits is the number of drains granted per DrainPermitInterval,
+	// or zero when unlimited.
+	DrainPermits        int
+	DrainPermitInterval time.Duration
+	// DisruptionCeiling is the maximum percentage of the pods of the cluster
+	// evicted at once, or zero when unlimited.
+	DisruptionCeiling float64
+
+	// Stages is true if drains are split into a cordon stage and an
+	// eviction stage, bounded by MaxCordons and MaxEvictions unless zero.
+	Stages       bool
+	MaxCordons   int
+	MaxEvictions int
+
+	MaxAttempts         int
+	RetryBackoff        time.Duration
+	ForceOnFinalAttempt bool
+	FailedDrainAction   FailedDrainAction
+	PDBAwareOrder       bool
+	// MinPodsMoved is the number of pods a drain must move to count as
+	// succeeded, or zero when any drain does.
+	MinPodsMoved int
+}
+
+// EffectiveConfig returns a snapshot of the configuration currently in effect.
+func (d *DrainSchedules) EffectiveConfig() SchedulerConfig {
+	d.Lock()
+	defer d.Unlock()
+	c := SchedulerConfig{
+		Period:              d.period,
+		EffectivePeriod:     d.period,
+		MinPeriod:           d.minPeriod,
+		MaxDeferral:         d.maxDeferral,
+		MinNodeAge:          d.minNodeAge,
+		ConditionDelay:      d.conditionDelay,
+		GroupLabel:          d.groupLabel,
+		GroupCooldown:       d.groupCooldown,
+		MaxSchedules:        d.maxSchedules,
+		MaxFailedSchedules:  d.maxFailedSchedules,
+		DailyLimit:          d.dailyLimit,
+		DailyReset:          d.dailyReset,
+		ZoneLimit:           d.zoneLimit,
+		ZoneWindow:          d.zoneWindow,
+		PodBudget:           d.podBudget,
+		PodBudgetWindow:     d.podBudgetWindow,
+		DisruptionCeiling:   d.disruptionCeiling,
+		Stages:              d.stages,
+		MaxAttempts:         d.maxAttempts,
+		RetryBackoff:        d.retryBackoff,
+		ForceOnFinalAttempt: d.forceOnFinalAttempt,
+		FailedDrainAction:   d.failedDrainAction,
+		PDBAwareOrder:       d.pdbAwareOrder,
+		MinPodsMoved:        d.minPodsMoved,
+	}
+	if c.FailedDrainAction == "" {
+		c.FailedDrainAction = FailedDrainKeepCordoned
+	}
+	if d.throttle != nil {
+		c.EffectivePeriod = d.throttle.Period()
+	}
+	if d.permits != nil {
+		c.DrainPermits = d.permits.batch
+		c.DrainPermitInterval = d.permits.interval
+	}
+	if d.cordonSlots != nil {
+		c.MaxCordons = cap(d.cordonSlots.slots)
+	}
+	if d.evictionSlots != nil {
+		c.MaxEvictions = cap(d.evictionSlots.slots)
+	}
+	for group := range d.pausedGroups {
+		c.PausedGroups = append(c.PausedGroups, group)
+	}
+	sort.Strings(c.PausedGroups)
+	return c
+}
@@ -0,0 +1,46 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_EffectiveConfig(t *testing.T) {
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 10*time.Minute, zap.NewNop(),
+		WithMaxSchedules(5),
+		WithStageConcurrency(2, 0),
+		WithZoneDrainLimit(1, time.Hour),
+		WithDrainRetries(3, time.Minute),
+	).(*DrainSchedules)
+	scheduler.PauseGroup("web")
+	scheduler.PauseGroup("batch")
+	scheduler.SetPeriod(time.Minute)
+
+	want := SchedulerConfig{
+		Period:            time.Minute,
+		EffectivePeriod:   time.Minute,
+		MinPeriod:         DefaultMinDrainPeriod,
+		PausedGroups:      []string{"batch", "web"},
+		MaxSchedules:      5,
+		ZoneLimit:         1,
+		ZoneWindow:        time.Hour,
+		Stages:            true,
+		MaxCordons:        2,
+		MaxAttempts:       3,
+		RetryBackoff:      time.Minute,
+		FailedDrainAction: FailedDrainKeepCordoned,
+	}
+	if got := scheduler.EffectiveConfig(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EffectiveConfig():\nwant %+v\n got %+v", want, got)
+	}
+
+	// The period remains floored at runtime.
+	scheduler.SetPeriod(0)
+	if got := scheduler.EffectiveConfig().Period; got != DefaultMinDrainPeriod {
+		t.Errorf("EffectiveConfig().Period: want %v, got %v", DefaultMinDrainPeriod, got)
+	}
+}
@@ -0,0 +1,113 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// A DrainEndState is the state drained nodes are expected to reach.
+type DrainEndState string
+
+// Drain end states.
+const (
+	// DrainEndStateRemoved expects drained nodes to be removed from the
+	// cluster, for example by the cluster autoscaler.
+	DrainEndStateRemoved DrainEndState = "removed"
+	// DrainEndStateCordoned expects drained nodes to stay cordoned, unless
+	// they are removed.
+	DrainEndStateCordoned DrainEndState = "cordoned"
+)
+
+const (
+	// DefaultDrainConfirmationTimeout is how long drained nodes are polled for
+	// their expected end state when WithDrainConfirmation is configured
+	// without a timeout.
+	DefaultDrainConfirmationTimeout = 10 * time.Minute
+
+	drainConfirmationPollPeriod = 10 * time.Second
+)
+
+// WithDrainConfirmation confirms that nodes reach the supplied end state once
+// drained, for node replacement workflows where drained nodes are expected to
+// go away, by polling the nodes of the supplied lister for up to the supplied
+// timeout. DefaultDrainConfirmationTimeout applies when the timeout is zero.
+// Drains that succeeded are not failed when their node lingers, but recorded
+// with a DrainNotConfirmed event.
+func WithDrainConfirmation(nodes NodeLister, state DrainEndState, timeout time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if timeout <= 0 {
+			timeout = DefaultDrainConfirmationTimeout
+		}
+		d.confirmNodes = nodes
+		d.confirmState = state
+		d.confirmTimeout = timeout
+	}
+}
+
+// confirmDrain polls, in the background, the supplied drained node until it
+// reaches its expected end state or the confirmation times out, and records
+// the result.
+func (d *DrainSchedules) confirmDrain(node *core.Node) {
+	if d.confirmNodes == nil {
+		return
+	}
+	period := d.confirmPollPeriod
+	if period <= 0 {
+		period = drainConfirmationPollPeriod
+	}
+	go func() {
+		result := d.awaitEndState(node.GetName(), period)
+		defer d.metrics.DrainConfirmed(node.GetName(), result)
+		log := d.logger.With(zap.String("node", node.GetName()), zap.String("result", result))
+		if result == tagResultConfirmedRemoved || result == tagResultConfirmedCordoned {
+			log.Info("Drain confirmed")
+			return
+		}
+		log.Warn("Drained node did not reach its expected end state", zap.String("expected", string(d.confirmState)), zap.Duration("timeout", d.confirmTimeout))
+		nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainNotConfirmed, "Drained node is not %s after %s", d.confirmState, d.confirmTimeout)
+	}()
+}
+
+// awaitEndState polls the named node every supplied period until it reaches
+// its expected end state, and returns the result of the confirmation. Nodes
+// that cannot be listed are polled again, so that polling only ends once
+// confirmed or timed out.
+func (d *DrainSchedules) awaitEndState(name string, period time.Duration) string {
+	result := tagResultDrainedButPresent
+	ctx, cancel := context.WithTimeout(context.Background(), d.confirmTimeout)
+	defer cancel()
+	wait.PollImmediateUntil(period, func() (bool, error) { // nolint:errcheck,gosec
+		nodes, err := d.confirmNodes.ListNodes(ctx)
+		if err != nil {
+			d.logger.Info("Cannot list nodes to confirm drain", zap.String("node", name), zap.Error(err))
+			return false, nil
+		}
+		var found *core.Node
+		for _, n := range nodes {
+			if n.GetName() == name {
+				found = n
+				break
+			}
+		}
+		switch {
+		case found == nil:
+			result = tagResultConfirmedRemoved
+			return true, nil
+		case d.confirmState != DrainEndStateCordoned:
+			result = tagResultDrainedButPresent
+		case found.Spec.Unschedulable:
+			result = tagResultConfirmedCordoned
+			return true, nil
+		default:
+			result = tagResultDrainedButUncordoned
+		}
+		return false, nil
+	}, ctx.Done())
+	return result
+}
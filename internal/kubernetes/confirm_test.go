@@ -0,0 +1,116 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// fakeNodeLister lists the nodes it holds, removing them after listing them a
+// number of times.
+type fakeNodeLister struct {
+	sync.Mutex
+	nodes     []*v1.Node
+	removeAt  int
+	listCalls int
+}
+
+func (l *fakeNodeLister) ListNodes(_ context.Context) ([]*v1.Node, error) {
+	l.Lock()
+	defer l.Unlock()
+	l.listCalls++
+	if l.removeAt > 0 && l.listCalls >= l.removeAt {
+		return nil, nil
+	}
+	return l.nodes, nil
+}
+
+type confirmationMetrics struct {
+	OpenCensusMetricsRecorder
+	confirmed chan string
+}
+
+func (m *confirmationMetrics) DrainConfirmed(node, result string) {
+	m.confirmed <- result
+}
+
+func TestDrainSchedules_DrainConfirmation(t *testing.T) {
+	cases := []struct {
+		name      string
+		state     DrainEndState
+		node      *v1.Node
+		removeAt  int
+		want      string
+		wantEvent bool
+	}{
+		{
+			name:     "Removed",
+			state:    DrainEndStateRemoved,
+			node:     &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: v1.NodeSpec{Unschedulable: true}},
+			removeAt: 3,
+			want:     tagResultConfirmedRemoved,
+		},
+		{
+			name:      "StillPresent",
+			state:     DrainEndStateRemoved,
+			node:      &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: v1.NodeSpec{Unschedulable: true}},
+			want:      tagResultDrainedButPresent,
+			wantEvent: true,
+		},
+		{
+			name:  "Cordoned",
+			state: DrainEndStateCordoned,
+			node:  &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: v1.NodeSpec{Unschedulable: true}},
+			want:  tagResultConfirmedCordoned,
+		},
+		{
+			name:      "Uncordoned",
+			state:     DrainEndStateCordoned,
+			node:      &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+			want:      tagResultDrainedButUncordoned,
+			wantEvent: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lister := &fakeNodeLister{nodes: []*v1.Node{tc.node}, removeAt: tc.removeAt}
+			metrics := &confirmationMetrics{confirmed: make(chan string, 1)}
+			recorder := record.NewFakeRecorder(100)
+			scheduler := NewDrainSchedules(&NoopCordonDrainer{}, recorder, 0, zap.NewNop(),
+				WithDrainConfirmation(lister, tc.state, 200*time.Millisecond),
+				WithMetricsRecorder(metrics),
+			).(*DrainSchedules)
+			scheduler.confirmPollPeriod = 10 * time.Millisecond
+			if _, err := scheduler.Schedule(tc.node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(tc.node, sched)
+
+			select {
+			case got := <-metrics.confirmed:
+				if got != tc.want {
+					t.Errorf("DrainConfirmed(): want %s, got %s", tc.want, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("DrainConfirmed(): want a call, got none")
+			}
+			var notConfirmed bool
+			for len(recorder.Events) > 0 {
+				notConfirmed = notConfirmed || strings.Contains(<-recorder.Events, eventReasonDrainNotConfirmed)
+			}
+			if notConfirmed != tc.wantEvent {
+				t.Errorf("%s event: want %v, got %v", eventReasonDrainNotConfirmed, tc.wantEvent, notConfirmed)
+			}
+		})
+	}
+}
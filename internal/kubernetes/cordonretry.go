@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Default cordon retry settings.
+const (
+	DefaultCordonRetryPeriod  = 500 * time.Millisecond
+	DefaultCordonRetryTimeout = 30 * time.Second
+)
+
+// WithCordonRetry configures Cordon to retry every supplied period, for at most
+// the supplied timeout, when the node cannot be cordoned because of a conflict
+// or another transient error of the API server, so that the drain only fails
+// once the retries are exhausted. Other errors are not retried. A zero timeout
+// disables retries.
+func WithCordonRetry(period, timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.cordonRetryPeriod = period
+		d.cordonRetryTimeout = timeout
+	}
+}
+
+// Cordon the supplied node. Marks it unschedulable for new pods, retrying
+// conflicts and transient errors.
+func (d *APICordonDrainer) Cordon(n *core.Node, mutators ...nodeMutatorFn) error {
+	if d.cordonRetryTimeout <= 0 {
+		return d.cordon(n, mutators...)
+	}
+	var last error
+	attempts := 0
+	err := wait.PollImmediate(d.cordonRetryPeriod, d.cordonRetryTimeout, func() (bool, error) {
+		if attempts > 0 {
+			d.metrics.CordonRetried(n.GetName())
+		}
+		attempts++
+		last = d.cordon(n, mutators...)
+		if last == nil {
+			return true, nil
+		}
+		if !retriableCordonError(errors.Cause(last)) {
+			return false, last
+		}
+		d.l.Info("Retrying cordon", zap.String("node", n.GetName()), zap.Int("attempt", attempts), zap.Error(last))
+		return false, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return errors.Wrapf(last, "cannot cordon node %s after %d attempts", n.GetName(), attempts)
+	}
+	return err
+}
+
+// retriableCordonError returns true if the supplied error of the API server is
+// worth retrying a cordon for.
+func retriableCordonError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err)
+}
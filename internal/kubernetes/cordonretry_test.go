@@ -0,0 +1,75 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// cordonRetryMetrics counts the retried cordons.
+type cordonRetryMetrics struct {
+	OpenCensusMetricsRecorder
+	retries int
+}
+
+func (m *cordonRetryMetrics) CordonRetried(string) {
+	m.retries++
+}
+
+func TestCordonRetry(t *testing.T) {
+	cases := []struct {
+		name      string
+		conflicts int
+		wantErr   bool
+		wantRetry int
+	}{
+		{name: "ConflictsThenSucceeds", conflicts: 2, wantRetry: 2},
+		{name: "RetriesExhausted", conflicts: 1000, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			c := fake.NewSimpleClientset(node)
+			conflicts := tc.conflicts
+			c.PrependReactor("update", "nodes", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if conflicts == 0 {
+					return false, nil, nil
+				}
+				conflicts--
+				return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "nodes"}, nodeName, nil)
+			})
+
+			m := &cordonRetryMetrics{}
+			d := NewAPICordonDrainer(c, WithCordonRetry(10*time.Millisecond, 200*time.Millisecond), WithDrainerMetricsRecorder(m))
+			err := d.Cordon(node)
+			if tc.wantErr {
+				if !apierrors.IsConflict(errors.Cause(err)) {
+					t.Fatalf("d.Cordon(): want a conflict once retries are exhausted, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("d.Cordon(): %v", err)
+			}
+			fresh, err := c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{})
+			if err != nil {
+				t.Fatalf("nodes.Get(): %v", err)
+			}
+			if !fresh.Spec.Unschedulable {
+				t.Error("node: want cordoned, got schedulable")
+			}
+			if m.retries != tc.wantRetry {
+				t.Errorf("cordon retries: want %d, got %d", tc.wantRetry, m.retries)
+			}
+		})
+	}
+}
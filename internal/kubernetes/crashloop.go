@@ -0,0 +1,50 @@
+package kubernetes
+
+import (
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// reasonCrashLoopBackOff is the waiting reason of containers the kubelet backs
+// off restarting because they keep crashing.
+const reasonCrashLoopBackOff = "CrashLoopBackOff"
+
+// WithCrashLoopFastPath clears the pods with a container in CrashLoopBackOff,
+// which serve nothing, before all others, so that drains do not wait for them
+// to terminate gracefully. Drain evicts them at once, allowing each at most the
+// supplied grace period to shut down, then evicts the other pods as usual.
+func WithCrashLoopFastPath(gracePeriod time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.crashLoopFastPath = true
+		d.crashLoopGracePeriod = gracePeriod
+	}
+}
+
+// podCrashLooping returns true if a container of the supplied pod, init
+// containers included, is in CrashLoopBackOff.
+func podCrashLooping(p core.Pod) bool {
+	for _, statuses := range [][]core.ContainerStatus{p.Status.InitContainerStatuses, p.Status.ContainerStatuses} {
+		for _, s := range statuses {
+			if s.State.Waiting != nil && s.State.Waiting.Reason == reasonCrashLoopBackOff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// crashLooping returns true if the supplied pod is crashlooping and fast
+// pathed.
+func (d *APICordonDrainer) crashLooping(p core.Pod) bool {
+	return d.crashLoopFastPath && podCrashLooping(p)
+}
+
+// recordCrashLoopCleared records the removal of the supplied pod if it was
+// fast pathed because it was crashlooping.
+func (d *APICordonDrainer) recordCrashLoopCleared(p core.Pod) {
+	if !d.crashLooping(p) {
+		return
+	}
+	d.metrics.CrashLoopCleared(p.Spec.NodeName)
+}
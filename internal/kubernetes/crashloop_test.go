@@ -0,0 +1,108 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestPodCrashLooping(t *testing.T) {
+	waiting := func(reason string) core.ContainerStatus {
+		return core.ContainerStatus{State: core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: reason}}}
+	}
+	cases := []struct {
+		name   string
+		status core.PodStatus
+		want   bool
+	}{
+		{name: "NoStatus"},
+		{name: "Running", status: core.PodStatus{ContainerStatuses: []core.ContainerStatus{{State: core.ContainerState{Running: &core.ContainerStateRunning{}}}}}},
+		{name: "PullingImage", status: core.PodStatus{ContainerStatuses: []core.ContainerStatus{waiting("ContainerCreating")}}},
+		{name: "CrashLoopBackOff", status: core.PodStatus{ContainerStatuses: []core.ContainerStatus{{}, waiting(reasonCrashLoopBackOff)}}, want: true},
+		{name: "InitCrashLoopBackOff", status: core.PodStatus{InitContainerStatuses: []core.ContainerStatus{waiting(reasonCrashLoopBackOff)}}, want: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := podCrashLooping(core.Pod{Status: tc.status}); got != tc.want {
+				t.Errorf("podCrashLooping(): want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDrainCrashLoopFastPath(t *testing.T) {
+	pod := func(name string, crashing bool) core.Pod {
+		grace := int64(30)
+		p := core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       core.PodSpec{TerminationGracePeriodSeconds: &grace},
+			Status:     core.PodStatus{Conditions: []core.PodCondition{{Type: core.PodReady, Status: core.ConditionTrue}}},
+		}
+		if crashing {
+			p.Status.Conditions[0].Status = core.ConditionFalse
+			p.Status.ContainerStatuses = []core.ContainerStatus{{State: core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: reasonCrashLoopBackOff}}}}
+		}
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			pod("web", false),
+			pod("api", false),
+			pod("crashing", true),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	m := &crashLoopMetrics{}
+	d := NewAPICordonDrainer(c, MaxGracePeriod(time.Minute), WithCrashLoopFastPath(time.Second), WithDeterministicOrder(true), WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	var got []string
+	grace := map[string]int64{}
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			e := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+			got = append(got, e.GetName())
+			grace[e.GetName()] = *e.DeleteOptions.GracePeriodSeconds
+		}
+	}
+	// The crashlooping pod comes first, then the others in deterministic
+	// order.
+	if want := []string{"crashing", "api", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("evictions: want %v, got %v", want, got)
+	}
+	want := map[string]int64{"crashing": 1, "web": 30, "api": 30}
+	if !reflect.DeepEqual(grace, want) {
+		t.Errorf("grace periods: want %v, got %v", want, grace)
+	}
+	m.Lock()
+	defer m.Unlock()
+	if m.cleared != 1 {
+		t.Errorf("crashloops cleared: want 1, got %d", m.cleared)
+	}
+}
+
+// crashLoopMetrics counts the crashlooping pods cleared by drains.
+type crashLoopMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	cleared int
+}
+
+func (m *crashLoopMetrics) CrashLoopCleared(string) {
+	m.Lock()
+	defer m.Unlock()
+	m.cleared++
+}
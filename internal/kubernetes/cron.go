@@ -0,0 +1,168 @@
+package kubernetes
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// cronHorizon bounds how far ahead a CronSchedule looks for its next
+// occurrence. Expressions that never match, such as February 30th, have none.
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// A CronSchedule is a recurring time expressed as a standard five field cron
+// expression: minute, hour, day of month, month and day of week.
+type CronSchedule struct {
+	expr string
+
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny are set when the day of month or the day of week
+	// is unrestricted. Per cron convention, a day matches if either field
+	// matches when both are restricted.
+	domAny, dowAny bool
+}
+
+// ParseCronSchedule parses a five field cron expression, e.g. "0 1 * * 0" for
+// Sundays at 01:00. Each field may be a wildcard, a value, a range, a list, or
+// any of these with a step, e.g. "*/15" or "1-5". Days of week go from 0, for
+// Sunday, to 6; 7 is also Sunday.
+func ParseCronSchedule(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+	s := &CronSchedule{expr: expr, domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	for i, f := range []struct {
+		bits     *uint64
+		min, max int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dom, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dow, 0, 7},
+	} {
+		bits, err := parseCronField(fields[i], f.min, f.max)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cron expression %q", expr)
+		}
+		*f.bits = bits
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField returns the supplied cron field as a set of bits, one per
+// allowed value between min and max.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, errors.Errorf("invalid step in %q", part)
+			}
+			rng = part[:i]
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.Errorf("invalid value in %q", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.Errorf("invalid value in %q", part)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, errors.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *CronSchedule) String() string {
+	return s.expr
+}
+
+// Next returns the first occurrence of the schedule at or after the supplied
+// time, in its location, to the minute. It returns the zero time if there is
+// no occurrence within the next five years.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute)
+	if next.Before(t) {
+		next = next.Add(time.Minute)
+	}
+	for deadline := t.Add(cronHorizon); !next.After(deadline); {
+		y, m, d := next.Date()
+		switch {
+		case s.month&(1<<uint(m)) == 0:
+			next = time.Date(y, m+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.dayMatches(next):
+			next = time.Date(y, m, d+1, 0, 0, 0, 0, next.Location())
+		case s.hour&(1<<uint(next.Hour())) == 0:
+			next = time.Date(y, m, d, next.Hour()+1, 0, 0, 0, next.Location())
+		case s.minute&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// WithGroupDrainSchedules restricts the drains of the nodes of some node
+// groups to the occurrences of a cron schedule. Each minute matching the
+// schedule of a group allows the drain of one of its nodes, at least the period
+// between drains after the previous one. Drains of these groups are pushed to
+// the next such minute. Nodes of other groups are drained as soon as possible.
+// Groups are identified by the node group label.
+func WithGroupDrainSchedules(schedules map[string]*CronSchedule) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.groupSchedules = schedules
+	}
+}
+
+// groupScheduleSlotLocked returns the earliest time, no sooner than the
+// supplied time, allowed by the cron schedule of the supplied group, if any. It
+// must be called with the lock held.
+func (d *DrainSchedules) groupScheduleSlotLocked(group string, when time.Time) time.Time {
+	s, ok := d.groupSchedules[group]
+	if !ok || group == "" {
+		return when
+	}
+	if last, ok := d.groupLastDrain[group]; ok && when.Before(last.Add(d.period)) {
+		when = last.Add(d.period)
+	}
+	next := s.Next(when)
+	if next.IsZero() {
+		d.logger.Warn("Drain schedule of node group never occurs, ignoring it", zap.String("group", group), zap.Stringer("schedule", s))
+		return when
+	}
+	return next
+}
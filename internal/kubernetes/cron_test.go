@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCronSchedule(t *testing.T) {
+	// Wednesday.
+	from := time.Date(2020, 1, 1, 12, 30, 15, 0, time.UTC)
+	cases := []struct {
+		name    string
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "EveryMinute", expr: "* * * * *", want: time.Date(2020, 1, 1, 12, 31, 0, 0, time.UTC)},
+		{name: "Sundays", expr: "0 1 * * 0", want: time.Date(2020, 1, 5, 1, 0, 0, 0, time.UTC)},
+		{name: "SundaysAsSeven", expr: "0 1 * * 7", want: time.Date(2020, 1, 5, 1, 0, 0, 0, time.UTC)},
+		{name: "Step", expr: "*/20 13-14 * * *", want: time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC)},
+		{name: "List", expr: "15,45 12 * * *", want: time.Date(2020, 1, 1, 12, 45, 0, 0, time.UTC)},
+		{name: "DayOfMonthOrWeek", expr: "0 0 15 * 5", want: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{name: "Month", expr: "0 0 1 3 *", want: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Never", expr: "0 0 30 2 *", want: time.Time{}},
+		{name: "TooFewFields", expr: "0 1 * *", wantErr: true},
+		{name: "OutOfRange", expr: "60 * * * *", wantErr: true},
+		{name: "InvalidStep", expr: "*/0 * * * *", wantErr: true},
+		{name: "InvalidValue", expr: "a * * * *", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseCronSchedule(tc.expr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseCronSchedule(%q) error = %v, wantErr %v", tc.expr, err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := s.Next(from); !got.Equal(tc.want) {
+				t.Errorf("Next(%v): want %v, got %v", from, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_GroupDrainSchedules(t *testing.T) {
+	// Wednesday.
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	sundays, err := ParseCronSchedule("0 1 * * 0")
+	if err != nil {
+		t.Fatalf("ParseCronSchedule() error = %v", err)
+	}
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Minute, zap.NewNop(),
+		WithDispatcher(NewManualDispatcher(start)),
+		WithNodeGroupLabel("group"),
+		WithGroupDrainSchedules(map[string]*CronSchedule{"batch": sundays}),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	cases := []struct {
+		name  string
+		group string
+		want  time.Time
+	}{
+		{name: "batch-1", group: "batch", want: time.Date(2020, 1, 5, 1, 0, 0, 0, time.UTC)},
+		{name: "web", group: "web", want: start.Add(2 * time.Minute)},
+		{name: "ungrouped", want: start.Add(3 * time.Minute)},
+		// Only one drain of the group fits each occurrence.
+		{name: "batch-2", group: "batch", want: time.Date(2020, 1, 12, 1, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		n := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: tc.name, Labels: map[string]string{"group": tc.group}}}
+		when, err := scheduler.Schedule(n)
+		if err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", n.GetName(), err)
+		}
+		if !when.Equal(tc.want) {
+			t.Errorf("DrainSchedules.Schedule(%s): want %v, got %v", n.GetName(), tc.want, when)
+		}
+	}
+}
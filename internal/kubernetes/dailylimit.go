@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DailyAttempts counts the drains of a node scheduled during a day.
+type DailyAttempts struct {
+	// Day is when the day started.
+	Day   time.Time `json:"day"`
+	Count int       `json:"count"`
+}
+
+// WithDailyDrainLimit limits the drains scheduled for each node to max per day,
+// so that a flapping node is not drained over and over. Days start the supplied
+// offset past midnight UTC. Further drains are refused with a DailyLimitError
+// until the next day starts. Retries of a scheduled drain are not counted. Zero
+// disables the limit.
+func WithDailyDrainLimit(max int, reset time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.dailyLimit = max
+		d.dailyReset = reset
+	}
+}
+
+// dayStart returns when the day including the supplied time started.
+func (d *DrainSchedules) dayStart(t time.Time) time.Time {
+	return t.UTC().Add(-d.dailyReset).Truncate(24 * time.Hour).Add(d.dailyReset)
+}
+
+// checkDailyLimitLocked returns a DailyLimitError if the named node reached the
+// daily drain limit. Otherwise it counts a new drain of the node. It must be
+// called with the lock held.
+func (d *DrainSchedules) checkDailyLimitLocked(name string) error {
+	if d.dailyLimit <= 0 {
+		return nil
+	}
+	day := d.dayStart(d.now())
+	a := d.dailyAttempts[name]
+	if !a.Day.Equal(day) {
+		a = DailyAttempts{Day: day}
+	}
+	if a.Count >= d.dailyLimit {
+		d.logger.Info("Refusing to schedule drain, daily limit reached", zap.String("node", name), zap.Int("attempts", a.Count), zap.Time("day", day))
+		d.metrics.DailyLimitReached(name)
+		return NewDailyLimitError(name, a.Count, day.Add(24*time.Hour))
+	}
+	a.Count++
+	d.dailyAttempts[name] = a
+	d.forgetPastDaysLocked(day)
+	return nil
+}
+
+// forgetPastDaysLocked forgets the attempts counted before the supplied day. It
+// must be called with the lock held.
+func (d *DrainSchedules) forgetPastDaysLocked(day time.Time) {
+	for name, a := range d.dailyAttempts {
+		if a.Day.Before(day) {
+			delete(d.dailyAttempts, name)
+		}
+	}
+}
+
+type DailyLimitError struct {
+	error
+}
+
+func NewDailyLimitError(name string, attempts int, next time.Time) error {
+	return &DailyLimitError{
+		fmt.Errorf("node %s was already scheduled for drain %d times today, refusing until %s", name, attempts, next.Format(time.RFC3339)),
+	}
+}
+
+func IsDailyLimitError(err error) bool {
+	_, ok := err.(*DailyLimitError)
+	return ok
+}
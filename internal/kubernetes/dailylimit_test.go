@@ -0,0 +1,51 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_DailyDrainLimit(t *testing.T) {
+	start := time.Date(2020, 1, 1, 5, 0, 0, 0, time.UTC)
+	store := &MemoryStateStore{}
+	dispatcher := NewManualDispatcher(start)
+	newScheduler := func() *DrainSchedules {
+		return NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(),
+			WithDailyDrainLimit(2, 6*time.Hour), WithStateStore(store), WithDispatcher(dispatcher)).(*DrainSchedules)
+	}
+	scheduler := newScheduler()
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	other := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "other"}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule() attempt %d error = %v", i+1, err)
+		}
+		scheduler.DeleteSchedule(nodeName)
+	}
+	if _, err := scheduler.Schedule(node); !IsDailyLimitError(err) {
+		t.Errorf("DrainSchedules.Schedule() attempt 3: want DailyLimitError, got %v", err)
+	}
+	if _, err := scheduler.Schedule(other); err != nil {
+		t.Errorf("DrainSchedules.Schedule(other) error = %v", err)
+	}
+	scheduler.DeleteSchedule(other.GetName())
+
+	// The attempts survive restarts.
+	scheduler = newScheduler()
+	if _, err := scheduler.Schedule(node); !IsDailyLimitError(err) {
+		t.Errorf("DrainSchedules.Schedule() after restart: want DailyLimitError, got %v", err)
+	}
+
+	// The day ends at 06:00 UTC.
+	dispatcher.ProcessDue(start.Add(time.Hour))
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Errorf("DrainSchedules.Schedule() the next day error = %v", err)
+	}
+	scheduler.DeleteSchedule(nodeName)
+}
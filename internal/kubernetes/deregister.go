@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const deferralReasonDeregister = "deregister-failed"
+
+// A PreDrainDeregisterHook removes the supplied node from the external service
+// registries, such as Consul or Eureka, that route traffic to the workloads it
+// runs outside Kubernetes. The drain is deferred if it returns an error.
+type PreDrainDeregisterHook func(ctx context.Context, n *core.Node) error
+
+// WithPreDrainDeregisterHook configures a hook invoked before evicting the pods
+// of a node, once its replacement capacity is available, so that traffic
+// drains from external service registries first. The drain is deferred by
+// DefaultDrainDeferralPeriod, and the hook invoked again when it next fires, if
+// the hook fails or does not complete within the supplied timeout. Zero means
+// no timeout. The hook must be idempotent.
+func WithPreDrainDeregisterHook(hook PreDrainDeregisterHook, timeout time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.deregisterHook = hook
+		d.deregisterTimeout = timeout
+	}
+}
+
+// deferDeregister runs the deregister hook, if any, and returns true if the
+// drain of the supplied schedule is deferred because it failed.
+func (d *DrainSchedules) deferDeregister(node *core.Node, sched *schedule) bool {
+	if d.deregisterHook == nil {
+		return false
+	}
+	ctx, cancel := sched.spanContext(), context.CancelFunc(func() {})
+	if d.deregisterTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d.deregisterTimeout)
+	}
+	err := d.deregisterHook(ctx, node)
+	cancel()
+	if err == nil {
+		return false
+	}
+
+	d.logger.Info("Deferring drain, cannot deregister node from external service registries", zap.String("node", node.GetName()), zap.String("drainID", sched.drainID), zap.Error(err))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Cannot deregister node from external service registries, retrying in %s: %v", DefaultDrainDeferralPeriod, err)
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonDeregister))
+	d.recordDeferral(node.GetName(), deferralReasonDeregister)
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	return true
+}
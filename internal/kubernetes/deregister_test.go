@@ -0,0 +1,52 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_PreDrainDeregisterHook(t *testing.T) {
+	hookErr := errors.New("consul unavailable")
+	var deregistered []string
+	m := &deferralMetrics{}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithPreDrainDeregisterHook(func(_ context.Context, n *v1.Node) error {
+			deregistered = append(deregistered, n.GetName())
+			return hookErr
+		}, 0),
+		WithMetricsRecorder(m),
+	).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+
+	// The drain is deferred while the node cannot be deregistered.
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("drained %v while the node could not be deregistered", got)
+	}
+	if want := []string{nodeName + "=" + deferralReasonDeregister}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+
+	hookErr = nil
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{nodeName}) {
+		t.Errorf("drained nodes once deregistered: want %v, got %v", []string{nodeName}, got)
+	}
+	if want := []string{nodeName, nodeName}; !reflect.DeepEqual(deregistered, want) {
+		t.Errorf("deregister hook: want calls %v, got %v", want, deregistered)
+	}
+}
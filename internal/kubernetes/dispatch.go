@@ -0,0 +1,136 @@
+package kubernetes
+
+import (
+	"sync"
+	"time"
+)
+
+// A Timer fires a function once its duration elapses. *time.Timer is a Timer.
+type Timer interface {
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+	// Reset re-arms the timer to fire after the supplied duration. It returns
+	// false if the timer already fired or was stopped.
+	Reset(d time.Duration) bool
+}
+
+// A Dispatcher tells the time and arms the timers of drain schedules.
+type Dispatcher interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// realDispatcher is the default Dispatcher, using the system clock and real
+// timers.
+type realDispatcher struct{}
+
+func (realDispatcher) Now() time.Time { return time.Now() }
+
+func (realDispatcher) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// WithDispatcher configures the dispatcher used to tell the time and to fire
+// drain schedules, in place of the system clock and real timers.
+func WithDispatcher(dispatcher Dispatcher) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.dispatcher = dispatcher
+	}
+}
+
+// now returns the current time according to the dispatcher.
+func (d *DrainSchedules) now() time.Time {
+	return d.dispatcher.Now()
+}
+
+// A ManualDispatcher is a Dispatcher whose clock only moves, and whose timers
+// only fire, when ProcessDue is called. It allows tests to drive a scheduler
+// deterministically.
+type ManualDispatcher struct {
+	sync.Mutex
+	now   time.Time
+	seq   int
+	armed map[*manualTimer]struct{}
+}
+
+// NewManualDispatcher returns a ManualDispatcher whose clock is set to the
+// supplied time.
+func NewManualDispatcher(now time.Time) *ManualDispatcher {
+	return &ManualDispatcher{now: now, armed: map[*manualTimer]struct{}{}}
+}
+
+// Now returns the time of the dispatcher clock.
+func (m *ManualDispatcher) Now() time.Time {
+	m.Lock()
+	defer m.Unlock()
+	return m.now
+}
+
+// AfterFunc returns a timer that calls f from ProcessDue once the dispatcher
+// clock reaches d past its current time.
+func (m *ManualDispatcher) AfterFunc(d time.Duration, f func()) Timer {
+	t := &manualTimer{m: m, f: f}
+	t.Reset(d)
+	return t
+}
+
+// ProcessDue moves the dispatcher clock to the supplied time, unless it is
+// already past it, then fires the timers that are due, earliest first. Timers
+// due at the same time fire in the order they were armed. Timers are fired
+// synchronously, and timers armed while firing fire too if they are due.
+// ProcessDue returns the number of timers fired.
+func (m *ManualDispatcher) ProcessDue(now time.Time) int {
+	m.Lock()
+	if now.After(m.now) {
+		m.now = now
+	}
+	m.Unlock()
+
+	fired := 0
+	for {
+		m.Lock()
+		var next *manualTimer
+		for t := range m.armed {
+			if t.due.After(m.now) {
+				continue
+			}
+			if next == nil || t.due.Before(next.due) || (t.due.Equal(next.due) && t.seq < next.seq) {
+				next = t
+			}
+		}
+		if next == nil {
+			m.Unlock()
+			return fired
+		}
+		delete(m.armed, next)
+		m.Unlock()
+
+		next.f()
+		fired++
+	}
+}
+
+type manualTimer struct {
+	m   *ManualDispatcher
+	f   func()
+	due time.Time
+	seq int
+}
+
+func (t *manualTimer) Stop() bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+	_, armed := t.m.armed[t]
+	delete(t.m.armed, t)
+	return armed
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+	_, armed := t.m.armed[t]
+	t.due = t.m.now.Add(d)
+	t.m.seq++
+	t.seq = t.m.seq
+	t.m.armed[t] = struct{}{}
+	return armed
+}
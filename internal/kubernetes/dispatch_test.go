@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestManualDispatcher(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManualDispatcher(start)
+
+	var fired []string
+	fire := func(name string) func() { return func() { fired = append(fired, name) } }
+	late := m.AfterFunc(2*time.Minute, fire("late"))
+	m.AfterFunc(time.Minute, fire("early"))
+	m.AfterFunc(time.Minute, fire("tied"))
+	stopped := m.AfterFunc(time.Minute, fire("stopped"))
+	if !stopped.Stop() {
+		t.Errorf("Stop(): want true for an armed timer")
+	}
+
+	if got := m.ProcessDue(start.Add(59 * time.Second)); got != 0 {
+		t.Errorf("ProcessDue(59s): want 0 timers fired, got %d", got)
+	}
+	if got := m.ProcessDue(start.Add(time.Minute)); got != 2 {
+		t.Errorf("ProcessDue(1m): want 2 timers fired, got %d", got)
+	}
+	if !reflect.DeepEqual(fired, []string{"early", "tied"}) {
+		t.Errorf("fired: want [early tied], got %v", fired)
+	}
+
+	// Re-arming a timer counts from the dispatcher clock.
+	if !late.Reset(30 * time.Second) {
+		t.Errorf("Reset(): want true for an armed timer")
+	}
+	m.ProcessDue(start.Add(90 * time.Second))
+	if !reflect.DeepEqual(fired, []string{"early", "tied", "late"}) {
+		t.Errorf("fired: want [early tied late], got %v", fired)
+	}
+	if late.Stop() {
+		t.Errorf("Stop(): want false for a fired timer")
+	}
+	if got := m.Now(); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Now(): want %v, got %v", start.Add(90*time.Second), got)
+	}
+}
+
+func TestDrainSchedules_ManualDispatcher(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManualDispatcher(start)
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithDispatcher(m)).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	app := &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        "app",
+		Annotations: map[string]string{drainAfterAnnotationKey: "cache"},
+	}}
+	want := map[string]time.Time{
+		"cache": start.Add(time.Minute),
+		"app":   start.Add(2 * time.Minute),
+		"web":   start.Add(3 * time.Minute),
+	}
+	for _, n := range []*v1.Node{{ObjectMeta: meta.ObjectMeta{Name: "cache"}}, app, {ObjectMeta: meta.ObjectMeta{Name: "web"}}} {
+		when, err := scheduler.Schedule(n)
+		if err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", n.GetName(), err)
+		}
+		if !when.Equal(want[n.GetName()]) {
+			t.Errorf("DrainSchedules.Schedule(%s): want %v, got %v", n.GetName(), want[n.GetName()], when)
+		}
+	}
+
+	steps := []struct {
+		now   time.Time
+		fired int
+		want  []string
+	}{
+		{now: start.Add(59 * time.Second), want: []string{}},
+		{now: start.Add(time.Minute), fired: 1, want: []string{"cache"}},
+		{now: start.Add(2 * time.Minute), fired: 1, want: []string{"cache", "app"}},
+		{now: start.Add(time.Hour), fired: 1, want: []string{"cache", "app", "web"}},
+		{now: start.Add(2 * time.Hour), want: []string{"cache", "app", "web"}},
+	}
+	for _, s := range steps {
+		if got := m.ProcessDue(s.now); got != s.fired {
+			t.Errorf("ProcessDue(%v): want %d timers fired, got %d", s.now.Sub(start), s.fired, got)
+		}
+		if got := drainer.nodes(); !reflect.DeepEqual(got, s.want) {
+			t.Errorf("ProcessDue(%v): want %v drained, got %v", s.now.Sub(start), s.want, got)
+		}
+	}
+	// Drains are timed by the dispatcher clock.
+	if h := scheduler.History("web"); len(h) != 1 || !h[0].Started.Equal(start.Add(time.Hour)) || !h[0].Finished.Equal(start.Add(time.Hour)) {
+		t.Errorf("DrainSchedules.History(web): want one drain started and finished at 1h, got %+v", h)
+	}
+}
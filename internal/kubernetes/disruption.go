@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// A PDBImpact is the disruption a drain would cause to the pods covered by a
+// PodDisruptionBudget.
+type PDBImpact struct {
+	Namespace string
+	Name      string
+	// DisruptionsAllowed is how many pods the budget currently allows to be
+	// disrupted.
+	DisruptionsAllowed int32
+	// Pods are the sorted names of the pods covered by the budget that the
+	// drain would evict.
+	Pods []string
+}
+
+// Exceeded returns true if the drain would disrupt more pods than the budget
+// currently allows, in which case some evictions would be refused.
+func (i PDBImpact) Exceeded() bool {
+	return int32(len(i.Pods)) > i.DisruptionsAllowed
+}
+
+// DisruptionPreview returns, for each PodDisruptionBudget covering pods the
+// drain of the supplied node would evict, how many disruptions it allows and
+// which of its pods would be evicted. Pods are selected as by Drain, but pods
+// already terminating are not counted since they are not evicted again.
+// Impacts are sorted by namespace then name.
+func (d *APICordonDrainer) DisruptionPreview(n *core.Node) ([]PDBImpact, error) {
+	l, err := d.listPods(n.GetName())
+	if err != nil {
+		return nil, err
+	}
+	pods := map[string][]core.Pod{}
+	for _, p := range l {
+		passes, _, err := d.evictable(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get pods for node %s", n.GetName())
+		}
+		if passes && p.GetDeletionTimestamp() == nil {
+			pods[p.GetNamespace()] = append(pods[p.GetNamespace()], p)
+		}
+	}
+
+	var impacts []PDBImpact
+	for ns, nsPods := range pods {
+		pdbs, err := d.c.PolicyV1().PodDisruptionBudgets(ns).List(context.Background(), meta.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot list PodDisruptionBudgets in namespace %s", ns)
+		}
+		for _, pdb := range pdbs.Items {
+			// A budget without a selector covers no pods.
+			if pdb.Spec.Selector == nil {
+				continue
+			}
+			selector, err := meta.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid selector of PodDisruptionBudget %s/%s", ns, pdb.GetName())
+			}
+			impact := PDBImpact{Namespace: ns, Name: pdb.GetName(), DisruptionsAllowed: pdb.Status.DisruptionsAllowed}
+			for _, p := range nsPods {
+				if selector.Matches(labels.Set(p.GetLabels())) {
+					impact.Pods = append(impact.Pods, p.GetName())
+				}
+			}
+			if len(impact.Pods) == 0 {
+				continue
+			}
+			sort.Strings(impact.Pods)
+			impacts = append(impacts, impact)
+		}
+	}
+	sort.Slice(impacts, func(i, j int) bool {
+		if impacts[i].Namespace != impacts[j].Namespace {
+			return impacts[i].Namespace < impacts[j].Namespace
+		}
+		return impacts[i].Name < impacts[j].Name
+	})
+	return impacts, nil
+}
@@ -0,0 +1,47 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDisruptionPreview(t *testing.T) {
+	pod := func(name, app string) *core.Pod {
+		return &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": app}}, Spec: core.PodSpec{NodeName: nodeName}}
+	}
+	pdb := func(name, app string, allowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &meta.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+	c := fake.NewSimpleClientset(
+		pod("web-1", "web"), pod("web-2", "web"), pod("db-1", "db"), pod("cache-1", "cache"),
+		pdb("web", "web", 1), pdb("db", "db", 1), pdb("unrelated", "other", 0),
+	)
+
+	d := NewAPICordonDrainer(c)
+	got, err := d.DisruptionPreview(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if err != nil {
+		t.Fatalf("d.DisruptionPreview(%v): %v", nodeName, err)
+	}
+	want := []PDBImpact{
+		{Namespace: "default", Name: "db", DisruptionsAllowed: 1, Pods: []string{"db-1"}},
+		{Namespace: "default", Name: "web", DisruptionsAllowed: 1, Pods: []string{"web-1", "web-2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("d.DisruptionPreview(%v): want %+v, got %+v", nodeName, want, got)
+	}
+	if got[0].Exceeded() {
+		t.Errorf("db budget exceeded, want within budget")
+	}
+	if !got[1].Exceeded() {
+		t.Errorf("web budget within budget, want exceeded")
+	}
+}
@@ -3,53 +3,484 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"go.opencensus.io/stats"
-	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	core "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/tools/record"
 )
 
 const (
 	SetConditionTimeout     = 10 * time.Second
 	SetConditionRetryPeriod = 50 * time.Millisecond
+
+	// DefaultDrainDeferralPeriod is how long a drain is postponed when a
+	// pre-drain check is not satisfied.
+	DefaultDrainDeferralPeriod = 1 * time.Minute
+
+	// DefaultMinDrainPeriod is the shortest period between drains allowed
+	// unless WithMinPeriod says otherwise, so that a misconfigured period
+	// cannot drain the whole cluster at once.
+	DefaultMinDrainPeriod = 10 * time.Second
+
+	// drainAfterAnnotationKey lists the nodes, or node groups prefixed with
+	// drainAfterGroupPrefix, that must complete draining before this node.
+	drainAfterAnnotationKey = "draino.kubernetes.io/drain-after"
+	drainAfterGroupPrefix   = "group:"
 )
 
 type DrainScheduler interface {
 	HasSchedule(name string) (has, failed bool)
+	// FailureReason returns the reason the drain of the named node failed,
+	// if its schedule is failed.
+	FailureReason(name string) (reason string, failed bool)
+	// HasSchedules returns the schedule status of each of the named nodes.
+	HasSchedules(names []string) map[string]ScheduleStatus
 	Schedule(node *v1.Node) (time.Time, error)
+	// ScheduleWithKey schedules the drain of the supplied node on behalf of
+	// the request identified by key. Repeating a request with the same key
+	// returns the existing schedule, while a different key reschedules the
+	// drain.
+	ScheduleWithKey(node *v1.Node, key string) (time.Time, error)
+	// ScheduleWithTransition schedules the drain of the supplied node, whose
+	// offending condition transitioned at the supplied time, recording how
+	// long after the transition the drain was scheduled.
+	ScheduleWithTransition(node *v1.Node, transitionTime time.Time) (time.Time, error)
 	DeleteSchedule(name string)
+	// ReconcileFromNodes recreates the schedules of the supplied nodes from
+	// their DrainScheduled condition.
+	ReconcileFromNodes(nodes []*v1.Node)
 	IsScheduledByOldEvent(name string, transitionTime time.Time) bool
+	// RecordStaleEvent records that the schedule of the named node is older
+	// than the supplied transition of its offending condition, and is being
+	// replaced.
+	RecordStaleEvent(name string, transitionTime time.Time)
+	// AnyInProgress returns true if a node is currently being drained.
+	AnyInProgress() bool
+	// InProgressNodes returns the sorted names of the nodes currently being
+	// drained.
+	InProgressNodes() []string
+	// IsDraining returns true if the named node is currently being drained,
+	// without contending with the scheduler.
+	IsDraining(name string) bool
+	// State returns the state of the drain of the named node.
+	State(name string) DrainState
 }
 
 type DrainSchedules struct {
 	sync.Mutex
 	schedules map[string]*schedule
+	// inProgress holds the names of the nodes being drained, and draining a
+	// drainingSet snapshot of them.
+	inProgress map[string]struct{}
+	draining   atomic.Value
 
 	lastDrainScheduledFor time.Time
 	period                time.Duration
+	minPeriod             time.Duration
 
 	logger        *zap.Logger
 	drainer       Drainer
 	eventRecorder record.EventRecorder
+
+	groupLabel  string
+	maxDeferral time.Duration
+
+	preDrainCapacityHook    PreDrainCapacityHook
+	preDrainCapacityTimeout time.Duration
+
+	// deregisterHook deregisters nodes from external service registries
+	// before their pods are evicted.
+	deregisterHook    PreDrainDeregisterHook
+	deregisterTimeout time.Duration
+
+	feasibilityScorer FeasibilityScorer
+
+	rateBudget RateBudget
+	// permits, if any, grants a batch of drain permits per interval.
+	permits *drainPermits
+
+	tracer trace.Tracer
+
+	history      *drainHistory
+	timelines    *drainTimelines
+	throughput   *drainThroughput
+	eventReasons EventReasons
+
+	throttle *LatencyThrottle
+
+	conditionSink ConditionSink
+
+	// groupCooldown is the minimum time between the drains of a node group.
+	// groupLastDrain holds the time the last drain of each group was
+	// scheduled for.
+	groupCooldown  time.Duration
+	groupLastDrain map[string]time.Time
+	// groupSchedules holds the cron schedules restricting when the nodes of
+	// each group may be drained.
+	groupSchedules map[string]*CronSchedule
+	// interleaveGroups alternates drains across the node groups with pending
+	// drains. groupTurns holds the sequence number of the last turn of each
+	// group, groupTurn the last sequence number handed out.
+	interleaveGroups bool
+	groupTurns       map[string]int
+	groupTurn        int
+
+	// ageProfiles determine how aggressively nodes are drained from the age
+	// of their offending condition.
+	ageProfiles []ConditionAgeProfile
+
+	// blocked tracks the constraint each pending drain is blocked on.
+	blocked *blockedDrains
+
+	// pausedGroups are the node groups whose drains are deferred.
+	pausedGroups map[string]struct{}
+	// drainsEnabled, if any, defers all drains while false.
+	drainsEnabled DrainSwitch
+
+	// dailyAttempts counts the drains of each node scheduled during the
+	// current day, limited to dailyLimit.
+	dailyLimit    int
+	dailyReset    time.Duration
+	dailyAttempts map[string]DailyAttempts
+
+	stateStore StateStore
+	stateMu    sync.Mutex
+	// restoredRetries are the retries of failed drains loaded from the state
+	// store, until the schedules of their nodes are reconciled.
+	restoredRetries map[string]DrainRetryState
+	// restoredKeys are the keys of the drains loaded from the state store,
+	// until the schedules of their nodes are reconciled.
+	restoredKeys map[string]string
+
+	// waves lists the scheduled waves, in order.
+	waves []*drainWave
+	// drained maps the nodes whose last drain succeeded to their group, so
+	// that drains depending on them are released once their schedule is
+	// gone.
+	drained map[string]string
+
+	metrics MetricsRecorder
+
+	postDrainPolicy   map[string]PostDrainAction
+	failedDrainAction FailedDrainAction
+
+	minNodeAge time.Duration
+
+	maxSchedules int
+
+	// maxFailedSchedules, if positive, is the number of failed schedules
+	// retained.
+	maxFailedSchedules int
+
+	eligibilityCheck DrainEligibilityCheck
+
+	safetyValidator SafetyValidator
+	safeModePolicy  SafeModePolicy
+
+	// scaleDownActive defers drains while the cluster autoscaler is
+	// scaling down.
+	scaleDownActive ScaleDownSignal
+
+	// healthGate defers drains, by healthBackoff doubling up to
+	// maxHealthBackoff, while the cluster is unhealthy.
+	healthGate       HealthGate
+	healthBackoff    time.Duration
+	maxHealthBackoff time.Duration
+
+	// countPending counts the pending pods of the cluster, deferring drains
+	// while there are more than maxPending.
+	countPending PendingPodCounter
+	maxPending   int
+
+	// approver must approve drains before they proceed.
+	approver DrainApprover
+
+	// pdbAwareOrder defers drains sharing a tight PodDisruptionBudget with a
+	// drain in progress.
+	pdbAwareOrder bool
+	// pdbPrecheck refuses to schedule drains that PodDisruptionBudgets
+	// allowing no disruptions would block.
+	pdbPrecheck bool
+
+	// allowedReasons, if any, are the only reasons drains may be scheduled
+	// for by ScheduleWithReason.
+	allowedReasons map[string]bool
+	// optInAnnotation, if any, must be true for the drains of nodes to be
+	// scheduled.
+	optInAnnotation string
+
+	// breaker pauses all drains while too many recent attempts failed.
+	breaker *circuitBreaker
+
+	// termination skips the drains of nodes being terminated by their
+	// provider.
+	termination *terminationCheck
+
+	// unreachableBackoff, if positive, defers the drains that fail because
+	// the API server is unreachable, by up to maxUnreachableBackoff.
+	unreachableBackoff    time.Duration
+	maxUnreachableBackoff time.Duration
+
+	// cordonOwnerAnnotation, if any, identifies the nodes already cordoned
+	// by draino when their drain fires, handled per alreadyCordoned.
+	cordonOwnerAnnotation string
+	alreadyCordoned       AlreadyCordonedPolicy
+
+	// mergeReasons merges the reasons of further requests to drain a node
+	// into its existing schedule.
+	mergeReasons bool
+
+	onDrainStats  OnDrainStats
+	onDrainFailed OnDrainFailed
+
+	// confirmNodes, if set, lists the nodes polled for the confirmation
+	// that drained nodes reached confirmState within confirmTimeout.
+	confirmNodes      NodeLister
+	confirmState      DrainEndState
+	confirmTimeout    time.Duration
+	confirmPollPeriod time.Duration
+
+	// conditionDelay delays writing the drain condition of newly scheduled
+	// drains.
+	conditionDelay time.Duration
+
+	// prepareHook and commitHook run around writing the drain condition of
+	// newly scheduled drains.
+	prepareHook         PrepareHook
+	commitHook          CommitHook
+	scheduleHookTimeout time.Duration
+
+	// legacyConditionTypes are treated as the DrainScheduled condition when
+	// reconciling schedules from nodes.
+	legacyConditionTypes []string
+
+	// pendingAgeInterval is how often the age of the oldest pending schedule
+	// is recorded.
+	pendingAgeInterval time.Duration
+
+	// minPodsMoved is the number of pods a drain must move to count as
+	// succeeded rather than empty.
+	minPodsMoved int
+
+	// stages splits drains into a cordon stage and an eviction stage,
+	// bounded by cordonSlots and evictionSlots when they are not nil.
+	stages            bool
+	cordonSlots       *LocalRateBudget
+	evictionSlots     *LocalRateBudget
+	cordonsInFlight   int32
+	evictionsInFlight int32
+
+	instanceTypeLabel string
+
+	zoneLimit  int
+	zoneWindow time.Duration
+	// zoneDrains holds the completion times of the recent drains of each
+	// zone.
+	zoneDrains map[string][]time.Time
+
+	podBudget       int
+	podBudgetWindow time.Duration
+	podCounter      PodCounter
+	// podEvictions are the drains counted against the pod eviction budget,
+	// oldest first.
+	podEvictions []podEviction
+
+	disruptionCeiling float64
+	disruptionCounter DisruptionCounter
+	// disruptedPods holds the number of pods being evicted by each drain in
+	// progress, out of the disruptionTotal pods last counted in the cluster.
+	disruptedPods   map[string]int
+	disruptionTotal int
+
+	dispatcher Dispatcher
+
+	// maxAttempts is the number of times a drain is attempted before it is
+	// marked failed. Failed attempts are retried after retryBackoff, doubled
+	// after each failure.
+	maxAttempts         int
+	retryBackoff        time.Duration
+	forceOnFinalAttempt bool
+}
+
+// A DrainEligibilityCheck returns false if the supplied node no longer meets
+// the criteria to be drained, typically because it recovered.
+type DrainEligibilityCheck func(n *v1.Node) (stillEligible bool)
+
+// A PreDrainCapacityHook requests replacement capacity for the pods of the
+// supplied node and waits for it to become available. The drain is deferred
+// if it returns an error.
+type PreDrainCapacityHook func(ctx context.Context, n *v1.Node) error
+
+// DrainSchedulesOption configures a DrainSchedules.
+type DrainSchedulesOption func(d *DrainSchedules)
+
+// WithNodeGroupLabel configures the node label whose value identifies the
+// group a node belongs to.
+func WithNodeGroupLabel(label string) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.groupLabel = label
+	}
+}
+
+// WithMinPeriod configures the shortest period between drains. Shorter periods,
+// whether supplied to NewDrainSchedules or SetPeriod, are raised to it. It
+// defaults to DefaultMinDrainPeriod; zero disables the floor.
+func WithMinPeriod(p time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.minPeriod = p
+	}
+}
+
+// WithMaxDeferral configures how long soft constraints, such as drain
+// dependencies, may defer a schedule. Once a schedule is older than the
+// supplied duration its drain is fired regardless of soft constraints. Zero
+// disables the deadline.
+func WithMaxDeferral(m time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.maxDeferral = m
+	}
+}
+
+// WithPreDrainCapacityHook configures a hook invoked before evicting the pods of
+// a node. The drain is deferred if the hook fails or does not complete within
+// the supplied timeout. Zero means no timeout.
+func WithPreDrainCapacityHook(hook PreDrainCapacityHook, timeout time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.preDrainCapacityHook = hook
+		d.preDrainCapacityTimeout = timeout
+	}
+}
+
+// WithTracer configures the tracer used to record a span for each drain, from
+// its schedule to its completion. Tracing is disabled when no tracer is set.
+func WithTracer(t trace.Tracer) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.tracer = t
+	}
+}
+
+// WithDrainHistory configures how many past drain outcomes are remembered for
+// each node, and for how many nodes at most. A size of zero disables history.
+func WithDrainHistory(size, maxNodes int) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.history = newDrainHistory(size, maxNodes)
+	}
+}
+
+// WithScheduleEventReasons configures the reasons of the events recorded by
+// the scheduler.
+func WithScheduleEventReasons(r EventReasons) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.eventReasons = r.withDefaults()
+	}
+}
+
+// WithLatencyThrottle configures a throttle that backs off the period between
+// drains while the API server is slow to respond. The latency of the calls
+// marking nodes is fed to the throttle.
+func WithLatencyThrottle(t *LatencyThrottle) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.throttle = t
+	}
+}
+
+// WithMinNodeAge configures the minimum age of the nodes that may be scheduled
+// for drain. Younger nodes, which may still be initializing, are refused with a
+// NodeTooYoungError. Zero disables the minimum.
+func WithMinNodeAge(a time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.minNodeAge = a
+	}
+}
+
+// WithMaxSchedules limits the number of schedules whose drain is pending or in
+// progress at once. Once it is reached, new drains are refused with a
+// SchedulerFullError until drains finish or schedules are deleted. Zero
+// disables the limit.
+func WithMaxSchedules(max int) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.maxSchedules = max
+	}
 }
 
-func NewDrainSchedules(drainer Drainer, eventRecorder record.EventRecorder, period time.Duration, logger *zap.Logger) DrainScheduler {
-	return &DrainSchedules{
-		schedules:     map[string]*schedule{},
-		period:        period,
-		logger:        logger,
-		drainer:       drainer,
-		eventRecorder: eventRecorder,
+// WithMinPodsMoved records the drains that succeeded but moved fewer than the
+// supplied number of pods with result empty, rather than succeeded, so that
+// only drains that relocated workload count as succeeded. It requires a
+// drainer that is a DrainSummarizer. Zero disables the threshold.
+func WithMinPodsMoved(min int) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.minPodsMoved = min
 	}
 }
 
+// WithDrainEligibilityCheck configures a check run when a drain fires. Drains
+// of nodes that are no longer eligible are skipped: their schedule is deleted
+// and, if the drainer is a ConditionClearer, their drain condition cleared.
+func WithDrainEligibilityCheck(c DrainEligibilityCheck) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.eligibilityCheck = c
+	}
+}
+
+func NewDrainSchedules(drainer Drainer, eventRecorder record.EventRecorder, period time.Duration, logger *zap.Logger, opts ...DrainSchedulesOption) DrainScheduler {
+	d := &DrainSchedules{
+		schedules:         map[string]*schedule{},
+		inProgress:        map[string]struct{}{},
+		groupLastDrain:    map[string]time.Time{},
+		groupTurns:        map[string]int{},
+		drained:           map[string]string{},
+		blocked:           &blockedDrains{by: map[string]string{}},
+		zoneDrains:        map[string][]time.Time{},
+		pausedGroups:      map[string]struct{}{},
+		disruptedPods:     map[string]int{},
+		dailyAttempts:     map[string]DailyAttempts{},
+		restoredRetries:   map[string]DrainRetryState{},
+		restoredKeys:      map[string]string{},
+		period:            period,
+		minPeriod:         DefaultMinDrainPeriod,
+		logger:            logger,
+		drainer:           drainer,
+		eventRecorder:     eventRecorder,
+		history:           newDrainHistory(DefaultDrainHistorySize, DefaultDrainHistoryMaxNodes),
+		throughput:        newDrainThroughput(throughputBucket, throughputRetention),
+		eventReasons:      DefaultEventReasons,
+		metrics:           OpenCensusMetricsRecorder{},
+		dispatcher:        realDispatcher{},
+		maxAttempts:       1,
+		instanceTypeLabel: v1.LabelInstanceTypeStable,
+	}
+	d.timelines = newDrainTimelines(d.now, DefaultTimelineMaxEntries, DefaultTimelineMaxNodes)
+	for _, o := range opts {
+		o(d)
+	}
+	if p := d.floorPeriod(period); p != period {
+		d.period = p
+		if d.throttle != nil {
+			d.throttle.SetPeriod(p)
+		}
+	}
+	if d.permits != nil {
+		d.permits.granted = d.now()
+	}
+	d.loadState()
+	if d.pendingAgeInterval > 0 {
+		d.reportPendingAge()
+	}
+	return d
+}
+
 func (d *DrainSchedules) IsScheduledByOldEvent(name string, transitionTime time.Time) bool {
 	d.Lock()
 	defer d.Unlock()
@@ -60,6 +491,28 @@ func (d *DrainSchedules) IsScheduledByOldEvent(name string, transitionTime time.
 	return sched.when.Before(transitionTime) && !sched.isFailed() && !sched.finish.IsZero()
 }
 
+// RecordStaleEvent records a metric and an event for the named node, whose
+// schedule is older than the supplied transition of its offending condition.
+// Such schedules surface events of the watch stream received out of order.
+func (d *DrainSchedules) RecordStaleEvent(name string, transitionTime time.Time) {
+	d.Lock()
+	sched, ok := d.schedules[name]
+	var when time.Time
+	if ok {
+		when = sched.when
+	}
+	d.Unlock()
+
+	d.logger.Info("Schedule predates condition transition", zap.String("node", name), zap.Time("when", when), zap.Time("transition", transitionTime))
+	d.metrics.StaleEvent(name)
+	nr := &v1.ObjectReference{Kind: "Node", Name: name, UID: types.UID(name)}
+	if when.IsZero() {
+		d.eventRecorder.Eventf(nr, v1.EventTypeWarning, d.eventReasons.DrainStaleEvent, "Condition transitioned at %s after the drain was scheduled", transitionTime.Format(time.RFC3339))
+		return
+	}
+	d.eventRecorder.Eventf(nr, v1.EventTypeWarning, d.eventReasons.DrainStaleEvent, "Drain scheduled for %s predates the condition transition at %s", when.Format(time.RFC3339), transitionTime.Format(time.RFC3339))
+}
+
 func (d *DrainSchedules) HasSchedule(name string) (has, failed bool) {
 	d.Lock()
 	defer d.Unlock()
@@ -71,48 +524,334 @@ func (d *DrainSchedules) HasSchedule(name string) (has, failed bool) {
 	return true, sched.isFailed()
 }
 
+// FailureReason returns the reason the drain of the named node failed, as
+// classified by DrainFailureReason, if its schedule is failed. It returns an
+// empty reason for unknown schedules and schedules that are not failed.
+func (d *DrainSchedules) FailureReason(name string) (reason string, failed bool) {
+	d.Lock()
+	defer d.Unlock()
+	sched, ok := d.schedules[name]
+	if !ok || !sched.isFailed() {
+		return "", false
+	}
+	return sched.failureReason, true
+}
+
+// ScheduleStatus describes the drain schedule of a node.
+type ScheduleStatus struct {
+	Has    bool
+	Failed bool
+	When   time.Time
+}
+
+func (d *DrainSchedules) HasSchedules(names []string) map[string]ScheduleStatus {
+	d.Lock()
+	defer d.Unlock()
+	statuses := make(map[string]ScheduleStatus, len(names))
+	for _, name := range names {
+		sched, ok := d.schedules[name]
+		if !ok {
+			statuses[name] = ScheduleStatus{}
+			continue
+		}
+		statuses[name] = ScheduleStatus{Has: true, Failed: sched.isFailed(), When: sched.when}
+	}
+	return statuses
+}
+
+func (d *DrainSchedules) AnyInProgress() bool {
+	d.Lock()
+	defer d.Unlock()
+	return len(d.inProgress) > 0
+}
+
+func (d *DrainSchedules) InProgressNodes() []string {
+	d.Lock()
+	defer d.Unlock()
+	nodes := make([]string, 0, len(d.inProgress))
+	for name := range d.inProgress {
+		nodes = append(nodes, name)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// History returns the outcomes of the last drains of the named node, oldest
+// first. History is kept after the schedule of the node is deleted.
+func (d *DrainSchedules) History(name string) []DrainRecord {
+	return d.history.get(name)
+}
+
 func (d *DrainSchedules) DeleteSchedule(name string) {
 	d.Lock()
 	defer d.Unlock()
 	if s, ok := d.schedules[name]; ok {
-		s.timer.Stop()
-		delete(d.schedules, name)
+		d.deleteScheduleLocked(name, s)
 	} else {
 		d.logger.Warn("Entry not found in deletion schedule", zap.String("node", name))
 	}
 }
 
+// DeleteSchedulesMatching deletes the schedules of the nodes matching the
+// supplied selector, aborting their drains if in progress, and clears their
+// drain condition if the drainer is a ConditionClearer. Nodes are looked up in
+// the supplied store; schedules of nodes it cannot get are kept. It returns the
+// number of schedules deleted.
+func (d *DrainSchedules) DeleteSchedulesMatching(selector labels.Selector, nodes NodeStore) (deleted int) {
+	d.Lock()
+	names := make([]string, 0, len(d.schedules))
+	for name := range d.schedules {
+		names = append(names, name)
+	}
+	d.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		node, err := nodes.Get(name)
+		if err != nil {
+			d.logger.Info("Cannot get node, keeping its schedule", zap.String("node", name), zap.Error(err))
+			continue
+		}
+		if !selector.Matches(labels.Set(node.GetLabels())) {
+			continue
+		}
+		d.Lock()
+		s, ok := d.schedules[name]
+		if ok {
+			d.deleteScheduleLocked(name, s)
+		}
+		d.Unlock()
+		if !ok {
+			continue
+		}
+		deleted++
+		d.logger.Info("Deleted schedule matching selector", zap.String("node", name), zap.Stringer("selector", selector))
+		if c, ok := d.drainer.(ConditionClearer); ok {
+			if err := c.ClearDrainCondition(node); err != nil {
+				d.logger.Info("Failed to clear drain condition", zap.String("node", name), zap.Error(err))
+			}
+		}
+	}
+	if deleted > 0 {
+		d.saveState()
+	}
+	return deleted
+}
+
+func (d *DrainSchedules) deleteScheduleLocked(name string, s *schedule) {
+	s.timer.Stop()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.endSpan("schedule deleted")
+	delete(d.schedules, name)
+	d.unblockDrain(name)
+	d.releaseDependentsLocked()
+}
+
 func (d *DrainSchedules) WhenNextSchedule() time.Time {
+	return d.whenNextSchedule(d.effectivePeriod())
+}
+
+// whenNextSchedule returns when the next drain may be scheduled, the supplied
+// period after the previous one.
+func (d *DrainSchedules) whenNextSchedule(period time.Duration) time.Time {
 	// compute drain schedule time
-	sooner := time.Now().Add(SetConditionTimeout + time.Second)
-	when := d.lastDrainScheduledFor.Add(d.period)
+	sooner := d.now().Add(SetConditionTimeout + time.Second)
+	when := d.lastDrainScheduledFor.Add(period)
 	if when.Before(sooner) {
 		when = sooner
 	}
 	return when
 }
 
+// checkSchedulable returns an error if the drain of the supplied node must not
+// be scheduled, whichever way it is requested.
+func (d *DrainSchedules) checkSchedulable(node *v1.Node) error {
+	if err := d.checkOptIn(node); err != nil {
+		return err
+	}
+	if err := d.checkNodeAge(node); err != nil {
+		return err
+	}
+	return d.checkPDBs(node)
+}
+
 func (d *DrainSchedules) Schedule(node *v1.Node) (time.Time, error) {
+	if err := d.checkSchedulable(node); err != nil {
+		return time.Time{}, err
+	}
 	d.Lock()
 	if sched, ok := d.schedules[node.GetName()]; ok {
 		d.Unlock()
+		d.MergeReasons(node.GetName(), drainReasons(node))
 		return sched.when, NewAlreadyScheduledError() // we already have a schedule planned
 	}
+	return d.scheduleLocked(node, "", nil)
+}
+
+func (d *DrainSchedules) ScheduleWithKey(node *v1.Node, key string) (time.Time, error) {
+	if err := d.checkSchedulable(node); err != nil {
+		return time.Time{}, err
+	}
+	d.Lock()
+	if sched, ok := d.schedules[node.GetName()]; ok {
+		if sched.key == key {
+			d.Unlock()
+			d.MergeReasons(node.GetName(), drainReasons(node))
+			return sched.when, nil
+		}
+		if _, draining := d.inProgress[node.GetName()]; draining {
+			d.Unlock()
+			d.MergeReasons(node.GetName(), drainReasons(node))
+			return sched.when, NewAlreadyScheduledError()
+		}
+		d.logger.Info("Rescheduling drain requested with a new key", zap.String("node", node.GetName()), zap.String("key", key), zap.String("previousKey", sched.key))
+		d.deleteScheduleLocked(node.GetName(), sched)
+	}
+	return d.scheduleLocked(node, key, nil)
+}
+
+func (d *DrainSchedules) ScheduleWithTransition(node *v1.Node, transitionTime time.Time) (time.Time, error) {
+	when, err := d.Schedule(node)
+	if err == nil && !transitionTime.IsZero() {
+		d.metrics.ScheduleLatency(node.GetName(), d.now().Sub(transitionTime))
+		d.applyConditionAge(node, transitionTime)
+	}
+	return when, err
+}
+
+// checkNodeAge returns a NodeTooYoungError if the supplied node is younger than
+// the minimum node age.
+func (d *DrainSchedules) checkNodeAge(node *v1.Node) error {
+	if d.minNodeAge <= 0 {
+		return nil
+	}
+	age := d.now().Sub(node.GetCreationTimestamp().Time)
+	if age >= d.minNodeAge {
+		return nil
+	}
+	d.metrics.NodeTooYoung(node.GetName())
+	return NewNodeTooYoungError(node.GetName(), age, d.minNodeAge)
+}
+
+// checkCapacityLocked returns a SchedulerFullError if the supplied number of
+// schedules would exceed the maximum number of schedules. It must be called
+// with the lock held.
+func (d *DrainSchedules) checkCapacityLocked(n int) error {
+	if d.maxSchedules <= 0 {
+		return nil
+	}
+	active := d.activeSchedulesLocked()
+	if active+n <= d.maxSchedules {
+		return nil
+	}
+	d.logger.Warn("Refusing to schedule drain, the scheduler is full", zap.Int("schedules", active), zap.Int("maxSchedules", d.maxSchedules))
+	d.metrics.SchedulerFull()
+	return NewSchedulerFullError(d.maxSchedules)
+}
+
+// activeSchedulesLocked returns the number of schedules whose drain is pending
+// or in progress, rather than finished. It must be called with the lock held.
+func (d *DrainSchedules) activeSchedulesLocked() int {
+	n := 0
+	for _, s := range d.schedules {
+		if s.finish.IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
+// scheduleLocked schedules the drain of the supplied node, as part of the
+// supplied wave if any. It must be called with the lock held, and releases it.
+func (d *DrainSchedules) scheduleLocked(node *v1.Node, key string, wave *drainWave) (time.Time, error) {
+	dependsOn := parseDrainAfter(node)
+	if wave != nil {
+		dependsOn = append(dependsOn, wave.after...)
+	}
+	if err := d.checkCapacityLocked(1); err != nil {
+		d.Unlock()
+		return time.Time{}, err
+	}
+	if d.hasDependencyCycle(node.GetName(), d.nodeGroup(node), dependsOn) {
+		d.Unlock()
+		return time.Time{}, NewDependencyCycleError(node.GetName())
+	}
+	if err := d.checkDailyLimitLocked(node.GetName()); err != nil {
+		d.Unlock()
+		return time.Time{}, err
+	}
 
 	// compute drain schedule time
-	when := d.WhenNextSchedule()
+	when := d.whenNextSchedule(d.periodBefore(node))
+	previous, reserved := d.lastDrainScheduledFor, when
 	d.lastDrainScheduledFor = when
-	d.schedules[node.GetName()] = d.newSchedule(node, when)
+	// The group cooldown only delays the drains of the same group.
+	// The drain is reported blocked on the last gate that pushed it later.
+	var heldBy string
+	group := d.nodeGroup(node)
+	if group != "" && d.groupCooldown > 0 {
+		if cooled := d.groupLastDrain[group].Add(d.groupCooldown); when.Before(cooled) {
+			when = cooled
+			heldBy = deferralReasonGroupCooldown
+		}
+		d.groupLastDrain[group] = when
+	}
+	// The group drain schedule and the zone drain limit may each push the
+	// drain later, until both allow it.
+	zone := nodeZone(node)
+	for {
+		slot := d.groupScheduleSlotLocked(group, when)
+		if !slot.Equal(when) {
+			heldBy = deferralReasonGroupSchedule
+		}
+		next := d.zoneSlotLocked(zone, slot)
+		if !next.Equal(slot) {
+			heldBy = deferralReasonZoneLimit
+		}
+		if next.Equal(when) {
+			break
+		}
+		when = next
+	}
+	if _, ok := d.groupSchedules[group]; ok {
+		d.groupLastDrain[group] = when
+	}
+	when = d.afterConditionDelay(when)
+	sched := d.newSchedule(node, when)
+	sched.key = key
+	sched.group = group
+	sched.zone = zone
+	sched.dependsOn = dependsOn
+	sched.reasons = drainReasons(node)
+	if wave != nil {
+		sched.wave = wave
+		wave.drainIDs[node.GetName()] = sched.drainID
+	}
+	if d.prepareHook != nil {
+		// The timer is armed once the drain is prepared.
+		sched.timer.Stop()
+	}
+	d.startDrainSpan(node, sched)
+	d.schedules[node.GetName()] = sched
+	delete(d.drained, node.GetName())
+	d.timelines.start(node.GetName(), TimelineScheduled, when.Format(time.RFC3339))
+	if heldBy != "" {
+		d.blockDrain(node.GetName(), heldBy)
+	}
 	d.Unlock()
+	if err := d.prepareSchedule(node, sched, previous, reserved); err != nil {
+		return time.Time{}, err
+	}
+	d.saveState()
+	d.mapPDBs(node, sched)
 
-	// Mark the node with the condition stating that drain is scheduled
-	if err := RetryWithTimeout(
-		func() error {
-			return d.drainer.MarkDrain(node, when, time.Time{}, false)
-		},
-		SetConditionRetryPeriod,
-		SetConditionTimeout,
-	); err != nil {
+	if d.conditionDelay > 0 {
+		d.dispatcher.AfterFunc(d.conditionDelay, func() { d.markScheduledLate(node, sched) })
+		return when, nil
+	}
+	if err := d.markScheduled(node, sched); err != nil {
 		// if we cannot mark the node, let's remove the schedule
 		d.logger.Info("Delete Schedule")
 		d.DeleteSchedule(node.GetName())
@@ -121,11 +860,94 @@ func (d *DrainSchedules) Schedule(node *v1.Node) (time.Time, error) {
 	return when, nil
 }
 
+// markScheduled marks the node of the supplied schedule with the condition
+// stating that its drain is scheduled.
+func (d *DrainSchedules) markScheduled(node *v1.Node, sched *schedule) error {
+	_, span := d.startSpan(sched.spanContext(), "draino.drain.mark_scheduled")
+	err := RetryWithTimeout(
+		func() error {
+			return d.markDrain(node, DrainStateScheduled, sched.when, time.Time{}, "")
+		},
+		SetConditionRetryPeriod,
+		SetConditionTimeout,
+	)
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	d.setDrainState(node, DrainStateScheduled, sched.when, time.Time{}, "")
+	d.commitSchedule(node, sched)
+	return nil
+}
+
 type schedule struct {
-	when   time.Time
-	failed int32
-	finish time.Time
-	timer  *time.Timer
+	drainID string
+	// key identifies the request that created the schedule, if any.
+	key     string
+	when    time.Time
+	created time.Time
+	failed  int32
+	finish  time.Time
+	timer   Timer
+
+	// node is the node the schedule drains, as it was when scheduled.
+	node *v1.Node
+
+	// failureReason is the reason the drain failed. It is set with the lock
+	// held.
+	failureReason string
+
+	// span covers the whole drain lifecycle. It is nil when tracing is
+	// disabled.
+	span trace.Span
+
+	group string
+	zone  string
+	// dependsOn lists the nodes and groups that must drain successfully
+	// before this schedule may fire. blocked is set when the timer fired
+	// while one of them was still pending. succeeded is set once the drain
+	// of this schedule succeeded.
+	dependsOn []string
+	blocked   bool
+	succeeded bool
+
+	// wave is the wave the schedule is part of, if any.
+	wave *drainWave
+
+	// reasons lists the node conditions that caused the drain.
+	reasons []string
+
+	// cancel cancels the drain of the schedule once it started. It is set
+	// with the lock held.
+	cancel context.CancelFunc
+
+	// attempt counts the times the drain of the schedule started.
+	attempt int
+
+	// paused is set when the drain was deferred because its node group is
+	// paused. It is set with the lock held.
+	paused bool
+
+	// pdbs are the PodDisruptionBudgets covering the pods of the node when it
+	// was scheduled. They are set with the lock held.
+	pdbs []PDBImpact
+
+	// lastFailure is when the last failed attempt of the drain ended, and
+	// backoff how long after it the drain is retried. They are set with the
+	// lock held.
+	lastFailure time.Time
+	backoff     time.Duration
+
+	// unreachable counts the consecutive attempts deferred because the API
+	// server was unreachable. It is set with the lock held.
+	unreachable int
+	// unhealthy counts the consecutive times the drain was deferred by the
+	// health gate. It is set with the lock held.
+	unhealthy int
+	// maxGracePeriod caps the grace period of the pods evicted, per the
+	// condition age profile of the drain, unless zero. It is set with the
+	// lock held.
+	maxGracePeriod time.Duration
 }
 
 func (s *schedule) setFailed() {
@@ -138,50 +960,661 @@ func (s *schedule) isFailed() bool {
 
 func (d *DrainSchedules) newSchedule(node *v1.Node, when time.Time) *schedule {
 	sched := &schedule{
-		when: when,
-	}
-	sched.timer = time.AfterFunc(time.Until(when), func() {
-		log := d.logger.With(zap.String("node", node.GetName()))
-		nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
-		tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node.GetName())) // nolint:gosec
-		d.eventRecorder.Event(nr, core.EventTypeWarning, eventReasonDrainStarting, "Draining node")
-		if err := d.drainer.Drain(node); err != nil {
-			log.Info("Failed to drain", zap.Error(err))
-
-			sched.finish = time.Now()
-			sched.setFailed()
-			tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultFailed)) // nolint:gosec
-			stats.Record(tags, MeasureNodesDrained.M(1))
-			d.eventRecorder.Eventf(nr, core.EventTypeWarning, eventReasonDrainFailed, "Draining failed: %v", err)
-			if err := RetryWithTimeout(
-				func() error {
-					return d.drainer.MarkDrain(node, when, sched.finish, true)
-				},
-				SetConditionRetryPeriod,
-				SetConditionTimeout,
-			); err != nil {
-				log.Error("Failed to place condition following drain failure")
+		drainID: string(uuid.NewUUID()),
+		when:    when,
+		created: d.now(),
+		node:    node,
+	}
+	sched.timer = d.dispatcher.AfterFunc(when.Sub(d.now()), func() {
+		d.runDrain(node, sched)
+	})
+	return sched
+}
+
+// runDrain is invoked when the timer of the supplied schedule fires.
+func (d *DrainSchedules) runDrain(node *v1.Node, sched *schedule) {
+	when := sched.when
+	if d.abortDeleted(node, sched) {
+		return
+	}
+	if d.skipPreempted(node, sched) {
+		return
+	}
+	if d.skipAlreadyCordoned(node, sched) {
+		return
+	}
+	if d.deferPaused(node, sched) {
+		return
+	}
+	if d.deferDisabled(node, sched) {
+		return
+	}
+	if d.deferCircuitOpen(node, sched) {
+		return
+	}
+	if d.skipRecovered(node, sched) {
+		return
+	}
+	if d.yieldTurn(node, sched) {
+		return
+	}
+	if d.deferDrain(node, sched) {
+		return
+	}
+	if d.deferScaleDown(node, sched) {
+		return
+	}
+	if d.deferUnhealthy(node, sched) {
+		return
+	}
+	if d.deferPendingPods(node, sched) {
+		return
+	}
+	if d.deferApproval(node, sched) {
+		return
+	}
+	if d.deferPDBConflict(node, sched) {
+		return
+	}
+	if !d.checkFeasibility(node, sched) {
+		return
+	}
+	if !d.checkPodBudget(node, sched) {
+		return
+	}
+	if !d.awaitCapacity(node, sched) {
+		return
+	}
+	// The schedule may have been deleted while waiting for capacity.
+	if d.abortDeleted(node, sched) {
+		return
+	}
+	if d.deferDeregister(node, sched) {
+		return
+	}
+	if d.deferNoPermit(node, sched) {
+		return
+	}
+	d.unblockDrain(node.GetName())
+	drainCtx, cancel := context.WithCancel(sched.spanContext())
+	defer cancel()
+	d.Lock()
+	sched.cancel = cancel
+	if current, ok := d.schedules[node.GetName()]; !ok || current != sched {
+		cancel()
+	}
+	d.Unlock()
+	if !d.cordonStage(drainCtx, node) {
+		d.abortDeleted(node, sched)
+		return
+	}
+	d.timelines.add(node.GetName(), TimelineCordoned, "")
+	if d.rateBudget != nil {
+		d.blockDrain(node.GetName(), deferralReasonRateBudget)
+		if err := d.rateBudget.Acquire(drainCtx); err != nil {
+			if !d.abortDeleted(node, sched) {
+				d.deferForBudget(node, sched, err)
 			}
 			return
 		}
+		d.unblockDrain(node.GetName())
+		defer d.rateBudget.Release()
+	}
+	defer func() {
+		d.Lock()
+		d.releaseDependentsLocked()
+		d.Unlock()
+	}()
+	if !d.checkSafety(node, sched) {
+		return
+	}
+	endEviction, ok := d.evictionStage(drainCtx, node)
+	if !ok {
+		d.abortDeleted(node, sched)
+		return
+	}
+	defer endEviction()
+	endDisruption, ok := d.admitDisruption(node, sched)
+	if !ok {
+		return
+	}
+	defer endDisruption()
+	defer sched.endSpan("")
 
-		log.Info("Drained")
-		sched.finish = time.Now()
-		tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultSucceeded)) // nolint:gosec
-		stats.Record(tags, MeasureNodesDrained.M(1))
-		d.eventRecorder.Event(nr, core.EventTypeWarning, eventReasonDrainSucceeded, "Drained node")
-		if err := RetryWithTimeout(
-			func() error {
-				return d.drainer.MarkDrain(node, when, sched.finish, false)
-			},
-			SetConditionRetryPeriod,
-			SetConditionTimeout,
-		); err != nil {
-			d.eventRecorder.Eventf(nr, core.EventTypeWarning, eventReasonDrainFailed, "Failed to place drain condition: %v", err)
-			log.Error(fmt.Sprintf("Failed to place condition following drain success : %v", err))
-		}
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	if v := kubeletVersion(node); v != unknownKubeletVersion {
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainStarting, "Draining node running kubelet %s", v)
+	} else {
+		d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainStarting, "Draining node")
+	}
+	started := d.now()
+	d.setDrainState(node, DrainStateInProgress, when, time.Time{}, "")
+	d.markInProgress(node, when)
+	d.Lock()
+	sched.attempt++
+	maxGracePeriod := sched.maxGracePeriod
+	d.Unlock()
+	force := d.escalateDrain(node, sched)
+	d.timelines.add(node.GetName(), TimelineEvictionStarted, fmt.Sprintf("attempt %d", sched.attempt))
+	ctx, span := d.startSpan(withMaxGracePeriod(withNodeTimeline(drainCtx, d.timelines, node.GetName()), maxGracePeriod), "draino.drain.evict")
+	ctx, timeout, cancelTimeout := d.withDrainTimeout(ctx, node)
+	err := drainTimedOut(ctx, timeout, d.drainInProgress(ctx, node, force))
+	cancelTimeout()
+	endSpan(span, err)
+	if err != nil && drainCtx.Err() != nil {
+		d.cancelledInFlight(node, sched, started, err)
+		return
+	}
+	if apierrors.IsNotFound(err) {
+		d.nodeGone(node, sched, started, err)
+		return
+	}
+	if d.deferUnreachable(node, sched, err) {
+		return
+	}
+	if IsDryRunEvictionsError(err) {
+		d.dryRunDrain(node, sched, started, err)
+		return
+	}
+	noop := IsNothingToEvictError(err)
+	if noop {
+		err = nil
+	}
+	d.recordAttempt(node, err)
+	if err != nil && d.retryDrain(node, sched, started, err) {
+		return
+	}
+	if err != nil {
+		d.failDrain(node, sched, started, err)
+		return
+	}
+
+	summary, summarized := d.takeDrainSummary(node)
+	result, reason, msg := tagResultSucceeded, d.eventReasons.DrainSucceeded, "Drained node"
+	if noop {
+		result, reason, msg = tagResultNoop, d.eventReasons.DrainNoop, "Node had no pods to evict"
+	} else if summarized && summary.podsMoved() < d.minPodsMoved {
+		result = tagResultEmpty
+	}
+	log.Info("Drained", zap.Bool("noop", noop))
+	d.Lock()
+	sched.finish = d.now()
+	sched.succeeded = true
+	d.drained[node.GetName()] = sched.group
+	d.recordZoneDrainLocked(sched)
+	d.Unlock()
+	d.history.add(node.GetName(), DrainRecord{
+		DrainID:   sched.drainID,
+		Scheduled: when,
+		Started:   started,
+		Finished:  sched.finish,
 	})
-	return sched
+	d.metrics.NodeDrained(node.GetName(), d.instanceType(node), kubeletVersion(node), result)
+	d.metrics.DrainDuration(node.GetName(), result, sched.finish.Sub(started))
+	d.recordResourcesFreed(node, result)
+	d.recordThroughput(sched.finish)
+	d.eventRecorder.AnnotatedEventf(nr, drainOutcomeAnnotations(result, sched.finish.Sub(started), summary, summarized, ""), core.EventTypeWarning, reason, msg)
+	_, span = d.startSpan(sched.spanContext(), "draino.drain.mark_succeeded")
+	err = RetryWithTimeout(
+		func() error {
+			return d.markDrain(node, DrainStateSucceeded, when, sched.finish, "")
+		},
+		SetConditionRetryPeriod,
+		SetConditionTimeout,
+	)
+	endSpan(span, err)
+	if err != nil {
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainFailed, "Failed to place drain condition: %v", err)
+		log.Error(fmt.Sprintf("Failed to place condition following drain success : %v", err))
+	}
+	d.setDrainState(node, DrainStateSucceeded, when, sched.finish, "")
+	d.timelines.add(node.GetName(), TimelineCompleted, result)
+	d.annotateResult(node, result, sched.finish)
+	d.recordDrainSummary(node, sched, result, started, sched.finish, summary, summarized)
+	d.recordWaveOutcome(node.GetName(), sched, false)
+	d.afterDrain(node, sched)
+	d.confirmDrain(node)
+}
+
+// dryRunDrain records the drain of the supplied schedule, started at the
+// supplied time, as a dry run whose evictions the API server accepted. The node
+// was not actually drained, so it is neither marked drained nor uncordoned or
+// confirmed.
+func (d *DrainSchedules) dryRunDrain(node *v1.Node, sched *schedule, started time.Time, err error) {
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	log.Info("Dry run drain accepted", zap.Error(err))
+	d.Lock()
+	sched.finish = d.now()
+	d.Unlock()
+	d.history.add(node.GetName(), DrainRecord{
+		DrainID:   sched.drainID,
+		Scheduled: sched.when,
+		Started:   started,
+		Finished:  sched.finish,
+		Error:     "dry run",
+	})
+	d.metrics.NodeDrained(node.GetName(), d.instanceType(node), kubeletVersion(node), tagResultDryRun)
+	d.metrics.DrainDuration(node.GetName(), tagResultDryRun, sched.finish.Sub(started))
+	summary, summarized := d.takeDrainSummary(node)
+	d.eventRecorder.AnnotatedEventf(nr, drainOutcomeAnnotations(tagResultDryRun, sched.finish.Sub(started), summary, summarized, ""), core.EventTypeWarning, d.eventReasons.DrainDryRun, "Dry run drain: %v", err)
+	d.timelines.add(node.GetName(), TimelineCompleted, tagResultDryRun)
+}
+
+// failDrain records the failure of the drain of the supplied schedule, started
+// at the supplied time.
+func (d *DrainSchedules) failDrain(node *v1.Node, sched *schedule, started time.Time, err error) {
+	when := sched.when
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	log.Info("Failed to drain", zap.Error(err))
+	reason := DrainFailureReason(err)
+	result := tagResultFailed
+	if IsAdmissionDeniedError(err) {
+		result = tagResultAdmissionDenied
+	}
+
+	d.Lock()
+	sched.finish = d.now()
+	sched.failureReason = reason
+	d.recordZoneDrainLocked(sched)
+	d.Unlock()
+	d.history.add(node.GetName(), DrainRecord{
+		DrainID:   sched.drainID,
+		Scheduled: when,
+		Started:   started,
+		Finished:  sched.finish,
+		Failed:    true,
+		Error:     reason,
+	})
+	sched.setFailed()
+	d.reportDrainFailed(node, reason, sched.attempt)
+	d.metrics.NodeDrained(node.GetName(), d.instanceType(node), kubeletVersion(node), result)
+	d.metrics.DrainDuration(node.GetName(), result, sched.finish.Sub(started))
+	d.recordResourcesFreed(node, result)
+	summary, summarized := d.takeDrainSummary(node)
+	d.eventRecorder.AnnotatedEventf(nr, drainOutcomeAnnotations(result, sched.finish.Sub(started), summary, summarized, reason), core.EventTypeWarning, d.eventReasons.DrainFailed, "Draining failed: %v", err)
+	_, span := d.startSpan(sched.spanContext(), "draino.drain.mark_failed")
+	err = RetryWithTimeout(
+		func() error {
+			return d.markDrain(node, DrainStateFailed, when, sched.finish, reason)
+		},
+		SetConditionRetryPeriod,
+		SetConditionTimeout,
+	)
+	endSpan(span, err)
+	if err != nil {
+		log.Error("Failed to place condition following drain failure")
+	}
+	d.setDrainState(node, DrainStateFailed, when, sched.finish, reason)
+	d.timelines.add(node.GetName(), TimelineFailed, reason)
+	d.annotateResult(node, result, sched.finish)
+	d.recordDrainSummary(node, sched, tagResultFailed, started, sched.finish, summary, summarized)
+	d.recordWaveOutcome(node.GetName(), sched, true)
+	d.afterFailedDrain(node, sched)
+	d.evictFailedSchedules()
+}
+
+// annotateResult records the supplied result of the drain of the supplied node
+// as annotations, if the drainer is a DrainResultAnnotator.
+func (d *DrainSchedules) annotateResult(node *v1.Node, result string, finish time.Time) {
+	a, ok := d.drainer.(DrainResultAnnotator)
+	if !ok {
+		return
+	}
+	if err := a.AnnotateDrainResult(node, result, finish); err != nil {
+		d.logger.Info("Failed to annotate drain result", zap.String("node", node.GetName()), zap.Error(err))
+	}
+}
+
+// abortDeleted returns true if the supplied schedule is no longer current for
+// its node. This happens when its timer fired while it was being deleted.
+func (d *DrainSchedules) abortDeleted(node *v1.Node, sched *schedule) bool {
+	d.Lock()
+	current, ok := d.schedules[node.GetName()]
+	d.Unlock()
+	if ok && current == sched {
+		return false
+	}
+	d.logger.Info("Aborting drain of deleted schedule", zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	d.metrics.DrainAborted(node.GetName())
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainAbortedDeleted, "Drain aborted because its schedule was deleted")
+	return true
+}
+
+// nodeGone records the drain of the supplied schedule as ended because its
+// node was deleted while draining. The schedule is deleted rather than failed.
+func (d *DrainSchedules) nodeGone(node *v1.Node, sched *schedule, started time.Time, err error) {
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	log.Info("Node deleted while draining, deleting its schedule", zap.Error(err))
+	sched.addSpanEvent("node gone")
+	d.Lock()
+	sched.finish = d.now()
+	if current, ok := d.schedules[node.GetName()]; ok && current == sched {
+		d.deleteScheduleLocked(node.GetName(), sched)
+	}
+	d.Unlock()
+	d.saveState()
+	d.history.add(node.GetName(), DrainRecord{
+		DrainID:   sched.drainID,
+		Scheduled: sched.when,
+		Started:   started,
+		Finished:  sched.finish,
+		Error:     "node gone",
+	})
+	d.metrics.NodeDrained(node.GetName(), d.instanceType(node), kubeletVersion(node), tagResultNodeGone)
+	d.metrics.DrainDuration(node.GetName(), tagResultNodeGone, sched.finish.Sub(started))
+	d.recordResourcesFreed(node, tagResultNodeGone)
+	d.recordWaveOutcome(node.GetName(), sched, false)
+}
+
+// cancelledInFlight records the drain of the supplied schedule as cancelled
+// while evicting, because its schedule was deleted.
+func (d *DrainSchedules) cancelledInFlight(node *v1.Node, sched *schedule, started time.Time, err error) {
+	const reason = "drain cancelled"
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	log.Info("Drain cancelled in flight because its schedule was deleted", zap.Error(err))
+	d.Lock()
+	sched.finish = d.now()
+	sched.failureReason = reason
+	d.recordZoneDrainLocked(sched)
+	d.Unlock()
+	d.history.add(node.GetName(), DrainRecord{
+		DrainID:   sched.drainID,
+		Scheduled: sched.when,
+		Started:   started,
+		Finished:  sched.finish,
+		Failed:    true,
+		Error:     reason,
+	})
+	sched.setFailed()
+	d.metrics.NodeDrained(node.GetName(), d.instanceType(node), kubeletVersion(node), tagResultCancelled)
+	d.metrics.DrainDuration(node.GetName(), tagResultCancelled, sched.finish.Sub(started))
+	d.recordResourcesFreed(node, tagResultCancelled)
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainCancelledInFlight, "Drain cancelled while evicting because its schedule was deleted")
+	if err := RetryWithTimeout(
+		func() error {
+			return d.markDrain(node, DrainStateFailed, sched.when, sched.finish, reason)
+		},
+		SetConditionRetryPeriod,
+		SetConditionTimeout,
+	); err != nil {
+		log.Error("Failed to place condition following drain cancellation")
+	}
+	d.setDrainState(node, DrainStateFailed, sched.when, sched.finish, reason)
+	d.annotateResult(node, tagResultCancelled, sched.finish)
+	d.summarizeDrain(node, sched, tagResultCancelled, started, sched.finish)
+	d.evictFailedSchedules()
+}
+
+// skipRecovered returns true if the eligibility check, if any, finds that the
+// supplied node no longer needs to be drained. The schedule is then deleted and
+// the drain condition of the node cleared.
+func (d *DrainSchedules) skipRecovered(node *v1.Node, sched *schedule) bool {
+	if d.eligibilityCheck == nil || d.eligibilityCheck(node) {
+		return false
+	}
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	log.Info("Skipping drain of recovered node")
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainSkippedRecovered, "Drain skipped because the node recovered")
+	sched.addSpanEvent("node recovered")
+	d.Lock()
+	if current, ok := d.schedules[node.GetName()]; ok && current == sched {
+		d.deleteScheduleLocked(node.GetName(), sched)
+	}
+	d.Unlock()
+	if c, ok := d.drainer.(ConditionClearer); ok {
+		if err := c.ClearDrainCondition(node); err != nil {
+			log.Info("Failed to clear drain condition", zap.Error(err))
+		}
+	}
+	return true
+}
+
+// drainInProgress drains the supplied node, tracking it as in progress for the
+// duration of the drain.
+func (d *DrainSchedules) drainInProgress(ctx context.Context, node *v1.Node, force bool) error {
+	d.Lock()
+	d.inProgress[node.GetName()] = struct{}{}
+	d.publishInProgressLocked()
+	d.Unlock()
+	defer func() {
+		d.Lock()
+		delete(d.inProgress, node.GetName())
+		d.publishInProgressLocked()
+		d.Unlock()
+	}()
+	return d.drain(ctx, node, force)
+}
+
+// parseDrainAfter returns the drain dependencies declared on the supplied node.
+func parseDrainAfter(n *v1.Node) []string {
+	raw := n.GetAnnotations()[drainAfterAnnotationKey]
+	if raw == "" {
+		return nil
+	}
+	var deps []string
+	for _, dep := range strings.Split(raw, ",") {
+		if dep = strings.TrimSpace(dep); dep != "" {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+func (d *DrainSchedules) nodeGroup(n *v1.Node) string {
+	if d.groupLabel == "" {
+		return ""
+	}
+	return n.GetLabels()[d.groupLabel]
+}
+
+// dependencyMatches returns true if the dependency designates the supplied
+// node, either by name or by group.
+func dependencyMatches(dep, name, group string) bool {
+	if g := strings.TrimPrefix(dep, drainAfterGroupPrefix); g != dep {
+		return group != "" && g == group
+	}
+	return dep == name
+}
+
+// hasDependencyCycle returns true if scheduling the named node with the
+// supplied dependencies would make it transitively depend on itself. It must
+// be called with the lock held.
+func (d *DrainSchedules) hasDependencyCycle(name, group string, dependsOn []string) bool {
+	visited := map[string]bool{}
+	queue := append([]string{}, dependsOn...)
+	for len(queue) > 0 {
+		dep := queue[0]
+		queue = queue[1:]
+		if dependencyMatches(dep, name, group) {
+			return true
+		}
+		for n, s := range d.schedules {
+			if visited[n] || !dependencyMatches(dep, n, s.group) {
+				continue
+			}
+			visited[n] = true
+			queue = append(queue, s.dependsOn...)
+		}
+	}
+	return false
+}
+
+// pendingDependencyLocked returns the first dependency of the schedule that
+// did not drain successfully yet, if any. Failed drains keep their dependents
+// pending, as do dependencies with no schedule that never drained successfully.
+// The nodes of the previous wave only need to finish draining, whether they
+// succeeded or failed.
+func (d *DrainSchedules) pendingDependencyLocked(name string, sched *schedule) (string, bool) {
+	for _, dep := range sched.dependsOn {
+		if sched.wave != nil && waveDependency(sched.wave, dep) {
+			if !d.dependencyFinishedLocked(name, dep) {
+				return dep, true
+			}
+			continue
+		}
+		if !d.dependencyDrainedLocked(name, dep) {
+			return dep, true
+		}
+	}
+	return "", false
+}
+
+// waveDependency returns true if the supplied dependency is a node of the wave
+// scheduled before the supplied one.
+func waveDependency(w *drainWave, dep string) bool {
+	for _, n := range w.after {
+		if n == dep {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyFinishedLocked returns true if no node matching the supplied
+// dependency of the named node is still to be drained. It must be called with
+// the lock held.
+func (d *DrainSchedules) dependencyFinishedLocked(name, dep string) bool {
+	for n, s := range d.schedules {
+		if n != name && dependencyMatches(dep, n, s.group) && s.finish.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// dependencyDrainedLocked returns true if the supplied dependency of the named
+// node drained successfully: every node matching it that has a schedule
+// drained successfully, and at least one of them did. It must be called with
+// the lock held.
+func (d *DrainSchedules) dependencyDrainedLocked(name, dep string) bool {
+	drained := false
+	for n, s := range d.schedules {
+		if n == name || !dependencyMatches(dep, n, s.group) {
+			continue
+		}
+		if !s.succeeded {
+			return false
+		}
+		drained = true
+	}
+	if drained {
+		return true
+	}
+	for n, group := range d.drained {
+		if n != name && dependencyMatches(dep, n, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// deferDrain returns true if a soft constraint defers the drain of the
+// supplied schedule. A schedule blocked on its dependencies is re-armed by
+// releaseDependentsLocked, or once its maximum deferral elapses, after which
+// soft constraints no longer apply and the drain is force fired.
+func (d *DrainSchedules) deferDrain(node *v1.Node, sched *schedule) bool {
+	d.Lock()
+	dep, pending := d.pendingDependencyLocked(node.GetName(), sched)
+	if !pending {
+		sched.blocked = false
+		d.Unlock()
+		return false
+	}
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	if d.maxDeferral > 0 {
+		deadline := sched.created.Add(d.maxDeferral)
+		if !d.now().Before(deadline) {
+			sched.blocked = false
+			d.Unlock()
+			d.logger.Info("Force firing drain deferred for too long", zap.String("node", node.GetName()), zap.String("dependency", dep))
+			d.metrics.DrainForceFired(node.GetName())
+			sched.addSpanEvent("force fired", attribute.String("dependency", dep))
+			d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for %s", sched.created.Format(time.RFC3339), dep)
+			return false
+		}
+		sched.timer.Reset(deadline.Sub(d.now()))
+	}
+	sched.blocked = true
+	sched.addSpanEvent("deferred", attribute.String("dependency", dep))
+	d.Unlock()
+	d.blockDrain(node.GetName(), deferralReasonDependency)
+	d.logger.Info("Drain is waiting for a dependency", zap.String("node", node.GetName()), zap.String("dependency", dep))
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainWaitingForDependency, "Waiting for %s to drain successfully", dep)
+	return true
+}
+
+// awaitCapacity runs the pre-drain capacity hook, if any, and returns true if
+// the drain may proceed. Otherwise the drain is deferred by
+// DefaultDrainDeferralPeriod.
+func (d *DrainSchedules) awaitCapacity(node *v1.Node, sched *schedule) bool {
+	if d.preDrainCapacityHook == nil {
+		return true
+	}
+	ctx := context.Background()
+	if d.preDrainCapacityTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.preDrainCapacityTimeout)
+		defer cancel()
+	}
+
+	start := d.now()
+	errs := make(chan error, 1)
+	go func() { errs <- d.preDrainCapacityHook(ctx, node) }()
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := tagResultSucceeded
+	if err != nil {
+		result = tagResultFailed
+	}
+	d.metrics.PreDrainCapacityWait(node.GetName(), result, d.now().Sub(start))
+	if err == nil {
+		return true
+	}
+
+	d.logger.Info("Deferring drain, replacement capacity is not available", zap.String("node", node.GetName()), zap.Error(err))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Replacement capacity is not available: %v", err)
+	sched.addSpanEvent("deferred", attribute.String("reason", "replacement capacity is not available"))
+	d.blockDrain(node.GetName(), deferralReasonReplacementCapacity)
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	return false
+}
+
+// deferForBudget defers the drain of the supplied schedule by
+// DefaultDrainDeferralPeriod because the rate budget could not be acquired.
+func (d *DrainSchedules) deferForBudget(node *v1.Node, sched *schedule, err error) {
+	d.logger.Info("Deferring drain, drain rate budget is not available", zap.String("node", node.GetName()), zap.Error(err))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Drain rate budget is not available: %v", err)
+	sched.addSpanEvent("deferred", attribute.String("reason", "drain rate budget is not available"))
+	d.blockDrain(node.GetName(), deferralReasonRateBudget)
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+}
+
+// releaseDependentsLocked fires the blocked schedules whose dependencies have
+// all drained successfully. It must be called with the lock held.
+func (d *DrainSchedules) releaseDependentsLocked() {
+	for name, s := range d.schedules {
+		if !s.blocked {
+			continue
+		}
+		if _, pending := d.pendingDependencyLocked(name, s); pending {
+			continue
+		}
+		s.blocked = false
+		s.timer.Reset(0)
+	}
 }
 
 type AlreadyScheduledError struct {
@@ -197,3 +1630,63 @@ func IsAlreadyScheduledError(err error) bool {
 	_, ok := err.(*AlreadyScheduledError)
 	return ok
 }
+
+type NotFailedError struct {
+	error
+}
+
+func NewNotFailedError(name string) error {
+	return &NotFailedError{
+		fmt.Errorf("node %s has no failed drain schedule", name),
+	}
+}
+
+func IsNotFailedError(err error) bool {
+	_, ok := err.(*NotFailedError)
+	return ok
+}
+
+type NodeTooYoungError struct {
+	error
+}
+
+func NewNodeTooYoungError(name string, age, minAge time.Duration) error {
+	return &NodeTooYoungError{
+		fmt.Errorf("node %s is %s old, younger than the minimum of %s", name, age.Round(time.Second), minAge),
+	}
+}
+
+func IsNodeTooYoungError(err error) bool {
+	_, ok := err.(*NodeTooYoungError)
+	return ok
+}
+
+type DependencyCycleError struct {
+	error
+}
+
+func NewDependencyCycleError(name string) error {
+	return &DependencyCycleError{
+		fmt.Errorf("drain dependencies of node %s form a cycle", name),
+	}
+}
+
+func IsDependencyCycleError(err error) bool {
+	_, ok := err.(*DependencyCycleError)
+	return ok
+}
+
+type SchedulerFullError struct {
+	error
+}
+
+func NewSchedulerFullError(max int) error {
+	return &SchedulerFullError{
+		fmt.Errorf("the scheduler already holds the maximum of %d schedules", max),
+	}
+}
+
+func IsSchedulerFullError(err error) bool {
+	_, ok := err.(*SchedulerFullError)
+	return ok
+}
@@ -1,14 +1,23 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -127,3 +136,839 @@ func TestDrainSchedules_HasSchedule_Polling(t *testing.T) {
 		}
 	}
 }
+
+func TestDrainSchedules_ScheduleWithKey(t *testing.T) {
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, time.Minute, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	defer scheduler.DeleteSchedule(nodeName)
+
+	first, err := scheduler.ScheduleWithKey(node, "a")
+	if err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWithKey() error = %v", err)
+	}
+	drainID := scheduler.schedules[nodeName].drainID
+
+	again, err := scheduler.ScheduleWithKey(node, "a")
+	if err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWithKey() same key error = %v", err)
+	}
+	if !again.Equal(first) || scheduler.schedules[nodeName].drainID != drainID {
+		t.Errorf("same key: want existing schedule at %v, got %v", first, again)
+	}
+
+	rescheduled, err := scheduler.ScheduleWithKey(node, "b")
+	if err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWithKey() new key error = %v", err)
+	}
+	if !rescheduled.After(first) || scheduler.schedules[nodeName].drainID == drainID {
+		t.Errorf("new key: want a new schedule after %v, got %v", first, rescheduled)
+	}
+	if got := scheduler.schedules[nodeName].key; got != "b" {
+		t.Errorf("new key: want key %q recorded, got %q", "b", got)
+	}
+}
+
+func TestDrainSchedules_DeletedWhileFiring(t *testing.T) {
+	drainer := newRecordingDrainer()
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+
+	// Fire the timer while the schedule is being deleted, so that the drain
+	// waits for the deletion to complete.
+	scheduler.Lock()
+	done := make(chan struct{})
+	go func() {
+		scheduler.runDrain(node, sched)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	scheduler.deleteScheduleLocked(nodeName, sched)
+	scheduler.Unlock()
+	<-done
+
+	if got := drainer.nodes(); len(got) > 0 {
+		t.Errorf("drained %v after the schedule was deleted", got)
+	}
+	want := "Warning DrainAbortedDeleted Drain aborted because its schedule was deleted"
+	if got := <-recorder.Events; got != want {
+		t.Errorf("event: want %q, got %q", want, got)
+	}
+}
+
+// clearingDrainer is a recordingDrainer that records the nodes whose drain
+// condition is cleared.
+type clearingDrainer struct {
+	*recordingDrainer
+	cleared []string
+}
+
+func (d *clearingDrainer) ClearDrainCondition(n *v1.Node) error {
+	d.cleared = append(d.cleared, n.GetName())
+	return nil
+}
+
+func TestDrainSchedules_SkipRecovered(t *testing.T) {
+	drainer := &clearingDrainer{recordingDrainer: newRecordingDrainer()}
+	recorder := record.NewFakeRecorder(10)
+	recovered := false
+	scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop(),
+		WithDrainEligibilityCheck(func(*v1.Node) bool { return !recovered })).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+
+	// The node recovers before its drain fires.
+	recovered = true
+	scheduler.runDrain(node, sched)
+
+	if got := drainer.nodes(); len(got) > 0 {
+		t.Errorf("drained recovered nodes %v", got)
+	}
+	if has, _ := scheduler.HasSchedule(nodeName); has {
+		t.Errorf("schedule of recovered node not deleted")
+	}
+	if !reflect.DeepEqual(drainer.cleared, []string{nodeName}) {
+		t.Errorf("cleared conditions: want %v, got %v", []string{nodeName}, drainer.cleared)
+	}
+	want := "Warning DrainSkippedRecovered Drain skipped because the node recovered"
+	if got := <-recorder.Events; got != want {
+		t.Errorf("event: want %q, got %q", want, got)
+	}
+}
+
+func TestDrainSchedules_DeleteSchedulesMatching(t *testing.T) {
+	drainer := &clearingDrainer{recordingDrainer: newRecordingDrainer()}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	store := nodeStore{}
+	for name, group := range map[string]string{"batch-a": "batch", "batch-b": "batch", "web": "web"} {
+		n := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name, Labels: map[string]string{"group": group}}}
+		store[name] = n
+		if _, err := scheduler.Schedule(n); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		scheduler.schedules[name].timer.Stop()
+	}
+	// Schedules of nodes missing from the store are kept.
+	if _, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: "gone"}}); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(gone) error = %v", err)
+	}
+	scheduler.schedules["gone"].timer.Stop()
+
+	if got := scheduler.DeleteSchedulesMatching(labels.SelectorFromSet(labels.Set{"group": "batch"}), store); got != 2 {
+		t.Errorf("DeleteSchedulesMatching(): want 2 deleted, got %d", got)
+	}
+	for _, name := range []string{"batch-a", "batch-b"} {
+		if has, _ := scheduler.HasSchedule(name); has {
+			t.Errorf("schedule of %s not deleted", name)
+		}
+	}
+	for _, name := range []string{"web", "gone"} {
+		if has, _ := scheduler.HasSchedule(name); !has {
+			t.Errorf("schedule of %s deleted", name)
+		}
+	}
+	if want := []string{"batch-a", "batch-b"}; !reflect.DeepEqual(drainer.cleared, want) {
+		t.Errorf("cleared conditions: want %v, got %v", want, drainer.cleared)
+	}
+}
+
+// goneDrainer is a drainer whose nodes are deleted while they drain.
+type goneDrainer struct {
+	NoopCordonDrainer
+}
+
+func (d *goneDrainer) Drain(n *v1.Node) error {
+	return errors.Wrap(apierrors.NewNotFound(v1.Resource("nodes"), n.GetName()), "cannot cordon node")
+}
+
+func TestDrainSchedules_NodeGone(t *testing.T) {
+	m := &recordingMetrics{}
+	scheduler := NewDrainSchedules(&goneDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(), WithMetricsRecorder(m), WithDrainRetries(3, time.Minute)).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	if has, _ := scheduler.HasSchedule(nodeName); has {
+		t.Errorf("schedule of deleted node not deleted")
+	}
+	if sched.isFailed() {
+		t.Errorf("schedule of deleted node marked failed")
+	}
+	if want := []string{nodeName + "=" + tagResultNodeGone}; !reflect.DeepEqual(m.drained, want) {
+		t.Errorf("NodeDrained: want %v, got %v", want, m.drained)
+	}
+	if h := scheduler.History(nodeName); len(h) != 1 || h[0].Failed {
+		t.Errorf("History(): want a single record not failed, got %+v", h)
+	}
+}
+
+func TestDrainSchedules_AnnotateDrainResult(t *testing.T) {
+	finish := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name       string
+		skipDrain  bool
+		listErr    error
+		wantResult string
+	}{
+		{name: "Succeeded", skipDrain: true, wantResult: tagResultSucceeded},
+		{name: "Failed", listErr: errExploded, wantResult: tagResultFailed},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{"keep": "me"}}}
+			c := fake.NewSimpleClientset(node)
+			c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, &v1.PodList{}, tc.listErr
+			})
+			drainer := NewAPICordonDrainer(c, WithSkipDrain(tc.skipDrain), WithDrainResultAnnotations(DefaultDrainResultAnnotation, DefaultDrainTimeAnnotation))
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithDispatcher(NewManualDispatcher(finish))).(*DrainSchedules)
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			n, err := c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{})
+			if err != nil {
+				t.Fatalf("node.Get(%v): %v", nodeName, err)
+			}
+			want := map[string]string{
+				"keep":                       "me",
+				DefaultDrainResultAnnotation: tc.wantResult,
+				DefaultDrainTimeAnnotation:   finish.Format(time.RFC3339),
+			}
+			if !reflect.DeepEqual(n.GetAnnotations(), want) {
+				t.Errorf("annotations: want %v, got %v", want, n.GetAnnotations())
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_HasSchedules(t *testing.T) {
+	scheduler := NewDrainSchedules(&failDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	for _, name := range []string{"ok", "failed"} {
+		if _, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}); err != nil {
+			t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+		}
+		scheduler.schedules[name].timer.Stop()
+		defer scheduler.DeleteSchedule(name)
+	}
+	scheduler.schedules["failed"].setFailed()
+
+	got := scheduler.HasSchedules([]string{"ok", "failed", "missing"})
+	want := map[string]ScheduleStatus{
+		"ok":      {Has: true, When: scheduler.schedules["ok"].when},
+		"failed":  {Has: true, Failed: true, When: scheduler.schedules["failed"].when},
+		"missing": {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HasSchedules(): want %+v, got %+v", want, got)
+	}
+}
+
+func benchmarkScheduler(n int) (*DrainSchedules, []string) {
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("node-%d", i)
+		scheduler.schedules[names[i]] = &schedule{when: time.Now()}
+	}
+	return scheduler, names
+}
+
+func BenchmarkHasSchedule(b *testing.B) {
+	scheduler, names := benchmarkScheduler(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			scheduler.HasSchedule(name)
+		}
+	}
+}
+
+func BenchmarkHasSchedules(b *testing.B) {
+	scheduler, names := benchmarkScheduler(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scheduler.HasSchedules(names)
+	}
+}
+
+type pdbBlockedDrainer struct {
+	NoopCordonDrainer
+}
+
+func (d *pdbBlockedDrainer) Drain(n *v1.Node) error {
+	return errors.Wrap(errPDBBlocked{pods: 2}, "timed out waiting for evictions to complete")
+}
+
+func TestDrainSchedules_FailureReason(t *testing.T) {
+	scheduler := NewDrainSchedules(&pdbBlockedDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	if reason, failed := scheduler.FailureReason(nodeName); failed || reason != "" {
+		t.Errorf("FailureReason() of an unknown schedule: want no reason, got %q, %v", reason, failed)
+	}
+
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	if reason, failed := scheduler.FailureReason(nodeName); failed || reason != "" {
+		t.Errorf("FailureReason() of a pending schedule: want no reason, got %q, %v", reason, failed)
+	}
+
+	scheduler.runDrain(node, sched)
+	reason, failed := scheduler.FailureReason(nodeName)
+	if want := "PodDisruptionBudget blocked 2 pods"; !failed || reason != want {
+		t.Errorf("FailureReason() of a failed schedule: want %q, true, got %q, %v", want, reason, failed)
+	}
+}
+
+func TestDrainSchedules_MaxSchedules(t *testing.T) {
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(), WithMaxSchedules(2)).(*DrainSchedules)
+	for _, name := range []string{"first", "second"} {
+		if _, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+	}
+	defer scheduler.DeleteSchedule("second")
+
+	third := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "third"}}
+	if _, err := scheduler.Schedule(third); !IsSchedulerFullError(err) {
+		t.Errorf("DrainSchedules.Schedule(third): want SchedulerFullError, got %v", err)
+	}
+	if has, _ := scheduler.HasSchedule(third.Name); has {
+		t.Errorf("third node should not be scheduled")
+	}
+	if err := scheduler.ScheduleWave("wave", []*v1.Node{third}); !IsSchedulerFullError(errors.Cause(err)) {
+		t.Errorf("DrainSchedules.ScheduleWave(): want SchedulerFullError, got %v", err)
+	}
+
+	scheduler.DeleteSchedule("first")
+	if _, err := scheduler.Schedule(third); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(third) after a deletion error = %v", err)
+	}
+	defer scheduler.DeleteSchedule(third.Name)
+
+	// Schedules whose drain finished no longer count against the limit.
+	fourth := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "fourth"}}
+	if _, err := scheduler.Schedule(fourth); !IsSchedulerFullError(err) {
+		t.Errorf("DrainSchedules.Schedule(fourth): want SchedulerFullError, got %v", err)
+	}
+	sched := scheduler.schedules["second"]
+	sched.timer.Stop()
+	scheduler.runDrain(sched.node, sched)
+	if _, err := scheduler.Schedule(fourth); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(fourth) after a drain finished error = %v", err)
+	}
+	scheduler.DeleteSchedule(fourth.Name)
+}
+
+func TestDrainSchedules_MinNodeAge(t *testing.T) {
+	// Node ages are measured by the dispatcher clock.
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(), WithMinNodeAge(10*time.Minute), WithDispatcher(NewManualDispatcher(start))).(*DrainSchedules)
+
+	young := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "young", CreationTimestamp: meta.NewTime(start.Add(-5 * time.Second))}}
+	if _, err := scheduler.Schedule(young); !IsNodeTooYoungError(err) {
+		t.Errorf("DrainSchedules.Schedule(young): want NodeTooYoungError, got %v", err)
+	}
+	if has, _ := scheduler.HasSchedule(young.Name); has {
+		t.Errorf("young node should not be scheduled")
+	}
+
+	old := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "old", CreationTimestamp: meta.NewTime(start.Add(-time.Hour))}}
+	if _, err := scheduler.Schedule(old); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(old) error = %v", err)
+	}
+	scheduler.DeleteSchedule(old.Name)
+}
+
+// recordingDrainer records the nodes it drains and signals each drain on the
+// drained channel.
+type recordingDrainer struct {
+	NoopCordonDrainer
+	sync.Mutex
+	drainedNodes []string
+	drained      chan string
+}
+
+func newRecordingDrainer() *recordingDrainer {
+	return &recordingDrainer{drained: make(chan string, 10)}
+}
+
+func (d *recordingDrainer) Drain(n *v1.Node) error {
+	d.Lock()
+	d.drainedNodes = append(d.drainedNodes, n.GetName())
+	d.Unlock()
+	d.drained <- n.GetName()
+	return nil
+}
+
+func (d *recordingDrainer) nodes() []string {
+	d.Lock()
+	defer d.Unlock()
+	return append([]string{}, d.drainedNodes...)
+}
+
+func TestDrainSchedules_DependencyOrder(t *testing.T) {
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+
+	cache := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "cache"}}
+	app := &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        "app",
+		Annotations: map[string]string{drainAfterAnnotationKey: "cache"},
+	}}
+	for _, n := range []*v1.Node{cache, app} {
+		if _, err := scheduler.Schedule(n); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%v) error = %v", n.Name, err)
+		}
+		// Timers are driven by hand below.
+		scheduler.schedules[n.Name].timer.Stop()
+	}
+
+	scheduler.runDrain(app, scheduler.schedules[app.Name])
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("app drained before its dependency: %v", got)
+	}
+	if !scheduler.schedules[app.Name].blocked {
+		t.Fatalf("app schedule should be blocked on its dependency")
+	}
+
+	scheduler.runDrain(cache, scheduler.schedules[cache.Name])
+	select {
+	case <-drainer.drained: // cache
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for cache drain")
+	}
+	select {
+	case name := <-drainer.drained:
+		if name != app.Name {
+			t.Fatalf("want %v drained once its dependency completed, got %v", app.Name, name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for app to be released")
+	}
+	if want, got := []string{"cache", "app"}, drainer.nodes(); !reflect.DeepEqual(want, got) {
+		t.Errorf("drain order: want %v, got %v", want, got)
+	}
+}
+
+// selectiveFailDrainer fails the drains of the named nodes, and records the
+// others.
+type selectiveFailDrainer struct {
+	*recordingDrainer
+	fail map[string]bool
+}
+
+func (d *selectiveFailDrainer) Drain(n *v1.Node) error {
+	if d.fail[n.GetName()] {
+		return errors.New("myerr")
+	}
+	return d.recordingDrainer.Drain(n)
+}
+
+func TestDrainSchedules_FailedDependency(t *testing.T) {
+	drainer := &selectiveFailDrainer{recordingDrainer: newRecordingDrainer(), fail: map[string]bool{"cache": true}}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithMaxDeferral(time.Hour)).(*DrainSchedules)
+
+	cache := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "cache"}}
+	app := &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        "app",
+		Annotations: map[string]string{drainAfterAnnotationKey: "cache"},
+	}}
+	for _, n := range []*v1.Node{cache, app} {
+		if _, err := scheduler.Schedule(n); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%v) error = %v", n.Name, err)
+		}
+		// Timers are driven by hand below.
+		scheduler.schedules[n.Name].timer.Stop()
+	}
+	sched := scheduler.schedules[app.Name]
+
+	scheduler.runDrain(cache, scheduler.schedules[cache.Name])
+	if !scheduler.schedules[cache.Name].isFailed() {
+		t.Fatalf("cache drain should have failed")
+	}
+	scheduler.runDrain(app, sched)
+	sched.timer.Stop()
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("app drained after its dependency failed: %v", got)
+	}
+	if !sched.blocked {
+		t.Fatalf("app schedule should stay blocked on its failed dependency")
+	}
+
+	// A deleted dependency that never drained keeps the drain deferred too.
+	scheduler.DeleteSchedule(cache.Name)
+	sched.timer.Stop()
+	scheduler.runDrain(app, sched)
+	sched.timer.Stop()
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("app drained after its dependency was deleted: %v", got)
+	}
+
+	// Past the maximum deferral the drain is force fired.
+	sched.created = time.Now().Add(-2 * time.Hour)
+	scheduler.runDrain(app, sched)
+	if want, got := []string{"app"}, drainer.nodes(); !reflect.DeepEqual(want, got) {
+		t.Errorf("drained nodes: want %v, got %v", want, got)
+	}
+}
+
+func TestDrainSchedules_DependencyCycle(t *testing.T) {
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithNodeGroupLabel("group"))
+
+	a := &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        "a",
+		Labels:      map[string]string{"group": "cache"},
+		Annotations: map[string]string{drainAfterAnnotationKey: "b"},
+	}}
+	b := &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        "b",
+		Annotations: map[string]string{drainAfterAnnotationKey: "group:cache"},
+	}}
+	if _, err := scheduler.Schedule(a); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(%v) error = %v", a.Name, err)
+	}
+	defer scheduler.DeleteSchedule(a.Name)
+	if _, err := scheduler.Schedule(b); !IsDependencyCycleError(err) {
+		t.Fatalf("DrainSchedules.Schedule(%v): want dependency cycle error, got %v", b.Name, err)
+	}
+	if has, _ := scheduler.HasSchedule(b.Name); has {
+		t.Errorf("Node %v should not have any schedule", b.Name)
+	}
+}
+
+func TestDrainSchedules_MaxDeferral(t *testing.T) {
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithMaxDeferral(time.Hour)).(*DrainSchedules)
+
+	cache := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "cache"}}
+	app := &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        "app",
+		Annotations: map[string]string{drainAfterAnnotationKey: "cache"},
+	}}
+	for _, n := range []*v1.Node{cache, app} {
+		if _, err := scheduler.Schedule(n); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%v) error = %v", n.Name, err)
+		}
+		scheduler.schedules[n.Name].timer.Stop()
+	}
+	sched := scheduler.schedules[app.Name]
+
+	// Within the deferral deadline the dependency is honoured.
+	scheduler.runDrain(app, sched)
+	sched.timer.Stop()
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("app drained before its dependency: %v", got)
+	}
+
+	// Past the deadline the drain is force fired.
+	sched.created = time.Now().Add(-2 * time.Hour)
+	scheduler.runDrain(app, sched)
+	if want, got := []string{"app"}, drainer.nodes(); !reflect.DeepEqual(want, got) {
+		t.Errorf("drained nodes: want %v, got %v", want, got)
+	}
+	if sched.blocked {
+		t.Errorf("force fired schedule should not be blocked")
+	}
+}
+
+func TestDrainSchedules_PreDrainCapacityHook(t *testing.T) {
+	cases := []struct {
+		name      string
+		hook      PreDrainCapacityHook
+		wantDrain bool
+		wantEvent string
+	}{
+		{
+			name:      "CapacityAvailable",
+			hook:      func(ctx context.Context, n *v1.Node) error { return nil },
+			wantDrain: true,
+			wantEvent: "Warning DrainStarting Draining node",
+		},
+		{
+			name: "CapacityTimeout",
+			hook: func(ctx context.Context, n *v1.Node) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantEvent: "Warning DrainDeferred Replacement capacity is not available: context deadline exceeded",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := newRecordingDrainer()
+			recorder := record.NewFakeRecorder(10)
+			scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop(), WithPreDrainCapacityHook(tc.hook, 50*time.Millisecond)).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[node.Name]
+			sched.timer.Stop()
+
+			scheduler.runDrain(node, sched)
+			sched.timer.Stop()
+			if drained := len(drainer.nodes()) == 1; drained != tc.wantDrain {
+				t.Errorf("drained: want %v, got %v", tc.wantDrain, drained)
+			}
+			if got := <-recorder.Events; got != tc.wantEvent {
+				t.Errorf("event: want %q, got %q", tc.wantEvent, got)
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_EventReasons(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewDrainSchedules(&failDrainer{}, recorder, 0, zap.NewNop(),
+		WithScheduleEventReasons(EventReasons{DrainStarting: "MyDrainStarting", DrainFailed: "MyDrainFailed"})).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[node.Name]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	for _, want := range []string{"Warning MyDrainStarting Draining node", "Warning MyDrainFailed Draining failed: myerr"} {
+		if got := withoutAnnotations(<-recorder.Events); got != want {
+			t.Errorf("event: want %q, got %q", want, got)
+		}
+	}
+}
+
+type emptyNodeDrainer struct {
+	NoopCordonDrainer
+}
+
+func (d *emptyNodeDrainer) Drain(n *v1.Node) error { return NewNothingToEvictError(n.GetName()) }
+
+func TestDrainSchedules_NothingToEvict(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewDrainSchedules(&emptyNodeDrainer{}, recorder, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[node.Name]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	for _, want := range []string{"Warning DrainStarting Draining node", "Warning DrainNoop Node had no pods to evict"} {
+		if got := withoutAnnotations(<-recorder.Events); got != want {
+			t.Errorf("event: want %q, got %q", want, got)
+		}
+	}
+	if atomic.LoadInt32(&sched.failed) != 0 {
+		t.Error("drain of an empty node was marked failed")
+	}
+}
+
+// dryRunDrainer has the API server accept the dry run eviction of every pod,
+// and records the drain conditions it is asked to set.
+type dryRunDrainer struct {
+	NoopCordonDrainer
+	marked int32
+}
+
+func (d *dryRunDrainer) Drain(n *v1.Node) error { return NewDryRunEvictionsError(n.GetName(), 2) }
+
+func (d *dryRunDrainer) MarkDrain(n *v1.Node, when, finish time.Time, failed bool, reason string) error {
+	if !finish.IsZero() {
+		atomic.AddInt32(&d.marked, 1)
+	}
+	return nil
+}
+
+func TestDrainSchedules_DryRun(t *testing.T) {
+	drainer := &dryRunDrainer{}
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[node.Name]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	for _, want := range []string{"Warning DrainStarting Draining node", "Warning DrainDryRun Dry run drain: dry run evictions of 2 pods accepted on node " + nodeName} {
+		if got := withoutAnnotations(<-recorder.Events); got != want {
+			t.Errorf("event: want %q, got %q", want, got)
+		}
+	}
+	if atomic.LoadInt32(&sched.failed) != 0 {
+		t.Error("dry run drain was marked failed")
+	}
+	if n := atomic.LoadInt32(&drainer.marked); n != 0 {
+		t.Errorf("dry run drain set %d completed drain conditions, want none", n)
+	}
+	if h := scheduler.history.get(nodeName); len(h) != 1 || h[0].Error != "dry run" {
+		t.Errorf("history: want a single dry run record, got %+v", h)
+	}
+}
+
+// blockingDrainer blocks each drain until release is closed. It panics
+// instead if panics is set.
+type blockingDrainer struct {
+	NoopCordonDrainer
+	started chan struct{}
+	release chan struct{}
+	panics  bool
+}
+
+func (d *blockingDrainer) Drain(n *v1.Node) error {
+	if d.panics {
+		panic("drain exploded")
+	}
+	d.started <- struct{}{}
+	<-d.release
+	return nil
+}
+
+// cancellableDrainer blocks each drain until its context is cancelled.
+type cancellableDrainer struct {
+	NoopCordonDrainer
+	started chan struct{}
+}
+
+func (d *cancellableDrainer) DrainWithContext(ctx context.Context, n *v1.Node) error {
+	d.started <- struct{}{}
+	<-ctx.Done()
+	return errors.Wrap(ctx.Err(), "drain cancelled")
+}
+
+func TestDrainSchedules_CancelInFlight(t *testing.T) {
+	drainer := &cancellableDrainer{started: make(chan struct{}, 1)}
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.runDrain(node, sched)
+		close(done)
+	}()
+	<-drainer.started
+	scheduler.DeleteSchedule(nodeName)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the cancelled drain to return")
+	}
+
+	for _, want := range []string{"Warning DrainStarting Draining node", "Warning DrainCancelledInFlight Drain cancelled while evicting because its schedule was deleted"} {
+		if got := <-recorder.Events; got != want {
+			t.Errorf("event: want %q, got %q", want, got)
+		}
+	}
+	history := scheduler.History(nodeName)
+	if len(history) != 1 || !history[0].Failed || history[0].Error != "drain cancelled" {
+		t.Errorf("History(): want one cancelled drain, got %+v", history)
+	}
+	if scheduler.AnyInProgress() {
+		t.Errorf("cancelled drain still in progress")
+	}
+}
+
+func TestDrainSchedules_InProgress(t *testing.T) {
+	drainer := &blockingDrainer{started: make(chan struct{}, 1), release: make(chan struct{})}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[node.Name]
+	sched.timer.Stop()
+	if scheduler.AnyInProgress() {
+		t.Fatalf("no drain should be in progress before the timer fires")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.runDrain(node, sched)
+		close(done)
+	}()
+	<-drainer.started
+	if !scheduler.AnyInProgress() {
+		t.Errorf("a drain should be in progress")
+	}
+	if want, got := []string{nodeName}, scheduler.InProgressNodes(); !reflect.DeepEqual(want, got) {
+		t.Errorf("InProgressNodes(): want %v, got %v", want, got)
+	}
+	close(drainer.release)
+	<-done
+	if scheduler.AnyInProgress() {
+		t.Errorf("no drain should be in progress once the drain completed")
+	}
+}
+
+func TestDrainSchedules_InProgressPanic(t *testing.T) {
+	scheduler := NewDrainSchedules(&blockingDrainer{panics: true}, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	func() {
+		defer func() { _ = recover() }()
+		_ = scheduler.drainInProgress(context.Background(), node, false)
+	}()
+	if scheduler.AnyInProgress() {
+		t.Errorf("a panicking drain must not be left in progress")
+	}
+}
+
+// stateMarkingDrainer is a DrainStateMarker that records the drain states it
+// is marked with.
+type stateMarkingDrainer struct {
+	NoopCordonDrainer
+	sync.Mutex
+	states []DrainState
+}
+
+func (d *stateMarkingDrainer) MarkDrainState(n *v1.Node, state DrainState, when, finish time.Time, reason string) error {
+	d.Lock()
+	defer d.Unlock()
+	d.states = append(d.states, state)
+	return nil
+}
+
+func TestDrainSchedules_MarkDrainState(t *testing.T) {
+	drainer := &stateMarkingDrainer{}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	want := []DrainState{DrainStateScheduled, DrainStateInProgress, DrainStateSucceeded}
+	if !reflect.DeepEqual(drainer.states, want) {
+		t.Errorf("marked states: want %v, got %v", want, drainer.states)
+	}
+}
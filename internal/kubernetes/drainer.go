@@ -18,18 +18,30 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	core "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 )
 
 // Default pod eviction settings.
@@ -41,7 +53,45 @@ const (
 	kindStatefulSet = "StatefulSet"
 
 	ConditionDrainedScheduled = "DrainScheduled"
+	ConditionDrainInProgress  = "DrainInProgress"
+	ConditionDrainSucceeded   = "DrainSucceeded"
+	ConditionDrainFailed      = "DrainFailed"
 	DefaultSkipDrain          = false
+
+	// DefaultCordonReasonAnnotation and DefaultCordonOwnerAnnotation are the
+	// annotations recording why, and by whom, a node was cordoned.
+	DefaultCordonReasonAnnotation = "draino.kubernetes.io/cordon-reason"
+	DefaultCordonOwnerAnnotation  = "draino.kubernetes.io/cordon-owner"
+
+	// DefaultDrainResultAnnotation and DefaultDrainTimeAnnotation are the
+	// annotations recording the result, and the completion time, of the last
+	// drain of a node.
+	DefaultDrainResultAnnotation = "draino.kubernetes.io/last-drain-result"
+	DefaultDrainTimeAnnotation   = "draino.kubernetes.io/last-drain-time"
+
+	// DefaultEvictFirstAnnotation is the annotation of the pods evicted
+	// before all others when WithEvictFirstAnnotation is configured.
+	DefaultEvictFirstAnnotation = "draino.kubernetes.io/evict-first"
+
+	// DefaultStartupCostAnnotation is the annotation of the pods whose cost of
+	// starting elsewhere orders their eviction when WithStartupCostAnnotation
+	// is configured.
+	DefaultStartupCostAnnotation = "draino.kubernetes.io/startup-cost"
+
+	// MaxConditionReasonLength caps the failure reason carried in the drain
+	// condition message so that node conditions stay small.
+	MaxConditionReasonLength = 256
+
+	verifyEvictionsPollPeriod = 1 * time.Second
+
+	// evictionRetryPeriod is how long to wait before retrying a refused
+	// eviction.
+	evictionRetryPeriod = 5 * time.Second
+
+	// Phases of a drain in which a pod may be removed.
+	evictionPhaseEvicted     = "evicted"
+	evictionPhaseForced      = "forced"
+	evictionPhaseTerminating = "terminating"
 )
 
 type nodeMutatorFn func(*core.Node)
@@ -54,6 +104,28 @@ func (e errTimeout) Error() string {
 
 func (e errTimeout) Timeout() {}
 
+// errPDBBlocked is returned when evictions time out while some pods are still
+// being refused by a PodDisruptionBudget.
+type errPDBBlocked struct {
+	pods int32
+}
+
+func (e errPDBBlocked) Error() string {
+	return fmt.Sprintf("PodDisruptionBudget blocked %d pods", e.pods)
+}
+
+func (e errPDBBlocked) Timeout() {}
+
+// errPodsStillPresent is returned when evicted pods are still present on the
+// node once the eviction verification times out.
+type errPodsStillPresent struct {
+	pods int
+}
+
+func (e errPodsStillPresent) Error() string {
+	return fmt.Sprintf("pods still present: %d", e.pods)
+}
+
 // IsTimeout returns true if the supplied error was caused by a timeout.
 func IsTimeout(err error) bool {
 	err = errors.Cause(err)
@@ -63,6 +135,44 @@ func IsTimeout(err error) bool {
 	return ok
 }
 
+// DrainFailureReason returns a short, human readable reason for the supplied
+// drain error. The reason is truncated to MaxConditionReasonLength so that it
+// can safely be carried in a node condition.
+func DrainFailureReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	var reason string
+	switch cause := errors.Cause(err).(type) {
+	case errPDBBlocked, errPodsStillPresent:
+		reason = cause.Error()
+	case errTimeout:
+		reason = "Timed out waiting for evictions to complete"
+	default:
+		reason = err.Error()
+	}
+	return truncateReason(reason)
+}
+
+// truncateReason truncates the supplied reason to MaxConditionReasonLength
+// bytes, ellipsis included, without splitting a UTF-8 encoded rune.
+func truncateReason(reason string) string {
+	return truncate(reason, MaxConditionReasonLength)
+}
+
+// truncate truncates the supplied string to max bytes, ellipsis included,
+// without splitting a UTF-8 encoded rune.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	n := max - 3
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
 // A Cordoner cordons nodes.
 type Cordoner interface {
 	// Cordon the supplied node. Marks it unschedulable for new pods.
@@ -72,11 +182,56 @@ type Cordoner interface {
 	Uncordon(n *core.Node, mutators ...nodeMutatorFn) error
 }
 
+// A ReasonCordoner cordons nodes, recording why and on whose behalf.
+type ReasonCordoner interface {
+	// CordonWithReason cordons the supplied node, annotating it with the
+	// supplied reason and owner.
+	CordonWithReason(n *core.Node, reason, owner string, mutators ...nodeMutatorFn) error
+}
+
 // A Drainer drains nodes.
 type Drainer interface {
-	// Drain the supplied node. Evicts the node of all but mirror and DaemonSet pods.
+	// Drain the supplied node. Evicts the node of all but mirror and DaemonSet
+	// pods. A NothingToEvictError may be returned if the node had no pods to
+	// evict.
 	Drain(n *core.Node) error
-	MarkDrain(n *core.Node, when, finish time.Time, failed bool) error
+	// MarkDrain sets the drain condition on the supplied node. The reason is
+	// only used when failed is true.
+	MarkDrain(n *core.Node, when, finish time.Time, failed bool, reason string) error
+}
+
+// A ContextDrainer drains nodes using the supplied context. Drainers that
+// implement it are passed the context of the drain schedule, for example to
+// propagate tracing spans.
+type ContextDrainer interface {
+	DrainWithContext(ctx context.Context, n *core.Node) error
+}
+
+// A ForceDrainer drains nodes by deleting their pods without a grace period,
+// bypassing any PodDisruptionBudget. Drainers that implement it may have drains
+// that repeatedly failed escalated to a force drain.
+type ForceDrainer interface {
+	ForceDrain(ctx context.Context, n *core.Node) error
+}
+
+// A DrainStateMarker sets conditions reflecting each state of the drain
+// lifecycle on nodes. Drainers that implement it are marked at each change of
+// drain state instead of through MarkDrain.
+type DrainStateMarker interface {
+	MarkDrainState(n *core.Node, state DrainState, when, finish time.Time, reason string) error
+}
+
+// A ConditionClearer removes the drain condition from nodes. Drainers that
+// implement it have the condition cleared when a drain is skipped because its
+// node recovered.
+type ConditionClearer interface {
+	ClearDrainCondition(n *core.Node) error
+}
+
+// A DrainResultAnnotator records the result of the last drain of nodes as
+// annotations.
+type DrainResultAnnotator interface {
+	AnnotateDrainResult(n *core.Node, result string, finish time.Time) error
 }
 
 // A CordonDrainer both cordons and drains nodes!
@@ -98,7 +253,7 @@ func (d *NoopCordonDrainer) Uncordon(n *core.Node, mutators ...nodeMutatorFn) er
 func (d *NoopCordonDrainer) Drain(n *core.Node) error { return nil }
 
 // MarkDrain does nothing.
-func (d *NoopCordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, failed bool) error {
+func (d *NoopCordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, failed bool, reason string) error {
 	return nil
 }
 
@@ -108,11 +263,182 @@ type APICordonDrainer struct {
 	l *zap.Logger
 
 	filter PodFilterFunc
+	// evictionFilter is applied to the pods that pass filter.
+	evictionFilter EvictionFilter
+	// escalateAfter is how long evictions refused by a PodDisruptionBudget
+	// are retried before the pod is force deleted. Zero disables escalation.
+	escalateAfter time.Duration
+	// evictionCallTimeout bounds each eviction API call. Zero means no
+	// bound.
+	evictionCallTimeout time.Duration
+	// nodeGoneInterval is how often Drain checks that the node being drained
+	// still exists while evicting its pods. Zero disables the check.
+	nodeGoneInterval time.Duration
+	// propagationPolicy is the deletion propagation policy of evicted pods.
+	// The API server default applies when it is nil.
+	propagationPolicy *meta.DeletionPropagation
+	// verifyTimeout bounds how long Drain waits for evicted pods to be gone
+	// from the node. Zero disables verification.
+	verifyTimeout time.Duration
+	// successValidator, if any, must report a drain complete, within
+	// successTimeout, before Drain succeeds.
+	successValidator  DrainSuccessValidator
+	successTimeout    time.Duration
+	successPollPeriod time.Duration
+	// terminatingTimeout bounds how long Drain waits for pods that were
+	// already terminating before evicting them. The eviction timeout applies
+	// when it is zero.
+	terminatingTimeout time.Duration
+	// jobPodPolicy is how Drain handles the pods owned by Jobs, waiting for up
+	// to jobPodTimeout for them to complete when it is JobPodWait.
+	jobPodPolicy  JobPodPolicy
+	jobPodTimeout time.Duration
+	jobPollPeriod time.Duration
+	// replacementTimeout, if set, bounds how long Drain defers the eviction
+	// of the last ready replica of a workload while its replacement would
+	// not fit in the cluster.
+	replacementTimeout    time.Duration
+	replacementPollPeriod time.Duration
+
+	// spreadCheckDelay, if positive, is how long after the pods of a drained
+	// node are gone Drain checks where their replacements landed.
+	spreadCheckDelay time.Duration
 
 	maxGracePeriod   time.Duration
 	evictionHeadroom time.Duration
 	skipDrain        bool
 	skipDelete       bool
+	// admissionDenied is what Drain does with the pods whose eviction is
+	// denied by an admission webhook.
+	admissionDenied AdmissionDeniedPolicy
+	// protectedPodsAnnotation lists the pods of a node that are never
+	// evicted from it.
+	protectedPodsAnnotation string
+	// eventRecorder, if any, records events about the pods Drain skips,
+	// with the supplied reasons.
+	eventRecorder record.EventRecorder
+	eventReasons  EventReasons
+	// metrics records the metrics of drains.
+	metrics MetricsRecorder
+	// progress, if any, is told how many pods Drain evicted so far, at most
+	// once per progressInterval.
+	progress         ProgressReporter
+	progressInterval time.Duration
+	// stuckTerminatingThreshold, if not zero, is how long pods may be
+	// terminating before they are force deleted.
+	stuckTerminatingThreshold time.Duration
+
+	emptyNodeFastPath bool
+
+	// deterministicOrder evicts pods one at a time, sorted by namespace and
+	// name, rather than all at once.
+	deterministicOrder bool
+	// ownerAwareOrder evicts the pods of one controller at a time.
+	ownerAwareOrder bool
+	// largestFirstOrder evicts pods one at a time, by decreasing resource
+	// requests.
+	largestFirstOrder bool
+	// topologySpreadKey, if any, is the node label whose values are the
+	// topology domains pods are evicted from, one at a time, most populated
+	// first.
+	topologySpreadKey string
+	// extendedResourcesLast evicts the pods requesting extended resources
+	// after all others.
+	extendedResourcesLast bool
+	// systemNamespaces and systemLabels select the pods evicted in a final
+	// phase, after all others.
+	systemNamespaces map[string]bool
+	systemLabels     []string
+
+	// cordonRetryPeriod and cordonRetryTimeout bound the retries of cordons
+	// failing transiently.
+	cordonRetryPeriod  time.Duration
+	cordonRetryTimeout time.Duration
+
+	// sidecarShutdown runs against the evicted pods carrying sidecarLabel.
+	sidecarLabel    string
+	sidecarShutdown SidecarShutdownHook
+	// evictFirstAnnotation marks the pods evicted one at a time before all
+	// others, when true.
+	evictFirstAnnotation string
+	// startupCostAnnotation holds how costly pods are to start elsewhere,
+	// the cheapest being evicted first.
+	startupCostAnnotation string
+	// unreadyFastPath evicts the pods that are not ready at once, after the
+	// evict first pods and before all others, with at most
+	// unreadyGracePeriod to shut down.
+	unreadyFastPath    bool
+	unreadyGracePeriod time.Duration
+
+	// crashLoopFastPath evicts the crashlooping pods at once, before all
+	// others, allowing each at most crashLoopGracePeriod to shut down.
+	crashLoopFastPath    bool
+	crashLoopGracePeriod time.Duration
+	// namespaceTerminatingPodsGone treats the pods whose namespace is going
+	// away as gone when their eviction fails.
+	namespaceTerminatingPodsGone bool
+	// graceTierLabel is the label of pods whose values are mapped to the
+	// grace period of their eviction by graceTiers.
+	graceTierLabel string
+	graceTiers     map[string]time.Duration
+	// maxRequestAnnotation is the annotation of pods holding the duration
+	// of their longest requests, to which the grace period of their
+	// eviction is extended, up to maxRequestGrace.
+	maxRequestAnnotation string
+	maxRequestGrace      time.Duration
+	// evictionRate caps how many pods of a node are removed per second.
+	// Zero means no limit.
+	evictionRate float64
+	// minInterPodDelay is the minimum time between the removal of two pods
+	// of a node.
+	minInterPodDelay time.Duration
+	// maxTerminating caps how many pods are being removed at once. Zero
+	// means no limit.
+	maxTerminating int
+	// namespaceEvictions caps how many pods of each namespace are being
+	// removed at once, across drains.
+	namespaceEvictions *namespaceLimiter
+
+	// settleDelay is how long Drain waits before listing the pods to evict,
+	// so that pods being scheduled as the node was cordoned land first.
+	settleDelay time.Duration
+
+	// pvAwareDrain waits for the PersistentVolumes of evicted pods to be
+	// detached, for up to volumeDetachTimeout.
+	pvAwareDrain        bool
+	volumeDetachTimeout time.Duration
+
+	// serverDryRun issues evictions as server side dry runs, without
+	// evicting pods or deleting the node.
+	serverDryRun bool
+
+	// cordonReasonAnnotation and cordonOwnerAnnotation are the annotations
+	// set by CordonWithReason, and removed by Uncordon.
+	cordonReasonAnnotation string
+	cordonOwnerAnnotation  string
+
+	// resultAnnotation and timeAnnotation are the annotations set by
+	// AnnotateDrainResult. Drain results are not recorded when unset.
+	resultAnnotation string
+	timeAnnotation   string
+
+	// stateConditions enables a condition per drain state, in addition to
+	// the drain condition.
+	stateConditions bool
+
+	// latencyObserver is called with the latency of each eviction call.
+	latencyObserver func(time.Duration)
+
+	tracer trace.Tracer
+
+	// summaries are the summaries of the last drain of each node, until
+	// they are returned by DrainSummary.
+	summaryMu sync.Mutex
+	summaries map[string]*drainSummary
+
+	// pdbTags are the PodDisruptionBudgets MeasurePDBBlocks was tagged with.
+	pdbTagsMu sync.Mutex
+	pdbTags   map[string]struct{}
 }
 
 // SuppliedCondition defines the condition will be watched.
@@ -150,6 +476,65 @@ func WithPodFilter(f PodFilterFunc) APICordonDrainerOption {
 	}
 }
 
+// WithEvictionFilter configures a filter that may be used to exclude certain
+// pods from eviction when draining. It is applied to the pods that pass the
+// pod filter.
+func WithEvictionFilter(f EvictionFilter) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.evictionFilter = f
+	}
+}
+
+// WithEvictionVerification configures Drain to wait, for up to the supplied
+// timeout, for the evictable pods of the node to be gone before succeeding.
+// Zero disables verification.
+func WithEvictionVerification(timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.verifyTimeout = timeout
+	}
+}
+
+// WithEvictionEscalation allows Drain to force delete, without a grace period,
+// the pods whose eviction is still being refused after the supplied duration,
+// for example due to a PodDisruptionBudget. It should be shorter than the
+// maximum grace period plus the eviction headroom, after which the drain times
+// out. Zero disables escalation.
+func WithEvictionEscalation(after time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.escalateAfter = after
+	}
+}
+
+// WithEvictionCallTimeout bounds each eviction API call to the supplied
+// timeout, so that a call that hangs does not hold up the eviction of its pod
+// until the whole drain times out. Calls that time out are retried, within the
+// eviction timeout of the drain. Zero disables the bound.
+func WithEvictionCallTimeout(timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.evictionCallTimeout = timeout
+	}
+}
+
+// WithNodeGoneCheck checks, at the supplied interval and whenever an eviction
+// fails, that the node being drained still exists. Drains of nodes deleted
+// while their pods are being evicted, for example by the cluster autoscaler,
+// stop with a NotFound error rather than retrying evictions until they time
+// out. Zero disables the check.
+func WithNodeGoneCheck(interval time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.nodeGoneInterval = interval
+	}
+}
+
+// WithPropagationPolicy configures the deletion propagation policy of evicted
+// pods, for example Foreground so that their dependents are cleaned up before
+// they are gone.
+func WithPropagationPolicy(p meta.DeletionPropagation) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.propagationPolicy = &p
+	}
+}
+
 // WithDrain determines if we're actually going to drain nodes
 func WithSkipDrain(b bool) APICordonDrainerOption {
 	return func(d *APICordonDrainer) {
@@ -164,6 +549,193 @@ func WithSkipDelete(b bool) APICordonDrainerOption {
 	}
 }
 
+// WithCordonAnnotations configures the annotations recording the reason and the
+// owner of the cordons made by CordonWithReason.
+func WithCordonAnnotations(reasonKey, ownerKey string) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.cordonReasonAnnotation = reasonKey
+		d.cordonOwnerAnnotation = ownerKey
+	}
+}
+
+// WithDrainResultAnnotations configures the annotations recording the result,
+// and the completion time, of the last drain of a node. Drain results are not
+// recorded unless configured.
+func WithDrainResultAnnotations(resultKey, timeKey string) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.resultAnnotation = resultKey
+		d.timeAnnotation = timeKey
+	}
+}
+
+// WithTerminatingPodTimeout configures how long Drain waits for the pods that
+// are already terminating, typically because another controller is evicting
+// them, to be gone before evicting them itself. Zero waits as long as Drain
+// waits for evicted pods.
+func WithTerminatingPodTimeout(timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.terminatingTimeout = timeout
+	}
+}
+
+// WithDrainStateConditions determines whether MarkDrainState sets, in addition
+// to the drain condition, a DrainInProgress, DrainSucceeded or DrainFailed
+// condition that is true only while the drain is in the matching state. The
+// drain condition alone is set when disabled.
+func WithDrainStateConditions(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.stateConditions = b
+	}
+}
+
+// WithEmptyNodeFastPath determines whether Drain completes immediately, with a
+// NothingToEvictError, when the node has no pods to evict.
+func WithEmptyNodeFastPath(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.emptyNodeFastPath = b
+	}
+}
+
+// WithDeterministicOrder determines whether Drain evicts pods one at a time,
+// sorted by namespace then name, so that drains of the same pods are
+// reproducible. Each eviction then waits for the previous pod to be gone, so
+// drains take longer; the eviction timeout still bounds the whole drain. Pods
+// are evicted all at once when disabled.
+func WithDeterministicOrder(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.deterministicOrder = b
+	}
+}
+
+// WithCordonSettleDelay configures how long Drain waits, once the node is
+// cordoned, before selecting and evicting its pods. This lets scheduling
+// decisions made before the cordon complete, so that their pods are evicted
+// too rather than landing on the node mid-drain. Zero does not wait.
+func WithCordonSettleDelay(delay time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.settleDelay = delay
+	}
+}
+
+// WithOwnerAwareOrder determines whether Drain evicts the pods of one
+// controller at a time, waiting for all the pods of a controller to be gone
+// before evicting those of the next, so that several workloads are never
+// partially disrupted at once. Pods without a controller are evicted on their
+// own. Pods of the same owner are evicted one at a time too with
+// WithDeterministicOrder. All pods are evicted at once when disabled.
+func WithOwnerAwareOrder(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.ownerAwareOrder = b
+	}
+}
+
+// WithEvictFirstAnnotation determines which pods Drain evicts before all
+// others: those whose supplied annotation is true. These pods are evicted one at
+// a time, sorted by namespace then name, each once the previous one is gone, and
+// the remaining pods are only evicted once they are all gone, in the order
+// configured by WithOwnerAwareOrder and WithDeterministicOrder. An empty
+// annotation disables this phase.
+func WithEvictFirstAnnotation(annotation string) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.evictFirstAnnotation = annotation
+	}
+}
+
+// WithStartupCostAnnotation orders the eviction of pods by the number held by
+// the supplied annotation, the cost of starting them elsewhere, so that pods
+// that are cheap to start are evicted first and those that are slow to start,
+// say because of large images or long init containers, last. Pods of equal
+// cost are evicted together, each batch once the previous one is gone. Pods
+// without a valid cost are evicted halfway through the others. Within a batch,
+// pods are evicted in the order configured by WithOwnerAwareOrder and
+// WithDeterministicOrder. The evict first pods, and fast pathed unready pods,
+// still precede them all. An empty annotation disables this order.
+func WithStartupCostAnnotation(annotation string) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.startupCostAnnotation = annotation
+	}
+}
+
+// WithUnreadyPodFastPath clears the pods that are not ready, and so serve no
+// traffic, before the ready ones. Drain evicts them at once, once the pods
+// selected by WithEvictFirstAnnotation are gone, allowing each at most the
+// supplied grace period to shut down. Ready pods are only evicted once they
+// are all gone, with their usual grace period.
+func WithUnreadyPodFastPath(gracePeriod time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.unreadyFastPath = true
+		d.unreadyGracePeriod = gracePeriod
+	}
+}
+
+// WithGracePeriodTiers overrides the grace period of the eviction of pods
+// whose supplied label has one of the values of the supplied tiers, for
+// example tier=critical for extra grace and tier=batch for none. Tiers may
+// exceed MaxGracePeriod, and the pod's own grace period; the eviction timeout
+// is raised to fit the longest tier. Pods without the label, or with another
+// value, keep the default grace period.
+func WithGracePeriodTiers(label string, tiers map[string]time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.graceTierLabel = label
+		d.graceTiers = tiers
+	}
+}
+
+// WithMaxTerminatingPods caps how many pods of a node Drain removes at once.
+// Drain evicts up to max pods, then evicts another each time one is gone, so
+// that the kubelet is never terminating more than max pods. A pod counts from
+// its eviction, including refused evictions being retried, until it is gone.
+// The limit applies within each batch with WithOwnerAwareOrder. Zero means
+// no limit.
+func WithMaxTerminatingPods(max int) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.maxTerminating = max
+	}
+}
+
+// WithEvictionRatePerNode caps how many pods of a node Drain removes per
+// second, so that the kubelet of a large node is not overwhelmed by a burst of
+// evictions. Each Drain paces its own evictions, and force deletions, with a
+// token bucket holding a single token. Refused evictions being retried are not
+// paced again. Zero means no limit.
+func WithEvictionRatePerNode(perSecond float64) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.evictionRate = perSecond
+	}
+}
+
+// WithMinInterPodDelay spaces the removal of the pods of a node by at least the
+// supplied delay, so that the scheduler places evicted pods one at a time
+// rather than all at once. Unlike batches, the delay applies between any two
+// removals, including force deletions, and combines with
+// WithEvictionRatePerNode. The total delay added to each drain is recorded in
+// its summary. Zero means no delay.
+func WithMinInterPodDelay(delay time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.minInterPodDelay = delay
+	}
+}
+
+// WithServerDryRun determines whether Drain issues evictions as server side
+// dry runs. The API server then validates each eviction, including against
+// PodDisruptionBudgets and admission, without evicting the pod. Drain returns
+// an EvictionsRejectedError listing the pods whose eviction the API server
+// rejected, a DryRunEvictionsError if it accepted them all, and never deletes
+// the node.
+func WithServerDryRun(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.serverDryRun = b
+	}
+}
+
+// WithLatencyObserver configures a function called with the latency of each
+// eviction call, for example LatencyThrottle.Observe.
+func WithLatencyObserver(fn func(time.Duration)) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.latencyObserver = fn
+	}
+}
+
 // WithAPICordonDrainerLogger configures a APICordonDrainer to use the supplied
 // logger.
 func WithAPICordonDrainerLogger(l *zap.Logger) APICordonDrainerOption {
@@ -172,6 +744,14 @@ func WithAPICordonDrainerLogger(l *zap.Logger) APICordonDrainerOption {
 	}
 }
 
+// WithAPICordonDrainerTracer configures a APICordonDrainer to record a span for
+// each pod eviction. Spans are children of the span found in the drain context.
+func WithAPICordonDrainerTracer(t trace.Tracer) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.tracer = t
+	}
+}
+
 // NewAPICordonDrainer returns a CordonDrainer that cordons and drains nodes via
 // the Kubernetes API.
 func NewAPICordonDrainer(c kubernetes.Interface, ao ...APICordonDrainerOption) *APICordonDrainer {
@@ -182,6 +762,19 @@ func NewAPICordonDrainer(c kubernetes.Interface, ao ...APICordonDrainerOption) *
 		maxGracePeriod:   DefaultMaxGracePeriod,
 		evictionHeadroom: DefaultEvictionOverhead,
 		skipDrain:        DefaultSkipDrain,
+
+		emptyNodeFastPath:            true,
+		namespaceTerminatingPodsGone: true,
+
+		cordonRetryPeriod:  DefaultCordonRetryPeriod,
+		cordonRetryTimeout: DefaultCordonRetryTimeout,
+
+		cordonReasonAnnotation:  DefaultCordonReasonAnnotation,
+		cordonOwnerAnnotation:   DefaultCordonOwnerAnnotation,
+		protectedPodsAnnotation: DefaultProtectedPodsAnnotation,
+
+		eventReasons: DefaultEventReasons,
+		metrics:      OpenCensusMetricsRecorder{},
 	}
 	for _, o := range ao {
 		o(d)
@@ -190,44 +783,86 @@ func NewAPICordonDrainer(c kubernetes.Interface, ao ...APICordonDrainerOption) *
 }
 
 func (d *APICordonDrainer) deleteTimeout() time.Duration {
-	return d.maxGracePeriod + d.evictionHeadroom
+	grace := d.maxGracePeriod
+	for _, tier := range d.graceTiers {
+		if tier > grace {
+			grace = tier
+		}
+	}
+	if d.maxRequestAnnotation != "" && d.maxRequestGrace > grace {
+		grace = d.maxRequestGrace
+	}
+	return grace + d.evictionHeadroom
 }
 
-// Cordon the supplied node. Marks it unschedulable for new pods.
-func (d *APICordonDrainer) Cordon(n *core.Node, mutators ...nodeMutatorFn) error {
-	for {
-		fresh, err := d.c.CoreV1().Nodes().Get(context.Background(), n.GetName(), meta.GetOptions{})
-		if err != nil {
-			return errors.Wrapf(err, "cannot get node %s", n.GetName())
-		}
+// gracePeriod returns the grace period, in seconds, of the eviction of the
+// supplied pod.
+func (d *APICordonDrainer) gracePeriod(p core.Pod) int64 {
+	gracePeriod := int64(d.maxGracePeriod.Seconds())
+	if p.Spec.TerminationGracePeriodSeconds != nil && *p.Spec.TerminationGracePeriodSeconds < gracePeriod {
+		gracePeriod = *p.Spec.TerminationGracePeriodSeconds
+	}
+	if tier, ok := d.graceTiers[p.GetLabels()[d.graceTierLabel]]; ok && d.graceTierLabel != "" {
+		gracePeriod = int64(tier.Seconds())
+	}
+	gracePeriod = d.longRequestGrace(p, gracePeriod)
+	if d.unreadyFastPath && !podReady(p) && int64(d.unreadyGracePeriod.Seconds()) < gracePeriod {
+		gracePeriod = int64(d.unreadyGracePeriod.Seconds())
+	}
+	if d.crashLooping(p) && int64(d.crashLoopGracePeriod.Seconds()) < gracePeriod {
+		gracePeriod = int64(d.crashLoopGracePeriod.Seconds())
+	}
+	return gracePeriod
+}
 
-		if fresh.Spec.Unschedulable {
-			return nil
-		}
+func (d *APICordonDrainer) terminatingPodTimeout() time.Duration {
+	if d.terminatingTimeout > 0 {
+		return d.terminatingTimeout
+	}
+	return d.deleteTimeout()
+}
 
-		fresh.Spec.Unschedulable = true
-		// Remove any karpenter annotations
-		delete(fresh.Annotations, "karpenter.sh/do-not-evict")
-		delete(fresh.Annotations, "karpenter.sh/do-not-disrupt")
-		delete(fresh.Annotations, "karpenter.sh/do-not-consolidate")
-		for _, m := range mutators {
-			m(fresh)
-		}
+// cordon attempts to cordon the supplied node once.
+func (d *APICordonDrainer) cordon(n *core.Node, mutators ...nodeMutatorFn) error {
+	fresh, err := d.c.CoreV1().Nodes().Get(context.Background(), n.GetName(), meta.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "cannot get node %s", n.GetName())
+	}
 
-		if _, err := d.c.CoreV1().Nodes().Update(context.Background(), fresh, meta.UpdateOptions{}); err != nil {
-			if apierrors.IsConflict(err) {
-				d.l.Info("Sleep to retry for conflict error")
-				time.Sleep(5)
-			} else {
-				return errors.Wrapf(err, "cannot cordon node %s", fresh.GetName())
-			}
-		} else {
-			return nil
+	if fresh.Spec.Unschedulable {
+		return nil
+	}
+
+	fresh.Spec.Unschedulable = true
+	// Remove any karpenter annotations
+	delete(fresh.Annotations, "karpenter.sh/do-not-evict")
+	delete(fresh.Annotations, "karpenter.sh/do-not-disrupt")
+	delete(fresh.Annotations, "karpenter.sh/do-not-consolidate")
+	for _, m := range mutators {
+		m(fresh)
+	}
+
+	if _, err := d.c.CoreV1().Nodes().Update(context.Background(), fresh, meta.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "cannot cordon node %s", fresh.GetName())
+	}
+	return nil
+}
+
+// CordonWithReason cordons the supplied node, annotating it with the supplied
+// reason and owner so that humans and other tooling know why it was cordoned.
+func (d *APICordonDrainer) CordonWithReason(n *core.Node, reason, owner string, mutators ...nodeMutatorFn) error {
+	annotate := func(n *core.Node) {
+		if n.Annotations == nil {
+			n.Annotations = make(map[string]string)
 		}
+		n.Annotations[d.cordonReasonAnnotation] = reason
+		n.Annotations[d.cordonOwnerAnnotation] = owner
 	}
+	return d.Cordon(n, append(mutators, annotate)...)
 }
 
-// Uncordon the supplied node. Marks it schedulable for new pods.
+// Uncordon the supplied node. Marks it schedulable for new pods, and removes
+// the annotations set by CordonWithReason.
 func (d *APICordonDrainer) Uncordon(n *core.Node, mutators ...nodeMutatorFn) error {
 	fresh, err := d.c.CoreV1().Nodes().Get(context.Background(), n.GetName(), meta.GetOptions{})
 	if err != nil {
@@ -237,6 +872,8 @@ func (d *APICordonDrainer) Uncordon(n *core.Node, mutators ...nodeMutatorFn) err
 		return nil
 	}
 	fresh.Spec.Unschedulable = false
+	delete(fresh.Annotations, d.cordonReasonAnnotation)
+	delete(fresh.Annotations, d.cordonOwnerAnnotation)
 	for _, m := range mutators {
 		m(fresh)
 	}
@@ -246,8 +883,60 @@ func (d *APICordonDrainer) Uncordon(n *core.Node, mutators ...nodeMutatorFn) err
 	return nil
 }
 
+// AnnotateDrainResult records the supplied result and completion time of the
+// last drain of the supplied node as annotations, if configured. The node is
+// patched, leaving its other annotations untouched.
+func (d *APICordonDrainer) AnnotateDrainResult(n *core.Node, result string, finish time.Time) error {
+	if d.resultAnnotation == "" && d.timeAnnotation == "" {
+		return nil
+	}
+	annotations := map[string]string{}
+	if d.resultAnnotation != "" {
+		annotations[d.resultAnnotation] = result
+	}
+	if d.timeAnnotation != "" {
+		annotations[d.timeAnnotation] = finish.UTC().Format(time.RFC3339)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot encode drain result annotations")
+	}
+	if _, err := d.c.CoreV1().Nodes().Patch(context.Background(), n.GetName(), types.MergePatchType, patch, meta.PatchOptions{}); err != nil {
+		return errors.Wrapf(err, "cannot annotate drain result of node %s", n.GetName())
+	}
+	return nil
+}
+
 // MarkDrain set a condition on the node to mark that that drain is scheduled.
-func (d *APICordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, failed bool) error {
+func (d *APICordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, failed bool, reason string) error {
+	return d.markDrain(n, "", when, finish, failed, reason)
+}
+
+// MarkDrainState sets the drain condition on the supplied node according to the
+// supplied state. If state conditions are enabled, the condition matching the
+// state is also set true, and those of the other states false.
+func (d *APICordonDrainer) MarkDrainState(n *core.Node, state DrainState, when, finish time.Time, reason string) error {
+	if !d.stateConditions && state == DrainStateInProgress {
+		// The drain condition does not distinguish in progress drains
+		// from scheduled ones.
+		return nil
+	}
+	if state == DrainStateScheduled || state == DrainStateInProgress {
+		finish = time.Time{}
+	}
+	return d.markDrain(n, state, when, finish, state == DrainStateFailed, reason)
+}
+
+// stateConditionTypes are the condition types matching drain states.
+var stateConditionTypes = map[DrainState]string{
+	DrainStateInProgress: ConditionDrainInProgress,
+	DrainStateSucceeded:  ConditionDrainSucceeded,
+	DrainStateFailed:     ConditionDrainFailed,
+}
+
+func (d *APICordonDrainer) markDrain(n *core.Node, state DrainState, when, finish time.Time, failed bool, reason string) error {
 	nodeName := n.Name
 	// Refresh the node object
 	freshNode, err := d.c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{})
@@ -263,6 +952,9 @@ func (d *APICordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, faile
 	if !finish.IsZero() {
 		if failed {
 			msgSuffix = fmt.Sprintf(" | Failed: %s", finish.Format(time.RFC3339))
+			if reason != "" {
+				msgSuffix += " | Reason: " + truncateReason(reason)
+			}
 		} else {
 			msgSuffix = fmt.Sprintf(" | Completed: %s", finish.Format(time.RFC3339))
 		}
@@ -275,7 +967,7 @@ func (d *APICordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, faile
 	for i, condition := range freshNode.Status.Conditions {
 		if string(condition.Type) == ConditionDrainedScheduled {
 			freshNode.Status.Conditions[i].LastHeartbeatTime = now
-			freshNode.Status.Conditions[i].Message = "Drain activity scheduled " + when.Format(time.RFC3339) + msgSuffix
+			freshNode.Status.Conditions[i].Message = scheduledConditionPrefix + when.Format(time.RFC3339) + msgSuffix
 			freshNode.Status.Conditions[i].Status = conditionStatus
 			conditionUpdated = true
 			break
@@ -289,16 +981,95 @@ func (d *APICordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, faile
 				LastHeartbeatTime:  now,
 				LastTransitionTime: now,
 				Reason:             "Draino",
-				Message:            "Drain activity scheduled " + when.Format(time.RFC3339) + msgSuffix,
+				Message:            scheduledConditionPrefix + when.Format(time.RFC3339) + msgSuffix,
 			},
 		)
 	}
+	if d.stateConditions && state != "" {
+		setStateConditions(freshNode, state, scheduledConditionPrefix+when.Format(time.RFC3339)+msgSuffix, now)
+	}
 	if _, err := d.c.CoreV1().Nodes().UpdateStatus(context.Background(), freshNode, meta.UpdateOptions{}); err != nil {
 		return err
 	}
 	return nil
 }
 
+// isDrainCondition returns true if the supplied condition type is set by
+// draino to track drains.
+func isDrainCondition(t core.NodeConditionType) bool {
+	if string(t) == ConditionDrainedScheduled {
+		return true
+	}
+	for _, st := range stateConditionTypes {
+		if string(t) == st {
+			return true
+		}
+	}
+	return false
+}
+
+// setStateConditions sets the condition matching the supplied drain state true
+// on the supplied node, and those of the other states false. Conditions of the
+// other states are only added to the node once they become true.
+func setStateConditions(n *core.Node, state DrainState, message string, now meta.Time) {
+	for s, t := range stateConditionTypes {
+		status := core.ConditionFalse
+		if s == state {
+			status = core.ConditionTrue
+		}
+		found := false
+		for i, c := range n.Status.Conditions {
+			if string(c.Type) != t {
+				continue
+			}
+			found = true
+			if c.Status != status {
+				n.Status.Conditions[i].LastTransitionTime = now
+			}
+			n.Status.Conditions[i].Status = status
+			n.Status.Conditions[i].LastHeartbeatTime = now
+			n.Status.Conditions[i].Message = message
+			break
+		}
+		if !found && status == core.ConditionTrue {
+			n.Status.Conditions = append(n.Status.Conditions, core.NodeCondition{
+				Type:               core.NodeConditionType(t),
+				Status:             status,
+				LastHeartbeatTime:  now,
+				LastTransitionTime: now,
+				Reason:             "Draino",
+				Message:            message,
+			})
+		}
+	}
+}
+
+// ClearDrainCondition removes the conditions marking the drain of the supplied
+// node, if any.
+func (d *APICordonDrainer) ClearDrainCondition(n *core.Node) error {
+	fresh, err := d.c.CoreV1().Nodes().Get(context.Background(), n.GetName(), meta.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "cannot get node %s", n.GetName())
+	}
+	conditions := fresh.Status.Conditions[:0]
+	for _, c := range fresh.Status.Conditions {
+		if !isDrainCondition(c.Type) {
+			conditions = append(conditions, c)
+		}
+	}
+	if len(conditions) == len(fresh.Status.Conditions) {
+		return nil
+	}
+	fresh.Status.Conditions = conditions
+	if _, err := d.c.CoreV1().Nodes().UpdateStatus(context.Background(), fresh, meta.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "cannot clear drain condition of node %s", n.GetName())
+	}
+	return nil
+}
+
 func IsMarkedForDrain(n *core.Node) bool {
 	for _, condition := range n.Status.Conditions {
 		if string(condition.Type) == ConditionDrainedScheduled && condition.Status == core.ConditionTrue {
@@ -310,118 +1081,850 @@ func IsMarkedForDrain(n *core.Node) bool {
 
 // Drain the supplied node. Evicts the node of all but mirror and DaemonSet pods.
 func (d *APICordonDrainer) Drain(n *core.Node) error {
+	return d.DrainWithContext(context.Background(), n)
+}
+
+// DrainWithContext drains the supplied node using the supplied context.
+func (d *APICordonDrainer) DrainWithContext(ctx context.Context, n *core.Node) error {
+	return d.drain(ctx, n, false)
+}
+
+// ForceDrain the supplied node. Like DrainWithContext, but pods are deleted
+// without a grace period instead of being evicted.
+func (d *APICordonDrainer) ForceDrain(ctx context.Context, n *core.Node) error {
+	return d.drain(ctx, n, true)
+}
+
+func (d *APICordonDrainer) drain(ctx context.Context, n *core.Node, force bool) error {
 	// Do nothing if draining is not enabled.
 	if d.skipDrain {
 		d.l.Debug("Skipping drain because draining is disabled")
 		return nil
 	}
+	summary := d.startDrainSummary(n.GetName())
+	ctx = withDrainSummary(ctx, summary)
+
+	if d.settleDelay > 0 {
+		d.l.Info("Waiting for cordon to settle", zap.String("node", n.GetName()), zap.Duration("delay", d.settleDelay))
+		select {
+		case <-time.After(d.settleDelay):
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "drain cancelled")
+		}
+	}
 
-	pods, err := d.getPods(n.GetName())
+	pods, err := d.selectPods(n.GetName(), summary)
 	if err != nil {
 		return errors.Wrapf(err, "cannot get pods for node %s", n.GetName())
 	}
+	pods, protected := d.skipProtected(ctx, n, pods, summary)
+	for _, p := range pods {
+		if len(claimNames(p)) > 0 {
+			summary.update(func(s *DrainSummary) { s.VolumeBacked++ })
+		}
+	}
+	if d.serverDryRun {
+		return d.dryRunEvictions(ctx, n, pods)
+	}
+	if len(pods) == 0 && d.emptyNodeFastPath {
+		d.l.Info("No pods to evict", zap.String("node", n.GetName()))
+		if err := d.awaitDrainSuccess(ctx, n); err != nil {
+			return err
+		}
+		if err := d.deleteNode(ctx, n); err != nil {
+			return err
+		}
+		return NewNothingToEvictError(n.GetName())
+	}
 
 	abort := make(chan struct{})
 	errs := make(chan error, 1)
-	for _, pod := range pods {
-		go d.evict(pod, abort, errs)
+	// blocked counts the pods whose eviction is currently being refused,
+	// typically due to a pod disruption budget.
+	var blocked int32
+	// limit holds a token per pod being removed, up to the maximum number of
+	// terminating pods.
+	var limit chan struct{}
+	if d.maxTerminating > 0 {
+		limit = make(chan struct{}, d.maxTerminating)
+	}
+	// pace paces the removal of pods to the eviction rate, if any.
+	pace := newEvictionPacer(ctx, d.evictionRate, d.minInterPodDelay)
+	defer pace.stop()
+	var terminating, peak int32
+	defer func() {
+		max := int(atomic.LoadInt32(&peak))
+		summary.update(func(s *DrainSummary) { s.PeakTerminating = max })
+		d.metrics.PeakTerminatingPods(n.GetName(), max)
+		d.recordPodsByOwnerKind(n.GetName(), pods)
+		if rate, ok := pace.observed(); ok {
+			d.metrics.NodeEvictionRate(n.GetName(), rate)
+		}
+		if d.minInterPodDelay > 0 {
+			delayed := pace.addedDelay()
+			summary.update(func(s *DrainSummary) { s.InterPodDelay = delayed })
+			d.metrics.InterPodDelay(n.GetName(), delayed)
+		}
+	}()
+	remove := func(p core.Pod) {
+		if limit != nil {
+			select {
+			case limit <- struct{}{}:
+				defer func() { <-limit }()
+			case <-abort:
+				errs <- errors.New("pod eviction aborted")
+				return
+			case <-ctx.Done():
+				errs <- errors.Wrap(ctx.Err(), "pod eviction cancelled")
+				return
+			}
+		}
+		release, err := d.namespaceEvictions.acquire(ctx, abort, p.GetNamespace(), d.l, d.metrics)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer release()
+		if err := pace.wait(); err != nil {
+			errs <- errors.Wrap(err, "pod eviction cancelled")
+			return
+		}
+		current := atomic.AddInt32(&terminating, 1)
+		defer atomic.AddInt32(&terminating, -1)
+		for {
+			prev := atomic.LoadInt32(&peak)
+			if current <= prev || atomic.CompareAndSwapInt32(&peak, prev, current) {
+				break
+			}
+		}
+		if force {
+			errs <- d.forceDelete(ctx, p)
+			return
+		}
+		d.evict(ctx, p, abort, errs, &blocked)
+	}
+	evicting := time.Now()
+	batches := d.podBatches(pods, d.topologySpread(ctx, n, pods))
+	if len(batches) == 1 {
+		for _, pod := range pods {
+			go remove(pod)
+		}
+	} else {
+		// Each batch is evicted at once, once the pods of the previous one
+		// are gone.
+		go func() {
+			for _, batch := range batches {
+				select {
+				case <-abort:
+					return
+				default:
+				}
+				var wg sync.WaitGroup
+				for _, pod := range batch {
+					wg.Add(1)
+					go func(p core.Pod) {
+						defer wg.Done()
+						remove(p)
+					}(pod)
+				}
+				wg.Wait()
+			}
+		}()
 	}
 
-	// This will _eventually_ abort evictions. Evictions may spend up to
-	// d.deleteTimeout() in d.awaitDeletion(), or 5 seconds in backoff before
-	// noticing they've been aborted.
+	// This will _eventually_ abort evictions, which may spend up to
+	// d.evictionTimeout() or 5 seconds in backoff before noticing they've
+	// been aborted.
 	defer close(abort)
 
-	deadline := time.After(d.deleteTimeout())
+	timeout := d.evictionTimeout(batches)
+	if override, ok := drainTimeout(ctx); ok {
+		timeout = override
+	}
+	deadline := time.After(timeout)
+	var gone <-chan time.Time
+	if d.nodeGoneInterval > 0 {
+		ticker := time.NewTicker(d.nodeGoneInterval)
+		defer ticker.Stop()
+		gone = ticker.C
+	}
+	progress := d.trackProgress(ctx, n, len(pods))
+	defer progress.stop()
 
-	for range pods {
+	for remaining := len(pods); remaining > 0; {
 		select {
+		case <-progress.tick():
+			progress.report(ctx, len(pods)-remaining)
 		case err := <-errs:
+			remaining--
 			if err != nil {
+				if goneErr := d.checkNodeGone(ctx, n); goneErr != nil {
+					return goneErr
+				}
 				return errors.Wrap(err, "cannot evict all pods")
 			}
+		case <-gone:
+			if err := d.checkNodeGone(ctx, n); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "drain cancelled")
 		case <-deadline:
+			if n := atomic.LoadInt32(&blocked); n > 0 {
+				return errors.Wrap(errPDBBlocked{pods: n}, "timed out waiting for evictions to complete")
+			}
 			return errors.Wrap(errTimeout{}, "timed out waiting for evictions to complete")
 		}
 	}
+	progress.report(ctx, len(pods))
+	d.checkReplacementSpread(ctx, n, pods, evicting)
+
+	if d.verifyTimeout > 0 {
+		skip := summary.denied()
+		for pod := range protected {
+			skip[pod] = true
+		}
+		if err := d.verifyEvictions(n.GetName(), skip); err != nil {
+			return err
+		}
+	}
+	if err := d.awaitDrainSuccess(ctx, n); err != nil {
+		return err
+	}
 
 	// All pods have been evicted, delete the node
+	return d.deleteNode(ctx, n)
+}
+
+// evictionTimeout returns how long Drain waits for the supplied batches of
+// pods to be removed. The removal of a pod may spend up to d.deleteTimeout() in
+// d.awaitDeletion(), the stuck terminating threshold before force deleting it,
+// the Job pod timeout in d.awaitJobCompletion(), the replacement timeout in
+// d.awaitReplacementRoom() and the volume detach timeout in
+// d.awaitVolumeDetach(). Batches, and the rounds of pods admitted by the
+// maximum number of terminating pods, are removed one after the other, and
+// pacing delays the removal of each pod.
+func (d *APICordonDrainer) evictionTimeout(batches [][]core.Pod) time.Duration {
+	perPod := d.deleteTimeout() + d.jobWaitTimeout() + d.replacementTimeout + d.stuckTerminatingThreshold
+	if d.pvAwareDrain {
+		perPod += d.detachTimeout()
+	}
+	rounds, pods := 0, 0
+	for _, batch := range batches {
+		pods += len(batch)
+		if d.maxTerminating > 0 {
+			rounds += (len(batch) + d.maxTerminating - 1) / d.maxTerminating
+		} else {
+			rounds++
+		}
+	}
+	if rounds < 1 {
+		rounds = 1
+	}
+	pacing := d.minInterPodDelay
+	if d.evictionRate > 0 {
+		if interval := time.Duration(float64(time.Second) / d.evictionRate); interval > pacing {
+			pacing = interval
+		}
+	}
+	return time.Duration(rounds)*perPod + time.Duration(pods)*pacing
+}
+
+// dryRunEvictions issues a server side dry run eviction of each of the
+// supplied pods of the supplied node. It returns an EvictionsRejectedError if
+// the API server rejected any of them, and a DryRunEvictionsError otherwise.
+func (d *APICordonDrainer) dryRunEvictions(ctx context.Context, n *core.Node, pods []core.Pod) error {
+	var rejected []string
+	for _, p := range pods {
+		err := d.c.CoreV1().Pods(p.GetNamespace()).Evict(ctx, &policy.Eviction{
+			ObjectMeta:    meta.ObjectMeta{Namespace: p.GetNamespace(), Name: p.GetName()},
+			DeleteOptions: &meta.DeleteOptions{DryRun: []string{meta.DryRunAll}, PropagationPolicy: d.propagationPolicy},
+		})
+		if err == nil || apierrors.IsNotFound(err) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "drain cancelled")
+		}
+		d.l.Info("Dry run eviction rejected", zap.String("node", n.GetName()), zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Error(err))
+		rejected = append(rejected, p.GetNamespace()+"/"+p.GetName())
+	}
+	if len(rejected) > 0 {
+		return NewEvictionsRejectedError(n.GetName(), rejected)
+	}
+	d.l.Info("Dry run evictions accepted", zap.String("node", n.GetName()), zap.Int("pods", len(pods)))
+	return NewDryRunEvictionsError(n.GetName(), len(pods))
+}
+
+// DryRunEvictionsError is returned by Drain, when evictions are server side
+// dry runs, if the API server accepted the eviction of all pods. It does not
+// denote a failure, but that the node was not actually drained.
+type DryRunEvictionsError struct {
+	error
+}
+
+func NewDryRunEvictionsError(name string, pods int) error {
+	return &DryRunEvictionsError{
+		fmt.Errorf("dry run evictions of %d pods accepted on node %s", pods, name),
+	}
+}
+
+func IsDryRunEvictionsError(err error) bool {
+	_, ok := errors.Cause(err).(*DryRunEvictionsError)
+	return ok
+}
+
+// EvictionsRejectedError is returned by Drain, when evictions are server side
+// dry runs, if the API server rejected the eviction of some pods.
+type EvictionsRejectedError struct {
+	error
+	// Pods are the namespaced names of the pods whose eviction was rejected.
+	Pods []string
+}
+
+func NewEvictionsRejectedError(name string, pods []string) error {
+	return &EvictionsRejectedError{
+		error: fmt.Errorf("dry run evictions rejected on node %s: %s", name, strings.Join(pods, ", ")),
+		Pods:  pods,
+	}
+}
+
+func IsEvictionsRejectedError(err error) bool {
+	_, ok := errors.Cause(err).(*EvictionsRejectedError)
+	return ok
+}
+
+// podBatches splits the supplied pods into the batches evicted one after the
+// other. Crashlooping pods come first, at once, if they are fast pathed. Pods
+// to evict first come next, one pod at a time, then the unready pods at once
+// if they are fast pathed. All other pods are evicted by
+// increasing startup cost, if configured, and within each cost at once unless
+// they are ordered by owner, one batch per owner, or deterministically, one pod
+// at a time, or by the supplied topology spread, if any. Pods requesting
+// extended resources come last, if configured, and system pods after them.
+func (d *APICordonDrainer) podBatches(pods []core.Pod, spread map[string]int) [][]core.Pod {
+	var crashing, others []core.Pod
+	for _, p := range pods {
+		if d.crashLooping(p) {
+			crashing = append(crashing, p)
+		} else {
+			others = append(others, p)
+		}
+	}
+	if len(crashing) > 0 {
+		batches := [][]core.Pod{crashing}
+		if len(others) > 0 {
+			batches = append(batches, d.podBatches(others, spread)...)
+		}
+		return batches
+	}
+
+	var first, unready, rest, extended, system []core.Pod
+	for _, p := range pods {
+		switch {
+		case d.evictFirst(p):
+			first = append(first, p)
+		case d.isSystemPod(p):
+			system = append(system, p)
+		case d.extendedResourcesLast && requestsExtendedResources(p):
+			extended = append(extended, p)
+		case d.unreadyFastPath && !podReady(p):
+			unready = append(unready, p)
+		default:
+			rest = append(rest, p)
+		}
+	}
+	if len(extended) > 0 || len(system) > 0 {
+		var batches [][]core.Pod
+		if others := append(first, append(unready, rest...)...); len(others) > 0 {
+			batches = d.podBatches(others, spread)
+		}
+		for _, last := range [][]core.Pod{extended, system} {
+			if len(last) > 0 {
+				batches = append(batches, d.costBatches(last, spread)...)
+			}
+		}
+		return batches
+	}
+	if len(first) == 0 && len(unready) == 0 {
+		return d.costBatches(pods, spread)
+	}
+	sortPods(first)
+	batches := make([][]core.Pod, 0, len(first)+2)
+	for _, p := range first {
+		batches = append(batches, []core.Pod{p})
+	}
+	if len(unready) > 0 {
+		batches = append(batches, unready)
+	}
+	if len(rest) == 0 {
+		return batches
+	}
+	return append(batches, d.costBatches(rest, spread)...)
+}
+
+// costBatches splits the supplied pods into batches by increasing startup cost,
+// then according to the configured eviction order. Pods without a valid cost
+// are evicted halfway through the distinct costs of the others.
+func (d *APICordonDrainer) costBatches(pods []core.Pod, spread map[string]int) [][]core.Pod {
+	if d.startupCostAnnotation == "" {
+		return d.orderedBatches(pods, spread)
+	}
+	var unknown []core.Pod
+	costs := map[float64][]core.Pod{}
+	for _, p := range pods {
+		cost, err := strconv.ParseFloat(p.GetAnnotations()[d.startupCostAnnotation], 64)
+		if err != nil || math.IsNaN(cost) {
+			unknown = append(unknown, p)
+			continue
+		}
+		costs[cost] = append(costs[cost], p)
+	}
+	if len(costs) == 0 {
+		return d.orderedBatches(pods, spread)
+	}
+	keys := make([]float64, 0, len(costs))
+	for k := range costs {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+	var batches [][]core.Pod
+	for i, k := range keys {
+		if i == len(keys)/2 && len(unknown) > 0 {
+			batches = append(batches, d.orderedBatches(unknown, spread)...)
+		}
+		batches = append(batches, d.orderedBatches(costs[k], spread)...)
+	}
+	return batches
+}
+
+// podReady returns true if the supplied pod is ready to serve traffic.
+func podReady(p core.Pod) bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == core.PodReady {
+			return c.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
+
+// evictFirst returns true if the supplied pod must be evicted before all
+// others.
+func (d *APICordonDrainer) evictFirst(p core.Pod) bool {
+	if d.evictFirstAnnotation == "" {
+		return false
+	}
+	first, _ := strconv.ParseBool(p.GetAnnotations()[d.evictFirstAnnotation])
+	return first
+}
+
+// orderedBatches splits the supplied pods into batches according to the
+// supplied topology spread, if any, or the configured eviction order.
+func (d *APICordonDrainer) orderedBatches(pods []core.Pod, spread map[string]int) [][]core.Pod {
+	if spread != nil {
+		return spreadBatches(pods, spread)
+	}
+	if d.largestFirstOrder {
+		return largestFirstBatches(pods)
+	}
+	if d.deterministicOrder {
+		sortPods(pods)
+	}
+	if !d.ownerAwareOrder {
+		if !d.deterministicOrder {
+			return [][]core.Pod{pods}
+		}
+		batches := make([][]core.Pod, 0, len(pods))
+		for _, p := range pods {
+			batches = append(batches, []core.Pod{p})
+		}
+		return batches
+	}
+
+	owners := map[string][]core.Pod{}
+	for _, p := range pods {
+		owners[podOwner(p)] = append(owners[podOwner(p)], p)
+	}
+	keys := make([]string, 0, len(owners))
+	for k := range owners {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var batches [][]core.Pod
+	for _, k := range keys {
+		if !d.deterministicOrder {
+			batches = append(batches, owners[k])
+			continue
+		}
+		for _, p := range owners[k] {
+			batches = append(batches, []core.Pod{p})
+		}
+	}
+	return batches
+}
+
+// podOwner identifies the controller of the supplied pod, or the pod itself if
+// it has none.
+func podOwner(p core.Pod) string {
+	if c := meta.GetControllerOf(&p); c != nil {
+		return p.GetNamespace() + "/" + c.Kind + "/" + c.Name
+	}
+	return p.GetNamespace() + "/Pod/" + p.GetName()
+}
+
+// sortPods sorts the supplied pods by namespace, then name.
+func sortPods(pods []core.Pod) {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].GetNamespace() != pods[j].GetNamespace() {
+			return pods[i].GetNamespace() < pods[j].GetNamespace()
+		}
+		return pods[i].GetName() < pods[j].GetName()
+	})
+}
+
+func (d *APICordonDrainer) deleteNode(ctx context.Context, n *core.Node) error {
 	if d.skipDrain {
 		d.l.Debug("Skipping delete because draining is disabled")
 		return nil
-	} else {
-		err = d.c.CoreV1().Nodes().Delete(context.Background(), n.GetName(), meta.DeleteOptions{})
-		if err != nil {
-			return errors.Wrapf(err, "cannot delete node %s", n.GetName())
-		}
+	}
+	if err := d.c.CoreV1().Nodes().Delete(ctx, n.GetName(), meta.DeleteOptions{}); err != nil {
+		return errors.Wrapf(err, "cannot delete node %s", n.GetName())
 	}
 	return nil
 }
 
+// checkNodeGone returns a NotFound error if the supplied node was deleted, and
+// the node gone check is enabled.
+func (d *APICordonDrainer) checkNodeGone(ctx context.Context, n *core.Node) error {
+	if d.nodeGoneInterval <= 0 {
+		return nil
+	}
+	_, err := d.c.CoreV1().Nodes().Get(ctx, n.GetName(), meta.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		return nil
+	}
+	d.l.Info("Node deleted while draining, stopping drain", zap.String("node", n.GetName()))
+	return errors.Wrapf(err, "node %s deleted while draining", n.GetName())
+}
+
+// NothingToEvictError is returned by Drain when the node had no pods to evict.
+// It does not denote a failure.
+type NothingToEvictError struct {
+	error
+}
+
+func NewNothingToEvictError(name string) error {
+	return &NothingToEvictError{
+		fmt.Errorf("node %s has no pods to evict", name),
+	}
+}
+
+func IsNothingToEvictError(err error) bool {
+	_, ok := errors.Cause(err).(*NothingToEvictError)
+	return ok
+}
+
 func (d *APICordonDrainer) getPods(node string) ([]core.Pod, error) {
+	return d.selectPods(node, nil)
+}
+
+// selectPods returns the pods of the supplied node to evict, counting those
+// skipped by the eviction filter in the supplied summary.
+func (d *APICordonDrainer) selectPods(node string, summary *drainSummary) ([]core.Pod, error) {
+	l, err := d.listPods(node)
+	if err != nil {
+		return nil, err
+	}
+
+	include := make([]core.Pod, 0, len(l))
+	for _, p := range l {
+		passes, reason, err := d.evictable(p)
+		if err != nil {
+			return nil, err
+		}
+		if !passes {
+			if reason == "" {
+				d.l.Info("Pod ignored list", zap.String("node", node), zap.String("PodName", p.Name))
+				continue
+			}
+			d.l.Info("Pod skipped by eviction filter", zap.String("node", node), zap.String("PodName", p.Name), zap.String("reason", reason))
+			d.metrics.PodSkipped(node, reason)
+			summary.update(func(s *DrainSummary) { s.Skipped++ })
+			continue
+		}
+		d.l.Info("Pod added to list", zap.String("node", node), zap.String("PodName", p.Name))
+		include = append(include, p)
+	}
+	return include, nil
+}
+
+func (d *APICordonDrainer) listPods(node string) ([]core.Pod, error) {
 	l, err := d.c.CoreV1().Pods(meta.NamespaceAll).List(context.Background(), meta.ListOptions{
 		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node}).String(),
 	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot get pods for node %s", node)
 	}
+	return l.Items, nil
+}
 
-	include := make([]core.Pod, 0, len(l.Items))
-	for _, p := range l.Items {
-		passes, err := d.filter(p)
+// evictable returns true if the supplied pod should be evicted. The reason is
+// set when the pod was skipped by the eviction filter.
+func (d *APICordonDrainer) evictable(p core.Pod) (bool, string, error) {
+	passes, err := d.filter(p)
+	if err != nil {
+		return false, "", errors.Wrap(err, "cannot filter pods")
+	}
+	if !passes {
+		return false, "", nil
+	}
+	if d.evictionFilter != nil {
+		if evict, reason := d.evictionFilter(&p); !evict {
+			return false, reason, nil
+		}
+	}
+	return true, "", nil
+}
+
+// verifyEvictions waits for the evictable pods of the supplied node to be gone.
+func (d *APICordonDrainer) verifyEvictions(node string, skip map[string]bool) error {
+	var remaining int
+	err := wait.PollImmediate(verifyEvictionsPollPeriod, d.verifyTimeout, func() (bool, error) {
+		l, err := d.listPods(node)
 		if err != nil {
-			return nil, errors.Wrap(err, "cannot filter pods")
+			return false, err
 		}
-		if passes {
-			d.l.Info("Pod added to list", zap.String("node", node), zap.String("PodName", p.Name))
-			include = append(include, p)
-		} else {
-			d.l.Info("Pod ignored list", zap.String("node", node), zap.String("PodName", p.Name))
+		remaining = 0
+		for _, p := range l {
+			if skip[p.GetNamespace()+"/"+p.GetName()] {
+				continue
+			}
+			passes, _, err := d.evictable(p)
+			if err != nil {
+				return false, err
+			}
+			if passes {
+				remaining++
+			}
 		}
+		return remaining == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Wrap(errPodsStillPresent{pods: remaining}, "cannot verify evictions")
 	}
-	return include, nil
+	return err
 }
 
-func (d *APICordonDrainer) evict(p core.Pod, abort <-chan struct{}, e chan<- error) {
-	gracePeriod := int64(d.maxGracePeriod.Seconds())
-	if p.Spec.TerminationGracePeriodSeconds != nil && *p.Spec.TerminationGracePeriodSeconds < gracePeriod {
-		gracePeriod = *p.Spec.TerminationGracePeriodSeconds
+func (d *APICordonDrainer) evict(ctx context.Context, p core.Pod, abort <-chan struct{}, e chan<- error, blocked *int32) {
+	var span trace.Span
+	if d.tracer != nil {
+		ctx, span = d.tracer.Start(ctx, "draino.evict_pod", trace.WithAttributes(
+			attribute.String("pod", p.GetNamespace()+"/"+p.GetName()),
+		))
+		defer span.End()
 	}
 
+	gracePeriod := capGracePeriod(ctx, d.gracePeriod(p))
+
+	isBlocked := false
+	setBlocked := func(b bool) {
+		if b == isBlocked {
+			return
+		}
+		isBlocked = b
+		if b {
+			atomic.AddInt32(blocked, 1)
+		} else {
+			atomic.AddInt32(blocked, -1)
+		}
+	}
+	defer setBlocked(false)
+
+	// Pods being deleted by someone else are waited for rather than evicted
+	// again, until they are late.
+	if p.GetDeletionTimestamp() != nil {
+		d.l.Info("Waiting for terminating pod", zap.String("pod", p.GetNamespace()+"/"+p.GetName()))
+		if span != nil {
+			span.AddEvent("pod already terminating, waiting")
+		}
+		timeout := d.terminatingPodTimeout()
+		if d.stuckTerminatingThreshold > 0 {
+			timeout = d.stuckTerminatingWait(p.GetDeletionTimestamp().Time)
+		}
+		err := d.awaitDeletion(ctx, p, timeout)
+		if err == nil {
+			d.recordRemoval(ctx, p, evictionPhaseTerminating)
+			e <- d.awaitVolumeDetach(ctx, p)
+			return
+		}
+		if ctx.Err() != nil {
+			e <- errors.Wrap(ctx.Err(), "pod eviction cancelled")
+			return
+		}
+		if d.stuckTerminatingThreshold > 0 {
+			e <- d.forceDeleteStuck(ctx, p)
+			return
+		}
+		d.l.Info("Terminating pod is late, evicting it", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Error(err))
+		drainSummaryFrom(ctx).warn("%s/%s was still terminating after %s", p.GetNamespace(), p.GetName(), d.terminatingPodTimeout())
+	} else if err := d.awaitJobCompletion(ctx, p); err != nil {
+		e <- err
+		return
+	} else if err := d.awaitReplacementRoom(ctx, p); err != nil {
+		e <- err
+		return
+	}
+
+	// pdb is the PodDisruptionBudget refusing the eviction, once known.
+	var pdb string
+	started := time.Now()
 	for {
 		select {
 		case <-abort:
 			e <- errors.New("pod eviction aborted")
 			return
+		case <-ctx.Done():
+			e <- errors.Wrap(ctx.Err(), "pod eviction cancelled")
+			return
 		default:
-			err := d.c.CoreV1().Pods(p.GetNamespace()).Evict(context.Background(), &policy.Eviction{
+			start := time.Now()
+			callCtx, cancel := ctx, context.CancelFunc(func() {})
+			if d.evictionCallTimeout > 0 {
+				callCtx, cancel = context.WithTimeout(ctx, d.evictionCallTimeout)
+			}
+			err := d.c.CoreV1().Pods(p.GetNamespace()).Evict(callCtx, &policy.Eviction{
 				ObjectMeta:    meta.ObjectMeta{Namespace: p.GetNamespace(), Name: p.GetName()},
-				DeleteOptions: &meta.DeleteOptions{GracePeriodSeconds: &gracePeriod},
+				DeleteOptions: &meta.DeleteOptions{GracePeriodSeconds: &gracePeriod, PropagationPolicy: d.propagationPolicy},
 			})
+			timedOut := err != nil && callCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+			cancel()
+			if d.latencyObserver != nil {
+				d.latencyObserver(time.Since(start))
+			}
 
 			switch {
+			// A call that hangs is abandoned and retried at once, as it
+			// already waited for the call timeout.
+			case timedOut:
+				d.l.Info("Eviction call timed out, retrying", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Duration("timeout", d.evictionCallTimeout), zap.Error(err))
+				if span != nil {
+					span.AddEvent("eviction call timed out, retrying")
+				}
+				d.metrics.EvictionCallTimedOut(p.Spec.NodeName)
+				drainSummaryFrom(ctx).retried(p.GetNamespace() + "/" + p.GetName())
 			// The eviction API returns 429 Too Many Requests if a pod
 			// cannot currently be evicted, for example due to a pod
 			// disruption budget.
 			case apierrors.IsTooManyRequests(err):
-				time.Sleep(5 * time.Second)
+				setBlocked(true)
+				if pdb == "" {
+					pdb = d.blockingPDB(ctx, p, err)
+				}
+				d.recordPDBBlock(p, pdb)
+				if d.escalateAfter > 0 && time.Since(started) >= d.escalateAfter && !d.keepsVolumes(p) {
+					d.l.Info("Escalating to force deletion", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Duration("after", d.escalateAfter))
+					drainSummaryFrom(ctx).warn("%s/%s force deleted, eviction refused for %s", p.GetNamespace(), p.GetName(), d.escalateAfter)
+					if span != nil {
+						span.AddEvent("eviction refused, escalating to force deletion")
+					}
+					e <- d.forceDelete(ctx, p)
+					return
+				}
+				if span != nil {
+					span.AddEvent("eviction refused, retrying")
+				}
+				d.metrics.EvictionBackedOff(p.Spec.NodeName)
+				drainSummaryFrom(ctx).retried(p.GetNamespace() + "/" + p.GetName())
+				select {
+				case <-abort:
+				case <-ctx.Done():
+				case <-time.After(d.evictionRetryPeriod(started, err)):
+				}
+			case d.namespaceGone(ctx, p, err):
+				e <- nil
+				return
 			case apierrors.IsNotFound(err):
 				e <- nil
 				return
+			case isAdmissionDenied(err):
+				e <- d.admissionDeniedEviction(ctx, p, err)
+				return
 			case err != nil:
 				e <- errors.Wrapf(err, "cannot evict pod %s/%s", p.GetNamespace(), p.GetName())
 				return
 			default:
-				e <- errors.Wrapf(d.awaitDeletion(p, d.deleteTimeout()), "cannot confirm pod %s/%s was deleted", p.GetNamespace(), p.GetName())
+				d.shutdownSidecars(ctx, p)
+				timeout := d.deleteTimeout()
+				if d.stuckTerminatingThreshold > 0 {
+					timeout = d.stuckTerminatingThreshold
+				}
+				if err := d.awaitDeletion(ctx, p, timeout); err != nil {
+					if err == wait.ErrWaitTimeout && ctx.Err() == nil && d.stuckTerminatingThreshold > 0 {
+						e <- d.forceDeleteStuck(ctx, p)
+						return
+					}
+					e <- errors.Wrapf(err, "cannot confirm pod %s/%s was deleted", p.GetNamespace(), p.GetName())
+					return
+				}
+				d.recordRemoval(ctx, p, evictionPhaseEvicted)
+				e <- d.awaitVolumeDetach(ctx, p)
 				return
 			}
 		}
 	}
 }
 
-func (d *APICordonDrainer) awaitDeletion(p core.Pod, timeout time.Duration) error {
-	return wait.PollImmediate(1*time.Second, timeout, func() (bool, error) {
-		got, err := d.c.CoreV1().Pods(p.GetNamespace()).Get(context.Background(), p.GetName(), meta.GetOptions{})
+// evictionRetryPeriod returns how long to wait before retrying an eviction,
+// started at the supplied time, that was refused with the supplied error. The
+// delay suggested by the API server, if any, is honored. Retries are hastened
+// so that escalation happens on time.
+func (d *APICordonDrainer) evictionRetryPeriod(started time.Time, err error) time.Duration {
+	period := evictionRetryPeriod
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+		period = time.Duration(seconds) * time.Second
+	}
+	if d.escalateAfter > 0 {
+		if remaining := d.escalateAfter - time.Since(started); remaining < period {
+			period = remaining
+		}
+	}
+	return period
+}
+
+// forceDelete deletes the supplied pod without a grace period, bypassing any
+// PodDisruptionBudget, and waits for it to be gone.
+func (d *APICordonDrainer) forceDelete(ctx context.Context, p core.Pod) error {
+	d.l.Info("Force deleting pod", zap.String("pod", p.GetNamespace()+"/"+p.GetName()))
+	zero := int64(0)
+	err := d.c.CoreV1().Pods(p.GetNamespace()).Delete(ctx, p.GetName(), meta.DeleteOptions{GracePeriodSeconds: &zero, PropagationPolicy: d.propagationPolicy})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot force delete pod %s/%s", p.GetNamespace(), p.GetName())
+	}
+	if err := d.awaitDeletion(ctx, p, d.deleteTimeout()); err != nil {
+		return errors.Wrapf(err, "cannot confirm pod %s/%s was deleted", p.GetNamespace(), p.GetName())
+	}
+	d.recordRemoval(ctx, p, evictionPhaseForced)
+	return d.awaitVolumeDetach(ctx, p)
+}
+
+// recordRemoval records the phase of the drain in which the supplied pod was
+// removed.
+func (d *APICordonDrainer) recordRemoval(ctx context.Context, p core.Pod, phase string) {
+	drainSummaryFrom(ctx).removed(phase)
+	nodeTimelineFrom(ctx).podRemoved(p, phase)
+	d.l.Info("Pod removed", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.String("phase", phase))
+	d.metrics.PodRemoved(p.Spec.NodeName, phase)
+	d.recordCrashLoopCleared(p)
+}
+
+func (d *APICordonDrainer) awaitDeletion(ctx context.Context, p core.Pod, timeout time.Duration) error {
+	stop, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(1*time.Second, func() (bool, error) {
+		got, err := d.c.CoreV1().Pods(p.GetNamespace()).Get(ctx, p.GetName(), meta.GetOptions{})
 		if apierrors.IsNotFound(err) {
 			return true, nil
 		}
@@ -432,5 +1935,5 @@ func (d *APICordonDrainer) awaitDeletion(p core.Pod, timeout time.Duration) erro
 			return true, nil
 		}
 		return false, nil
-	})
+	}, stop.Done())
 }
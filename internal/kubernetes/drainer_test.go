@@ -18,12 +18,18 @@ package kubernetes
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/pkg/errors"
 	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -260,6 +266,59 @@ func TestUncordon(t *testing.T) {
 	}
 }
 
+func TestCordonWithReason(t *testing.T) {
+	cases := []struct {
+		name      string
+		options   []APICordonDrainerOption
+		reasonKey string
+		ownerKey  string
+	}{
+		{
+			name:      "DefaultAnnotations",
+			reasonKey: DefaultCordonReasonAnnotation,
+			ownerKey:  DefaultCordonOwnerAnnotation,
+		},
+		{
+			name:      "CustomAnnotations",
+			options:   []APICordonDrainerOption{WithCordonAnnotations("example.org/reason", "example.org/owner")},
+			reasonKey: "example.org/reason",
+			ownerKey:  "example.org/owner",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{"foo": "1"}}}
+			c := fake.NewSimpleClientset(node)
+			d := NewAPICordonDrainer(c, tc.options...)
+
+			if err := d.CordonWithReason(node, "KernelPanic", Component); err != nil {
+				t.Fatalf("d.CordonWithReason(%v): %v", node.Name, err)
+			}
+			n, err := c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{})
+			if err != nil {
+				t.Fatalf("node.Get(%v): %v", nodeName, err)
+			}
+			want := map[string]string{"foo": "1", tc.reasonKey: "KernelPanic", tc.ownerKey: Component}
+			if !n.Spec.Unschedulable || !reflect.DeepEqual(n.Annotations, want) {
+				t.Errorf("cordoned node: want unschedulable with annotations %v, got unschedulable=%v with %v", want, n.Spec.Unschedulable, n.Annotations)
+			}
+
+			if err := d.Uncordon(n); err != nil {
+				t.Fatalf("d.Uncordon(%v): %v", node.Name, err)
+			}
+			n, err = c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{})
+			if err != nil {
+				t.Fatalf("node.Get(%v): %v", nodeName, err)
+			}
+			want = map[string]string{"foo": "1"}
+			if n.Spec.Unschedulable || !reflect.DeepEqual(n.Annotations, want) {
+				t.Errorf("uncordoned node: want schedulable with annotations %v, got unschedulable=%v with %v", want, n.Spec.Unschedulable, n.Annotations)
+			}
+		})
+	}
+}
+
 func TestDrain(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -358,7 +417,10 @@ func TestDrain(t *testing.T) {
 					err:         apierrors.NewTooManyRequests("nope", 5),
 				},
 			},
-			errFn: IsTimeout,
+			errFn: func(err error) bool {
+				_, blocked := errors.Cause(err).(errPDBBlocked)
+				return blocked && IsTimeout(err)
+			},
 		},
 		{
 			name: "EvictedPodReplacedWithDifferentUID",
@@ -500,6 +562,824 @@ func TestDrain(t *testing.T) {
 	}
 }
 
+func TestDrainOnlyDaemonSetPods(t *testing.T) {
+	pods := &core.PodList{Items: []core.Pod{
+		core.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Name: podName,
+				OwnerReferences: []meta.OwnerReference{meta.OwnerReference{
+					Controller: &isController,
+					Kind:       kindDaemonSet,
+					Name:       daemonsetName,
+				}},
+			},
+		},
+	}}
+
+	cases := []struct {
+		name     string
+		fastPath bool
+		noop     bool
+	}{
+		{name: "FastPath", fastPath: true, noop: true},
+		{name: "FastPathDisabled", fastPath: false, noop: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeClientSet(
+				reactor{verb: "list", resource: "pods", ret: pods},
+				reactor{verb: "get", resource: "daemonsets"},
+			)
+			d := NewAPICordonDrainer(c,
+				WithPodFilter(NewDaemonSetPodFilter(c)),
+				WithEmptyNodeFastPath(tc.fastPath),
+			)
+			err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if tc.noop && !IsNothingToEvictError(err) {
+				t.Errorf("d.Drain(%v): want NothingToEvictError, got %v", nodeName, err)
+			}
+			if !tc.noop && err != nil {
+				t.Errorf("d.Drain(%v): %v", nodeName, err)
+			}
+			if evicted := evictedPods(c.(*fake.Clientset)); len(evicted) > 0 {
+				t.Errorf("d.Drain(%v): evicted %v, want none", nodeName, evicted)
+			}
+		})
+	}
+}
+
+func TestDrainEvictionFilter(t *testing.T) {
+	pods := &core.PodList{Items: []core.Pod{
+		core.Pod{ObjectMeta: meta.ObjectMeta{Name: "keepMe", Annotations: map[string]string{"example.org/keep": "true"}}},
+		core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+		core.Pod{ObjectMeta: meta.ObjectMeta{Name: "mirrorPod", Annotations: map[string]string{core.MirrorPodAnnotationKey: "true"}}},
+	}}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: pods},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+	)
+
+	var filtered []string
+	d := NewAPICordonDrainer(c,
+		WithPodFilter(MirrorPodFilter),
+		WithEvictionFilter(func(p *core.Pod) (bool, string) {
+			filtered = append(filtered, p.GetName())
+			if p.GetAnnotations()["example.org/keep"] == "true" {
+				return false, "annotated with example.org/keep"
+			}
+			return true, ""
+		}),
+	)
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	// The built in pod filter runs first, so the mirror pod never reaches the
+	// eviction filter.
+	if want := []string{"keepMe", podName}; !reflect.DeepEqual(filtered, want) {
+		t.Errorf("eviction filter called with %v, want %v", filtered, want)
+	}
+	if got, want := evictedPods(c.(*fake.Clientset)), []string{podName}; !reflect.DeepEqual(got, want) {
+		t.Errorf("evicted %v, want %v", got, want)
+	}
+}
+
+func TestDrainVerifiesEvictions(t *testing.T) {
+	// The pod is reported deleted once evicted, but lingers in pod listings.
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+	)
+	d := NewAPICordonDrainer(c, WithEvictionVerification(100*time.Millisecond))
+	err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if _, ok := errors.Cause(err).(errPodsStillPresent); !ok {
+		t.Fatalf("d.Drain(%v): want errPodsStillPresent, got %v", nodeName, err)
+	}
+	if got, want := DrainFailureReason(err), "pods still present: 1"; got != want {
+		t.Errorf("DrainFailureReason(): want %q, got %q", want, got)
+	}
+}
+
+func TestDrainEvictionEscalation(t *testing.T) {
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction", err: apierrors.NewTooManyRequests("nope", 5)},
+		reactor{verb: "delete", resource: "pods"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+	)
+	m := newDrainerMetrics()
+	d := NewAPICordonDrainer(c, WithEvictionEscalation(10*time.Millisecond), WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	var deleted *int64
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetVerb() == "delete" && a.GetResource().Resource == "pods" {
+			deleted = a.(clienttesting.DeleteAction).GetDeleteOptions().GracePeriodSeconds
+		}
+	}
+	if deleted == nil || *deleted != 0 {
+		t.Errorf("pod was not force deleted with a zero grace period")
+	}
+	if got := m.count("removed/" + evictionPhaseForced); got != 1 {
+		t.Errorf("pods removed forced: want 1, got %d", got)
+	}
+}
+
+func TestDrainTerminatingPod(t *testing.T) {
+	cases := []struct {
+		name      string
+		gone      bool
+		wantEvict bool
+	}{
+		{name: "GoneWhileWaiting", gone: true},
+		{name: "Late", wantEvict: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			deleting := meta.Now()
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, DeletionTimestamp: &deleting}}
+			c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, pod)
+			var evicted int32
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if a.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				atomic.StoreInt32(&evicted, 1)
+				return true, nil, nil
+			})
+			c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if tc.gone || atomic.LoadInt32(&evicted) == 1 {
+					return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+				}
+				return true, pod, nil
+			})
+
+			d := NewAPICordonDrainer(c, WithTerminatingPodTimeout(10*time.Millisecond))
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+			if got := atomic.LoadInt32(&evicted) == 1; got != tc.wantEvict {
+				t.Errorf("evicted: want %v, got %v", tc.wantEvict, got)
+			}
+		})
+	}
+}
+
+func TestForceDrain(t *testing.T) {
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+		}}},
+		reactor{verb: "delete", resource: "pods"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+	)
+	d := NewAPICordonDrainer(c)
+	if err := d.ForceDrain(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.ForceDrain(%v): %v", nodeName, err)
+	}
+
+	var deleted *int64
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			t.Errorf("pod was evicted instead of force deleted")
+		}
+		if a.GetVerb() == "delete" && a.GetResource().Resource == "pods" {
+			deleted = a.(clienttesting.DeleteAction).GetDeleteOptions().GracePeriodSeconds
+		}
+	}
+	if deleted == nil || *deleted != 0 {
+		t.Errorf("pod was not force deleted with a zero grace period")
+	}
+}
+
+func TestDrainDeterministicOrder(t *testing.T) {
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			{ObjectMeta: meta.ObjectMeta{Namespace: "b", Name: "a"}},
+			{ObjectMeta: meta.ObjectMeta{Namespace: "a", Name: "c"}},
+			{ObjectMeta: meta.ObjectMeta{Namespace: "a", Name: "b"}},
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c, WithDeterministicOrder(true))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	var got []string
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			e := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+			got = append(got, e.GetNamespace()+"/"+e.GetName())
+		}
+	}
+	if want := []string{"a/b", "a/c", "b/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("evictions: want %v, got %v", want, got)
+	}
+}
+
+func TestDrainDeterministicOrderOutlastsDeleteTimeout(t *testing.T) {
+	var pods []core.Pod
+	for i := 0; i < 5; i++ {
+		pods = append(pods, core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: pods}, nil
+	})
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		time.Sleep(30 * time.Millisecond)
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+	})
+
+	// Each pod is removed well within the time allowed to delete a pod, but
+	// evicting them one at a time takes longer than that overall.
+	d := NewAPICordonDrainer(c,
+		MaxGracePeriod(0),
+		EvictionHeadroom(50*time.Millisecond),
+		WithDeterministicOrder(true),
+	)
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Errorf("d.Drain(%v): %v", nodeName, err)
+	}
+}
+
+func TestDrainOwnerAwareOrder(t *testing.T) {
+	owned := func(name, rs string) core.Pod {
+		return core.Pod{ObjectMeta: meta.ObjectMeta{
+			Namespace:       "default",
+			Name:            name,
+			OwnerReferences: []meta.OwnerReference{{Controller: &isController, Kind: "ReplicaSet", Name: rs}},
+		}}
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			owned("web-1", "web"), owned("api-1", "api"), owned("web-2", "web"), owned("api-2", "api"),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c, WithOwnerAwareOrder(true))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	// The pods of a ReplicaSet are all evicted and gone before those of the
+	// next are evicted.
+	var owners []string
+	for _, a := range c.(*fake.Clientset).Actions() {
+		var name string
+		switch {
+		case a.GetSubresource() == "eviction":
+			name = a.(clienttesting.CreateAction).GetObject().(*policy.Eviction).GetName()
+		case a.GetVerb() == "get" && a.GetResource().Resource == "pods":
+			name = a.(clienttesting.GetAction).GetName()
+		default:
+			continue
+		}
+		owner := strings.SplitN(name, "-", 2)[0]
+		if len(owners) == 0 || owners[len(owners)-1] != owner {
+			owners = append(owners, owner)
+		}
+	}
+	if want := []string{"api", "web"}; !reflect.DeepEqual(owners, want) {
+		t.Errorf("pods evicted and awaited by owner: want %v, got %v", want, owners)
+	}
+}
+
+func TestDrainEvictFirst(t *testing.T) {
+	pod := func(name string, priority int32, first bool) core.Pod {
+		p := core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       core.PodSpec{Priority: &priority},
+		}
+		if first {
+			p.SetAnnotations(map[string]string{DefaultEvictFirstAnnotation: "true"})
+		}
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			pod("critical", 1000000, false),
+			pod("registrator", 0, true),
+			pod("batch", 0, false),
+			pod("lb", 10, true),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c, WithEvictFirstAnnotation(DefaultEvictFirstAnnotation))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	var got []string
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			got = append(got, a.(clienttesting.CreateAction).GetObject().(*policy.Eviction).GetName())
+		}
+	}
+	if len(got) != 4 {
+		t.Fatalf("evictions: want 4, got %v", got)
+	}
+	// The remaining pods are evicted at once, in no particular order.
+	if want := []string{"lb", "registrator"}; !reflect.DeepEqual(got[:2], want) {
+		t.Errorf("first evictions: want %v, got %v", want, got[:2])
+	}
+}
+
+func TestDrainStartupCostOrder(t *testing.T) {
+	pod := func(name, cost string) core.Pod {
+		p := core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name}}
+		if cost != "" {
+			p.SetAnnotations(map[string]string{DefaultStartupCostAnnotation: cost})
+		}
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			pod("database", "100"),
+			pod("web", ""),
+			pod("cache", "10"),
+			pod("sidecar", "1"),
+			pod("invalid", "slow"),
+			pod("search", "50"),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c,
+		WithStartupCostAnnotation(DefaultStartupCostAnnotation),
+		WithDeterministicOrder(true),
+	)
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	var got []string
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			got = append(got, a.(clienttesting.CreateAction).GetObject().(*policy.Eviction).GetName())
+		}
+	}
+	// Pods without a valid cost are evicted halfway through the others.
+	want := []string{"sidecar", "cache", "invalid", "web", "search", "database"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("evictions: want %v, got %v", want, got)
+	}
+}
+
+// drainerMetrics counts the measures recorded by drains, by name, and
+// records the last eviction rate observed and inter pod delay added.
+type drainerMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	recorded map[string]int
+	rate     float64
+	delay    time.Duration
+}
+
+func newDrainerMetrics() *drainerMetrics {
+	return &drainerMetrics{recorded: map[string]int{}}
+}
+
+func (m *drainerMetrics) record(name string) {
+	m.Lock()
+	defer m.Unlock()
+	m.recorded[name]++
+}
+
+func (m *drainerMetrics) count(name string) int {
+	m.Lock()
+	defer m.Unlock()
+	return m.recorded[name]
+}
+
+func (m *drainerMetrics) EvictionCallTimedOut(string) { m.record("timeout") }
+
+func (m *drainerMetrics) EvictionBackedOff(string) { m.record("backoff") }
+
+func (m *drainerMetrics) PodRemoved(_, phase string) { m.record("removed/" + phase) }
+
+func (m *drainerMetrics) NodeEvictionRate(_ string, perSecond float64) {
+	m.Lock()
+	defer m.Unlock()
+	m.recorded["rate"]++
+	m.rate = perSecond
+}
+
+func (m *drainerMetrics) InterPodDelay(_ string, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.recorded["delay"]++
+	m.delay = d
+}
+
+func (m *drainerMetrics) PeakTerminatingPods(_ string, n int) {
+	m.Lock()
+	defer m.Unlock()
+	m.recorded["peak"] = n
+}
+
+func TestDrainEvictionCallTimeout(t *testing.T) {
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: []core.Pod{{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName}}}}, nil
+	})
+	// The first eviction call hangs past the call timeout.
+	calls := 0
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if calls++; calls == 1 {
+			time.Sleep(50 * time.Millisecond)
+			return true, nil, context.DeadlineExceeded
+		}
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+	})
+
+	m := newDrainerMetrics()
+	d := NewAPICordonDrainer(c, WithEvictionCallTimeout(10*time.Millisecond), WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if calls != 2 {
+		t.Errorf("eviction calls: want 2, got %d", calls)
+	}
+	s, _ := d.DrainSummary(nodeName)
+	if s.Evicted != 1 || s.Retries != 1 {
+		t.Errorf("DrainSummary(): want 1 pod evicted after 1 retry, got %+v", s)
+	}
+	if got := m.count("timeout"); got != 1 {
+		t.Errorf("eviction call timeouts: want 1, got %d", got)
+	}
+}
+
+func TestDrainMaxTerminatingPods(t *testing.T) {
+	const max = 2
+	var pods []core.Pod
+	for i := 0; i < 6; i++ {
+		pods = append(pods, core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: pods}, nil
+	})
+	// Evicted pods are terminating until they are found gone.
+	var terminating, peak int
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if terminating++; terminating > peak {
+			peak = terminating
+		}
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		terminating--
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+	})
+
+	m := newDrainerMetrics()
+	d := NewAPICordonDrainer(c, WithMaxTerminatingPods(max), WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if peak > max {
+		t.Errorf("pods terminating at once: want at most %d, got %d", max, peak)
+	}
+	s, _ := d.DrainSummary(nodeName)
+	if s.Evicted != len(pods) {
+		t.Errorf("DrainSummary(): want %d pods evicted, got %d", len(pods), s.Evicted)
+	}
+	if s.PeakTerminating < 1 || s.PeakTerminating > max {
+		t.Errorf("DrainSummary(): want a peak of 1 to %d terminating pods, got %d", max, s.PeakTerminating)
+	}
+	if got := m.count("peak"); got != s.PeakTerminating {
+		t.Errorf("peak terminating pods: want %d recorded, got %d", s.PeakTerminating, got)
+	}
+}
+
+func TestDrainEvictionRatePerNode(t *testing.T) {
+	const perSecond = 20
+	var pods []core.Pod
+	for i := 0; i < 6; i++ {
+		pods = append(pods, core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: pods}, nil
+	})
+	var mu sync.Mutex
+	var evicted []time.Time
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		evicted = append(evicted, time.Now())
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+	})
+
+	m := newDrainerMetrics()
+	d := NewAPICordonDrainer(c, WithEvictionRatePerNode(perSecond), WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if len(evicted) != len(pods) {
+		t.Fatalf("evictions: want %d, got %d", len(pods), len(evicted))
+	}
+	sort.Slice(evicted, func(i, j int) bool { return evicted[i].Before(evicted[j]) })
+	// The token bucket allows one eviction at once, then one per interval.
+	want := time.Duration(len(pods)-1) * time.Second / perSecond
+	if got := evicted[len(evicted)-1].Sub(evicted[0]); got < want*9/10 {
+		t.Errorf("evictions: want spread over at least %s, got %s", want, got)
+	}
+
+	if got := m.count("rate"); got != 1 {
+		t.Fatalf("observed eviction rate: want recorded once, got %d", got)
+	}
+	if m.rate <= 0 || m.rate > perSecond*1.1 {
+		t.Errorf("observed eviction rate: want at most %d per second, got %f", perSecond, m.rate)
+	}
+}
+
+func TestDrainMinInterPodDelay(t *testing.T) {
+	const delay = 40 * time.Millisecond
+	var pods []core.Pod
+	for i := 0; i < 4; i++ {
+		pods = append(pods, core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: pods}, nil
+	})
+	var mu sync.Mutex
+	var evicted []time.Time
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		evicted = append(evicted, time.Now())
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+	})
+
+	m := newDrainerMetrics()
+	d := NewAPICordonDrainer(c, WithMinInterPodDelay(delay), WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if len(evicted) != len(pods) {
+		t.Fatalf("evictions: want %d, got %d", len(pods), len(evicted))
+	}
+	sort.Slice(evicted, func(i, j int) bool { return evicted[i].Before(evicted[j]) })
+	// Evictions are spaced when they start, slightly before the API call.
+	for i := 1; i < len(evicted); i++ {
+		if got := evicted[i].Sub(evicted[i-1]); got < delay*9/10 {
+			t.Errorf("eviction %d: want at least %s after the previous one, got %s", i, delay, got)
+		}
+	}
+	// The evictions started at once, each waiting for the previous one.
+	summary, _ := d.DrainSummary(nodeName)
+	if want := delay * time.Duration(len(pods)-1); summary.InterPodDelay < want*9/10 {
+		t.Errorf("summary: want at least %s of added delay, got %s", want, summary.InterPodDelay)
+	}
+	if got := m.count("delay"); got != 1 || m.delay != summary.InterPodDelay {
+		t.Errorf("inter pod delay: want %s recorded once, got %s recorded %d times", summary.InterPodDelay, m.delay, got)
+	}
+}
+
+func TestDrainCordonSettleDelay(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	c := fake.NewSimpleClientset(
+		&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+		&core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+	)
+	var evicted time.Time
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		evicted = time.Now()
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+	})
+
+	d := NewAPICordonDrainer(c, WithCordonSettleDelay(delay))
+	start := time.Now()
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if evicted.IsZero() {
+		t.Fatalf("pod was not evicted")
+	}
+	if got := evicted.Sub(start); got < delay {
+		t.Errorf("eviction began %v after the drain, want at least %v", got, delay)
+	}
+}
+
+func TestDrainServerDryRun(t *testing.T) {
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: []core.Pod{
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "ok"}},
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "protected"}},
+		}}, nil
+	})
+	var dryRuns [][]string
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		e := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+		dryRuns = append(dryRuns, e.DeleteOptions.DryRun)
+		if e.GetName() == "protected" {
+			return true, nil, apierrors.NewTooManyRequests("PodDisruptionBudget", 0)
+		}
+		return true, nil, nil
+	})
+
+	d := NewAPICordonDrainer(c, WithServerDryRun(true))
+	err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if !IsEvictionsRejectedError(err) {
+		t.Fatalf("d.Drain(%v): want EvictionsRejectedError, got %v", nodeName, err)
+	}
+	if got, want := errors.Cause(err).(*EvictionsRejectedError).Pods, []string{"default/protected"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rejected pods: want %v, got %v", want, got)
+	}
+	if len(dryRuns) != 2 {
+		t.Fatalf("want 2 evictions, got %d", len(dryRuns))
+	}
+	for _, dr := range dryRuns {
+		if !reflect.DeepEqual(dr, []string{meta.DryRunAll}) {
+			t.Errorf("eviction DryRun: want %v, got %v", []string{meta.DryRunAll}, dr)
+		}
+	}
+	if _, err := c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{}); err != nil {
+		t.Errorf("node deleted by dry run drain: %v", err)
+	}
+}
+
+func TestDrainServerDryRunAccepted(t *testing.T) {
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: []core.Pod{{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName}}}}, nil
+	})
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return a.GetSubresource() == "eviction", nil, nil
+	})
+
+	d := NewAPICordonDrainer(c, WithServerDryRun(true))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); !IsDryRunEvictionsError(err) {
+		t.Errorf("d.Drain(%v): want DryRunEvictionsError, got %v", nodeName, err)
+	}
+}
+
+func TestDrainHonorsRetryAfter(t *testing.T) {
+	c := fake.NewSimpleClientset(
+		&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+		&core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+	)
+	var attempts []time.Time
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		attempts = append(attempts, time.Now())
+		if len(attempts) == 1 {
+			return true, nil, apierrors.NewTooManyRequests("nope", 1)
+		}
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+	})
+
+	m := newDrainerMetrics()
+	d := NewAPICordonDrainer(c, WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("want 2 eviction attempts, got %d", len(attempts))
+	}
+	if got := attempts[1].Sub(attempts[0]); got < time.Second || got >= evictionRetryPeriod {
+		t.Errorf("eviction retried after %v, want the suggested 1s", got)
+	}
+	if got := m.count("backoff"); got != 1 {
+		t.Errorf("eviction backoffs: want 1, got %d", got)
+	}
+}
+
+func TestDrainCancelled(t *testing.T) {
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction", err: apierrors.NewTooManyRequests("nope", 0)},
+	)
+	d := NewAPICordonDrainer(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := d.DrainWithContext(ctx, &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if errors.Cause(err) != context.Canceled {
+		t.Errorf("d.DrainWithContext(%v): want context.Canceled, got %v", nodeName, err)
+	}
+	if elapsed := time.Since(start); elapsed >= evictionRetryPeriod {
+		t.Errorf("d.DrainWithContext(%v): returned after %v, want before the retry period", nodeName, elapsed)
+	}
+}
+
+func TestDrainPropagationPolicy(t *testing.T) {
+	foreground := meta.DeletePropagationForeground
+	cases := []struct {
+		name     string
+		options  []APICordonDrainerOption
+		evictErr error
+		want     *meta.DeletionPropagation
+	}{
+		{name: "Default"},
+		{name: "Eviction", options: []APICordonDrainerOption{WithPropagationPolicy(foreground)}, want: &foreground},
+		{
+			name:     "ForceDeletion",
+			options:  []APICordonDrainerOption{WithPropagationPolicy(foreground), WithEvictionEscalation(time.Nanosecond)},
+			evictErr: apierrors.NewTooManyRequests("nope", 5),
+			want:     &foreground,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeClientSet(
+				reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+					core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+				}}},
+				reactor{verb: "create", resource: "pods", subresource: "eviction", err: tc.evictErr},
+				reactor{verb: "delete", resource: "pods"},
+				reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+			)
+			d := NewAPICordonDrainer(c, tc.options...)
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+
+			var got *meta.DeletionPropagation
+			for _, a := range c.(*fake.Clientset).Actions() {
+				switch {
+				case a.GetVerb() == "create" && a.GetSubresource() == "eviction" && tc.evictErr == nil:
+					got = a.(clienttesting.CreateAction).GetObject().(*policy.Eviction).DeleteOptions.PropagationPolicy
+				case a.GetVerb() == "delete" && a.GetResource().Resource == "pods":
+					got = a.(clienttesting.DeleteAction).GetDeleteOptions().PropagationPolicy
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("propagation policy: want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
 func TestMarkDrain(t *testing.T) {
 	now := meta.Time{Time: time.Now()}
 	cases := []struct {
@@ -546,7 +1426,7 @@ func TestMarkDrain(t *testing.T) {
 					t.Errorf("node %v initial mark is not correct", tc.node.Name)
 				}
 			}
-			if err := d.MarkDrain(tc.node, time.Now(), time.Time{}, false); err != nil {
+			if err := d.MarkDrain(tc.node, time.Now(), time.Time{}, false, ""); err != nil {
 				t.Errorf("d.MarkDrain(%v): %v", tc.node.Name, err)
 			}
 			{
@@ -561,3 +1441,313 @@ func TestMarkDrain(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkDrainState(t *testing.T) {
+	when := time.Now()
+	finish := when.Add(time.Minute)
+	steps := []struct {
+		state  DrainState
+		legacy map[string]core.ConditionStatus
+		want   map[string]core.ConditionStatus
+	}{
+		{
+			state:  DrainStateScheduled,
+			legacy: map[string]core.ConditionStatus{ConditionDrainedScheduled: core.ConditionTrue},
+			want:   map[string]core.ConditionStatus{ConditionDrainedScheduled: core.ConditionTrue},
+		},
+		{
+			state:  DrainStateInProgress,
+			legacy: map[string]core.ConditionStatus{ConditionDrainedScheduled: core.ConditionTrue},
+			want: map[string]core.ConditionStatus{
+				ConditionDrainedScheduled: core.ConditionTrue,
+				ConditionDrainInProgress:  core.ConditionTrue,
+			},
+		},
+		{
+			state:  DrainStateFailed,
+			legacy: map[string]core.ConditionStatus{ConditionDrainedScheduled: core.ConditionFalse},
+			want: map[string]core.ConditionStatus{
+				ConditionDrainedScheduled: core.ConditionFalse,
+				ConditionDrainInProgress:  core.ConditionFalse,
+				ConditionDrainFailed:      core.ConditionTrue,
+			},
+		},
+		{
+			state:  DrainStateScheduled,
+			legacy: map[string]core.ConditionStatus{ConditionDrainedScheduled: core.ConditionTrue},
+			want: map[string]core.ConditionStatus{
+				ConditionDrainedScheduled: core.ConditionTrue,
+				ConditionDrainInProgress:  core.ConditionFalse,
+				ConditionDrainFailed:      core.ConditionFalse,
+			},
+		},
+		{
+			state:  DrainStateSucceeded,
+			legacy: map[string]core.ConditionStatus{ConditionDrainedScheduled: core.ConditionFalse},
+			want: map[string]core.ConditionStatus{
+				ConditionDrainedScheduled: core.ConditionFalse,
+				ConditionDrainInProgress:  core.ConditionFalse,
+				ConditionDrainSucceeded:   core.ConditionTrue,
+				ConditionDrainFailed:      core.ConditionFalse,
+			},
+		},
+	}
+
+	for _, stateConditions := range []bool{false, true} {
+		t.Run(fmt.Sprintf("StateConditions=%v", stateConditions), func(t *testing.T) {
+			c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			d := NewAPICordonDrainer(c, WithDrainStateConditions(stateConditions))
+			for _, s := range steps {
+				if err := d.MarkDrainState(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, s.state, when, finish, "nope"); err != nil {
+					t.Fatalf("d.MarkDrainState(%v): %v", s.state, err)
+				}
+				n, err := c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{})
+				if err != nil {
+					t.Fatalf("node.Get(%v): %v", nodeName, err)
+				}
+				got := map[string]core.ConditionStatus{}
+				for _, c := range n.Status.Conditions {
+					got[string(c.Type)] = c.Status
+				}
+				want := s.legacy
+				if stateConditions {
+					want = s.want
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("d.MarkDrainState(%v): want conditions %v, got %v", s.state, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClearDrainCondition(t *testing.T) {
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Status: core.NodeStatus{Conditions: []core.NodeCondition{
+			{Type: core.NodeReady, Status: core.ConditionTrue},
+			{Type: core.NodeConditionType(ConditionDrainedScheduled), Status: core.ConditionTrue},
+		}},
+	}
+	c := fake.NewSimpleClientset(node)
+	d := NewAPICordonDrainer(c)
+	if err := d.ClearDrainCondition(node); err != nil {
+		t.Fatalf("d.ClearDrainCondition(%v): %v", node.Name, err)
+	}
+	n, err := c.CoreV1().Nodes().Get(context.Background(), node.GetName(), meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("node.Get(%v): %v", node.Name, err)
+	}
+	if len(n.Status.Conditions) != 1 || n.Status.Conditions[0].Type != core.NodeReady {
+		t.Errorf("node %v: want only the Ready condition, got %v", node.Name, n.Status.Conditions)
+	}
+	if err := d.ClearDrainCondition(&core.Node{ObjectMeta: meta.ObjectMeta{Name: "missing"}}); err != nil {
+		t.Errorf("d.ClearDrainCondition(missing): %v", err)
+	}
+}
+
+func TestMarkDrainFailedReason(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	c := fake.NewSimpleClientset(node)
+	d := NewAPICordonDrainer(c)
+
+	reason := DrainFailureReason(errors.Wrap(errPDBBlocked{pods: 2}, "timed out waiting for evictions to complete"))
+	if err := d.MarkDrain(node, time.Now(), time.Now(), true, reason); err != nil {
+		t.Fatalf("d.MarkDrain(%v): %v", node.Name, err)
+	}
+	n, err := c.CoreV1().Nodes().Get(context.Background(), node.GetName(), meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("node.Get(%v): %v", node.Name, err)
+	}
+	if len(n.Status.Conditions) != 1 {
+		t.Fatalf("node %v: want 1 condition, got %d", node.Name, len(n.Status.Conditions))
+	}
+	cond := n.Status.Conditions[0]
+	if string(cond.Type) != ConditionDrainedScheduled {
+		t.Errorf("condition type: want %v, got %v", ConditionDrainedScheduled, cond.Type)
+	}
+	if !strings.HasSuffix(cond.Message, " | Reason: PodDisruptionBudget blocked 2 pods") {
+		t.Errorf("condition message does not carry the failure reason: %q", cond.Message)
+	}
+}
+
+func TestDrainFailureReason(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "NoError",
+		},
+		{
+			name: "PDBBlocked",
+			err:  errors.Wrap(errPDBBlocked{pods: 2}, "timed out waiting for evictions to complete"),
+			want: "PodDisruptionBudget blocked 2 pods",
+		},
+		{
+			name: "Timeout",
+			err:  errors.Wrap(errTimeout{}, "timed out waiting for evictions to complete"),
+			want: "Timed out waiting for evictions to complete",
+		},
+		{
+			name: "Other",
+			err:  errors.Wrap(errExploded, "cannot evict all pods"),
+			want: "cannot evict all pods: kaboom",
+		},
+		{
+			name: "Truncated",
+			err:  errors.New(strings.Repeat("x", 2*MaxConditionReasonLength)),
+			want: strings.Repeat("x", MaxConditionReasonLength-3) + "...",
+		},
+		{
+			// Runes straddling the truncation are not split.
+			name: "TruncatedMultibyte",
+			err:  errors.New(strings.Repeat("é", MaxConditionReasonLength)),
+			want: strings.Repeat("é", (MaxConditionReasonLength-3)/2) + "...",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DrainFailureReason(tc.err); got != tc.want {
+				t.Errorf("DrainFailureReason(): want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDrainNodeGone(t *testing.T) {
+	cases := []struct {
+		name     string
+		interval time.Duration
+		evictErr error
+	}{
+		{name: "Refused", interval: 10 * time.Millisecond, evictErr: apierrors.NewTooManyRequests("PodDisruptionBudget", 5)},
+		{name: "Failed", interval: time.Hour, evictErr: apierrors.NewInternalError(errors.New("nope"))},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, &core.PodList{Items: []core.Pod{{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName}}}}, nil
+			})
+			// The node is deleted underneath the drain as its pod is
+			// evicted.
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if a.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				if err := c.Tracker().Delete(core.SchemeGroupVersion.WithResource("nodes"), "", nodeName); err != nil && !apierrors.IsNotFound(err) {
+					t.Errorf("cannot delete node: %v", err)
+				}
+				return true, nil, tc.evictErr
+			})
+
+			d := NewAPICordonDrainer(c, WithNodeGoneCheck(tc.interval))
+			start := time.Now()
+			err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if !apierrors.IsNotFound(err) {
+				t.Fatalf("d.Drain(%v): want NotFound, got %v", nodeName, err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("d.Drain(%v): took %s to notice the node was gone", nodeName, elapsed)
+			}
+		})
+	}
+}
+
+func TestDrainUnreadyPodFastPath(t *testing.T) {
+	pod := func(name string, ready core.ConditionStatus) core.Pod {
+		grace := int64(30)
+		return core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       core.PodSpec{TerminationGracePeriodSeconds: &grace},
+			Status:     core.PodStatus{Conditions: []core.PodCondition{{Type: core.PodReady, Status: ready}}},
+		}
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			pod("web", core.ConditionTrue),
+			pod("crashing", core.ConditionFalse),
+			pod("api", core.ConditionTrue),
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "pending"}},
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c, MaxGracePeriod(time.Minute), WithUnreadyPodFastPath(time.Second))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	var got []string
+	grace := map[string]int64{}
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			e := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+			got = append(got, e.GetName())
+			grace[e.GetName()] = *e.DeleteOptions.GracePeriodSeconds
+		}
+	}
+	if len(got) != 4 {
+		t.Fatalf("evictions: want 4, got %v", got)
+	}
+	// Each batch is evicted at once, in no particular order.
+	first := append([]string{}, got[:2]...)
+	sort.Strings(first)
+	if want := []string{"crashing", "pending"}; !reflect.DeepEqual(first, want) {
+		t.Errorf("first evictions: want %v, got %v", want, got[:2])
+	}
+	want := map[string]int64{"crashing": 1, "pending": 1, "web": 30, "api": 30}
+	if !reflect.DeepEqual(grace, want) {
+		t.Errorf("grace periods: want %v, got %v", want, grace)
+	}
+}
+
+func TestDrainGracePeriodTiers(t *testing.T) {
+	pod := func(name, tier string) core.Pod {
+		grace := int64(30)
+		p := core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       core.PodSpec{TerminationGracePeriodSeconds: &grace},
+		}
+		if tier != "" {
+			p.SetLabels(map[string]string{"tier": tier})
+		}
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			pod("database", "critical"),
+			pod("report", "batch"),
+			pod("web", "frontend"),
+			pod("sidecar", ""),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c,
+		MaxGracePeriod(time.Minute),
+		WithGracePeriodTiers("tier", map[string]time.Duration{"critical": 5 * time.Minute, "batch": 0}))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	got := map[string]int64{}
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			e := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+			got[e.GetName()] = *e.DeleteOptions.GracePeriodSeconds
+		}
+	}
+	want := map[string]int64{"database": 300, "report": 0, "web": 30, "sidecar": 30}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grace periods: want %v, got %v", want, got)
+	}
+	if got, want := d.deleteTimeout(), 5*time.Minute+d.evictionHeadroom; got != want {
+		t.Errorf("deleteTimeout(): want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,33 @@
+package kubernetes
+
+import (
+	"strconv"
+	"time"
+)
+
+// Annotations of the events recording the outcome of drains, so that their
+// consumers read fields rather than parse the event messages.
+const (
+	eventOutcomeAnnotation     = "draino.kubernetes.io/outcome"
+	eventDurationAnnotation    = "draino.kubernetes.io/duration-ms"
+	eventPodsEvictedAnnotation = "draino.kubernetes.io/pods-evicted"
+	eventReasonAnnotation      = "draino.kubernetes.io/reason"
+)
+
+// drainOutcomeAnnotations returns the annotations of the event recording the
+// supplied outcome of a drain that took the supplied duration. The number of
+// pods evicted is only annotated if the drain was summarized, and the reason,
+// typically why the drain failed, only if not empty.
+func drainOutcomeAnnotations(result string, took time.Duration, summary DrainSummary, summarized bool, reason string) map[string]string {
+	a := map[string]string{
+		eventOutcomeAnnotation:  result,
+		eventDurationAnnotation: strconv.FormatInt(took.Milliseconds(), 10),
+	}
+	if summarized {
+		a[eventPodsEvictedAnnotation] = strconv.Itoa(summary.Evicted)
+	}
+	if reason != "" {
+		a[eventReasonAnnotation] = reason
+	}
+	return a
+}
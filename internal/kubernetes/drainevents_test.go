@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// outcomeDrainer fails its drains with err, if any.
+type outcomeDrainer struct {
+	*summarizingDrainer
+	err error
+}
+
+func (d *outcomeDrainer) Drain(n *v1.Node) error { return d.err }
+
+// annotationRecorder records the annotations of annotated events by reason.
+type annotationRecorder struct {
+	record.FakeRecorder
+	sync.Mutex
+	annotations map[string]map[string]string
+}
+
+func (r *annotationRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Lock()
+	defer r.Unlock()
+	r.annotations[reason] = annotations
+}
+
+// withoutAnnotations returns the supplied event, as recorded by a FakeRecorder,
+// without the annotations appended to it.
+func withoutAnnotations(event string) string {
+	if i := strings.Index(event, " map["); i >= 0 {
+		return event[:i]
+	}
+	return event
+}
+
+func TestDrainSchedules_DrainOutcomeAnnotations(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		reason string
+		want   map[string]string
+	}{
+		{
+			name:   "Succeeded",
+			reason: eventReasonDrainSucceeded,
+			want: map[string]string{
+				eventOutcomeAnnotation:     tagResultSucceeded,
+				eventPodsEvictedAnnotation: "3",
+			},
+		},
+		{
+			name:   "Failed",
+			err:    errors.New("eviction refused"),
+			reason: eventReasonDrainFailed,
+			want: map[string]string{
+				eventOutcomeAnnotation:     tagResultFailed,
+				eventPodsEvictedAnnotation: "3",
+				eventReasonAnnotation:      "eviction refused",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := &outcomeDrainer{
+				summarizingDrainer: &summarizingDrainer{summaries: map[string]DrainSummary{nodeName: {Evicted: 3}}},
+				err:                tc.err,
+			}
+			recorder := &annotationRecorder{annotations: map[string]map[string]string{}}
+			scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop()).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			got := recorder.annotations[tc.reason]
+			if ms, err := strconv.Atoi(got[eventDurationAnnotation]); err != nil || ms < 0 {
+				t.Errorf("%s annotation: want milliseconds, got %q", eventDurationAnnotation, got[eventDurationAnnotation])
+			}
+			delete(got, eventDurationAnnotation)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("annotations of the %s event: want %v, got %v", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package kubernetes
+
+import (
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+)
+
+// An OnDrainFailed hook is called when the drain of the supplied node failed
+// permanently, with the reason it failed, as classified by DrainFailureReason,
+// and the number of times it was attempted.
+type OnDrainFailed func(n *core.Node, reason string, attempts int)
+
+// WithOnDrainFailed configures a hook called when a drain fails permanently,
+// once its retries are exhausted, for example to open a ticket or page
+// someone. Drains that are retried, or cancelled, are not reported. The hook
+// runs in its own goroutine, so that a slow hook does not hold up drains, and
+// panics are recovered and logged.
+func WithOnDrainFailed(fn OnDrainFailed) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.onDrainFailed = fn
+	}
+}
+
+// reportDrainFailed calls the drain failed hook, if any, for the supplied node.
+func (d *DrainSchedules) reportDrainFailed(node *core.Node, reason string, attempts int) {
+	if d.onDrainFailed == nil {
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				d.logger.Error("Drain failed hook panicked", zap.String("node", node.GetName()), zap.Any("panic", r))
+			}
+		}()
+		d.onDrainFailed(node, reason, attempts)
+	}()
+}
@@ -0,0 +1,55 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type drainFailure struct {
+	node     string
+	reason   string
+	attempts int
+}
+
+func TestDrainSchedules_OnDrainFailed(t *testing.T) {
+	failures := make(chan drainFailure, 3)
+	scheduler := NewDrainSchedules(&failDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDrainRetries(3, time.Hour),
+		WithOnDrainFailed(func(n *v1.Node, reason string, attempts int) {
+			failures <- drainFailure{node: n.GetName(), reason: reason, attempts: attempts}
+			panic("remediation failed")
+		}),
+	).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	for i := 0; i < 3; i++ {
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+		// Drains that are retried are not reported.
+		if i < 2 && len(failures) > 0 {
+			t.Fatalf("OnDrainFailed: want no call on attempt %d, got %+v", i+1, <-failures)
+		}
+	}
+	sched.timer.Stop()
+
+	select {
+	case got := <-failures:
+		if want := (drainFailure{node: nodeName, reason: "myerr", attempts: 3}); got != want {
+			t.Errorf("OnDrainFailed: want %+v, got %+v", want, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnDrainFailed: want a call once the drain failed, got none")
+	}
+	// The scheduler survives the hook panicking.
+	if has, failed := scheduler.HasSchedule(nodeName); !has || !failed {
+		t.Errorf("DrainSchedules.HasSchedule(): want a failed schedule, got %v, %v", has, failed)
+	}
+}
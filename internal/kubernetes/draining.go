@@ -0,0 +1,25 @@
+package kubernetes
+
+// drainingSet is an immutable set of the names of the nodes being drained.
+type drainingSet map[string]struct{}
+
+// publishInProgressLocked publishes a snapshot of the nodes being drained for
+// IsDraining to read without the lock. It must be called with the lock held,
+// whenever the nodes being drained change.
+func (d *DrainSchedules) publishInProgressLocked() {
+	set := make(drainingSet, len(d.inProgress))
+	for name := range d.inProgress {
+		set[name] = struct{}{}
+	}
+	d.draining.Store(set)
+}
+
+// IsDraining returns true if the named node is currently being drained. It
+// reads a snapshot of the nodes being drained without taking the lock, so
+// that hot read paths such as admission webhooks do not contend with the
+// scheduler.
+func (d *DrainSchedules) IsDraining(name string) bool {
+	set, _ := d.draining.Load().(drainingSet)
+	_, ok := set[name]
+	return ok
+}
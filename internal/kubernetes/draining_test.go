@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_IsDraining(t *testing.T) {
+	const drains = 8
+	drainer := &blockingDrainer{started: make(chan struct{}, drains), release: make(chan struct{})}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	if scheduler.IsDraining(nodeName) {
+		t.Fatalf("IsDraining(%v): no drain should be in progress yet", nodeName)
+	}
+
+	var names []string
+	for i := 0; i < drains; i++ {
+		names = append(names, fmt.Sprintf("node-%d", i))
+	}
+
+	// Readers hammer IsDraining while the drains start and complete.
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					for _, name := range names {
+						scheduler.IsDraining(name)
+					}
+				}
+			}
+		}()
+	}
+
+	var drainsDone sync.WaitGroup
+	for _, name := range names {
+		drainsDone.Add(1)
+		go func(name string) {
+			defer drainsDone.Done()
+			_ = scheduler.drainInProgress(context.Background(), &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}, false)
+		}(name)
+	}
+	for i := 0; i < drains; i++ {
+		<-drainer.started
+	}
+	for _, name := range names {
+		if !scheduler.IsDraining(name) {
+			t.Errorf("IsDraining(%v): want true while its drain is in progress", name)
+		}
+	}
+	if scheduler.IsDraining(nodeName) {
+		t.Errorf("IsDraining(%v): want false for a node that is not being drained", nodeName)
+	}
+
+	close(drainer.release)
+	drainsDone.Wait()
+	close(stop)
+	readers.Wait()
+	for _, name := range names {
+		if scheduler.IsDraining(name) {
+			t.Errorf("IsDraining(%v): want false once its drain completed", name)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package kubernetes
+
+// State returns the state of the drain of the named node:
+//
+//   - None if the node has no schedule.
+//   - InProgress while its pods are being evicted.
+//   - Failed once its drain failed, or was cancelled in flight.
+//   - Succeeded once it was drained.
+//   - Cooldown while a failed attempt waits for its retry backoff to elapse.
+//   - Deferred once its drain was due but did not start, for example because
+//     its node group is paused or a dependency is still draining.
+//   - Scheduled otherwise, until its drain is due.
+func (d *DrainSchedules) State(name string) DrainState {
+	d.Lock()
+	defer d.Unlock()
+	sched, ok := d.schedules[name]
+	if !ok {
+		return DrainStateNone
+	}
+	return d.drainStateLocked(name, sched)
+}
+
+// drainStateLocked returns the state of the supplied schedule of the named
+// node. It must be called with the lock held.
+func (d *DrainSchedules) drainStateLocked(name string, sched *schedule) DrainState {
+	if _, draining := d.inProgress[name]; draining {
+		return DrainStateInProgress
+	}
+	switch {
+	// The failure reason is set with the lock held, before the schedule is
+	// failed.
+	case sched.isFailed() || sched.failureReason != "":
+		return DrainStateFailed
+	case !sched.finish.IsZero():
+		return DrainStateSucceeded
+	case sched.attempt > 0:
+		return DrainStateCooldown
+	case sched.paused || sched.blocked || !sched.when.After(d.now()):
+		return DrainStateDeferred
+	}
+	return DrainStateScheduled
+}
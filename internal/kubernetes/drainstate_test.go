@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// resultDrainer is a Drainer whose drains block until released with their
+// result.
+type resultDrainer struct {
+	NoopCordonDrainer
+	started chan string
+	release chan error
+}
+
+func newGatedDrainer() *resultDrainer {
+	return &resultDrainer{started: make(chan string), release: make(chan error)}
+}
+
+func (d *resultDrainer) Drain(n *v1.Node) error {
+	d.started <- n.GetName()
+	return <-d.release
+}
+
+func TestDrainSchedules_State(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := newGatedDrainer()
+	var enabled atomic.Bool
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Hour, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithDrainRetries(2, time.Minute),
+		WithDrainSwitch(enabled.Load),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	want := func(name string, state DrainState) {
+		t.Helper()
+		if got := scheduler.State(name); got != state {
+			t.Fatalf("DrainSchedules.State(%s): want %s, got %s", name, state, got)
+		}
+	}
+	// drain fires the timers due at the supplied time, and releases the drain
+	// they start with the supplied result once it is checked in progress.
+	drain := func(name string, now time.Time, result error) {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			dispatcher.ProcessDue(now)
+		}()
+		<-drainer.started
+		want(name, DrainStateInProgress)
+		drainer.release <- result
+		<-done
+	}
+
+	a := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	want(a.GetName(), DrainStateNone)
+	when, err := scheduler.Schedule(a)
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule(): %v", err)
+	}
+	want(a.GetName(), DrainStateScheduled)
+
+	// Drains are disabled when the drain is due.
+	dispatcher.ProcessDue(when)
+	want(a.GetName(), DrainStateDeferred)
+
+	enabled.Store(true)
+	now := when.Add(DefaultDrainDeferralPeriod)
+	drain(a.GetName(), now, errors.New("pod disruption budget"))
+	want(a.GetName(), DrainStateCooldown)
+
+	drain(a.GetName(), now.Add(time.Minute), nil)
+	want(a.GetName(), DrainStateSucceeded)
+
+	b := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "b"}}
+	when, err = scheduler.Schedule(b)
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule(): %v", err)
+	}
+	drain(b.GetName(), when, errors.New("pod disruption budget"))
+	drain(b.GetName(), when.Add(time.Minute), errors.New("pod disruption budget"))
+	want(b.GetName(), DrainStateFailed)
+
+	scheduler.DeleteSchedule(b.GetName())
+	want(b.GetName(), DrainStateNone)
+}
@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+)
+
+// DrainStats describe a finished drain, for cost attribution.
+type DrainStats struct {
+	Node         string
+	DrainID      string
+	InstanceType string
+	// Result is the result of the drain: succeeded, failed or cancelled.
+	Result string
+	// Cordoned is when the node was cordoned, or when its drain was
+	// scheduled if unknown, and CordonedFor how long before the drain
+	// finished.
+	Cordoned    time.Time
+	CordonedFor time.Duration
+	Started     time.Time
+	Finished    time.Time
+	// PodsMoved counts the pods removed from the node, by eviction, force
+	// deletion or termination. It is zero if the drainer is not a
+	// DrainSummarizer.
+	PodsMoved int
+}
+
+// An OnDrainStats callback is called with the stats of each finished drain.
+type OnDrainStats func(DrainStats)
+
+// WithOnDrainStats configures a callback called with the stats of each
+// finished drain. The callback runs in its own goroutine, so that a slow
+// callback does not hold up drains, and panics are recovered and logged.
+func WithOnDrainStats(fn OnDrainStats) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.onDrainStats = fn
+	}
+}
+
+// cordonedSince returns when the supplied node was cordoned according to its
+// unschedulable taint, or when the supplied schedule was created if the taint
+// does not record it.
+func cordonedSince(n *core.Node, sched *schedule) time.Time {
+	for _, t := range n.Spec.Taints {
+		if t.Key == core.TaintNodeUnschedulable && t.TimeAdded != nil {
+			return t.TimeAdded.Time
+		}
+	}
+	return sched.created
+}
+
+// reportDrainStats calls the drain stats callback, if any, with the stats of
+// the finished drain of the supplied schedule.
+func (d *DrainSchedules) reportDrainStats(node *core.Node, sched *schedule, result string, started, finish time.Time, summary DrainSummary) {
+	if d.onDrainStats == nil {
+		return
+	}
+	cordoned := cordonedSince(node, sched)
+	stats := DrainStats{
+		Node:         node.GetName(),
+		DrainID:      sched.drainID,
+		InstanceType: d.instanceType(node),
+		Result:       result,
+		Cordoned:     cordoned,
+		CordonedFor:  finish.Sub(cordoned),
+		Started:      started,
+		Finished:     finish,
+		PodsMoved:    summary.podsMoved(),
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				d.logger.Error("Drain stats callback panicked", zap.String("node", stats.Node), zap.Any("panic", r))
+			}
+		}()
+		d.onDrainStats(stats)
+	}()
+}
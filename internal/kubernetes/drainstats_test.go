@@ -0,0 +1,94 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_OnDrainStats(t *testing.T) {
+	cordoned := meta.NewTime(time.Now().Add(-time.Hour))
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName, Labels: map[string]string{core.LabelInstanceTypeStable: "m5.large"}},
+		Spec: core.NodeSpec{
+			Unschedulable: true,
+			Taints:        []core.Taint{{Key: core.TaintNodeUnschedulable, Effect: core.TaintEffectNoSchedule, TimeAdded: &cordoned}},
+		},
+	}
+	c := fake.NewSimpleClientset(node)
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: []core.Pod{
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "web"}},
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "api"}},
+		}}, nil
+	})
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return a.GetSubresource() == "eviction", nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+	})
+
+	reported := make(chan DrainStats, 1)
+	scheduler := NewDrainSchedules(NewAPICordonDrainer(c), &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithOnDrainStats(func(s DrainStats) { reported <- s })).(*DrainSchedules)
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	var s DrainStats
+	select {
+	case s = <-reported:
+	case <-time.After(time.Second):
+		t.Fatal("drain stats not reported")
+	}
+	if s.Node != nodeName || s.DrainID != sched.drainID || s.InstanceType != "m5.large" || s.Result != tagResultSucceeded {
+		t.Errorf("DrainStats: want node %s, drain %s, instance type m5.large, result %s, got %+v", nodeName, sched.drainID, tagResultSucceeded, s)
+	}
+	if s.PodsMoved != 2 {
+		t.Errorf("DrainStats.PodsMoved: want 2, got %d", s.PodsMoved)
+	}
+	if !s.Cordoned.Equal(cordoned.Time) || s.CordonedFor != s.Finished.Sub(cordoned.Time) || s.CordonedFor < time.Hour {
+		t.Errorf("DrainStats: want cordoned at %v for about an hour, got %v for %v", cordoned.Time, s.Cordoned, s.CordonedFor)
+	}
+	if s.Started.After(s.Finished) {
+		t.Errorf("DrainStats: started %v after finished %v", s.Started, s.Finished)
+	}
+}
+
+func TestDrainSchedules_OnDrainStatsPanic(t *testing.T) {
+	called := make(chan struct{})
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithOnDrainStats(func(DrainStats) {
+			defer close(called)
+			panic("callback exploded")
+		})).(*DrainSchedules)
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("drain stats callback not called")
+	}
+	if has, failed := scheduler.HasSchedule(nodeName); !has || failed {
+		t.Errorf("HasSchedule(): want a succeeded schedule, got %v, %v", has, failed)
+	}
+}
@@ -0,0 +1,148 @@
+package kubernetes
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// DefaultDrainSwitchKey is the key of the ConfigMap watched by a
+	// ConfigMapDrainSwitch. Drains are disabled while it is false.
+	DefaultDrainSwitchKey = "drain-enabled"
+
+	deferralReasonDisabled = "drains-disabled"
+)
+
+// A DrainSwitch returns false while drains are disabled.
+type DrainSwitch func() bool
+
+// WithDrainSwitch defers drains by DefaultDrainDeferralPeriod when they fire
+// while the supplied switch reports that drains are disabled, however long
+// they were deferred. Drains are still scheduled, and their nodes marked,
+// meanwhile. Drains already in progress are not affected.
+func WithDrainSwitch(enabled DrainSwitch) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.drainsEnabled = enabled
+	}
+}
+
+// deferDisabled returns true if the drain of the supplied schedule is deferred
+// because drains are disabled.
+func (d *DrainSchedules) deferDisabled(node *core.Node, sched *schedule) bool {
+	if d.drainsEnabled == nil || d.drainsEnabled() {
+		return false
+	}
+	d.Lock()
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	d.Unlock()
+
+	d.logger.Info("Deferring drain, drains are disabled", zap.String("node", node.GetName()))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Drains are disabled")
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonDisabled))
+	d.recordDeferral(node.GetName(), deferralReasonDisabled)
+	return true
+}
+
+// A ConfigMapDrainSwitch watches a key of a ConfigMap that enables drains while
+// true, and disables them while false, so that drains can be stopped without
+// restarting draino. Drains are enabled if the ConfigMap or its key does not
+// exist, or its value is invalid, so that a missing switch never blocks drains.
+type ConfigMapDrainSwitch struct {
+	cache.SharedInformer
+	key     string
+	l       *zap.Logger
+	metrics MetricsRecorder
+}
+
+// ConfigMapDrainSwitchOption configures a ConfigMapDrainSwitch.
+type ConfigMapDrainSwitchOption func(s *ConfigMapDrainSwitch)
+
+// WithDrainSwitchMetricsRecorder configures the recorder of whether drains are
+// enabled, in place of the process-global opencensus stats.
+func WithDrainSwitchMetricsRecorder(m MetricsRecorder) ConfigMapDrainSwitchOption {
+	return func(s *ConfigMapDrainSwitch) {
+		s.metrics = m
+	}
+}
+
+// NewConfigMapDrainSwitch returns a ConfigMapDrainSwitch watching the supplied
+// key of the supplied ConfigMap. It must be run to watch the ConfigMap.
+func NewConfigMapDrainSwitch(c kubernetes.Interface, namespace, name, key string, l *zap.Logger, so ...ConfigMapDrainSwitchOption) *ConfigMapDrainSwitch {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(o meta.ListOptions) (runtime.Object, error) {
+			o.FieldSelector = selector
+			return c.CoreV1().ConfigMaps(namespace).List(context.Background(), o)
+		},
+		WatchFunc: func(o meta.ListOptions) (watch.Interface, error) {
+			o.FieldSelector = selector
+			return c.CoreV1().ConfigMaps(namespace).Watch(context.Background(), o)
+		},
+	}
+	s := &ConfigMapDrainSwitch{
+		SharedInformer: cache.NewSharedInformer(lw, &core.ConfigMap{}, 30*time.Minute),
+		key:            key,
+		l:              l,
+		metrics:        OpenCensusMetricsRecorder{},
+	}
+	for _, o := range so {
+		o(s)
+	}
+	// Failing to list or watch the ConfigMap, e.g. for lack of permissions,
+	// leaves drains enabled however the switch is set.
+	s.SetWatchErrorHandler(func(_ *cache.Reflector, err error) { // nolint:errcheck,gosec
+		s.l.Warn("Cannot watch drain switch, drains stay enabled until it syncs", zap.Error(err))
+	})
+	record := func(o interface{}) { s.record() }
+	s.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    record,
+		UpdateFunc: func(_, o interface{}) { s.record() },
+		DeleteFunc: record,
+	})
+	return s
+}
+
+// Enabled returns false while the watched key of the ConfigMap is false. Drains
+// are enabled until the ConfigMap was listed.
+func (s *ConfigMapDrainSwitch) Enabled() bool {
+	if !s.HasSynced() {
+		s.l.Warn("Drain switch not synced, drains are enabled")
+		return true
+	}
+	for _, o := range s.GetStore().List() {
+		cm, ok := o.(*core.ConfigMap)
+		if !ok {
+			continue
+		}
+		v, ok := cm.Data[s.key]
+		if !ok {
+			return true
+		}
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return true
+		}
+		return enabled
+	}
+	return true
+}
+
+// record records whether drains are enabled once the ConfigMap changed.
+func (s *ConfigMapDrainSwitch) record() {
+	enabled := s.Enabled()
+	s.l.Info("Drain switch changed", zap.Bool("enabled", enabled))
+	s.metrics.DrainsEnabled(enabled)
+}
@@ -0,0 +1,136 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// switchMetrics records the last recorded state of the drain switch.
+type switchMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	enabled *bool
+}
+
+func (m *switchMetrics) DrainsEnabled(enabled bool) {
+	m.Lock()
+	defer m.Unlock()
+	m.enabled = &enabled
+}
+
+func TestDrainSchedules_ConfigMapDrainSwitch(t *testing.T) {
+	sm := &switchMetrics{}
+	// recorded returns true once the supplied enabled state was recorded.
+	recorded := func(want bool) func() (bool, error) {
+		return func() (bool, error) {
+			sm.Lock()
+			defer sm.Unlock()
+			return sm.enabled != nil && *sm.enabled == want, nil
+		}
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "draino"},
+		Data:       map[string]string{DefaultDrainSwitchKey: "false"},
+	}
+	c := fake.NewSimpleClientset(cm)
+	sw := NewConfigMapDrainSwitch(c, "kube-system", "draino", DefaultDrainSwitchKey, zap.NewNop(), WithDrainSwitchMetricsRecorder(sm))
+	stop := make(chan struct{})
+	defer close(stop)
+	go sw.Run(stop)
+	if !cache.WaitForCacheSync(stop, sw.HasSynced) {
+		t.Fatal("drain switch cache not synced")
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, recorded(false)); err != nil {
+		t.Errorf("drains enabled: want false recorded once disabled, got %v", err)
+	}
+
+	m := &deferralMetrics{}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDrainSwitch(sw.Enabled), WithMetricsRecorder(m)).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+
+	scheduler.runDrain(node, sched)
+	sched.timer.Stop()
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("drained %v while drains were disabled", got)
+	}
+	if want := []string{nodeName + "=" + deferralReasonDisabled}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+
+	cm.Data[DefaultDrainSwitchKey] = "true"
+	if _, err := c.CoreV1().ConfigMaps("kube-system").Update(context.Background(), cm, meta.UpdateOptions{}); err != nil {
+		t.Fatalf("configMaps.Update(): %v", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, recorded(true)); err != nil {
+		t.Fatalf("drains enabled: want true recorded once the ConfigMap changed, got %v", err)
+	}
+	scheduler.runDrain(node, sched)
+	if got, want := drainer.nodes(), []string{nodeName}; !reflect.DeepEqual(got, want) {
+		t.Errorf("drained nodes once drains were enabled: want %v, got %v", want, got)
+	}
+}
+
+func TestConfigMapDrainSwitchMissing(t *testing.T) {
+	sw := NewConfigMapDrainSwitch(fake.NewSimpleClientset(), "kube-system", "draino", DefaultDrainSwitchKey, zap.NewNop())
+	stop := make(chan struct{})
+	defer close(stop)
+	go sw.Run(stop)
+	if !cache.WaitForCacheSync(stop, sw.HasSynced) {
+		t.Fatal("drain switch cache not synced")
+	}
+	if !sw.Enabled() {
+		t.Error("Enabled(): want drains enabled without a ConfigMap")
+	}
+}
+
+func TestConfigMapDrainSwitchForbidden(t *testing.T) {
+	c := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "draino"},
+		Data:       map[string]string{DefaultDrainSwitchKey: "false"},
+	})
+	c.PrependReactor("list", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(v1.Resource("configmaps"), "", errors.New("no list"))
+	})
+	core, logs := observer.New(zap.WarnLevel)
+	sw := NewConfigMapDrainSwitch(c, "kube-system", "draino", DefaultDrainSwitchKey, zap.New(core))
+	stop := make(chan struct{})
+	defer close(stop)
+	go sw.Run(stop)
+
+	// Drains stay enabled, and the failure to watch the switch is logged.
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return logs.FilterMessage("Cannot watch drain switch, drains stay enabled until it syncs").Len() > 0, nil
+	}); err != nil {
+		t.Errorf("logs: want the failure to watch the drain switch, got %v", logs.All())
+	}
+	if !sw.Enabled() {
+		t.Error("Enabled(): want drains enabled until the drain switch synced")
+	}
+	if logs.FilterMessage("Drain switch not synced, drains are enabled").Len() != 1 {
+		t.Errorf("logs: want the drain switch reported not synced, got %v", logs.All())
+	}
+}
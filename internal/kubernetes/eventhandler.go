@@ -19,6 +19,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -49,8 +50,44 @@ const (
 	eventReasonDrainSucceeded        = "DrainSucceeded"
 	eventReasonDrainFailed           = "DrainFailed"
 
-	tagResultSucceeded = "succeeded"
-	tagResultFailed    = "failed"
+	eventReasonDrainWaitingForDependency = "DrainWaitingForDependency"
+	eventReasonDrainForceFired           = "DrainForceFired"
+	eventReasonDrainDeferred             = "DrainDeferred"
+	eventReasonDrainNoop                 = "DrainNoop"
+	eventReasonDrainAbortedDeleted       = "DrainAbortedDeleted"
+	eventReasonDrainSkippedRecovered     = "DrainSkippedRecovered"
+	eventReasonDrainCancelledInFlight    = "DrainCancelledInFlight"
+	eventReasonDrainRetrying             = "DrainRetrying"
+	eventReasonDrainEscalated            = "DrainEscalated"
+	eventReasonDrainSummary              = "DrainSummary"
+	eventReasonDrainStaleEvent           = "DrainStaleEvent"
+	eventReasonDrainCircuitOpen          = "DrainCircuitOpen"
+	eventReasonDrainCircuitClosed        = "DrainCircuitClosed"
+	eventReasonDrainPreempted            = "DrainPreempted"
+	eventReasonDrainNotConfirmed         = "DrainNotConfirmed"
+	eventReasonDrainSkippedCordoned      = "DrainSkippedCordoned"
+	eventReasonDrainReasonsMerged        = "DrainReasonsMerged"
+	eventReasonDrainDryRun               = "DrainDryRun"
+
+	eventReasonEvictionSkipped = "EvictionSkipped"
+	eventReasonPodForceDeleted = "PodForceDeleted"
+
+	tagResultSucceeded       = "succeeded"
+	tagResultFailed          = "failed"
+	tagResultNoop            = "noop"
+	tagResultCancelled       = "cancelled"
+	tagResultNodeGone        = "node-gone"
+	tagResultEmpty           = "empty"
+	tagResultPreempted       = "preempted"
+	tagResultAdmissionDenied = "admission-denied"
+	tagResultDryRun          = "dry-run"
+
+	tagResultConfirmedRemoved     = "confirmed-removed"
+	tagResultConfirmedCordoned    = "confirmed-cordoned"
+	tagResultDrainedButPresent    = "drained-but-present"
+	tagResultDrainedButUncordoned = "drained-but-uncordoned"
+
+	tagResultAlreadyCordonedByUs = "already-cordoned-by-us"
 
 	drainRetryAnnotationKey   = "draino/drain-retry"
 	drainRetryAnnotationValue = "true"
@@ -61,15 +98,171 @@ const (
 	KarpenterTaint  = "karpenter.sh/disruption"
 )
 
+// EventReasons are the reasons of the events recorded by draino. Empty reasons
+// fall back to those of DefaultEventReasons.
+type EventReasons struct {
+	CordonStarting  string
+	CordonSucceeded string
+	CordonFailed    string
+
+	UncordonStarting  string
+	UncordonSucceeded string
+	UncordonFailed    string
+
+	DrainScheduled        string
+	DrainSchedulingFailed string
+	DrainStarting         string
+	DrainSucceeded        string
+	DrainFailed           string
+
+	DrainWaitingForDependency string
+	DrainForceFired           string
+	DrainDeferred             string
+	DrainNoop                 string
+	DrainAbortedDeleted       string
+	DrainSkippedRecovered     string
+	DrainCancelledInFlight    string
+	DrainRetrying             string
+	DrainEscalated            string
+	DrainSummary              string
+	DrainStaleEvent           string
+	DrainCircuitOpen          string
+	DrainCircuitClosed        string
+	DrainPreempted            string
+	DrainNotConfirmed         string
+	DrainSkippedCordoned      string
+	DrainReasonsMerged        string
+	DrainDryRun               string
+
+	EvictionSkipped string
+	PodForceDeleted string
+}
+
+// DefaultEventReasons are the event reasons used unless configured otherwise.
+var DefaultEventReasons = EventReasons{
+	CordonStarting:  eventReasonCordonStarting,
+	CordonSucceeded: eventReasonCordonSucceeded,
+	CordonFailed:    eventReasonCordonFailed,
+
+	UncordonStarting:  eventReasonUncordonStarting,
+	UncordonSucceeded: eventReasonUncordonSucceeded,
+	UncordonFailed:    eventReasonUncordonFailed,
+
+	DrainScheduled:        eventReasonDrainScheduled,
+	DrainSchedulingFailed: eventReasonDrainSchedulingFailed,
+	DrainStarting:         eventReasonDrainStarting,
+	DrainSucceeded:        eventReasonDrainSucceeded,
+	DrainFailed:           eventReasonDrainFailed,
+
+	DrainWaitingForDependency: eventReasonDrainWaitingForDependency,
+	DrainForceFired:           eventReasonDrainForceFired,
+	DrainDeferred:             eventReasonDrainDeferred,
+	DrainNoop:                 eventReasonDrainNoop,
+	DrainAbortedDeleted:       eventReasonDrainAbortedDeleted,
+	DrainSkippedRecovered:     eventReasonDrainSkippedRecovered,
+	DrainCancelledInFlight:    eventReasonDrainCancelledInFlight,
+	DrainRetrying:             eventReasonDrainRetrying,
+	DrainEscalated:            eventReasonDrainEscalated,
+	DrainSummary:              eventReasonDrainSummary,
+	DrainStaleEvent:           eventReasonDrainStaleEvent,
+	DrainCircuitOpen:          eventReasonDrainCircuitOpen,
+	DrainCircuitClosed:        eventReasonDrainCircuitClosed,
+	DrainPreempted:            eventReasonDrainPreempted,
+	DrainNotConfirmed:         eventReasonDrainNotConfirmed,
+	DrainSkippedCordoned:      eventReasonDrainSkippedCordoned,
+	DrainReasonsMerged:        eventReasonDrainReasonsMerged,
+	DrainDryRun:               eventReasonDrainDryRun,
+
+	EvictionSkipped: eventReasonEvictionSkipped,
+	PodForceDeleted: eventReasonPodForceDeleted,
+}
+
+// withDefaults returns a copy of the reasons where empty reasons are replaced
+// by their default.
+func (r EventReasons) withDefaults() EventReasons {
+	rv := reflect.ValueOf(&r).Elem()
+	dv := reflect.ValueOf(DefaultEventReasons)
+	for i := 0; i < rv.NumField(); i++ {
+		if rv.Field(i).String() == "" {
+			rv.Field(i).SetString(dv.Field(i).String())
+		}
+	}
+	return r
+}
+
 // Opencensus measurements.
 var (
 	MeasureNodesCordoned       = stats.Int64("draino/nodes_cordoned", "Number of nodes cordoned.", stats.UnitDimensionless)
 	MeasureNodesUncordoned     = stats.Int64("draino/nodes_uncordoned", "Number of nodes uncordoned.", stats.UnitDimensionless)
 	MeasureNodesDrained        = stats.Int64("draino/nodes_drained", "Number of nodes drained.", stats.UnitDimensionless)
 	MeasureNodesDrainScheduled = stats.Int64("draino/nodes_drainScheduled", "Number of nodes drain scheduled.", stats.UnitDimensionless)
+	MeasureDrainsForceFired    = stats.Int64("draino/drains_force_fired", "Number of drains fired after being deferred for too long.", stats.UnitDimensionless)
+	MeasureDrainsDeferred      = stats.Int64("draino/drains_deferred", "Number of drains deferred when they fired.", stats.UnitDimensionless)
+	MeasureNodesTooYoung       = stats.Int64("draino/nodes_too_young", "Number of nodes not scheduled for drain because they are too young.", stats.UnitDimensionless)
+	MeasureNodesNotOptedIn     = stats.Int64("draino/nodes_not_opted_in", "Number of nodes not scheduled for drain because they are not opted in to draining.", stats.UnitDimensionless)
+	MeasureDrainsAborted       = stats.Int64("draino/drains_aborted", "Number of drains aborted because their schedule was deleted.", stats.UnitDimensionless)
+	MeasureDrainsEscalated     = stats.Int64("draino/drains_escalated", "Number of drains escalated to a force drain on their final attempt.", stats.UnitDimensionless)
+	MeasureSchedulesRejected   = stats.Int64("draino/schedules_rejected", "Number of drains not scheduled because the scheduler holds the maximum number of schedules.", stats.UnitDimensionless)
+	MeasureFailedDrainActions  = stats.Int64("draino/failed_drain_actions", "Number of failed drain policy actions applied to nodes whose drain failed.", stats.UnitDimensionless)
+	MeasureDailyLimitRefusals  = stats.Int64("draino/daily_limit_refusals", "Number of drains not scheduled because their node reached the daily drain limit.", stats.UnitDimensionless)
+	MeasurePDBConflictsAvoided = stats.Int64("draino/pdb_conflicts_avoided", "Number of drains deferred because a drain in progress evicts pods of the same PodDisruptionBudget.", stats.UnitDimensionless)
+	MeasureStaleEvents         = stats.Int64("draino/stale_events", "Number of node events whose condition transitioned after the drain they scheduled.", stats.UnitDimensionless)
+	MeasureDrainsEnabled       = stats.Int64("draino/drains_enabled", "Whether drains are enabled by the drain switch ConfigMap.", stats.UnitDimensionless)
+	MeasureCircuitBreakerOpen  = stats.Int64("draino/circuit_breaker_open", "Whether drains are paused because too many recent drain attempts failed.", stats.UnitDimensionless)
+	MeasurePDBBlocks           = stats.Int64("draino/pdb_blocks", "Number of evictions refused by each PodDisruptionBudget.", stats.UnitDimensionless)
+	MeasureEvictionBackoffs    = stats.Int64("draino/eviction_backoffs", "Number of evictions refused with 429 Too Many Requests and retried.", stats.UnitDimensionless)
+	MeasureEvictionTimeouts    = stats.Int64("draino/eviction_call_timeouts", "Number of eviction API calls that timed out and were retried.", stats.UnitDimensionless)
+	MeasurePodsRemoved         = stats.Int64("draino/pods_removed", "Number of pods removed from drained nodes.", stats.UnitDimensionless)
+	MeasurePodsSkipped         = stats.Int64("draino/pods_skipped", "Number of pods skipped by the eviction filter.", stats.UnitDimensionless)
+	MeasureZoneWindowDrains    = stats.Int64("draino/zone_window_drains", "Number of recent drains of the nodes of a zone, within the zone drain window.", stats.UnitDimensionless)
+	MeasurePeakTerminatingPods = stats.Int64("draino/peak_terminating_pods", "Largest number of pods of a node being removed at once during its last drain.", stats.UnitDimensionless)
+	MeasureMemoryFreed         = stats.Int64("draino/memory_freed", "Allocatable memory of the nodes whose drain completed.", stats.UnitBytes)
+	MeasureWindowPodEvictions  = stats.Int64("draino/window_pod_evictions", "Number of pods evicted by recent drains, within the pod eviction budget window.", stats.UnitDimensionless)
+	MeasureDrainPermits        = stats.Int64("draino/drain_permits", "Number of drain permits left in the current replenishment interval.", stats.UnitDimensionless)
+	MeasureNodeSnapshotLookups = stats.Int64("draino/node_snapshot_lookups", "Number of lookups of the node snapshot shared by scheduling constraints, by hit or miss.", stats.UnitDimensionless)
+	MeasureCordonRetries       = stats.Int64("draino/cordon_retries", "Number of cordons retried after failing transiently.", stats.UnitDimensionless)
+	MeasurePodsByOwnerKind     = stats.Int64("draino/pods_by_owner_kind", "Number of pods drained from nodes, by the kind of their controller.", stats.UnitDimensionless)
+	MeasureDisallowedReasons   = stats.Int64("draino/disallowed_reasons", "Number of drains not scheduled because the reason that triggered them is not allowed.", stats.UnitDimensionless)
+	MeasureDrainConfirmations  = stats.Int64("draino/drain_confirmations", "Number of drained nodes checked for their expected end state, by result.", stats.UnitDimensionless)
+	MeasureEvictionsDeferred   = stats.Int64("draino/evictions_deferred", "Number of evictions of the last ready replica of a workload deferred because its replacement would not fit in the cluster.", stats.UnitDimensionless)
+	MeasureNamespaceEvictions  = stats.Int64("draino/namespace_evictions_in_flight", "Number of pods of a namespace being removed at once, across drains.", stats.UnitDimensionless)
+	MeasureReentrantDrains     = stats.Int64("draino/reentrant_drains", "Number of drains that fired for nodes draino had already cordoned.", stats.UnitDimensionless)
+	MeasureSchedulesEvicted    = stats.Int64("draino/failed_schedules_evicted", "Number of failed schedules deleted because too many failed schedules were retained.", stats.UnitDimensionless)
+	MeasureReasonsMerged       = stats.Int64("draino/drain_reasons_merged", "Number of reasons merged into the existing drain schedules of nodes.", stats.UnitDimensionless)
+	MeasureMisplacedReplicas   = stats.Int64("draino/replacements_on_draining_nodes", "Number of replacements of evicted pods that landed on nodes also being drained.", stats.UnitDimensionless)
+	MeasureBlockedByConstraint = stats.Int64("draino/drains_blocked_by_constraint", "Number of pending drains currently blocked on a constraint.", stats.UnitDimensionless)
+	MeasurePendingPods         = stats.Int64("draino/cluster_pending_pods", "Number of pods of the cluster waiting to be scheduled, when drains last fired.", stats.UnitDimensionless)
+	MeasureCrashLoopsCleared   = stats.Int64("draino/crashloop_pods_cleared", "Number of crashlooping pods fast pathed out of drained nodes.", stats.UnitDimensionless)
+	MeasurePodsNamespaceGone   = stats.Int64("draino/pods_namespace_terminating", "Number of pods assumed gone because their namespace was being deleted while they were evicted.", stats.UnitDimensionless)
+
+	MeasurePreDrainCapacityWait = stats.Float64("draino/pre_drain_capacity_wait", "Time spent waiting for replacement capacity before draining.", stats.UnitSeconds)
+	MeasureDrainDuration        = stats.Float64("draino/drain_duration", "Time spent draining nodes.", stats.UnitSeconds)
+	MeasureScheduleLatency      = stats.Float64("draino/schedule_latency", "Time between the transition of the offending condition of a node and the scheduling of its drain.", stats.UnitSeconds)
+	MeasureClusterDisruption    = stats.Float64("draino/cluster_disruption", "Percentage of the pods of the cluster being evicted by the drains in progress.", stats.UnitDimensionless)
+	MeasureDrainThroughput      = stats.Float64("draino/drain_throughput", "Number of drains completed per minute within the last hour.", stats.UnitDimensionless)
+	MeasureCPUFreed             = stats.Float64("draino/cpu_freed", "Allocatable CPU cores of the nodes whose drain completed.", stats.UnitDimensionless)
+	MeasureEffectiveDrainPeriod = stats.Float64("draino/effective_drain_period", "Minimum time between starting each drain, after throttling.", stats.UnitSeconds)
+	MeasureNodeEvictionRate     = stats.Float64("draino/node_eviction_rate", "Number of pods of a node removed per second during its last drain.", stats.UnitDimensionless)
+	MeasureInterPodDelay        = stats.Float64("draino/inter_pod_delay", "Total time the pod removals of the last drain of a node waited for the minimum delay between them.", stats.UnitSeconds)
+
+	MeasureOldestPendingScheduleAge = stats.Float64("draino/oldest_pending_schedule_age", "Time since the oldest schedule whose drain has not started yet was created.", stats.UnitSeconds)
 
 	TagNodeName, _ = tag.NewKey("node_name")
 	TagResult, _   = tag.NewKey("result")
+	TagReason, _   = tag.NewKey("reason")
+	TagPhase, _    = tag.NewKey("phase")
+	TagZone, _     = tag.NewKey("zone")
+	TagAction, _   = tag.NewKey("action")
+
+	TagConstraint, _ = tag.NewKey("constraint")
+
+	TagOwnerKind, _ = tag.NewKey("owner_kind")
+
+	TagNamespace, _ = tag.NewKey("namespace")
+	TagPDB, _       = tag.NewKey("pdb")
+
+	TagInstanceType, _   = tag.NewKey("instance_type")
+	TagKubeletVersion, _ = tag.NewKey("kubelet_version")
 )
 
 // A DrainingResourceEventHandler cordons and drains any added or updated nodes.
@@ -83,6 +276,17 @@ type DrainingResourceEventHandler struct {
 	buffer                time.Duration
 
 	conditions []SuppliedCondition
+
+	scheduleOptions []DrainSchedulesOption
+	eventReasons    EventReasons
+
+	// recheckStore is used to get the current state of nodes when their drain
+	// fires. Drains are not rechecked if it is nil.
+	recheckStore NodeStore
+
+	// preCordonHooks run, in order, before nodes are cordoned.
+	preCordonHooks   []PreCordonHook
+	preCordonTimeout time.Duration
 }
 
 // DrainingResourceEventHandlerOption configures an DrainingResourceEventHandler.
@@ -110,6 +314,31 @@ func WithConditionsFilter(conditions []string) DrainingResourceEventHandlerOptio
 	}
 }
 
+// WithDrainSchedulesOptions configures the drain scheduler used by the
+// DrainingResourceEventHandler.
+func WithDrainSchedulesOptions(o ...DrainSchedulesOption) DrainingResourceEventHandlerOption {
+	return func(h *DrainingResourceEventHandler) {
+		h.scheduleOptions = append(h.scheduleOptions, o...)
+	}
+}
+
+// WithEventReasons configures the reasons of the events recorded when
+// cordoning, uncordoning and draining nodes.
+func WithEventReasons(r EventReasons) DrainingResourceEventHandlerOption {
+	return func(h *DrainingResourceEventHandler) {
+		h.eventReasons = r.withDefaults()
+	}
+}
+
+// WithRecheckBeforeDrain configures the handler to recheck the conditions of
+// nodes, as returned by the supplied store, when their drain fires. Drains of
+// nodes that recovered since they were scheduled are skipped.
+func WithRecheckBeforeDrain(s NodeStore) DrainingResourceEventHandlerOption {
+	return func(h *DrainingResourceEventHandler) {
+		h.recheckStore = s
+	}
+}
+
 // NewDrainingResourceEventHandler returns a new DrainingResourceEventHandler.
 func NewDrainingResourceEventHandler(d CordonDrainer, e record.EventRecorder, ho ...DrainingResourceEventHandlerOption) *DrainingResourceEventHandler {
 	h := &DrainingResourceEventHandler{
@@ -118,14 +347,33 @@ func NewDrainingResourceEventHandler(d CordonDrainer, e record.EventRecorder, ho
 		eventRecorder:         e,
 		lastDrainScheduledFor: time.Now(),
 		buffer:                DefaultDrainBuffer,
+		eventReasons:          DefaultEventReasons,
 	}
 	for _, o := range ho {
 		o(h)
 	}
-	h.drainScheduler = NewDrainSchedules(d, e, h.buffer, h.logger)
+	opts := append([]DrainSchedulesOption{WithScheduleEventReasons(h.eventReasons)}, h.scheduleOptions...)
+	if h.recheckStore != nil {
+		opts = append(opts, WithDrainEligibilityCheck(h.stillEligible))
+	}
+	h.drainScheduler = NewDrainSchedules(d, e, h.buffer, h.logger, opts...)
 	return h
 }
 
+// ReconcileFromNodes recreates the drain schedules of the supplied nodes from
+// their DrainScheduled condition. It should be called before handling nodes
+// on startup, so that pending drains are not rescheduled.
+func (h *DrainingResourceEventHandler) ReconcileFromNodes(nodes []*core.Node) {
+	h.drainScheduler.ReconcileFromNodes(nodes)
+}
+
+// ScheduleAPI returns the drain scheduler of the handler as a ScheduleAPI, if
+// it is one.
+func (h *DrainingResourceEventHandler) ScheduleAPI() (ScheduleAPI, bool) {
+	api, ok := h.drainScheduler.(ScheduleAPI)
+	return api, ok
+}
+
 // OnAdd cordons and drains the added node.
 func (h *DrainingResourceEventHandler) OnAdd(obj interface{}, _isInitialList bool) {
 	n, ok := obj.(*core.Node)
@@ -189,6 +437,9 @@ func (h *DrainingResourceEventHandler) HandleNode(n *core.Node) {
 
 	// First cordon the node if it is not yet cordonned
 	if !n.Spec.Unschedulable {
+		if !h.runPreCordonHooks(n) {
+			return
+		}
 		h.cordon(n, badConditions)
 	}
 
@@ -199,18 +450,28 @@ func (h *DrainingResourceEventHandler) HandleNode(n *core.Node) {
 		return
 	} else {
 		var isScheduledByOldEvent bool = false
+		var staleTransition time.Time
 		for _, c := range badConditions {
 			transitionTime, exist := getTransitionTime(n, c.Type)
 			if exist {
 				isScheduledByOldEvent = h.drainScheduler.IsScheduledByOldEvent(n.GetName(), transitionTime)
+				staleTransition = transitionTime
 			}
 		}
 		if isScheduledByOldEvent {
+			h.drainScheduler.RecordStaleEvent(n.GetName(), staleTransition)
 			h.logger.Info("Already scheduled by an old event, scheduling new drain.", zap.String("node", n.GetName()), zap.Bool("isValid", isScheduledByOldEvent))
 			h.drainScheduler.DeleteSchedule(n.GetName())
 			h.scheduleDrain(n)
 			return
 		}
+		if m, ok := h.drainScheduler.(ReasonMerger); ok {
+			reasons := make([]string, 0, len(badConditions))
+			for _, c := range badConditions {
+				reasons = append(reasons, string(c.Type))
+			}
+			m.MergeReasons(n.GetName(), reasons)
+		}
 	}
 
 	// Is there a request to retry a failed drain activity. If yes reschedule drain
@@ -221,6 +482,30 @@ func (h *DrainingResourceEventHandler) HandleNode(n *core.Node) {
 	}
 }
 
+// stillEligible returns true unless the current state of the supplied node no
+// longer has any of the offending conditions. Nodes whose current state cannot
+// be determined are considered eligible.
+func (h *DrainingResourceEventHandler) stillEligible(n *core.Node) bool {
+	fresh, err := h.recheckStore.Get(n.GetName())
+	if err != nil {
+		h.logger.Info("Cannot recheck node before drain", zap.String("node", n.GetName()), zap.Error(err))
+		return true
+	}
+	return len(h.offendingConditions(fresh)) > 0
+}
+
+// firstTransition returns the time the earliest of the offending conditions of
+// the supplied node transitioned, or the zero time if it has none.
+func (h *DrainingResourceEventHandler) firstTransition(n *core.Node) time.Time {
+	var first time.Time
+	for _, c := range h.offendingConditions(n) {
+		if t, ok := getTransitionTime(n, c.Type); ok && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+	return first
+}
+
 func getTransitionTime(n *core.Node, conditionType core.NodeConditionType) (time.Time, bool) {
 	for _, nodeCondition := range n.Status.Conditions {
 		if nodeCondition.Type == conditionType {
@@ -281,18 +566,18 @@ func (h *DrainingResourceEventHandler) uncordon(n *core.Node) {
 	nr := &core.ObjectReference{Kind: "Node", Name: n.GetName(), UID: types.UID(n.GetName())}
 
 	log.Debug("Uncordoning")
-	h.eventRecorder.Event(nr, core.EventTypeWarning, eventReasonUncordonStarting, "Uncordoning node")
+	h.eventRecorder.Event(nr, core.EventTypeWarning, h.eventReasons.UncordonStarting, "Uncordoning node")
 	if err := h.cordonDrainer.Uncordon(n, removeAnnotationMutator); err != nil {
 		log.Info("Failed to uncordon", zap.Error(err))
 		tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultFailed)) // nolint:gosec
 		stats.Record(tags, MeasureNodesUncordoned.M(1))
-		h.eventRecorder.Eventf(nr, core.EventTypeWarning, eventReasonUncordonFailed, "Uncordoning failed: %v", err)
+		h.eventRecorder.Eventf(nr, core.EventTypeWarning, h.eventReasons.UncordonFailed, "Uncordoning failed: %v", err)
 		return
 	}
 	log.Info("Uncordoned")
 	tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultSucceeded)) // nolint:gosec
 	stats.Record(tags, MeasureNodesUncordoned.M(1))
-	h.eventRecorder.Event(nr, core.EventTypeWarning, eventReasonUncordonSucceeded, "Uncordoned node")
+	h.eventRecorder.Event(nr, core.EventTypeWarning, h.eventReasons.UncordonSucceeded, "Uncordoned node")
 }
 
 func removeAnnotationMutator(n *core.Node) {
@@ -309,18 +594,33 @@ func (h *DrainingResourceEventHandler) cordon(n *core.Node, badConditions []Supp
 	nr := &core.ObjectReference{Kind: "Node", Name: n.GetName(), UID: types.UID(n.GetName())}
 
 	log.Debug("Cordoning")
-	h.eventRecorder.Event(nr, core.EventTypeWarning, eventReasonCordonStarting, "Cordoning node")
-	if err := h.cordonDrainer.Cordon(n, conditionAnnotationMutator(badConditions)); err != nil {
+	h.eventRecorder.Event(nr, core.EventTypeWarning, h.eventReasons.CordonStarting, "Cordoning node")
+	if err := h.cordonNode(n, badConditions); err != nil {
 		log.Info("Failed to cordon", zap.Error(err))
 		tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultFailed)) // nolint:gosec
 		stats.Record(tags, MeasureNodesCordoned.M(1))
-		h.eventRecorder.Eventf(nr, core.EventTypeWarning, eventReasonCordonFailed, "Cordoning failed: %v", err)
+		h.eventRecorder.Eventf(nr, core.EventTypeWarning, h.eventReasons.CordonFailed, "Cordoning failed: %v", err)
 		return
 	}
 	log.Info("Cordoned")
 	tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultSucceeded)) // nolint:gosec
 	stats.Record(tags, MeasureNodesCordoned.M(1))
-	h.eventRecorder.Event(nr, core.EventTypeWarning, eventReasonCordonSucceeded, "Cordoned node")
+	h.eventRecorder.Event(nr, core.EventTypeWarning, h.eventReasons.CordonSucceeded, "Cordoned node")
+}
+
+// cordonNode cordons the supplied node, recording the offending conditions as
+// the reason for the cordon if the cordoner supports it.
+func (h *DrainingResourceEventHandler) cordonNode(n *core.Node, badConditions []SuppliedCondition) error {
+	mutator := conditionAnnotationMutator(badConditions)
+	c, ok := h.cordonDrainer.(ReasonCordoner)
+	if !ok {
+		return h.cordonDrainer.Cordon(n, mutator)
+	}
+	reasons := make([]string, 0, len(badConditions))
+	for _, bc := range badConditions {
+		reasons = append(reasons, string(bc.Type))
+	}
+	return c.CordonWithReason(n, "Node conditions: "+strings.Join(reasons, ", "), Component, mutator)
 }
 
 func conditionAnnotationMutator(conditions []SuppliedCondition) func(*core.Node) {
@@ -342,21 +642,57 @@ func (h *DrainingResourceEventHandler) scheduleDrain(n *core.Node) {
 	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, n.GetName())) // nolint:gosec
 	nr := &core.ObjectReference{Kind: "Node", Name: n.GetName(), UID: types.UID(n.GetName())}
 	log.Debug("Scheduling drain")
-	when, err := h.drainScheduler.Schedule(n)
+	when, err := h.schedule(n)
 	if err != nil {
 		if IsAlreadyScheduledError(err) {
 			return
 		}
+		if IsDisallowedReasonError(err) {
+			log.Warn("Not scheduling a drain triggered by a disallowed reason", zap.Error(err))
+			h.eventRecorder.Eventf(nr, core.EventTypeWarning, h.eventReasons.DrainSchedulingFailed, "Drain scheduling refused: %v", err)
+			return
+		}
+		if IsNodeTooYoungError(err) {
+			log.Info("Not scheduling the drain of a young node", zap.Error(err))
+			return
+		}
+		if IsNotOptedInError(err) {
+			log.Info("Not scheduling the drain of a node not opted in", zap.Error(err))
+			return
+		}
+		if IsDailyLimitError(err) {
+			log.Info("Not scheduling the drain of a node drained too often today", zap.Error(err))
+			return
+		}
+		if IsPDBWouldBlockError(err) {
+			log.Info("Not scheduling the drain of a node whose evictions would be blocked", zap.Error(err))
+			h.eventRecorder.Eventf(nr, core.EventTypeWarning, h.eventReasons.DrainDeferred, "Drain not scheduled: %v", err)
+			return
+		}
 		log.Info("Failed to schedule the drain activity", zap.Error(err))
 		tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultFailed)) // nolint:gosec
 		stats.Record(tags, MeasureNodesDrainScheduled.M(1))
-		h.eventRecorder.Eventf(nr, core.EventTypeWarning, eventReasonDrainSchedulingFailed, "Drain scheduling failed: %v", err)
+		h.eventRecorder.Eventf(nr, core.EventTypeWarning, h.eventReasons.DrainSchedulingFailed, "Drain scheduling failed: %v", err)
 		return
 	}
 	log.Info("Drain scheduled ", zap.Time("after", when))
 	tags, _ = tag.New(tags, tag.Upsert(TagResult, tagResultSucceeded)) // nolint:gosec
 	stats.Record(tags, MeasureNodesDrainScheduled.M(1))
-	h.eventRecorder.Eventf(nr, core.EventTypeWarning, eventReasonDrainScheduled, "Will drain node after %s", when.Format(time.RFC3339Nano))
+	h.eventRecorder.Eventf(nr, core.EventTypeWarning, h.eventReasons.DrainScheduled, "Will drain node after %s", when.Format(time.RFC3339Nano))
+}
+
+// schedule schedules the drain of the supplied node, on behalf of its first
+// offending condition if the scheduler is a ReasonScheduler.
+func (h *DrainingResourceEventHandler) schedule(n *core.Node) (time.Time, error) {
+	rs, ok := h.drainScheduler.(ReasonScheduler)
+	if !ok {
+		return h.drainScheduler.ScheduleWithTransition(n, h.firstTransition(n))
+	}
+	var reason string
+	if conditions := h.offendingConditions(n); len(conditions) > 0 {
+		reason = string(conditions[0].Type)
+	}
+	return rs.ScheduleWithReason(n, reason, h.firstTransition(n))
 }
 
 func HasDrainRetryAnnotation(n *core.Node) bool {
@@ -17,10 +17,13 @@ and limitations under the License.
 package kubernetes
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"k8s.io/client-go/tools/record"
 
 	core "k8s.io/api/core/v1"
@@ -60,7 +63,7 @@ func (d *mockCordonDrainer) Drain(n *core.Node) error {
 	return nil
 }
 
-func (d *mockCordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, failed bool) error {
+func (d *mockCordonDrainer) MarkDrain(n *core.Node, when, finish time.Time, failed bool, reason string) error {
 	d.calls = append(d.calls, mockCall{
 		name: "MarkDrain",
 		node: n.Name,
@@ -76,6 +79,19 @@ func (d *mockCordonDrainer) HasSchedule(name string) (has, failed bool) {
 	return false, false
 }
 
+func (d *mockCordonDrainer) FailureReason(name string) (reason string, failed bool) {
+	d.calls = append(d.calls, mockCall{
+		name: "FailureReason",
+		node: name,
+	})
+	return "", false
+}
+
+func (d *mockCordonDrainer) HasSchedules(names []string) map[string]ScheduleStatus {
+	d.calls = append(d.calls, mockCall{name: "HasSchedules"})
+	return map[string]ScheduleStatus{}
+}
+
 func (d *mockCordonDrainer) IsScheduledByOldEvent(name string, transitionTime time.Time) bool {
 	d.calls = append(d.calls, mockCall{
 		name: "IsScheduledByOldEvent",
@@ -84,6 +100,13 @@ func (d *mockCordonDrainer) IsScheduledByOldEvent(name string, transitionTime ti
 	return true
 }
 
+func (d *mockCordonDrainer) RecordStaleEvent(name string, transitionTime time.Time) {
+	d.calls = append(d.calls, mockCall{
+		name: "RecordStaleEvent",
+		node: name,
+	})
+}
+
 func (d *mockCordonDrainer) Schedule(node *core.Node) (time.Time, error) {
 	d.calls = append(d.calls, mockCall{
 		name: "Schedule",
@@ -92,6 +115,42 @@ func (d *mockCordonDrainer) Schedule(node *core.Node) (time.Time, error) {
 	return time.Now(), nil
 }
 
+func (d *mockCordonDrainer) ScheduleWithKey(node *core.Node, key string) (time.Time, error) {
+	d.calls = append(d.calls, mockCall{
+		name: "ScheduleWithKey",
+		node: node.Name,
+	})
+	return time.Now(), nil
+}
+
+func (d *mockCordonDrainer) ScheduleWithTransition(node *core.Node, transitionTime time.Time) (time.Time, error) {
+	d.calls = append(d.calls, mockCall{
+		name: "ScheduleWithTransition",
+		node: node.Name,
+	})
+	return time.Now(), nil
+}
+
+func (d *mockCordonDrainer) AnyInProgress() bool {
+	d.calls = append(d.calls, mockCall{name: "AnyInProgress"})
+	return false
+}
+
+func (d *mockCordonDrainer) InProgressNodes() []string {
+	d.calls = append(d.calls, mockCall{name: "InProgressNodes"})
+	return nil
+}
+
+func (d *mockCordonDrainer) IsDraining(name string) bool {
+	d.calls = append(d.calls, mockCall{name: "IsDraining", node: name})
+	return false
+}
+
+func (d *mockCordonDrainer) State(name string) DrainState {
+	d.calls = append(d.calls, mockCall{name: "State", node: name})
+	return DrainStateNone
+}
+
 func (d *mockCordonDrainer) DeleteSchedule(name string) {
 	d.calls = append(d.calls, mockCall{
 		name: "DeleteSchedule",
@@ -99,6 +158,10 @@ func (d *mockCordonDrainer) DeleteSchedule(name string) {
 	})
 }
 
+func (d *mockCordonDrainer) ReconcileFromNodes(nodes []*core.Node) {
+	d.calls = append(d.calls, mockCall{name: "ReconcileFromNodes"})
+}
+
 func TestDrainingResourceEventHandler(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -142,7 +205,7 @@ func TestDrainingResourceEventHandler(t *testing.T) {
 			expected: []mockCall{
 				{name: "Cordon", node: nodeName},
 				{name: "HasSchedule", node: nodeName},
-				{name: "Schedule", node: nodeName},
+				{name: "ScheduleWithTransition", node: nodeName},
 			},
 		},
 		{
@@ -160,7 +223,7 @@ func TestDrainingResourceEventHandler(t *testing.T) {
 			},
 			expected: []mockCall{
 				{name: "HasSchedule", node: nodeName},
-				{name: "Schedule", node: nodeName},
+				{name: "ScheduleWithTransition", node: nodeName},
 			},
 		},
 		{
@@ -216,7 +279,7 @@ func TestDrainingResourceEventHandler(t *testing.T) {
 			},
 			expected: []mockCall{
 				{name: "HasSchedule", node: nodeName},
-				{name: "Schedule", node: nodeName},
+				{name: "ScheduleWithTransition", node: nodeName},
 			},
 		},
 	}
@@ -371,3 +434,125 @@ func TestOffendingConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestEventReasonsWithDefaults(t *testing.T) {
+	got := EventReasons{DrainFailed: "MyDrainFailed"}.withDefaults()
+	want := DefaultEventReasons
+	want.DrainFailed = "MyDrainFailed"
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("withDefaults(): want %#v, got %#v", want, got)
+	}
+}
+
+func TestDrainingResourceEventHandlerEventReasons(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	h := NewDrainingResourceEventHandler(&NoopCordonDrainer{}, recorder,
+		WithConditionsFilter([]string{"KernelPanic"}),
+		WithEventReasons(EventReasons{CordonStarting: "MyCordonStarting"}))
+	h.drainScheduler = &mockCordonDrainer{}
+	h.OnUpdate(nil, &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Status: core.NodeStatus{
+			Conditions: []core.NodeCondition{{Type: "KernelPanic", Status: core.ConditionTrue}},
+		},
+	})
+
+	for _, want := range []string{"Warning MyCordonStarting Cordoning node", "Warning CordonSucceeded Cordoned node"} {
+		if got := <-recorder.Events; got != want {
+			t.Errorf("event: want %q, got %q", want, got)
+		}
+	}
+}
+
+// nodeStore is a NodeStore backed by a map of nodes.
+type nodeStore map[string]*core.Node
+
+func (s nodeStore) Get(name string) (*core.Node, error) {
+	n, ok := s[name]
+	if !ok {
+		return nil, errors.Errorf("node %s does not exist", name)
+	}
+	return n, nil
+}
+
+func TestDrainingResourceEventHandlerRecheckBeforeDrain(t *testing.T) {
+	sick := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: "sick"},
+		Status: core.NodeStatus{
+			Conditions: []core.NodeCondition{{Type: "KernelPanic", Status: core.ConditionTrue}},
+		},
+	}
+	recovered := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: "recovered"},
+		Status: core.NodeStatus{
+			Conditions: []core.NodeCondition{{Type: "KernelPanic", Status: core.ConditionFalse}},
+		},
+	}
+	h := NewDrainingResourceEventHandler(&NoopCordonDrainer{}, &record.FakeRecorder{},
+		WithConditionsFilter([]string{"KernelPanic"}),
+		WithRecheckBeforeDrain(nodeStore{"sick": sick, "recovered": recovered}))
+
+	cases := map[string]bool{"sick": true, "recovered": false, "missing": true}
+	for name, want := range cases {
+		if got := h.stillEligible(&core.Node{ObjectMeta: meta.ObjectMeta{Name: name}}); got != want {
+			t.Errorf("stillEligible(%s): want %v, got %v", name, want, got)
+		}
+	}
+	if h.drainScheduler.(*DrainSchedules).eligibilityCheck == nil {
+		t.Errorf("drain scheduler does not recheck nodes before draining")
+	}
+}
+
+func TestDrainingResourceEventHandlerPreCordonHooks(t *testing.T) {
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Status: core.NodeStatus{
+			Conditions: []core.NodeCondition{{Type: "KernelPanic", Status: core.ConditionTrue}},
+		},
+	}
+	cases := []struct {
+		name      string
+		hookErr   error
+		wantHooks []string
+		wantCalls []mockCall
+	}{
+		{
+			name:      "Succeeded",
+			wantHooks: []string{"deregister", "notify"},
+			wantCalls: []mockCall{
+				{name: "Cordon", node: nodeName},
+				{name: "HasSchedule", node: nodeName},
+				{name: "ScheduleWithTransition", node: nodeName},
+			},
+		},
+		{
+			name:      "Failed",
+			hookErr:   errors.New("load balancer unavailable"),
+			wantHooks: []string{"deregister"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var hooks []string
+			hook := func(name string, err error) PreCordonHook {
+				return func(_ context.Context, n *core.Node) error {
+					hooks = append(hooks, name)
+					return err
+				}
+			}
+			m := &mockCordonDrainer{}
+			h := NewDrainingResourceEventHandler(m, &record.FakeRecorder{},
+				WithConditionsFilter([]string{"KernelPanic"}),
+				WithPreCordonHooks(time.Second, hook("deregister", tc.hookErr), hook("notify", nil)))
+			h.drainScheduler = m
+			h.OnUpdate(nil, node)
+
+			if !reflect.DeepEqual(hooks, tc.wantHooks) {
+				t.Errorf("hooks: want %v, got %v", tc.wantHooks, hooks)
+			}
+			if !reflect.DeepEqual(m.calls, tc.wantCalls) {
+				t.Errorf("calls: want %v, got %v", tc.wantCalls, m.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Expedite fires the pending drain of the named node now, rather than when it
+// is scheduled for, regardless of the period between drains. The drain is
+// still deferred when it fires if it otherwise would be, for example while its
+// node group is paused. It returns a NotPendingError if the node has no
+// schedule, or its drain is in progress, succeeded or failed.
+func (d *DrainSchedules) Expedite(name string) error {
+	d.Lock()
+	sched, ok := d.schedules[name]
+	if !ok {
+		d.Unlock()
+		return NewNotPendingError(name)
+	}
+	switch d.drainStateLocked(name, sched) {
+	case DrainStateInProgress, DrainStateSucceeded, DrainStateFailed:
+		d.Unlock()
+		return NewNotPendingError(name)
+	}
+	when := d.now()
+	sched.when = when
+	sched.timer.Reset(0)
+	d.Unlock()
+
+	d.logger.Info("Expediting drain", zap.String("node", name), zap.String("drainID", sched.drainID))
+	sched.addSpanEvent("expedited")
+	if err := d.markDrain(sched.node, DrainStateScheduled, when, time.Time{}, ""); err != nil {
+		d.logger.Info("Failed to mark expedited drain scheduled", zap.String("node", name), zap.Error(err))
+	}
+	d.setDrainState(sched.node, DrainStateScheduled, when, time.Time{}, "")
+	return nil
+}
+
+type NotPendingError struct {
+	error
+}
+
+func NewNotPendingError(name string) error {
+	return &NotPendingError{
+		fmt.Errorf("node %s has no pending drain schedule", name),
+	}
+}
+
+func IsNotPendingError(err error) bool {
+	_, ok := err.(*NotPendingError)
+	return ok
+}
@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_Expedite(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Hour, zap.NewNop(),
+		WithDispatcher(dispatcher),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	for _, name := range []string{"b", "a"} {
+		if _, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s): %v", name, err)
+		}
+	}
+	var nodes []string
+	for _, info := range scheduler.ListSchedules() {
+		if info.State != DrainStateScheduled {
+			t.Errorf("ListSchedules(): want %s scheduled, got %s", info.Node, info.State)
+		}
+		nodes = append(nodes, info.Node)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("ListSchedules(): want nodes %v, got %v", want, nodes)
+	}
+
+	if err := scheduler.Expedite("missing"); !IsNotPendingError(err) {
+		t.Errorf("Expedite(missing): want NotPendingError, got %v", err)
+	}
+
+	// b was scheduled two hours from now, and drains as soon as expedited.
+	if err := scheduler.Expedite("b"); err != nil {
+		t.Fatalf("Expedite(b): %v", err)
+	}
+	info, ok := scheduler.ScheduleInfo("b")
+	if !ok {
+		t.Fatal("ScheduleInfo(b): want schedule, got none")
+	}
+	if !info.When.Equal(start) {
+		t.Errorf("ScheduleInfo(b).When: want %s, got %s", start, info.When)
+	}
+	dispatcher.ProcessDue(start)
+	if got, want := drainer.nodes(), []string{"b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("drained: want %v, got %v", want, got)
+	}
+	if info, _ := scheduler.ScheduleInfo("b"); info.State != DrainStateSucceeded || info.Attempt != 1 {
+		t.Errorf("ScheduleInfo(b): want succeeded after 1 attempt, got %s after %d", info.State, info.Attempt)
+	}
+
+	// Drains that already happened cannot be expedited.
+	if err := scheduler.Expedite("b"); !IsNotPendingError(err) {
+		t.Errorf("Expedite(b): want NotPendingError, got %v", err)
+	}
+}
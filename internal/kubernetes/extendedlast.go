@@ -0,0 +1,41 @@
+package kubernetes
+
+import (
+	"strings"
+
+	core "k8s.io/api/core/v1"
+)
+
+// WithExtendedResourcesLast determines whether Drain evicts the pods requesting
+// extended resources, such as GPUs or FPGAs, after all others, since they are
+// often the hardest to reschedule and other drains or scale ups may free such
+// capacity meanwhile. These pods are evicted in the order configured for the
+// others, once those are all gone. The evict first pods still precede them.
+func WithExtendedResourcesLast(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.extendedResourcesLast = b
+	}
+}
+
+// requestsExtendedResources returns true if a container of the supplied pod
+// requests or limits an extended resource.
+func requestsExtendedResources(p core.Pod) bool {
+	containers := append(append([]core.Container{}, p.Spec.InitContainers...), p.Spec.Containers...)
+	for _, c := range containers {
+		for _, l := range []core.ResourceList{c.Resources.Requests, c.Resources.Limits} {
+			for name := range l {
+				if isExtendedResource(name) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isExtendedResource returns true if the supplied resource is an extended
+// resource, that is one qualified by a domain other than kubernetes.io.
+func isExtendedResource(name core.ResourceName) bool {
+	n := string(name)
+	return strings.Contains(n, "/") && !strings.Contains(n, core.ResourceDefaultNamespacePrefix) && !strings.HasPrefix(n, core.DefaultResourceRequestsPrefix)
+}
@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDrainExtendedResourcesLast(t *testing.T) {
+	gpu := func(name string) core.Pod {
+		p := newTestPod(name, 0, "1")
+		p.Spec.Containers[0].Resources.Limits = core.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			gpu("a-gpu"),
+			newTestPod("b", 0, "1"),
+			// Hugepages are not extended resources.
+			func() core.Pod {
+				p := newTestPod("c-hugepages", 0, "1")
+				p.Spec.Containers[0].Resources.Requests[core.ResourceHugePagesPrefix+"2Mi"] = resource.MustParse("2Mi")
+				return p
+			}(),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c, WithExtendedResourcesLast(true), WithDeterministicOrder(true))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if want, got := []string{"b", "c-hugepages", "a-gpu"}, evictedPods(c.(*fake.Clientset)); !reflect.DeepEqual(want, got) {
+		t.Errorf("evictions: want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// WithMaxFailedSchedules limits the number of failed schedules retained, so
+// that the schedules of chronically failing nodes do not accumulate. Once it
+// is exceeded, the schedules that failed the longest ago are deleted, after
+// the drain condition of their node was written. Their nodes keep their
+// DrainFailed condition, but may be scheduled again when next updated, as when
+// draino restarts. Pending and in progress schedules are not affected. Zero
+// disables the limit.
+func WithMaxFailedSchedules(max int) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.maxFailedSchedules = max
+	}
+}
+
+// evictFailedSchedules deletes the oldest failed schedules in excess of the
+// maximum number of failed schedules retained.
+func (d *DrainSchedules) evictFailedSchedules() {
+	if d.maxFailedSchedules <= 0 {
+		return
+	}
+	d.Lock()
+	var failed []string
+	for name, s := range d.schedules {
+		if s.isFailed() {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) <= d.maxFailedSchedules {
+		d.Unlock()
+		return
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		a, b := d.schedules[failed[i]], d.schedules[failed[j]]
+		if !a.finish.Equal(b.finish) {
+			return a.finish.Before(b.finish)
+		}
+		return failed[i] < failed[j]
+	})
+	evicted := failed[:len(failed)-d.maxFailedSchedules]
+	for _, name := range evicted {
+		d.deleteScheduleLocked(name, d.schedules[name])
+	}
+	d.Unlock()
+	d.saveState()
+	for _, name := range evicted {
+		d.logger.Info("Deleted failed schedule, too many failed schedules are retained", zap.String("node", name), zap.Int("maxFailedSchedules", d.maxFailedSchedules))
+		d.metrics.FailedScheduleEvicted(name)
+	}
+}
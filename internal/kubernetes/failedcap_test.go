@@ -0,0 +1,73 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type evictionMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	evicted []string
+}
+
+func (m *evictionMetrics) FailedScheduleEvicted(node string) {
+	m.Lock()
+	defer m.Unlock()
+	m.evicted = append(m.evicted, node)
+}
+
+func TestDrainSchedules_MaxFailedSchedules(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	m := &evictionMetrics{}
+	scheduler := NewDrainSchedules(&failDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithMetricsRecorder(m),
+		WithMaxFailedSchedules(2),
+	).(*DrainSchedules)
+
+	schedule := func(name string) *v1.Node {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		scheduler.schedules[name].timer.Stop()
+		return node
+	}
+	fail := func(node *v1.Node, at time.Time) {
+		dispatcher.ProcessDue(at)
+		scheduler.runDrain(node, scheduler.schedules[node.GetName()])
+	}
+
+	// The first node to fail is not the first by name.
+	pending := schedule("pending")
+	fail(schedule("c"), start.Add(time.Minute))
+	fail(schedule("a"), start.Add(2*time.Minute))
+	if len(m.evicted) != 0 {
+		t.Fatalf("FailedScheduleEvicted(): want no call within the limit, got %v", m.evicted)
+	}
+	fail(schedule("b"), start.Add(3*time.Minute))
+
+	if want := []string{"c"}; !reflect.DeepEqual(m.evicted, want) {
+		t.Errorf("FailedScheduleEvicted(): want %v, got %v", want, m.evicted)
+	}
+	if has, _ := scheduler.HasSchedule("c"); has {
+		t.Error("HasSchedule(c): want oldest failed schedule deleted, got scheduled")
+	}
+	for _, name := range []string{"a", "b"} {
+		if has, failed := scheduler.HasSchedule(name); !has || !failed {
+			t.Errorf("HasSchedule(%s): want failed schedule retained, got %v, %v", name, has, failed)
+		}
+	}
+	if has, _ := scheduler.HasSchedule(pending.GetName()); !has {
+		t.Error("HasSchedule(pending): want pending schedule retained, got none")
+	}
+}
@@ -0,0 +1,209 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// A FeasibilityScorer estimates how many of the pods of a node would have
+// nowhere to go if the node was drained.
+type FeasibilityScorer interface {
+	Unschedulable(ctx context.Context, n *core.Node) (int, error)
+}
+
+// A ClusterCapacityScorer is a FeasibilityScorer that fits the resource
+// requests of the pods of a node into the free capacity of the other ready,
+// schedulable nodes of the cluster. It does not account for taints, affinity
+// or any other scheduling constraint, and thus only gives an estimate.
+type ClusterCapacityScorer struct {
+	c     kubernetes.Interface
+	nodes NodeLister
+}
+
+// ClusterCapacityScorerOption configures a ClusterCapacityScorer.
+type ClusterCapacityScorerOption func(s *ClusterCapacityScorer)
+
+// WithScorerNodeLister configures the scorer to list nodes using the supplied
+// lister, typically a NodeSnapshot shared with other constraints, rather than
+// from the API server.
+func WithScorerNodeLister(l NodeLister) ClusterCapacityScorerOption {
+	return func(s *ClusterCapacityScorer) {
+		s.nodes = l
+	}
+}
+
+// NewClusterCapacityScorer returns a ClusterCapacityScorer that lists nodes
+// and pods using the supplied client.
+func NewClusterCapacityScorer(c kubernetes.Interface, so ...ClusterCapacityScorerOption) *ClusterCapacityScorer {
+	s := &ClusterCapacityScorer{c: c, nodes: NewAPINodeStore(c)}
+	for _, o := range so {
+		o(s)
+	}
+	return s
+}
+
+type freeCapacity struct {
+	cpu, memory int64
+}
+
+// Unschedulable returns the number of pods of the supplied node that do not
+// fit in the free capacity of the rest of the cluster. Mirror and DaemonSet
+// pods are not counted, since they are not rescheduled elsewhere.
+func (s *ClusterCapacityScorer) Unschedulable(ctx context.Context, n *core.Node) (int, error) {
+	nodes, err := s.nodes.ListNodes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pods, err := s.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot list pods")
+	}
+
+	free := freeCapacities(nodes, pods.Items, n.GetName())
+	var displaced []core.Pod
+	for _, p := range pods.Items {
+		if p.Spec.NodeName == n.GetName() && !completed(p) && rescheduled(p) {
+			displaced = append(displaced, p)
+		}
+	}
+
+	// Fit the largest pods first, each on the first node it fits on. Nodes
+	// are visited by name so that the estimate is deterministic.
+	names := make([]string, 0, len(free))
+	for name := range free {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sort.SliceStable(displaced, func(i, j int) bool {
+		ci, mi := podRequests(displaced[i])
+		cj, mj := podRequests(displaced[j])
+		if mi != mj {
+			return mi > mj
+		}
+		return ci > cj
+	})
+	unschedulable := 0
+	for _, p := range displaced {
+		cpu, memory := podRequests(p)
+		fit := false
+		for _, name := range names {
+			f := free[name]
+			if f.cpu >= cpu && f.memory >= memory {
+				f.cpu -= cpu
+				f.memory -= memory
+				fit = true
+				break
+			}
+		}
+		if !fit {
+			unschedulable++
+		}
+	}
+	return unschedulable, nil
+}
+
+// freeCapacities returns the free capacity of each of the supplied nodes that
+// is schedulable, other than the named one, once the requests of the supplied
+// pods running on them are accounted for.
+func freeCapacities(nodes []*core.Node, pods []core.Pod, exclude string) map[string]*freeCapacity {
+	free := map[string]*freeCapacity{}
+	for _, c := range nodes {
+		if c.GetName() == exclude || !schedulable(c) {
+			continue
+		}
+		free[c.GetName()] = &freeCapacity{
+			cpu:    c.Status.Allocatable.Cpu().MilliValue(),
+			memory: c.Status.Allocatable.Memory().Value(),
+		}
+	}
+	for _, p := range pods {
+		if completed(p) {
+			continue
+		}
+		if f, ok := free[p.Spec.NodeName]; ok {
+			cpu, memory := podRequests(p)
+			f.cpu -= cpu
+			f.memory -= memory
+		}
+	}
+	return free
+}
+
+// schedulable returns true if pods may be scheduled to the supplied node, i.e.
+// it is ready, not cordoned, and not about to be drained.
+func schedulable(n *core.Node) bool {
+	if n.Spec.Unschedulable || IsMarkedForDrain(n) {
+		return false
+	}
+	for _, c := range n.Status.Conditions {
+		if c.Type == core.NodeReady {
+			return c.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
+
+// rescheduled returns true if the supplied pod would be scheduled to another
+// node once evicted.
+func rescheduled(p core.Pod) bool {
+	if ok, _ := MirrorPodFilter(p); !ok {
+		return false
+	}
+	if c := meta.GetControllerOf(&p); c != nil && c.Kind == kindDaemonSet {
+		return false
+	}
+	return true
+}
+
+// WithFeasibilityScorer configures a scorer consulted before draining a node.
+// Drains that would leave pods unschedulable are deferred by
+// DefaultDrainDeferralPeriod, in favour of the drains of nodes whose pods can
+// be rescheduled, until the maximum deferral elapses.
+func WithFeasibilityScorer(s FeasibilityScorer) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.feasibilityScorer = s
+	}
+}
+
+// checkFeasibility returns true if the drain of the supplied schedule may
+// proceed according to the feasibility scorer, if any. Otherwise the drain is
+// deferred by DefaultDrainDeferralPeriod.
+func (d *DrainSchedules) checkFeasibility(node *core.Node, sched *schedule) bool {
+	if d.feasibilityScorer == nil {
+		return true
+	}
+	log := d.logger.With(zap.String("node", node.GetName()))
+	unschedulable, err := d.feasibilityScorer.Unschedulable(context.Background(), node)
+	if err != nil {
+		log.Info("Cannot estimate whether pods can be rescheduled, draining anyway", zap.Error(err))
+		return true
+	}
+	if unschedulable == 0 {
+		return true
+	}
+
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	if d.maxDeferral > 0 && !d.now().Before(sched.created.Add(d.maxDeferral)) {
+		log.Info("Force firing drain deferred for too long", zap.Int("unschedulablePods", unschedulable))
+		d.metrics.DrainForceFired(node.GetName())
+		sched.addSpanEvent("force fired", attribute.Int("unschedulablePods", unschedulable))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for %d pods to be schedulable elsewhere", sched.created.Format(time.RFC3339), unschedulable)
+		return true
+	}
+
+	log.Info("Deferring drain, pods would be unschedulable", zap.Int("unschedulablePods", unschedulable))
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "%d pods would be unschedulable", unschedulable)
+	sched.addSpanEvent("deferred", attribute.Int("unschedulablePods", unschedulable))
+	d.blockDrain(node.GetName(), deferralReasonInfeasible)
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	return false
+}
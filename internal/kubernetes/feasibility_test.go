@@ -0,0 +1,145 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func newCapacityNode(name, cpu string, ready bool) *core.Node {
+	status := core.ConditionTrue
+	if !ready {
+		status = core.ConditionFalse
+	}
+	return &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: name},
+		Status: core.NodeStatus{
+			Allocatable: core.ResourceList{
+				core.ResourceCPU:    resource.MustParse(cpu),
+				core.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			Conditions: []core.NodeCondition{{Type: core.NodeReady, Status: status}},
+		},
+	}
+}
+
+func newScheduledPod(name, node, cpu string) *core.Pod {
+	p := newTestPod(name, 0, cpu)
+	p.Spec.NodeName = node
+	return &p
+}
+
+func TestClusterCapacityScorer(t *testing.T) {
+	daemon := newScheduledPod("daemon", "draining", "8")
+	daemon.OwnerReferences = []meta.OwnerReference{{Kind: kindDaemonSet, Name: "d", Controller: &isController}}
+	done := newScheduledPod("done", "other", "3")
+	done.Status.Phase = core.PodSucceeded
+
+	cases := []struct {
+		name string
+		objs []runtime.Object
+		want int
+	}{
+		{
+			name: "AllFit",
+			objs: []runtime.Object{
+				newCapacityNode("draining", "4", true),
+				newCapacityNode("other", "4", true),
+				newScheduledPod("a", "draining", "2"),
+				newScheduledPod("b", "draining", "2"),
+				done,
+				daemon,
+			},
+		},
+		{
+			name: "OtherNodeBusy",
+			objs: []runtime.Object{
+				newCapacityNode("draining", "4", true),
+				newCapacityNode("other", "4", true),
+				newScheduledPod("a", "draining", "2"),
+				newScheduledPod("b", "draining", "2"),
+				newScheduledPod("busy", "other", "3"),
+			},
+			want: 2,
+		},
+		{
+			name: "OtherNodeNotReady",
+			objs: []runtime.Object{
+				newCapacityNode("draining", "4", true),
+				newCapacityNode("other", "4", false),
+				newScheduledPod("a", "draining", "1"),
+			},
+			want: 1,
+		},
+		{
+			name: "LargestFirst",
+			objs: []runtime.Object{
+				newCapacityNode("draining", "4", true),
+				newCapacityNode("other", "3", true),
+				newScheduledPod("small", "draining", "1"),
+				newScheduledPod("large", "draining", "3"),
+			},
+			want: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewClusterCapacityScorer(fake.NewSimpleClientset(tc.objs...))
+			got, err := s.Unschedulable(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: "draining"}})
+			if err != nil {
+				t.Fatalf("s.Unschedulable(): %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("s.Unschedulable(): want %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+type fixedScorer int
+
+func (s fixedScorer) Unschedulable(_ context.Context, _ *core.Node) (int, error) {
+	return int(s), nil
+}
+
+func TestDrainSchedules_FeasibilityScorer(t *testing.T) {
+	cases := []struct {
+		name        string
+		scorer      fixedScorer
+		maxDeferral time.Duration
+		wantDrain   bool
+	}{
+		{name: "Feasible", scorer: 0, wantDrain: true},
+		{name: "Unschedulable", scorer: 2},
+		{name: "DeferredTooLong", scorer: 2, maxDeferral: time.Nanosecond, wantDrain: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := newRecordingDrainer()
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+				WithFeasibilityScorer(tc.scorer), WithMaxDeferral(tc.maxDeferral)).(*DrainSchedules)
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+
+			scheduler.runDrain(node, sched)
+			if got := len(drainer.nodes()) == 1; got != tc.wantDrain {
+				t.Errorf("drained: want %v, got %v", tc.wantDrain, got)
+			}
+			sched.timer.Stop()
+		})
+	}
+}
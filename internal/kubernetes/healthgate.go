@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultHealthGateTimeout bounds each call of a HealthGate.
+	DefaultHealthGateTimeout = 10 * time.Second
+
+	deferralReasonUnhealthy = "health-gate"
+)
+
+// A HealthGate returns true while the cluster is healthy enough for drains to
+// proceed, for example while the replacement capacity is healthy, as
+// determined by an external health check.
+type HealthGate func(ctx context.Context) (healthy bool, err error)
+
+// NewHTTPHealthGate returns a HealthGate that is healthy while a GET of the
+// supplied URL succeeds with a 2xx status.
+func NewHTTPHealthGate(url string) HealthGate {
+	c := &http.Client{Timeout: DefaultHealthGateTimeout}
+	return func(ctx context.Context) (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return false, errors.Wrap(err, "cannot create health check request")
+		}
+		rsp, err := c.Do(req)
+		if err != nil {
+			return false, errors.Wrap(err, "cannot check health")
+		}
+		rsp.Body.Close() // nolint:errcheck,gosec
+		return rsp.StatusCode >= http.StatusOK && rsp.StatusCode < http.StatusMultipleChoices, nil
+	}
+}
+
+// WithHealthGate consults the supplied gate when drains fire, and defers them
+// while it reports that the cluster is unhealthy, or fails, so that drains do
+// not proceed while their pods could not be safely rescheduled. The gate is
+// global to the cluster, rather than consulted per node. Deferred drains wait
+// the supplied backoff, which doubles each time the gate is still unhealthy up
+// to the supplied maximum, and fire regardless once the maximum deferral
+// elapses. The backoff defaults to DefaultDrainDeferralPeriod if not positive,
+// and does not grow if the maximum is below it.
+func WithHealthGate(g HealthGate, backoff, max time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if backoff <= 0 {
+			backoff = DefaultDrainDeferralPeriod
+		}
+		if max < backoff {
+			max = backoff
+		}
+		d.healthGate = g
+		d.healthBackoff = backoff
+		d.maxHealthBackoff = max
+	}
+}
+
+// deferUnhealthy returns true if the drain of the supplied schedule is
+// deferred because the health gate reports that the cluster is unhealthy.
+func (d *DrainSchedules) deferUnhealthy(node *core.Node, sched *schedule) bool {
+	if d.healthGate == nil {
+		return false
+	}
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	ctx, cancel := context.WithTimeout(sched.spanContext(), DefaultHealthGateTimeout)
+	healthy, err := d.healthGate(ctx)
+	cancel()
+	if err != nil {
+		log.Info("Cannot check cluster health, assuming unhealthy", zap.Error(err))
+	}
+	d.Lock()
+	defer d.Unlock()
+	if healthy && err == nil {
+		sched.unhealthy = 0
+		return false
+	}
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	if d.maxDeferral > 0 && !d.now().Before(sched.created.Add(d.maxDeferral)) {
+		log.Info("Force firing drain deferred for too long", zap.String("reason", deferralReasonUnhealthy))
+		d.metrics.DrainForceFired(node.GetName())
+		sched.addSpanEvent("force fired", attribute.String("reason", deferralReasonUnhealthy))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for the cluster to be healthy", sched.created.Format(time.RFC3339))
+		return false
+	}
+	backoff := d.healthBackoff << uint(sched.unhealthy)
+	if backoff >= d.maxHealthBackoff {
+		backoff = d.maxHealthBackoff
+	} else {
+		sched.unhealthy++
+	}
+	log.Info("Deferring drain, the cluster is unhealthy", zap.Duration("backoff", backoff))
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "The cluster is unhealthy, retrying in %s", backoff)
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonUnhealthy))
+	d.recordDeferral(node.GetName(), deferralReasonUnhealthy)
+	sched.timer.Reset(backoff)
+	return true
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_HealthGate(t *testing.T) {
+	var healthy bool
+	var gateErr error
+	m := &deferralMetrics{}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithHealthGate(func(context.Context) (bool, error) { return healthy, gateErr }, time.Minute, 10*time.Minute),
+		WithMetricsRecorder(m),
+	).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+
+	// Drains are deferred while the cluster is unhealthy, or its health
+	// cannot be checked.
+	for _, err := range []error{nil, errors.New("unavailable")} {
+		gateErr = err
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+		if got := drainer.nodes(); len(got) != 0 {
+			t.Fatalf("drained %v while the cluster is unhealthy", got)
+		}
+	}
+	if want := []string{nodeName + "=" + deferralReasonUnhealthy, nodeName + "=" + deferralReasonUnhealthy}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+	if sched.unhealthy != 2 {
+		t.Errorf("unhealthy: want 2 deferrals backed off, got %d", sched.unhealthy)
+	}
+
+	healthy, gateErr = true, nil
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{nodeName}) {
+		t.Errorf("drained nodes once healthy: want %v, got %v", []string{nodeName}, got)
+	}
+	if sched.unhealthy != 0 {
+		t.Errorf("unhealthy: want reset, got %d", sched.unhealthy)
+	}
+}
+
+func TestHTTPHealthGate(t *testing.T) {
+	status := http.StatusOK
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer endpoint.Close()
+
+	gate := NewHTTPHealthGate(endpoint.URL)
+	if healthy, err := gate(context.Background()); err != nil || !healthy {
+		t.Errorf("gate(): want healthy, got %v, %v", healthy, err)
+	}
+	status = http.StatusServiceUnavailable
+	if healthy, err := gate(context.Background()); err != nil || healthy {
+		t.Errorf("gate(): want unhealthy, got %v, %v", healthy, err)
+	}
+}
@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"sync"
+	"time"
+)
+
+// Default drain history settings.
+const (
+	DefaultDrainHistorySize     = 10
+	DefaultDrainHistoryMaxNodes = 1000
+)
+
+// A DrainRecord describes the outcome of a past drain.
+type DrainRecord struct {
+	DrainID   string
+	Scheduled time.Time
+	Started   time.Time
+	Finished  time.Time
+	Failed    bool
+	Error     string
+}
+
+// drainHistory remembers the last outcomes of the drains of each node. It keeps
+// at most perNode records for each node and forgets the least recently drained
+// nodes once more than maxNodes are tracked.
+type drainHistory struct {
+	sync.Mutex
+	perNode  int
+	maxNodes int
+	records  map[string][]DrainRecord
+	// order lists the tracked nodes, least recently drained first.
+	order []string
+}
+
+func newDrainHistory(perNode, maxNodes int) *drainHistory {
+	return &drainHistory{
+		perNode:  perNode,
+		maxNodes: maxNodes,
+		records:  map[string][]DrainRecord{},
+	}
+}
+
+func (h *drainHistory) add(name string, r DrainRecord) {
+	if h.perNode <= 0 {
+		return
+	}
+	h.Lock()
+	defer h.Unlock()
+
+	records := append(h.records[name], r)
+	if len(records) > h.perNode {
+		records = append([]DrainRecord{}, records[len(records)-h.perNode:]...)
+	}
+	h.records[name] = records
+
+	for i, n := range h.order {
+		if n == name {
+			h.order = append(h.order[:i], h.order[i+1:]...)
+			break
+		}
+	}
+	h.order = append(h.order, name)
+	for h.maxNodes > 0 && len(h.order) > h.maxNodes {
+		delete(h.records, h.order[0])
+		h.order = h.order[1:]
+	}
+}
+
+func (h *drainHistory) get(name string) []DrainRecord {
+	h.Lock()
+	defer h.Unlock()
+	return append([]DrainRecord{}, h.records[name]...)
+}
@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainHistory(t *testing.T) {
+	h := newDrainHistory(2, 2)
+	h.add("a", DrainRecord{DrainID: "1"})
+	h.add("a", DrainRecord{DrainID: "2"})
+	h.add("a", DrainRecord{DrainID: "3"})
+	if want, got := []DrainRecord{{DrainID: "2"}, {DrainID: "3"}}, h.get("a"); !reflect.DeepEqual(want, got) {
+		t.Errorf("history of a: want %#v, got %#v", want, got)
+	}
+
+	h.add("b", DrainRecord{DrainID: "4"})
+	h.add("a", DrainRecord{DrainID: "5"})
+	// b is now the least recently drained node and is forgotten first.
+	h.add("c", DrainRecord{DrainID: "6"})
+	if got := h.get("b"); len(got) != 0 {
+		t.Errorf("history of b should have been evicted, got %#v", got)
+	}
+	if want, got := []DrainRecord{{DrainID: "3"}, {DrainID: "5"}}, h.get("a"); !reflect.DeepEqual(want, got) {
+		t.Errorf("history of a: want %#v, got %#v", want, got)
+	}
+	if want, got := []DrainRecord{{DrainID: "6"}}, h.get("c"); !reflect.DeepEqual(want, got) {
+		t.Errorf("history of c: want %#v, got %#v", want, got)
+	}
+}
+
+func TestDrainSchedules_History(t *testing.T) {
+	scheduler := NewDrainSchedules(&failDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+		}
+		sched := scheduler.schedules[node.Name]
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+		scheduler.DeleteSchedule(node.Name)
+	}
+
+	history := scheduler.History(node.Name)
+	if len(history) != 2 {
+		t.Fatalf("want 2 records, got %d", len(history))
+	}
+	for _, r := range history {
+		if !r.Failed || r.Error != "myerr" || r.Finished.IsZero() {
+			t.Errorf("unexpected record %#v", r)
+		}
+	}
+}
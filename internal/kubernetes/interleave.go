@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// WithGroupInterleaving interleaves the drains of the node groups, per
+// WithNodeGroupLabel, that have drains pending, rather than draining the nodes
+// in the order their drains were scheduled, which typically exhausts one group
+// before the next. When a drain fires while another group with pending drains
+// had its turn less recently, the drain swaps its slot with the next pending
+// drain of that group, so that disruption is spread evenly across groups.
+func WithGroupInterleaving() DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.interleaveGroups = true
+	}
+}
+
+// yieldTurn returns true if the drain of the supplied schedule yields its turn
+// to the next pending drain of a group whose turn came less recently. The
+// yielding drain is rescheduled for the slot of the drain it yields to, which
+// fires at once. Otherwise the group of the schedule takes its turn.
+func (d *DrainSchedules) yieldTurn(node *v1.Node, sched *schedule) bool {
+	if !d.interleaveGroups {
+		return false
+	}
+	d.Lock()
+	defer d.Unlock()
+	now := d.now()
+	var next *schedule
+	for name, s := range d.schedules {
+		if s.group == sched.group || s.blocked || s.isFailed() || !s.finish.IsZero() || !s.when.After(now) {
+			continue
+		}
+		if _, draining := d.inProgress[name]; draining {
+			continue
+		}
+		turn := d.groupTurns[s.group]
+		if turn >= d.groupTurns[sched.group] {
+			continue
+		}
+		if next == nil || turn < d.groupTurns[next.group] || (turn == d.groupTurns[next.group] && s.when.Before(next.when)) {
+			next = s
+		}
+	}
+	// The drain yielded to must not have fired meanwhile.
+	if next == nil || !next.timer.Stop() {
+		d.groupTurn++
+		d.groupTurns[sched.group] = d.groupTurn
+		return false
+	}
+	d.logger.Info("Yielding drain turn to another node group", zap.String("node", node.GetName()), zap.String("group", sched.group), zap.String("to", next.group))
+	sched.addSpanEvent("yielded", attribute.String("group", next.group))
+	sched.when, next.when = next.when, now
+	next.timer.Reset(0)
+	sched.timer.Reset(sched.when.Sub(now))
+	return true
+}
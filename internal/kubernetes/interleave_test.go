@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_GroupInterleaving(t *testing.T) {
+	cases := []struct {
+		name       string
+		interleave bool
+		want       []string
+	}{
+		{
+			name: "Sequential",
+			want: []string{"a1", "a2", "a3", "b1", "b2", "b3", "c1", "c2", "c3"},
+		},
+		{
+			name:       "Interleaved",
+			interleave: true,
+			want:       []string{"a1", "b1", "c1", "a2", "b2", "c2", "a3", "b3", "c3"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			dispatcher := NewManualDispatcher(start)
+			opts := []DrainSchedulesOption{WithDispatcher(dispatcher), WithNodeGroupLabel("group")}
+			if tc.interleave {
+				opts = append(opts, WithGroupInterleaving())
+			}
+			drainer := newRecordingDrainer()
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Hour, zap.NewNop(), opts...).(*DrainSchedules)
+			scheduler.lastDrainScheduledFor = start
+
+			// The nodes of each group are scheduled one group after the
+			// other.
+			for _, group := range []string{"a", "b", "c"} {
+				for _, i := range []string{"1", "2", "3"} {
+					node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: group + i, Labels: map[string]string{"group": group}}}
+					if _, err := scheduler.Schedule(node); err != nil {
+						t.Fatalf("DrainSchedules.Schedule(%s) error = %v", node.GetName(), err)
+					}
+				}
+			}
+			for i := 1; i <= len(tc.want); i++ {
+				dispatcher.ProcessDue(start.Add(time.Duration(i) * time.Hour))
+			}
+			if got := drainer.nodes(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("drained nodes: want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
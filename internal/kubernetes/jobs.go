@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// A JobPodPolicy is how Drain handles the pods owned by Jobs.
+type JobPodPolicy string
+
+// Job pod policies.
+const (
+	// JobPodEvict evicts Job pods like any other pod. This is the default.
+	JobPodEvict JobPodPolicy = "evict"
+	// JobPodWait waits for Job pods to complete before evicting them, so
+	// that nearly finished batch work is not restarted from scratch.
+	JobPodWait JobPodPolicy = "wait"
+)
+
+const (
+	// DefaultJobPodTimeout is how long Drain waits for Job pods to complete
+	// when WithJobPodPolicy is configured to wait without a timeout.
+	DefaultJobPodTimeout = 10 * time.Minute
+
+	jobPodPollPeriod = 1 * time.Second
+)
+
+// WithJobPodPolicy configures how Drain handles the pods owned by Jobs. With
+// JobPodWait, Drain waits for up to the supplied timeout for them to complete
+// before evicting them, or DefaultJobPodTimeout when the timeout is zero.
+func WithJobPodPolicy(policy JobPodPolicy, timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		if timeout <= 0 {
+			timeout = DefaultJobPodTimeout
+		}
+		d.jobPodPolicy = policy
+		d.jobPodTimeout = timeout
+	}
+}
+
+// jobOwned returns true if the supplied pod is controlled by a Job.
+func jobOwned(p core.Pod) bool {
+	c := meta.GetControllerOf(&p)
+	return c != nil && c.Kind == "Job" && (c.APIVersion == "batch/v1" || c.APIVersion == "batch/v1beta1")
+}
+
+// jobWaitTimeout returns how long Drain may wait for Job pods to complete
+// before evicting them.
+func (d *APICordonDrainer) jobWaitTimeout() time.Duration {
+	if d.jobPodPolicy != JobPodWait {
+		return 0
+	}
+	return d.jobPodTimeout
+}
+
+// awaitJobCompletion waits for the supplied pod to complete, or be gone, if it
+// is owned by a Job and the Job pod policy is to wait. Pods that are still
+// running once the Job pod timeout elapsed are evicted nonetheless.
+func (d *APICordonDrainer) awaitJobCompletion(ctx context.Context, p core.Pod) error {
+	if d.jobWaitTimeout() <= 0 || !jobOwned(p) || completed(p) {
+		return nil
+	}
+	period := d.jobPollPeriod
+	if period <= 0 {
+		period = jobPodPollPeriod
+	}
+	d.l.Info("Waiting for Job pod to complete", zap.String("pod", p.GetNamespace()+"/"+p.GetName()))
+	stop, cancel := context.WithTimeout(ctx, d.jobPodTimeout)
+	defer cancel()
+	err := wait.PollImmediateUntil(period, func() (bool, error) {
+		got, err := d.c.CoreV1().Pods(p.GetNamespace()).Get(ctx, p.GetName(), meta.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot get pod %s/%s", p.GetNamespace(), p.GetName())
+		}
+		return got.GetUID() != p.GetUID() || completed(*got), nil
+	}, stop.Done())
+	if err == wait.ErrWaitTimeout {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "pod eviction cancelled")
+		}
+		d.l.Info("Job pod did not complete, evicting it", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Duration("timeout", d.jobPodTimeout))
+		drainSummaryFrom(ctx).warn("%s/%s Job pod evicted before completing after %s", p.GetNamespace(), p.GetName(), d.jobPodTimeout)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,142 @@
+package kubernetes
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDrainJobPodPolicy(t *testing.T) {
+	isController := true
+	job := core.Pod{ObjectMeta: meta.ObjectMeta{
+		Namespace: "default",
+		Name:      podName,
+		UID:       "job-pod",
+		OwnerReferences: []meta.OwnerReference{
+			{APIVersion: "batch/v1", Kind: "Job", Name: "batch", Controller: &isController},
+		},
+	}}
+
+	cases := []struct {
+		name          string
+		policy        JobPodPolicy
+		wantCompleted bool
+	}{
+		{name: "Wait", policy: JobPodWait, wantCompleted: true},
+		{name: "Evict", policy: JobPodEvict},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			var (
+				mu          sync.Mutex
+				polls       int
+				evicted     bool
+				atEviction  core.PodPhase
+				currentPod  = job.DeepCopy()
+				completeAt  = 3
+				podNotFound = apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+			)
+			currentPod.Status.Phase = core.PodRunning
+			c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return true, &core.PodList{Items: []core.Pod{*currentPod}}, nil
+			})
+			// The Job pod completes after a few polls.
+			c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if evicted {
+					return true, nil, podNotFound
+				}
+				if polls++; polls >= completeAt {
+					currentPod.Status.Phase = core.PodSucceeded
+				}
+				return true, currentPod.DeepCopy(), nil
+			})
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if a.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				evicted, atEviction = true, currentPod.Status.Phase
+				return true, nil, nil
+			})
+
+			d := NewAPICordonDrainer(c, WithJobPodPolicy(tc.policy, time.Second))
+			d.jobPollPeriod = 10 * time.Millisecond
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+			if !evicted {
+				t.Fatalf("Job pod was not evicted")
+			}
+			if got := atEviction == core.PodSucceeded; got != tc.wantCompleted {
+				t.Errorf("Job pod completed before its eviction: want %v, got %v (phase %s)", tc.wantCompleted, got, atEviction)
+			}
+		})
+	}
+}
+
+func TestDrainJobPodTimeout(t *testing.T) {
+	isController := true
+	pod := core.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: "default",
+			Name:      podName,
+			OwnerReferences: []meta.OwnerReference{
+				{APIVersion: "batch/v1", Kind: "Job", Name: "batch", Controller: &isController},
+			},
+		},
+		Status: core.PodStatus{Phase: core.PodRunning},
+	}
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	var (
+		mu      sync.Mutex
+		evicted bool
+	)
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: []core.Pod{pod}}, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if evicted {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+		}
+		return true, pod.DeepCopy(), nil
+	})
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		evicted = true
+		return true, nil, nil
+	})
+
+	// The Job pod never completes, and is evicted once the timeout elapsed.
+	d := NewAPICordonDrainer(c, WithJobPodPolicy(JobPodWait, 50*time.Millisecond))
+	d.jobPollPeriod = 10 * time.Millisecond
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	if !evicted {
+		t.Errorf("Job pod was not evicted once the timeout elapsed")
+	}
+	summary, _ := d.DrainSummary(nodeName)
+	if len(summary.Warnings) != 1 {
+		t.Errorf("summary warnings: want 1, got %v", summary.Warnings)
+	}
+}
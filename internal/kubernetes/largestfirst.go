@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	"sort"
+
+	core "k8s.io/api/core/v1"
+)
+
+// WithLargestFirstOrder determines whether Drain evicts pods one at a time by
+// decreasing resource requests, summed across their containers, so that the
+// capacity of the node is freed as fast as possible. Pods are compared by
+// memory requests, then by CPU requests, then by namespace and name. This order
+// takes precedence over those configured by WithOwnerAwareOrder and
+// WithDeterministicOrder, but still applies after the pods evicted first and
+// within each startup cost.
+func WithLargestFirstOrder(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.largestFirstOrder = b
+	}
+}
+
+// largestFirstBatches splits the supplied pods into batches of one pod each, by
+// decreasing resource requests.
+func largestFirstBatches(pods []core.Pod) [][]core.Pod {
+	sortPods(pods)
+	sort.SliceStable(pods, func(i, j int) bool {
+		ci, mi := podRequests(pods[i])
+		cj, mj := podRequests(pods[j])
+		if mi != mj {
+			return mi > mj
+		}
+		return ci > cj
+	})
+	batches := make([][]core.Pod, 0, len(pods))
+	for _, p := range pods {
+		batches = append(batches, []core.Pod{p})
+	}
+	return batches
+}
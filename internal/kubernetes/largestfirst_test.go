@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDrainLargestFirstOrder(t *testing.T) {
+	sized := func(name, cpu, memory string) core.Pod {
+		p := newTestPod(name, 0, cpu)
+		p.Spec.Containers[0].Resources.Requests[core.ResourceMemory] = resource.MustParse(memory)
+		// Requests are summed across containers.
+		p.Spec.Containers = append(p.Spec.Containers, core.Container{Resources: core.ResourceRequirements{
+			Requests: core.ResourceList{core.ResourceMemory: resource.MustParse(memory)},
+		}})
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			sized("small", "1", "1Gi"),
+			sized("largest", "1", "8Gi"),
+			sized("busy", "4", "2Gi"),
+			sized("idle", "1", "2Gi"),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c, WithLargestFirstOrder(true), WithOwnerAwareOrder(true))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	// Pods of equal memory requests are ordered by CPU requests.
+	if want, got := []string{"largest", "busy", "idle", "small"}, evictedPods(c.(*fake.Clientset)); !reflect.DeepEqual(want, got) {
+		t.Errorf("evictions: want %v, got %v", want, got)
+	}
+}
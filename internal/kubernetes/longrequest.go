@@ -0,0 +1,47 @@
+package kubernetes
+
+import (
+	"strconv"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// DefaultMaxRequestAnnotation is the annotation of pods holding the duration,
+// in seconds, of the longest requests they serve.
+const DefaultMaxRequestAnnotation = "draino/max-request-seconds"
+
+// WithLongRequestGrace extends the grace period of the eviction of pods
+// serving long requests, such as streams or uploads, so that their requests
+// in flight may complete. The supplied annotation of such pods holds the
+// duration, in seconds, of their longest requests; their grace period is
+// extended to it, up to the supplied maximum. The extension may exceed
+// MaxGracePeriod, and the pod's own grace period; the eviction timeout is
+// raised to fit the maximum. Pods without a valid annotation keep their grace
+// period. An empty annotation, or a zero maximum, disables the extension.
+func WithLongRequestGrace(annotation string, max time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.maxRequestAnnotation = annotation
+		d.maxRequestGrace = max
+	}
+}
+
+// longRequestGrace returns the supplied grace period, in seconds, of the
+// eviction of the supplied pod, extended to the duration of its longest
+// requests.
+func (d *APICordonDrainer) longRequestGrace(p core.Pod, gracePeriod int64) int64 {
+	if d.maxRequestAnnotation == "" || d.maxRequestGrace <= 0 {
+		return gracePeriod
+	}
+	seconds, err := strconv.ParseInt(p.GetAnnotations()[d.maxRequestAnnotation], 10, 64)
+	if err != nil {
+		return gracePeriod
+	}
+	if max := int64(d.maxRequestGrace.Seconds()); seconds > max {
+		seconds = max
+	}
+	if seconds < gracePeriod {
+		return gracePeriod
+	}
+	return seconds
+}
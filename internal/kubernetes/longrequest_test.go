@@ -0,0 +1,63 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDrainLongRequestGrace(t *testing.T) {
+	pod := func(name, maxRequest string) core.Pod {
+		grace := int64(30)
+		p := core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       core.PodSpec{TerminationGracePeriodSeconds: &grace},
+		}
+		if maxRequest != "" {
+			p.SetAnnotations(map[string]string{DefaultMaxRequestAnnotation: maxRequest})
+		}
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			pod("upload", "120"),
+			pod("stream", "3600"),
+			pod("quick", "5"),
+			pod("invalid", "forever"),
+			pod("web", ""),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c,
+		MaxGracePeriod(time.Minute),
+		WithLongRequestGrace(DefaultMaxRequestAnnotation, 10*time.Minute))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	got := map[string]int64{}
+	for _, a := range c.(*fake.Clientset).Actions() {
+		if a.GetSubresource() == "eviction" {
+			e := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+			got[e.GetName()] = *e.DeleteOptions.GracePeriodSeconds
+		}
+	}
+	// Requests shorter than the grace period of the pod do not shorten it.
+	want := map[string]int64{"upload": 120, "stream": 600, "quick": 30, "invalid": 30, "web": 30}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grace periods: want %v, got %v", want, got)
+	}
+	if got, want := d.deleteTimeout(), 10*time.Minute+d.evictionHeadroom; got != want {
+		t.Errorf("deleteTimeout(): want %v, got %v", want, got)
+	}
+}
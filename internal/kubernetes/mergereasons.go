@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// A ReasonMerger merges the reasons of further requests to drain a node into
+// its existing drain schedule.
+type ReasonMerger interface {
+	// MergeReasons adds the supplied reasons to the drain schedule of the
+	// named node, returning those it did not carry yet.
+	MergeReasons(name string, reasons []string) []string
+}
+
+// WithReasonMerging merges the reasons of the requests to drain nodes that are
+// already scheduled, such as a second offending condition, into their
+// schedule rather than discarding them, so that the schedule carries every
+// reason its node was to be drained for. Merged reasons are recorded with a
+// DrainReasonsMerged event, and are accounted for by the post drain policy.
+func WithReasonMerging(merge bool) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.mergeReasons = merge
+	}
+}
+
+// MergeReasons adds the supplied reasons to the drain schedule of the named
+// node, if reasons are merged and it has a schedule, and returns those it did
+// not carry yet, sorted.
+func (d *DrainSchedules) MergeReasons(name string, reasons []string) []string {
+	if !d.mergeReasons {
+		return nil
+	}
+	d.Lock()
+	sched, ok := d.schedules[name]
+	if !ok {
+		d.Unlock()
+		return nil
+	}
+	has := make(map[string]bool, len(sched.reasons))
+	for _, r := range sched.reasons {
+		has[r] = true
+	}
+	var added []string
+	for _, r := range reasons {
+		if !has[r] {
+			has[r] = true
+			added = append(added, r)
+		}
+	}
+	if len(added) == 0 {
+		d.Unlock()
+		return nil
+	}
+	sort.Strings(added)
+	merged := append(append([]string(nil), sched.reasons...), added...)
+	sort.Strings(merged)
+	sched.reasons = merged
+	d.Unlock()
+
+	d.logger.Info("Merging drain reasons", zap.String("node", name), zap.String("drainID", sched.drainID), zap.Strings("added", added), zap.Strings("reasons", merged))
+	for _, r := range added {
+		d.metrics.DrainReasonMerged(name, r)
+	}
+	sched.addSpanEvent("reasons merged", attribute.StringSlice("reasons", added))
+	nr := &core.ObjectReference{Kind: "Node", Name: name, UID: types.UID(name)}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainReasonsMerged, "Drain also triggered by %s, now triggered by %s", strings.Join(added, ", "), strings.Join(merged, ", "))
+	return added
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type mergedMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	merged []string
+}
+
+func (m *mergedMetrics) DrainReasonMerged(node, reason string) {
+	m.Lock()
+	defer m.Unlock()
+	m.merged = append(m.merged, node+"="+reason)
+}
+
+func TestDrainSchedules_MergeReasons(t *testing.T) {
+	withConditions := func(types ...v1.NodeConditionType) *v1.Node {
+		n := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+		for _, c := range types {
+			n.Status.Conditions = append(n.Status.Conditions, v1.NodeCondition{Type: c, Status: v1.ConditionTrue})
+		}
+		return n
+	}
+	cases := []struct {
+		name        string
+		merge       bool
+		wantReasons []string
+		wantEvents  []string
+		wantMerged  []string
+	}{
+		{
+			name:        "Merged",
+			merge:       true,
+			wantReasons: []string{"KernelDeadlock", "OutOfDisk"},
+			wantEvents:  []string{"Warning DrainReasonsMerged Drain also triggered by OutOfDisk, now triggered by KernelDeadlock, OutOfDisk"},
+			wantMerged:  []string{nodeName + "=OutOfDisk"},
+		},
+		{
+			name:        "Discarded",
+			wantReasons: []string{"KernelDeadlock"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			m := &mergedMetrics{}
+			scheduler := NewDrainSchedules(newRecordingDrainer(), recorder, 0, zap.NewNop(),
+				WithMetricsRecorder(m),
+				WithReasonMerging(tc.merge),
+			).(*DrainSchedules)
+			if _, err := scheduler.Schedule(withConditions("KernelDeadlock")); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			defer scheduler.schedules[nodeName].timer.Stop()
+
+			// The same reason is only merged once.
+			for i := 0; i < 2; i++ {
+				if _, err := scheduler.Schedule(withConditions("OutOfDisk", "KernelDeadlock")); !IsAlreadyScheduledError(err) {
+					t.Fatalf("DrainSchedules.Schedule(): want AlreadyScheduledError, got %v", err)
+				}
+			}
+			if info, _ := scheduler.ScheduleInfo(nodeName); !reflect.DeepEqual(info.Reasons, tc.wantReasons) {
+				t.Errorf("reasons: want %v, got %v", tc.wantReasons, info.Reasons)
+			}
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			if !reflect.DeepEqual(events, tc.wantEvents) {
+				t.Errorf("events: want %v, got %v", tc.wantEvents, events)
+			}
+			if !reflect.DeepEqual(m.merged, tc.wantMerged) {
+				t.Errorf("DrainReasonMerged(): want %v, got %v", tc.wantMerged, m.merged)
+			}
+		})
+	}
+}
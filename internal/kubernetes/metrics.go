@@ -0,0 +1,505 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	v1 "k8s.io/api/core/v1"
+)
+
+// unknownInstanceType is the instance type of the nodes without the instance
+// type label.
+const unknownInstanceType = "unknown"
+
+// unknownKubeletVersion is the kubelet version of the nodes that do not report
+// one.
+const unknownKubeletVersion = "unknown"
+
+// A MetricsRecorder records the metrics of the drain scheduler.
+type MetricsRecorder interface {
+	// NodeDrained records the result of the drain of the named node, of the
+	// supplied instance type and kubelet version.
+	NodeDrained(node, instanceType, kubeletVersion, result string)
+	// DrainDuration records how long the drain of the named node took.
+	DrainDuration(node, result string, d time.Duration)
+	// DrainForceFired records a drain fired after being deferred too long.
+	DrainForceFired(node string)
+	// DrainDeferred records a drain deferred when it fired, for the supplied
+	// reason.
+	DrainDeferred(node, reason string)
+	// DrainAborted records a drain aborted because its schedule was deleted.
+	DrainAborted(node string)
+	// DrainEscalated records a drain escalated to a force drain on its final
+	// attempt.
+	DrainEscalated(node string)
+	// PreDrainCapacityWait records how long a drain waited for capacity.
+	PreDrainCapacityWait(node, result string, d time.Duration)
+	// NodeTooYoung records a node refused because it is too young to drain.
+	NodeTooYoung(node string)
+	// NodeNotOptedIn records a node refused because it is not opted in to
+	// draining.
+	NodeNotOptedIn(node string)
+	// EffectiveDrainPeriod records the period between drains, after
+	// throttling.
+	EffectiveDrainPeriod(p time.Duration)
+	// ScheduleLatency records how long after the transition of its offending
+	// condition the drain of the named node was scheduled.
+	ScheduleLatency(node string, d time.Duration)
+	// ZoneWindowDrains records the number of recent drains of the nodes of
+	// the supplied zone, within the zone drain window.
+	ZoneWindowDrains(zone string, n int)
+	// WindowPodEvictions records the number of pods evicted by recent
+	// drains, within the pod eviction budget window.
+	WindowPodEvictions(n int)
+	// DrainPermits records the number of drain permits left in the current
+	// interval.
+	DrainPermits(remaining int)
+	// ClusterDisruption records the percentage of the pods of the cluster
+	// being evicted by the drains in progress.
+	ClusterDisruption(percent float64)
+	// StaleEvent records a schedule of the named node older than the
+	// transition of its offending condition.
+	StaleEvent(node string)
+	// FailedDrainAction records the failed drain policy applied to the
+	// named node.
+	FailedDrainAction(node, action string)
+	// DailyLimitReached records a drain of the named node refused because
+	// of the daily drain limit.
+	DailyLimitReached(node string)
+	// PDBConflictAvoided records a drain of the named node deferred because
+	// a drain in progress evicts pods of the same PodDisruptionBudget.
+	PDBConflictAvoided(node string)
+	// ReasonDisallowed records a drain of the named node refused because the
+	// supplied reason that triggered it is not allowed.
+	ReasonDisallowed(node, reason string)
+	// DrainConfirmed records whether the named drained node reached its
+	// expected end state, with the supplied result.
+	DrainConfirmed(node, result string)
+	// DrainReentered records a drain of the named node that fired while the
+	// node was already cordoned by draino.
+	DrainReentered(node string)
+	// CircuitBreaker records whether the circuit breaker is open, pausing
+	// all drains.
+	CircuitBreaker(open bool)
+	// ResourcesFreed records the allocatable CPU cores and memory bytes of the
+	// named node once its drain completed with the supplied result.
+	ResourcesFreed(node, result string, cpu float64, memory int64)
+	// OldestPendingScheduleAge records how long ago the oldest schedule
+	// whose drain has not started yet was created.
+	OldestPendingScheduleAge(age time.Duration)
+	// SchedulerFull records a drain refused because the scheduler holds the
+	// maximum number of schedules.
+	SchedulerFull()
+	// FailedScheduleEvicted records the failed schedule of the named node
+	// deleted because too many failed schedules were retained.
+	FailedScheduleEvicted(node string)
+	// DrainReasonMerged records the supplied reason merged into the existing
+	// drain schedule of the named node.
+	DrainReasonMerged(node, reason string)
+	// DrainsBlocked records the number of pending drains currently blocked
+	// on the supplied constraint.
+	DrainsBlocked(constraint string, count int)
+	// PendingPods records the number of pods of the cluster waiting to be
+	// scheduled.
+	PendingPods(count int)
+	// DrainThroughput records the number of drains completed per minute
+	// within DefaultThroughputWindow.
+	DrainThroughput(perMinute float64)
+	// PodSkipped records a pod of the named node that was not evicted, for
+	// the supplied reason.
+	PodSkipped(node, reason string)
+	// DrainsEnabled records whether the drain switch enables drains.
+	DrainsEnabled(enabled bool)
+	// NodeSnapshotLookup records a lookup of a node snapshot, with the
+	// supplied hit or miss result.
+	NodeSnapshotLookup(result string)
+	// NamespaceEvictions records the number of evictions in flight of the
+	// supplied namespace.
+	NamespaceEvictions(namespace string, inFlight int)
+	// CrashLoopCleared records a crashlooping pod of the named node removed
+	// through the crashloop fast path.
+	CrashLoopCleared(node string)
+	// PodNamespaceGone records a pod of the named node treated as gone
+	// because its namespace is being deleted.
+	PodNamespaceGone(node string)
+	// CordonRetried records a cordon of the named node retried after a
+	// transient failure.
+	CordonRetried(node string)
+	// PodsByOwnerKind records the supplied number of pods of the supplied
+	// owner kind drained from the named node.
+	PodsByOwnerKind(node, kind string, n int)
+	// EvictionDeferred records the eviction of the last ready replica of a
+	// pod of the named node deferred because its replacement would not fit.
+	EvictionDeferred(node string)
+	// ReplicaMisplaced records a replacement of a pod evicted from the named
+	// node that landed on a node also being drained.
+	ReplicaMisplaced(node string)
+	// PDBBlocked records an eviction refused by the named
+	// PodDisruptionBudget of the supplied namespace.
+	PDBBlocked(namespace, name string)
+	// EvictionCallTimedOut records an eviction call for a pod of the named
+	// node abandoned and retried because it timed out.
+	EvictionCallTimedOut(node string)
+	// EvictionBackedOff records a refused eviction of a pod of the named node
+	// retried after backing off.
+	EvictionBackedOff(node string)
+	// PodRemoved records a pod of the named node removed in the supplied
+	// phase of its drain.
+	PodRemoved(node, phase string)
+	// PeakTerminatingPods records the most pods of the named node that were
+	// terminating at once during its drain.
+	PeakTerminatingPods(node string, n int)
+	// NodeEvictionRate records the eviction rate observed during the drain
+	// of the named node, in evictions per second.
+	NodeEvictionRate(node string, perSecond float64)
+	// InterPodDelay records the delay the minimum delay between evictions
+	// added to the drain of the named node.
+	InterPodDelay(node string, d time.Duration)
+}
+
+// OpenCensusMetricsRecorder records metrics using the process-global
+// opencensus stats. It is the default MetricsRecorder.
+type OpenCensusMetricsRecorder struct{}
+
+var _ MetricsRecorder = OpenCensusMetricsRecorder{}
+
+func (OpenCensusMetricsRecorder) NodeDrained(node, instanceType, kubeletVersion, result string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagInstanceType, instanceType), tag.Upsert(TagKubeletVersion, kubeletVersion), tag.Upsert(TagResult, result)) // nolint:gosec
+	stats.Record(tags, MeasureNodesDrained.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainDuration(node, result string, d time.Duration) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagResult, result)) // nolint:gosec
+	stats.Record(tags, MeasureDrainDuration.M(d.Seconds()))
+}
+
+func (OpenCensusMetricsRecorder) DrainForceFired(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureDrainsForceFired.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainDeferred(node, reason string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagReason, reason)) // nolint:gosec
+	stats.Record(tags, MeasureDrainsDeferred.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainAborted(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureDrainsAborted.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainEscalated(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureDrainsEscalated.M(1))
+}
+
+func (OpenCensusMetricsRecorder) PreDrainCapacityWait(node, result string, d time.Duration) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagResult, result)) // nolint:gosec
+	stats.Record(tags, MeasurePreDrainCapacityWait.M(d.Seconds()))
+}
+
+func (OpenCensusMetricsRecorder) NodeTooYoung(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureNodesTooYoung.M(1))
+}
+
+func (OpenCensusMetricsRecorder) NodeNotOptedIn(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureNodesNotOptedIn.M(1))
+}
+
+func (OpenCensusMetricsRecorder) EffectiveDrainPeriod(p time.Duration) {
+	stats.Record(context.Background(), MeasureEffectiveDrainPeriod.M(p.Seconds()))
+}
+
+func (OpenCensusMetricsRecorder) ScheduleLatency(node string, d time.Duration) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureScheduleLatency.M(d.Seconds()))
+}
+
+func (OpenCensusMetricsRecorder) ZoneWindowDrains(zone string, n int) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagZone, zone)) // nolint:gosec
+	stats.Record(tags, MeasureZoneWindowDrains.M(int64(n)))
+}
+
+func (OpenCensusMetricsRecorder) WindowPodEvictions(n int) {
+	stats.Record(context.Background(), MeasureWindowPodEvictions.M(int64(n)))
+}
+
+func (OpenCensusMetricsRecorder) DrainPermits(remaining int) {
+	stats.Record(context.Background(), MeasureDrainPermits.M(int64(remaining)))
+}
+
+func (OpenCensusMetricsRecorder) ClusterDisruption(percent float64) {
+	stats.Record(context.Background(), MeasureClusterDisruption.M(percent))
+}
+
+func (OpenCensusMetricsRecorder) DrainThroughput(perMinute float64) {
+	stats.Record(context.Background(), MeasureDrainThroughput.M(perMinute))
+}
+
+func (OpenCensusMetricsRecorder) PodSkipped(node, reason string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagReason, reason)) // nolint:gosec
+	stats.Record(tags, MeasurePodsSkipped.M(1))
+}
+
+func (OpenCensusMetricsRecorder) FailedDrainAction(node, action string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagAction, action)) // nolint:gosec
+	stats.Record(tags, MeasureFailedDrainActions.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DailyLimitReached(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureDailyLimitRefusals.M(1))
+}
+
+func (OpenCensusMetricsRecorder) PDBConflictAvoided(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasurePDBConflictsAvoided.M(1))
+}
+
+func (OpenCensusMetricsRecorder) ReasonDisallowed(node, reason string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagReason, reason)) // nolint:gosec
+	stats.Record(tags, MeasureDisallowedReasons.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainConfirmed(node, result string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagResult, result)) // nolint:gosec
+	stats.Record(tags, MeasureDrainConfirmations.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainReentered(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagResult, tagResultAlreadyCordonedByUs)) // nolint:gosec
+	stats.Record(tags, MeasureReentrantDrains.M(1))
+}
+
+func (OpenCensusMetricsRecorder) CircuitBreaker(open bool) {
+	var v int64
+	if open {
+		v = 1
+	}
+	stats.Record(context.Background(), MeasureCircuitBreakerOpen.M(v))
+}
+
+func (OpenCensusMetricsRecorder) DrainsEnabled(enabled bool) {
+	var v int64
+	if enabled {
+		v = 1
+	}
+	stats.Record(context.Background(), MeasureDrainsEnabled.M(v))
+}
+
+func (OpenCensusMetricsRecorder) NodeSnapshotLookup(result string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagResult, result)) // nolint:gosec
+	stats.Record(tags, MeasureNodeSnapshotLookups.M(1))
+}
+
+func (OpenCensusMetricsRecorder) ResourcesFreed(node, result string, cpu float64, memory int64) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagResult, result)) // nolint:gosec
+	stats.Record(tags, MeasureCPUFreed.M(cpu), MeasureMemoryFreed.M(memory))
+}
+
+func (OpenCensusMetricsRecorder) OldestPendingScheduleAge(age time.Duration) {
+	stats.Record(context.Background(), MeasureOldestPendingScheduleAge.M(age.Seconds()))
+}
+
+func (OpenCensusMetricsRecorder) SchedulerFull() {
+	stats.Record(context.Background(), MeasureSchedulesRejected.M(1))
+}
+
+func (OpenCensusMetricsRecorder) FailedScheduleEvicted(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureSchedulesEvicted.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainReasonMerged(node, reason string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagReason, reason)) // nolint:gosec
+	stats.Record(tags, MeasureReasonsMerged.M(1))
+}
+
+func (OpenCensusMetricsRecorder) DrainsBlocked(constraint string, count int) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagConstraint, constraint)) // nolint:gosec
+	stats.Record(tags, MeasureBlockedByConstraint.M(int64(count)))
+}
+
+func (OpenCensusMetricsRecorder) PendingPods(count int) {
+	stats.Record(context.Background(), MeasurePendingPods.M(int64(count)))
+}
+
+func (OpenCensusMetricsRecorder) StaleEvent(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureStaleEvents.M(1))
+}
+
+func (OpenCensusMetricsRecorder) NamespaceEvictions(namespace string, inFlight int) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNamespace, namespace)) // nolint:gosec
+	stats.Record(tags, MeasureNamespaceEvictions.M(int64(inFlight)))
+}
+
+func (OpenCensusMetricsRecorder) CrashLoopCleared(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureCrashLoopsCleared.M(1))
+}
+
+func (OpenCensusMetricsRecorder) PodNamespaceGone(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasurePodsNamespaceGone.M(1))
+}
+
+func (OpenCensusMetricsRecorder) CordonRetried(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureCordonRetries.M(1))
+}
+
+func (OpenCensusMetricsRecorder) PodsByOwnerKind(node, kind string, n int) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagOwnerKind, kind)) // nolint:gosec
+	stats.Record(tags, MeasurePodsByOwnerKind.M(int64(n)))
+}
+
+func (OpenCensusMetricsRecorder) EvictionDeferred(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureEvictionsDeferred.M(1))
+}
+
+func (OpenCensusMetricsRecorder) ReplicaMisplaced(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureMisplacedReplicas.M(1))
+}
+
+func (OpenCensusMetricsRecorder) PDBBlocked(namespace, name string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNamespace, namespace), tag.Upsert(TagPDB, name)) // nolint:gosec
+	stats.Record(tags, MeasurePDBBlocks.M(1))
+}
+
+func (OpenCensusMetricsRecorder) EvictionCallTimedOut(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureEvictionTimeouts.M(1))
+}
+
+func (OpenCensusMetricsRecorder) EvictionBackedOff(node string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureEvictionBackoffs.M(1))
+}
+
+func (OpenCensusMetricsRecorder) PodRemoved(node, phase string) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node), tag.Upsert(TagPhase, phase)) // nolint:gosec
+	stats.Record(tags, MeasurePodsRemoved.M(1))
+}
+
+func (OpenCensusMetricsRecorder) PeakTerminatingPods(node string, n int) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasurePeakTerminatingPods.M(int64(n)))
+}
+
+func (OpenCensusMetricsRecorder) NodeEvictionRate(node string, perSecond float64) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureNodeEvictionRate.M(perSecond))
+}
+
+func (OpenCensusMetricsRecorder) InterPodDelay(node string, d time.Duration) {
+	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, node)) // nolint:gosec
+	stats.Record(tags, MeasureInterPodDelay.M(d.Seconds()))
+}
+
+// WithInstanceTypeLabel configures the label holding the instance type of
+// nodes, used to break drain metrics down by instance type.
+func WithInstanceTypeLabel(label string) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.instanceTypeLabel = label
+	}
+}
+
+// instanceType returns the instance type of the supplied node, or
+// unknownInstanceType if it has none.
+func (d *DrainSchedules) instanceType(n *v1.Node) string {
+	if t, ok := n.GetLabels()[d.instanceTypeLabel]; ok && t != "" {
+		return t
+	}
+	return unknownInstanceType
+}
+
+// kubeletVersion returns the kubelet version reported by the supplied node, or
+// unknownKubeletVersion if it reports none.
+// recordResourcesFreed records the allocatable resources of the supplied node,
+// whose drain completed with the supplied result. Nodes without allocatable
+// resources record zero.
+func (d *DrainSchedules) recordResourcesFreed(n *v1.Node, result string) {
+	cpu := n.Status.Allocatable[v1.ResourceCPU]
+	memory := n.Status.Allocatable[v1.ResourceMemory]
+	d.metrics.ResourcesFreed(n.GetName(), result, float64(cpu.MilliValue())/1000, memory.Value())
+}
+
+func kubeletVersion(n *v1.Node) string {
+	if v := n.Status.NodeInfo.KubeletVersion; v != "" {
+		return v
+	}
+	return unknownKubeletVersion
+}
+
+// WithMetricsRecorder configures the recorder of the scheduler metrics, in
+// place of the process-global opencensus stats.
+func WithMetricsRecorder(m MetricsRecorder) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.metrics = m
+	}
+}
+
+// WithDrainerMetricsRecorder configures the recorder of the drain metrics, in
+// place of the process-global opencensus stats.
+func WithDrainerMetricsRecorder(m MetricsRecorder) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.metrics = m
+	}
+}
+
+// promLabelEscaper escapes label values per the Prometheus text format.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePromText writes the current schedules to the supplied writer in the
+// Prometheus text exposition format, as two gauges per scheduled node: the
+// seconds until its drain fires, zero once fired, and whether its drain
+// failed. It is a lightweight alternative to the opencensus views for per-node
+// series.
+//
+// Each scheduled node adds its own series, so the output grows with the number
+// of nodes being drained. Scrapers should expect series to appear and
+// disappear as schedules are created and deleted.
+func (d *DrainSchedules) WritePromText(w io.Writer) error {
+	d.Lock()
+	defer d.Unlock()
+
+	names := make([]string, 0, len(d.schedules))
+	for name := range d.schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := d.now()
+	var b strings.Builder
+	b.WriteString("# HELP draino_schedule_seconds_until_fire Seconds until the scheduled drain of the node fires.\n")
+	b.WriteString("# TYPE draino_schedule_seconds_until_fire gauge\n")
+	for _, name := range names {
+		until := d.schedules[name].when.Sub(now).Seconds()
+		if until < 0 {
+			until = 0
+		}
+		fmt.Fprintf(&b, "draino_schedule_seconds_until_fire{node=\"%s\"} %g\n", promLabelEscaper.Replace(name), until)
+	}
+	b.WriteString("# HELP draino_schedule_failed Whether the scheduled drain of the node failed.\n")
+	b.WriteString("# TYPE draino_schedule_failed gauge\n")
+	for _, name := range names {
+		failed := 0
+		if d.schedules[name].isFailed() {
+			failed = 1
+		}
+		fmt.Fprintf(&b, "draino_schedule_failed{node=\"%s\"} %d\n", promLabelEscaper.Replace(name), failed)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,329 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type recordingMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	drained   []string
+	instances []string
+	kubelets  []string
+	durations int
+	latencies []time.Duration
+	stale     []string
+	rates     []float64
+	freed     []string
+}
+
+func (m *recordingMetrics) NodeDrained(node, instanceType, kubeletVersion, result string) {
+	m.Lock()
+	defer m.Unlock()
+	m.drained = append(m.drained, node+"="+result)
+	m.instances = append(m.instances, instanceType)
+	m.kubelets = append(m.kubelets, kubeletVersion)
+}
+
+func (m *recordingMetrics) DrainDuration(node, result string, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.durations++
+}
+
+func (m *recordingMetrics) DrainThroughput(perMinute float64) {
+	m.Lock()
+	defer m.Unlock()
+	m.rates = append(m.rates, perMinute)
+}
+
+func (m *recordingMetrics) ResourcesFreed(node, result string, cpu float64, memory int64) {
+	m.Lock()
+	defer m.Unlock()
+	m.freed = append(m.freed, fmt.Sprintf("%s=%s,%g,%d", node, result, cpu, memory))
+}
+
+func (m *recordingMetrics) StaleEvent(node string) {
+	m.Lock()
+	defer m.Unlock()
+	m.stale = append(m.stale, node)
+}
+
+func TestDrainSchedules_MetricsRecorder(t *testing.T) {
+	cases := []struct {
+		name    string
+		drainer Drainer
+		want    []string
+	}{
+		{name: "Succeeded", drainer: &NoopCordonDrainer{}, want: []string{nodeName + "=" + tagResultSucceeded}},
+		{name: "Failed", drainer: &failDrainer{}, want: []string{nodeName + "=" + tagResultFailed}},
+		{name: "Noop", drainer: &emptyNodeDrainer{}, want: []string{nodeName + "=" + tagResultNoop}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &recordingMetrics{}
+			scheduler := NewDrainSchedules(tc.drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithMetricsRecorder(m)).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			if !reflect.DeepEqual(m.drained, tc.want) {
+				t.Errorf("NodeDrained: want %v, got %v", tc.want, m.drained)
+			}
+			if m.durations != 1 {
+				t.Errorf("DrainDuration: want 1 call, got %d", m.durations)
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_InstanceTypeMetrics(t *testing.T) {
+	cases := []struct {
+		name   string
+		label  string
+		labels map[string]string
+		want   string
+	}{
+		{name: "Labelled", labels: map[string]string{v1.LabelInstanceTypeStable: "m5.large"}, want: "m5.large"},
+		{name: "Unlabelled", want: unknownInstanceType},
+		{name: "CustomLabel", label: "example.org/flavor", labels: map[string]string{"example.org/flavor": "big"}, want: "big"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &recordingMetrics{}
+			opts := []DrainSchedulesOption{WithMetricsRecorder(m)}
+			if tc.label != "" {
+				opts = append(opts, WithInstanceTypeLabel(tc.label))
+			}
+			scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(), opts...).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Labels: tc.labels}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			if !reflect.DeepEqual(m.instances, []string{tc.want}) {
+				t.Errorf("NodeDrained: want instance type %v, got %v", tc.want, m.instances)
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_KubeletVersionMetrics(t *testing.T) {
+	cases := []struct {
+		name      string
+		version   string
+		want      string
+		wantEvent string
+	}{
+		{name: "Reported", version: "v1.27.3", want: "v1.27.3", wantEvent: "Warning DrainStarting Draining node running kubelet v1.27.3"},
+		{name: "Unreported", want: unknownKubeletVersion, wantEvent: "Warning DrainStarting Draining node"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &recordingMetrics{}
+			recorder := record.NewFakeRecorder(10)
+			scheduler := NewDrainSchedules(&NoopCordonDrainer{}, recorder, 0, zap.NewNop(), WithMetricsRecorder(m)).(*DrainSchedules)
+			node := &v1.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KubeletVersion: tc.version}},
+			}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			if !reflect.DeepEqual(m.kubelets, []string{tc.want}) {
+				t.Errorf("NodeDrained: want kubelet version %v, got %v", tc.want, m.kubelets)
+			}
+			found := false
+			for len(recorder.Events) > 0 {
+				if <-recorder.Events == tc.wantEvent {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("missing event %q", tc.wantEvent)
+			}
+		})
+	}
+}
+
+func (m *recordingMetrics) ScheduleLatency(node string, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.latencies = append(m.latencies, d)
+}
+
+func TestDrainSchedules_ScheduleLatency(t *testing.T) {
+	m := &recordingMetrics{}
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(), WithMetricsRecorder(m))
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	transition := time.Now().Add(-2 * time.Second)
+	if _, err := scheduler.ScheduleWithTransition(node, transition); err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWithTransition() error = %v", err)
+	}
+	defer scheduler.DeleteSchedule(nodeName)
+
+	// Nodes that are already scheduled are not recorded again.
+	if _, err := scheduler.ScheduleWithTransition(node, transition); !IsAlreadyScheduledError(err) {
+		t.Fatalf("DrainSchedules.ScheduleWithTransition() again: want AlreadyScheduledError, got %v", err)
+	}
+	if len(m.latencies) != 1 {
+		t.Fatalf("ScheduleLatency: want 1 call, got %d", len(m.latencies))
+	}
+	if got := m.latencies[0]; got < 2*time.Second || got > 3*time.Second {
+		t.Errorf("ScheduleLatency: want about 2s, got %v", got)
+	}
+}
+
+func TestDrainSchedules_WritePromText(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithDispatcher(NewManualDispatcher(start))).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+	for _, name := range []string{"web", "cache"} {
+		if _, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+	}
+	scheduler.schedules["cache"].setFailed()
+
+	var b strings.Builder
+	if err := scheduler.WritePromText(&b); err != nil {
+		t.Fatalf("DrainSchedules.WritePromText() error = %v", err)
+	}
+	want := `# HELP draino_schedule_seconds_until_fire Seconds until the scheduled drain of the node fires.
+# TYPE draino_schedule_seconds_until_fire gauge
+draino_schedule_seconds_until_fire{node="cache"} 120
+draino_schedule_seconds_until_fire{node="web"} 60
+# HELP draino_schedule_failed Whether the scheduled drain of the node failed.
+# TYPE draino_schedule_failed gauge
+draino_schedule_failed{node="cache"} 1
+draino_schedule_failed{node="web"} 0
+`
+	if got := b.String(); got != want {
+		t.Errorf("DrainSchedules.WritePromText():\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestDrainSchedules_RecordStaleEvent(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &recordingMetrics{}
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewDrainSchedules(newRecordingDrainer(), recorder, time.Minute, zap.NewNop(), WithMetricsRecorder(m), WithDispatcher(NewManualDispatcher(start))).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	transition := start.Add(time.Hour)
+	scheduler.RecordStaleEvent(nodeName, transition)
+	if want := []string{nodeName}; !reflect.DeepEqual(m.stale, want) {
+		t.Errorf("StaleEvent: want %v, got %v", want, m.stale)
+	}
+	want := "Warning DrainStaleEvent Drain scheduled for 2020-01-01T00:01:00Z predates the condition transition at 2020-01-01T01:00:00Z"
+	select {
+	case got := <-recorder.Events:
+		if got != want {
+			t.Errorf("event: want %q, got %q", want, got)
+		}
+	default:
+		t.Errorf("event: want %q, got none", want)
+	}
+}
+
+func TestDrainSchedules_ResourcesFreed(t *testing.T) {
+	m := &recordingMetrics{}
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(), WithMetricsRecorder(m)).(*DrainSchedules)
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "large"},
+			Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("3500m"),
+				v1.ResourceMemory: resource.MustParse("16Gi"),
+			}},
+		},
+		{ObjectMeta: meta.ObjectMeta{Name: "unknown"}},
+	}
+	for _, node := range nodes {
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", node.GetName(), err)
+		}
+		sched := scheduler.schedules[node.GetName()]
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+	}
+	want := []string{"large=" + tagResultSucceeded + ",3.5,17179869184", "unknown=" + tagResultSucceeded + ",0,0"}
+	if !reflect.DeepEqual(m.freed, want) {
+		t.Errorf("ResourcesFreed: want %v, got %v", want, m.freed)
+	}
+}
+
+type summarizingDrainer struct {
+	NoopCordonDrainer
+	summaries map[string]DrainSummary
+}
+
+func (d *summarizingDrainer) DrainSummary(node string) (DrainSummary, bool) {
+	s, ok := d.summaries[node]
+	return s, ok
+}
+
+func TestDrainSchedules_MinPodsMoved(t *testing.T) {
+	m := &recordingMetrics{}
+	drainer := &summarizingDrainer{summaries: map[string]DrainSummary{
+		"empty": {Skipped: 3},
+		"below": {Evicted: 1},
+		"at":    {Evicted: 1, Terminated: 1},
+		"above": {Evicted: 2, Forced: 1},
+	}}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithMetricsRecorder(m),
+		WithMinPodsMoved(2),
+	).(*DrainSchedules)
+	for _, name := range []string{"empty", "below", "at", "above", "unsummarized"} {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		sched := scheduler.schedules[name]
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+	}
+	want := []string{
+		"empty=" + tagResultEmpty,
+		"below=" + tagResultEmpty,
+		"at=" + tagResultSucceeded,
+		"above=" + tagResultSucceeded,
+		"unsummarized=" + tagResultSucceeded,
+	}
+	if !reflect.DeepEqual(m.drained, want) {
+		t.Errorf("NodeDrained: want %v, got %v", want, m.drained)
+	}
+}
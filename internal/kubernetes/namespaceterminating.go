@@ -0,0 +1,49 @@
+package kubernetes
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// WithNamespaceTerminatingPodsGone determines whether Drain treats the pods
+// whose eviction fails because their namespace is being, or was, deleted as
+// already gone, since the namespace teardown removes them, rather than failing
+// the drain. Such pods are counted in the drain summary.
+func WithNamespaceTerminatingPodsGone(b bool) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.namespaceTerminatingPodsGone = b
+	}
+}
+
+// isNamespaceGone returns true if the supplied eviction error was caused by
+// the namespace of the pod being terminating, or not found.
+func isNamespaceGone(err error) bool {
+	if apierrors.IsForbidden(err) && apierrors.HasStatusCause(err, core.NamespaceTerminatingCause) {
+		return true
+	}
+	if !apierrors.IsNotFound(err) {
+		return false
+	}
+	status, ok := err.(apierrors.APIStatus)
+	if !ok {
+		return false
+	}
+	details := status.Status().Details
+	return details != nil && details.Kind == "namespaces"
+}
+
+// namespaceGone returns true if the eviction of the supplied pod failed with
+// the supplied error because its namespace is going away, and such pods are
+// treated as gone, in which case it is recorded.
+func (d *APICordonDrainer) namespaceGone(ctx context.Context, p core.Pod, err error) bool {
+	if !d.namespaceTerminatingPodsGone || !isNamespaceGone(err) {
+		return false
+	}
+	d.l.Info("Namespace of pod is terminating, assuming it is gone", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Error(err))
+	drainSummaryFrom(ctx).update(func(s *DrainSummary) { s.NamespaceTerminating++ })
+	d.metrics.PodNamespaceGone(p.Spec.NodeName)
+	return true
+}
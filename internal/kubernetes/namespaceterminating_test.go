@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// namespaceGoneMetrics records the nodes of the pods treated as gone because
+// their namespace is being deleted.
+type namespaceGoneMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	nodes []string
+}
+
+func (m *namespaceGoneMetrics) PodNamespaceGone(node string) {
+	m.Lock()
+	defer m.Unlock()
+	m.nodes = append(m.nodes, node)
+}
+
+func TestDrainNamespaceTerminatingPods(t *testing.T) {
+	terminating := &apierrors.StatusError{ErrStatus: meta.Status{
+		Status:  meta.StatusFailure,
+		Code:    403,
+		Reason:  meta.StatusReasonForbidden,
+		Message: "unable to create new content in namespace tenant because it is being terminated",
+		Details: &meta.StatusDetails{Causes: []meta.StatusCause{{Type: core.NamespaceTerminatingCause}}},
+	}}
+	cases := []struct {
+		name    string
+		err     error
+		gone    bool
+		wantErr bool
+	}{
+		{name: "NamespaceNotFound", err: apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "tenant"), gone: true},
+		{name: "NamespaceTerminating", err: terminating, gone: true},
+		{name: "NamespaceTerminatingNotGone", err: terminating, wantErr: true},
+		{name: "Forbidden", err: apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, podName, nil), gone: true, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeClientSet(
+				reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+					{ObjectMeta: meta.ObjectMeta{Namespace: "tenant", Name: podName}, Spec: core.PodSpec{NodeName: nodeName}},
+				}}},
+				reactor{verb: "create", resource: "pods", subresource: "eviction", err: tc.err},
+				reactor{verb: "delete", resource: "nodes"},
+			)
+			m := &namespaceGoneMetrics{}
+			d := NewAPICordonDrainer(c, WithNamespaceTerminatingPodsGone(tc.gone), WithDrainerMetricsRecorder(m))
+			err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("d.Drain(%v): want error %v, got %v", nodeName, tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+			summary, _ := d.DrainSummary(nodeName)
+			if summary.NamespaceTerminating != 1 {
+				t.Errorf("DrainSummary().NamespaceTerminating: want 1, got %d", summary.NamespaceTerminating)
+			}
+			m.Lock()
+			defer m.Unlock()
+			if want := []string{nodeName}; !reflect.DeepEqual(m.nodes, want) {
+				t.Errorf("pods namespace gone: want %v, got %v", want, m.nodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DefaultProtectedPodsAnnotation is the annotation of nodes listing the
+	// pods Drain must never evict from them.
+	DefaultProtectedPodsAnnotation = "draino.kubernetes.io/protected-pods"
+
+	skipReasonNodeProtected = "node-protected"
+)
+
+// WithProtectedPodsAnnotation configures Drain to never evict the pods listed
+// by the supplied annotation of the node being drained, leaving the node
+// partially drained. The annotation lists comma separated pod names, each
+// either namespaced, e.g. kube-system/debug, or not, e.g. debug, to protect the
+// pods of that name in any namespace. An empty annotation disables protection.
+func WithProtectedPodsAnnotation(annotation string) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.protectedPodsAnnotation = annotation
+	}
+}
+
+// WithDrainerEventRecorder configures Drain to record events, such as those
+// warning of pods it skipped, to the supplied recorder.
+func WithDrainerEventRecorder(r record.EventRecorder) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.eventRecorder = r
+	}
+}
+
+// WithDrainerEventReasons configures the reasons of the events recorded by
+// Drain. Empty reasons fall back to those of DefaultEventReasons.
+func WithDrainerEventReasons(r EventReasons) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.eventReasons = r.withDefaults()
+	}
+}
+
+// protectedPods returns the pod names listed by the protected pods annotation
+// of the supplied node. The annotation is read from the current state of the
+// node, so that pods protected after the drain was scheduled are honored.
+func (d *APICordonDrainer) protectedPods(ctx context.Context, n *core.Node) map[string]bool {
+	if d.protectedPodsAnnotation == "" {
+		return nil
+	}
+	if fresh, err := d.c.CoreV1().Nodes().Get(ctx, n.GetName(), meta.GetOptions{}); err == nil {
+		n = fresh
+	}
+	raw := n.GetAnnotations()[d.protectedPodsAnnotation]
+	if raw == "" {
+		return nil
+	}
+	protected := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			protected[name] = true
+		}
+	}
+	return protected
+}
+
+// skipProtected returns the supplied pods but those the supplied node protects,
+// warning of each pod skipped, and the namespaced names of the pods skipped.
+func (d *APICordonDrainer) skipProtected(ctx context.Context, n *core.Node, pods []core.Pod, summary *drainSummary) ([]core.Pod, map[string]bool) {
+	protected := d.protectedPods(ctx, n)
+	if len(protected) == 0 {
+		return pods, nil
+	}
+	skipped := map[string]bool{}
+	nr := &core.ObjectReference{Kind: "Node", Name: n.GetName(), UID: types.UID(n.GetName())}
+	include := pods[:0:0]
+	for _, p := range pods {
+		pod := p.GetNamespace() + "/" + p.GetName()
+		if !protected[pod] && !protected[p.GetName()] {
+			include = append(include, p)
+			continue
+		}
+		skipped[pod] = true
+		d.l.Info("Pod protected by its node", zap.String("node", n.GetName()), zap.String("pod", pod), zap.String("annotation", d.protectedPodsAnnotation))
+		d.metrics.PodSkipped(n.GetName(), skipReasonNodeProtected)
+		summary.update(func(s *DrainSummary) { s.Skipped++ })
+		summary.warn("%s skipped, protected by the node", pod)
+		if d.eventRecorder != nil {
+			d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.EvictionSkipped, "Not evicting pod %s, protected by the %s annotation", pod, d.protectedPodsAnnotation)
+		}
+	}
+	return include, skipped
+}
@@ -0,0 +1,137 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainProtectedPods(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotation  string
+		value       string
+		wantEvicted []string
+		wantEvents  int
+	}{
+		{
+			name:        "Protected",
+			annotation:  DefaultProtectedPodsAnnotation,
+			value:       "default/protected, debug",
+			wantEvicted: []string{"default/web"},
+			wantEvents:  2,
+		},
+		{
+			name:        "NotAnnotated",
+			annotation:  DefaultProtectedPodsAnnotation,
+			wantEvicted: []string{"default/protected", "default/web", "tools/debug"},
+		},
+		{
+			name:        "Disabled",
+			value:       "default/protected, debug",
+			wantEvicted: []string{"default/protected", "default/web", "tools/debug"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if tc.value != "" {
+				node.Annotations = map[string]string{DefaultProtectedPodsAnnotation: tc.value}
+			}
+			c := fake.NewSimpleClientset(node,
+				&core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "protected"}},
+				&core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "web"}},
+				&core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "tools", Name: "debug"}},
+			)
+			var mu sync.Mutex
+			var evicted []string
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if a.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				e := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				mu.Lock()
+				defer mu.Unlock()
+				evicted = append(evicted, a.GetNamespace()+"/"+e.GetName())
+				return true, nil, nil
+			})
+			c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			d := NewAPICordonDrainer(c, WithProtectedPodsAnnotation(tc.annotation), WithDrainerEventRecorder(recorder))
+			// The drain is handed a stale copy of the node, without its
+			// annotations.
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+			sort.Strings(evicted)
+			if !reflect.DeepEqual(tc.wantEvicted, evicted) {
+				t.Errorf("evicted pods: want %v, got %v", tc.wantEvicted, evicted)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Fatalf("events: want %d, got %d", tc.wantEvents, got)
+			}
+			for i := 0; i < tc.wantEvents; i++ {
+				if e := <-recorder.Events; !strings.HasPrefix(e, "Warning "+eventReasonEvictionSkipped+" ") {
+					t.Errorf("event: want an %s warning, got %q", eventReasonEvictionSkipped, e)
+				}
+			}
+			summary, _ := d.DrainSummary(nodeName)
+			if summary.Skipped != tc.wantEvents {
+				t.Errorf("summary: want %d pods skipped, got %d", tc.wantEvents, summary.Skipped)
+			}
+		})
+	}
+}
+
+// skipMetrics records the pods skipped by drains.
+type skipMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	skipped []string
+}
+
+func (m *skipMetrics) PodSkipped(node, reason string) {
+	m.Lock()
+	defer m.Unlock()
+	m.skipped = append(m.skipped, node+"="+reason)
+}
+
+func TestDrainProtectedPodsReasonsAndMetrics(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        nodeName,
+		Annotations: map[string]string{DefaultProtectedPodsAnnotation: "default/protected"},
+	}}
+	c := fake.NewSimpleClientset(node, &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "protected"}})
+
+	recorder := record.NewFakeRecorder(10)
+	m := &skipMetrics{}
+	d := NewAPICordonDrainer(c,
+		WithDrainerEventRecorder(recorder),
+		WithDrainerEventReasons(EventReasons{EvictionSkipped: "MyEvictionSkipped"}),
+		WithDrainerMetricsRecorder(m),
+	)
+	if err := d.Drain(node); !IsNothingToEvictError(err) {
+		t.Fatalf("d.Drain(%v): want NothingToEvictError, got %v", nodeName, err)
+	}
+	if e := <-recorder.Events; !strings.HasPrefix(e, "Warning MyEvictionSkipped ") {
+		t.Errorf("event: want a MyEvictionSkipped warning, got %q", e)
+	}
+	if want := []string{nodeName + "=" + skipReasonNodeProtected}; !reflect.DeepEqual(m.skipped, want) {
+		t.Errorf("PodSkipped(): want %v, got %v", want, m.skipped)
+	}
+}
@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultNodeSnapshotTTL is the default time a node snapshot is reused for,
+// long enough to span the constraints evaluated for a single drain decision.
+const DefaultNodeSnapshotTTL = 2 * time.Second
+
+const (
+	snapshotResultHit  = "hit"
+	snapshotResultMiss = "miss"
+)
+
+// A NodeLister lists all the nodes of the cluster. Callers must not modify the
+// nodes it returns.
+type NodeLister interface {
+	ListNodes(ctx context.Context) ([]*core.Node, error)
+}
+
+// ListNodes lists the nodes of the cluster from the API server.
+func (s *APINodeStore) ListNodes(ctx context.Context) ([]*core.Node, error) {
+	list, err := s.c.CoreV1().Nodes().List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list nodes")
+	}
+	nodes := make([]*core.Node, 0, len(list.Items))
+	for i := range list.Items {
+		nodes = append(nodes, &list.Items[i])
+	}
+	return nodes, nil
+}
+
+// ListNodes lists the nodes of the cluster from the cache of the watch. It
+// returns an error until the cache synced.
+func (w *NodeWatch) ListNodes(ctx context.Context) ([]*core.Node, error) {
+	if !w.HasSynced() {
+		return nil, errors.New("node cache not synced")
+	}
+	objects := w.GetStore().List()
+	nodes := make([]*core.Node, 0, len(objects))
+	for _, o := range objects {
+		nodes = append(nodes, o.(*core.Node))
+	}
+	return nodes, nil
+}
+
+// A NodeSnapshot is a NodeLister that reuses the nodes it listed from another
+// NodeLister for a short time, so that the constraints evaluated for a single
+// drain decision share a single, consistent listing instead of each listing
+// nodes anew. Lookups are recorded as hits or misses.
+type NodeSnapshot struct {
+	source  NodeLister
+	ttl     time.Duration
+	now     func() time.Time
+	metrics MetricsRecorder
+
+	mu    sync.Mutex
+	nodes []*core.Node
+	taken time.Time
+}
+
+// NodeSnapshotOption configures a NodeSnapshot.
+type NodeSnapshotOption func(s *NodeSnapshot)
+
+// WithNodeSnapshotMetricsRecorder configures the recorder of the lookups of
+// the snapshot, in place of the process-global opencensus stats.
+func WithNodeSnapshotMetricsRecorder(m MetricsRecorder) NodeSnapshotOption {
+	return func(s *NodeSnapshot) {
+		s.metrics = m
+	}
+}
+
+// NewNodeSnapshot returns a NodeSnapshot listing nodes from the supplied source
+// at most once per supplied time to live, or DefaultNodeSnapshotTTL if zero.
+func NewNodeSnapshot(source NodeLister, ttl time.Duration, so ...NodeSnapshotOption) *NodeSnapshot {
+	if ttl <= 0 {
+		ttl = DefaultNodeSnapshotTTL
+	}
+	s := &NodeSnapshot{source: source, ttl: ttl, now: time.Now, metrics: OpenCensusMetricsRecorder{}}
+	for _, o := range so {
+		o(s)
+	}
+	return s
+}
+
+// ListNodes returns the nodes of the current snapshot, taking a new snapshot
+// from the source if the current one expired. Concurrent lookups of an expired
+// snapshot wait for a single listing. Failed listings are not cached.
+func (s *NodeSnapshot) ListNodes(ctx context.Context) ([]*core.Node, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nodes != nil && s.now().Sub(s.taken) < s.ttl {
+		s.metrics.NodeSnapshotLookup(snapshotResultHit)
+		return s.nodes, nil
+	}
+	s.metrics.NodeSnapshotLookup(snapshotResultMiss)
+	nodes, err := s.source.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if nodes == nil {
+		nodes = []*core.Node{}
+	}
+	s.nodes, s.taken = nodes, s.now()
+	return nodes, nil
+}
@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// nodeLists returns the number of times the supplied clientset listed nodes.
+func nodeLists(c *fake.Clientset) int {
+	lists := 0
+	for _, a := range c.Actions() {
+		if a.GetVerb() == "list" && a.GetResource().Resource == "nodes" {
+			lists++
+		}
+	}
+	return lists
+}
+
+// lookupMetrics counts the lookups of node snapshots by result.
+type lookupMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	lookups map[string]int
+}
+
+func (m *lookupMetrics) NodeSnapshotLookup(result string) {
+	m.Lock()
+	defer m.Unlock()
+	m.lookups[result]++
+}
+
+func TestNodeSnapshotShared(t *testing.T) {
+	c := fake.NewSimpleClientset(
+		newCapacityNode("draining", "4", true),
+		newCapacityNode("other", "4", true),
+		newScheduledPod("a", "draining", "2"),
+	)
+	m := &lookupMetrics{lookups: map[string]int{}}
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := NewNodeSnapshot(NewAPINodeStore(c), time.Second, WithNodeSnapshotMetricsRecorder(m))
+	snapshot.now = func() time.Time { return now }
+
+	// Two constraints evaluated for the same decision share a single listing.
+	feasibility := NewClusterCapacityScorer(c, WithScorerNodeLister(snapshot))
+	other := NewClusterCapacityScorer(c, WithScorerNodeLister(snapshot))
+	node := newCapacityNode("draining", "4", true)
+	for _, s := range []*ClusterCapacityScorer{feasibility, other} {
+		if got, err := s.Unschedulable(context.Background(), node); err != nil || got != 0 {
+			t.Fatalf("Unschedulable(): want 0, got %d, %v", got, err)
+		}
+	}
+	if got := nodeLists(c); got != 1 {
+		t.Errorf("node lists: want 1, got %d", got)
+	}
+
+	// Nodes are listed anew once the snapshot expired.
+	now = now.Add(time.Second)
+	if _, err := feasibility.Unschedulable(context.Background(), node); err != nil {
+		t.Fatalf("Unschedulable(): %v", err)
+	}
+	if got := nodeLists(c); got != 2 {
+		t.Errorf("node lists: want 2, got %d", got)
+	}
+
+	if m.lookups[snapshotResultHit] != 1 || m.lookups[snapshotResultMiss] != 2 {
+		t.Errorf("lookups: want 1 hit and 2 misses, got %v", m.lookups)
+	}
+}
+
+func TestNodeSnapshotListFailed(t *testing.T) {
+	c := fake.NewSimpleClientset(newCapacityNode("other", "4", true))
+	failed := true
+	c.PrependReactor("list", "nodes", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if failed {
+			failed = false
+			return true, nil, errors.New("unavailable")
+		}
+		return false, nil, nil
+	})
+	snapshot := NewNodeSnapshot(NewAPINodeStore(c), time.Minute)
+	if _, err := snapshot.ListNodes(context.Background()); err == nil {
+		t.Fatal("ListNodes(): want an error, got none")
+	}
+	// Failed listings are not cached.
+	nodes, err := snapshot.ListNodes(context.Background())
+	if err != nil {
+		t.Fatalf("ListNodes(): %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].GetName() != "other" {
+		t.Errorf("ListNodes(): want node other, got %v", nodes)
+	}
+}
@@ -0,0 +1,153 @@
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// Default drain timeline settings.
+const (
+	DefaultTimelineMaxEntries = 100
+	DefaultTimelineMaxNodes   = DefaultDrainHistoryMaxNodes
+)
+
+// Phases of a drain recorded in its timeline.
+const (
+	TimelineScheduled       = "scheduled"
+	TimelineCordoned        = "cordoned"
+	TimelineEvictionStarted = "eviction-started"
+	TimelinePodEvicted      = "pod-evicted"
+	TimelineCompleted       = "completed"
+	TimelineFailed          = "failed"
+)
+
+// A TimelineEntry is a phase reached by a drain.
+type TimelineEntry struct {
+	Time  time.Time
+	Phase string
+	// Detail qualifies the phase, e.g. the namespaced name of the pod
+	// evicted, or the result of the drain.
+	Detail string
+}
+
+// drainTimelines remembers the phases reached by the current, or most recent,
+// drain of each node. It keeps at most maxEntries entries for each node, always
+// including the first one, and forgets the least recently drained nodes once
+// more than maxNodes are tracked.
+type drainTimelines struct {
+	sync.Mutex
+	now        func() time.Time
+	maxEntries int
+	maxNodes   int
+	entries    map[string][]TimelineEntry
+	// order lists the tracked nodes, least recently scheduled first.
+	order []string
+}
+
+func newDrainTimelines(now func() time.Time, maxEntries, maxNodes int) *drainTimelines {
+	return &drainTimelines{
+		now:        now,
+		maxEntries: maxEntries,
+		maxNodes:   maxNodes,
+		entries:    map[string][]TimelineEntry{},
+	}
+}
+
+// WithDrainTimeline configures how many entries the drain timeline of each
+// node keeps, and for how many nodes at most. A size of zero disables
+// timelines.
+func WithDrainTimeline(maxEntries, maxNodes int) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.timelines = newDrainTimelines(d.now, maxEntries, maxNodes)
+	}
+}
+
+// start starts the timeline of a new drain of the named node, replacing that
+// of its previous drain.
+func (t *drainTimelines) start(name, phase, detail string) {
+	if t.maxEntries <= 0 {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	t.entries[name] = []TimelineEntry{{Time: t.now(), Phase: phase, Detail: detail}}
+	for i, n := range t.order {
+		if n == name {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+	t.order = append(t.order, name)
+	for t.maxNodes > 0 && len(t.order) > t.maxNodes {
+		delete(t.entries, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// add appends an entry to the timeline of the named node, if it was started.
+// The oldest entries but the first are dropped once the timeline is full.
+func (t *drainTimelines) add(name, phase, detail string) {
+	if t.maxEntries <= 0 {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	entries, ok := t.entries[name]
+	if !ok {
+		return
+	}
+	entries = append(entries, TimelineEntry{Time: t.now(), Phase: phase, Detail: detail})
+	if len(entries) > t.maxEntries {
+		entries = append(entries[:1], entries[len(entries)-t.maxEntries+1:]...)
+	}
+	t.entries[name] = entries
+}
+
+func (t *drainTimelines) get(name string) []TimelineEntry {
+	t.Lock()
+	defer t.Unlock()
+	return append([]TimelineEntry(nil), t.entries[name]...)
+}
+
+// Timeline returns the phases reached by the current, or most recent, drain of
+// the named node, oldest first.
+func (d *DrainSchedules) Timeline(name string) []TimelineEntry {
+	return d.timelines.get(name)
+}
+
+// A nodeTimeline records the phases of the drain of a node.
+type nodeTimeline struct {
+	timelines *drainTimelines
+	node      string
+}
+
+type nodeTimelineKey struct{}
+
+// withNodeTimeline returns a copy of the supplied context that carries the
+// timeline of the named node, so that the drainer records the pods it removes.
+func withNodeTimeline(ctx context.Context, t *drainTimelines, node string) context.Context {
+	return context.WithValue(ctx, nodeTimelineKey{}, &nodeTimeline{timelines: t, node: node})
+}
+
+// nodeTimelineFrom returns the timeline carried by the supplied context, if
+// any. Recording to a nil timeline does nothing.
+func nodeTimelineFrom(ctx context.Context) *nodeTimeline {
+	t, _ := ctx.Value(nodeTimelineKey{}).(*nodeTimeline)
+	return t
+}
+
+// podRemoved records the removal of the supplied pod in the supplied phase,
+// noting pods that were not evicted.
+func (t *nodeTimeline) podRemoved(p core.Pod, phase string) {
+	if t == nil {
+		return
+	}
+	detail := p.GetNamespace() + "/" + p.GetName()
+	if phase != evictionPhaseEvicted {
+		detail += " (" + phase + ")"
+	}
+	t.timelines.add(t.node, TimelinePodEvicted, detail)
+}
@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_Timeline(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	c := fake.NewSimpleClientset(node, &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName}})
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return a.GetSubresource() == "eviction", nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+	})
+
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	scheduler := NewDrainSchedules(NewAPICordonDrainer(c), record.NewFakeRecorder(100), time.Minute, zap.NewNop(),
+		WithDispatcher(dispatcher),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	dispatcher.ProcessDue(start.Add(time.Minute))
+
+	var got []string
+	for _, e := range scheduler.Timeline(nodeName) {
+		got = append(got, e.Phase+" "+e.Detail)
+	}
+	want := []string{
+		TimelineScheduled + " " + start.Add(time.Minute).Format(time.RFC3339),
+		TimelineCordoned + " ",
+		TimelineEvictionStarted + " attempt 1",
+		TimelinePodEvicted + " default/" + podName,
+		TimelineCompleted + " " + tagResultSucceeded,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Timeline(%v):\nwant %q\ngot  %q", nodeName, want, got)
+	}
+
+	// A new drain of the node starts a new timeline.
+	scheduler.DeleteSchedule(nodeName)
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	if got := scheduler.Timeline(nodeName); len(got) != 1 || got[0].Phase != TimelineScheduled {
+		t.Errorf("Timeline(%v): want a new timeline, got %v", nodeName, got)
+	}
+}
+
+func TestDrainTimelines_Bounded(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tl := newDrainTimelines(func() time.Time { return now }, 3, 2)
+
+	tl.start("a", TimelineScheduled, "")
+	for _, pod := range []string{"p1", "p2", "p3"} {
+		tl.add("a", TimelinePodEvicted, pod)
+	}
+	var got []string
+	for _, e := range tl.get("a") {
+		got = append(got, e.Phase+" "+e.Detail)
+	}
+	// The first entry is kept, the oldest pods are dropped.
+	want := []string{TimelineScheduled + " ", TimelinePodEvicted + " p2", TimelinePodEvicted + " p3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("get(a):\nwant %q\ngot  %q", want, got)
+	}
+
+	// Entries of drains that were never scheduled are dropped.
+	tl.add("b", TimelinePodEvicted, "p1")
+	if got := tl.get("b"); len(got) != 0 {
+		t.Errorf("get(b): want no entries, got %v", got)
+	}
+
+	// The least recently scheduled nodes are forgotten.
+	tl.start("b", TimelineScheduled, "")
+	tl.start("c", TimelineScheduled, "")
+	if got := tl.get("a"); len(got) != 0 {
+		t.Errorf("get(a): want forgotten, got %v", got)
+	}
+	if got := tl.get("c"); len(got) != 1 {
+		t.Errorf("get(c): want 1 entry, got %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// WithNamespaceEvictionConcurrency caps how many pods of each namespace Drain
+// removes at once, across all the nodes being drained, so that a mass drain
+// does not take down too many pods of one tenant of a shared cluster at once.
+// Namespaces are capped to their entry of perNamespace, if any, and to max
+// otherwise. Like with WithMaxTerminatingPods, a pod counts from its eviction,
+// including refused evictions being retried, until it is gone. Zero means no
+// limit.
+func WithNamespaceEvictionConcurrency(max int, perNamespace map[string]int) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.namespaceEvictions = newNamespaceLimiter(max, perNamespace)
+	}
+}
+
+// A namespaceLimiter caps the evictions in flight of each namespace. A nil
+// namespaceLimiter does not limit evictions.
+type namespaceLimiter struct {
+	max          int
+	perNamespace map[string]int
+
+	mu       sync.Mutex
+	slots    map[string]chan struct{}
+	inFlight map[string]int
+}
+
+// newNamespaceLimiter returns a limiter capping each namespace to its entry of
+// perNamespace, if any, or to max. It returns nil if nothing is capped.
+func newNamespaceLimiter(max int, perNamespace map[string]int) *namespaceLimiter {
+	limited := max > 0
+	for _, n := range perNamespace {
+		limited = limited || n > 0
+	}
+	if !limited {
+		return nil
+	}
+	return &namespaceLimiter{
+		max:          max,
+		perNamespace: perNamespace,
+		slots:        map[string]chan struct{}{},
+		inFlight:     map[string]int{},
+	}
+}
+
+// slot returns the semaphore of the supplied namespace, or nil if its
+// evictions are not capped.
+func (l *namespaceLimiter) slot(namespace string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s, ok := l.slots[namespace]; ok {
+		return s
+	}
+	max, ok := l.perNamespace[namespace]
+	if !ok {
+		max = l.max
+	}
+	var s chan struct{}
+	if max > 0 {
+		s = make(chan struct{}, max)
+	}
+	l.slots[namespace] = s
+	return s
+}
+
+// acquire blocks until a pod of the supplied namespace may be removed, or the
+// supplied eviction is aborted or cancelled. The evictions in flight of the
+// namespace are recorded by the supplied recorder. The returned function must
+// be called once the pod is gone, or its removal failed.
+func (l *namespaceLimiter) acquire(ctx context.Context, abort <-chan struct{}, namespace string, log *zap.Logger, m MetricsRecorder) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	s := l.slot(namespace)
+	if s == nil {
+		return func() {}, nil
+	}
+	select {
+	case s <- struct{}{}:
+	default:
+		log.Info("Waiting for the evictions of the namespace to complete", zap.String("namespace", namespace), zap.Int("max", cap(s)))
+		select {
+		case s <- struct{}{}:
+		case <-abort:
+			return nil, errors.New("pod eviction aborted")
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "pod eviction cancelled")
+		}
+	}
+	l.record(m, namespace, 1)
+	return func() {
+		l.record(m, namespace, -1)
+		<-s
+	}, nil
+}
+
+// record updates, and records, the evictions in flight of the supplied
+// namespace.
+func (l *namespaceLimiter) record(m MetricsRecorder, namespace string, delta int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight[namespace] += delta
+	m.NamespaceEvictions(namespace, l.inFlight[namespace])
+}
@@ -0,0 +1,155 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// namespaceMetrics records the last recorded evictions in flight of each
+// namespace.
+type namespaceMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	inFlight map[string]int
+}
+
+func (m *namespaceMetrics) NamespaceEvictions(namespace string, inFlight int) {
+	m.Lock()
+	defer m.Unlock()
+	m.inFlight[namespace] = inFlight
+}
+
+func TestDrainNamespaceEvictionConcurrency(t *testing.T) {
+	pods := map[string][]core.Pod{}
+	for _, node := range []string{"a", "b"} {
+		for _, name := range []string{"team-1", "other-1"} {
+			p := newTestPod(node+"-"+name, 0, "1")
+			p.SetNamespace(name[:len(name)-2])
+			p.Spec.NodeName = node
+			pods[node] = append(pods[node], p)
+		}
+	}
+
+	var mu sync.Mutex
+	inFlight, peak := map[string]int{}, map[string]int{}
+	c := &fake.Clientset{}
+	c.AddReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		node, _ := a.(clienttesting.ListAction).GetListRestrictions().Fields.RequiresExactMatch("spec.nodeName")
+		return true, &core.PodList{Items: pods[node]}, nil
+	})
+	// A pod is in flight from its eviction until it is found gone, on the
+	// second poll.
+	polled := map[string]bool{}
+	c.AddReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		ns := a.GetNamespace()
+		mu.Lock()
+		defer mu.Unlock()
+		inFlight[ns]++
+		if inFlight[ns] > peak[ns] {
+			peak[ns] = inFlight[ns]
+		}
+		return true, nil, nil
+	})
+	c.AddReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		name := a.(clienttesting.GetAction).GetName()
+		mu.Lock()
+		defer mu.Unlock()
+		if !polled[name] {
+			polled[name] = true
+			return true, &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: a.GetNamespace(), Name: name}}, nil
+		}
+		inFlight[a.GetNamespace()]--
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+	})
+
+	m := &namespaceMetrics{inFlight: map[string]int{}}
+	d := NewAPICordonDrainer(c, WithNamespaceEvictionConcurrency(0, map[string]int{"team": 1}), WithDrainerMetricsRecorder(m))
+	var wg sync.WaitGroup
+	for _, node := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: node}}); err != nil {
+				t.Errorf("d.Drain(%v): %v", node, err)
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak["team"] != 1 {
+		t.Errorf("team evictions in flight: want at most 1, got %d", peak["team"])
+	}
+	if len(evictedPods(c)) != 4 {
+		t.Errorf("evictions: want 4, got %v", evictedPods(c))
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if want := map[string]int{"team": 0}; !reflect.DeepEqual(m.inFlight, want) {
+		t.Errorf("namespace evictions in flight: want %v once drained, got %v", want, m.inFlight)
+	}
+}
+
+func TestNamespaceLimiter(t *testing.T) {
+	l := newNamespaceLimiter(1, map[string]int{"team": 2, "free": 0})
+	ctx, abort := context.Background(), make(chan struct{})
+	acquire := func(namespace string) func() {
+		t.Helper()
+		release, err := l.acquire(ctx, abort, namespace, zap.NewNop(), OpenCensusMetricsRecorder{})
+		if err != nil {
+			t.Fatalf("l.acquire(%s): %v", namespace, err)
+		}
+		return release
+	}
+
+	// Namespaces without a cap of their own are capped to the default.
+	release := acquire("default")
+	acquire("team")
+	acquire("team")
+	for i := 0; i < 3; i++ {
+		acquire("free")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		acquire("default")()
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("l.acquire(default): want to wait for the namespace to be below its cap")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("l.acquire(default): want to proceed once released")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := l.acquire(cancelled, abort, "team", zap.NewNop(), OpenCensusMetricsRecorder{}); err == nil {
+		t.Error("l.acquire(team): want an error once cancelled, got none")
+	}
+}
+
+func TestNamespaceLimiterUnlimited(t *testing.T) {
+	if l := newNamespaceLimiter(0, map[string]int{"team": 0}); l != nil {
+		t.Errorf("newNamespaceLimiter(): want nil without a cap, got %v", l)
+	}
+}
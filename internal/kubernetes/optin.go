@@ -0,0 +1,51 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultOptInAnnotation is the annotation of the nodes opted in to draining
+// when WithOptInAnnotation is configured.
+const DefaultOptInAnnotation = "draino.kubernetes.io/enabled"
+
+// WithOptInAnnotation configures Schedule and ScheduleWithKey to only schedule
+// the drains of nodes whose supplied annotation is true, refusing the others
+// with a NotOptedInError, so that draining can be rolled out to a few nodes at
+// a time regardless of their labels and conditions. An empty annotation
+// disables the gate.
+func WithOptInAnnotation(annotation string) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.optInAnnotation = annotation
+	}
+}
+
+// checkOptIn returns a NotOptedInError if the supplied node is not opted in to
+// draining.
+func (d *DrainSchedules) checkOptIn(node *v1.Node) error {
+	if d.optInAnnotation == "" {
+		return nil
+	}
+	if enabled, _ := strconv.ParseBool(node.GetAnnotations()[d.optInAnnotation]); enabled {
+		return nil
+	}
+	d.metrics.NodeNotOptedIn(node.GetName())
+	return NewNotOptedInError(node.GetName(), d.optInAnnotation)
+}
+
+type NotOptedInError struct {
+	error
+}
+
+func NewNotOptedInError(name, annotation string) error {
+	return &NotOptedInError{
+		fmt.Errorf("node %s is not opted in to draining, its annotation %s is not true", name, annotation),
+	}
+}
+
+func IsNotOptedInError(err error) bool {
+	_, ok := err.(*NotOptedInError)
+	return ok
+}
@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_OptInAnnotation(t *testing.T) {
+	v := &view.View{
+		Name:        "test_nodes_not_opted_in",
+		Measure:     MeasureNodesNotOptedIn,
+		Aggregation: view.Count(),
+	}
+	if err := view.Register(v); err != nil {
+		t.Fatalf("view.Register(): %v", err)
+	}
+	defer view.Unregister(v)
+
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, 0, zap.NewNop(), WithOptInAnnotation(DefaultOptInAnnotation)).(*DrainSchedules)
+
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "OptedIn", annotations: map[string]string{DefaultOptInAnnotation: "true"}, want: true},
+		{name: "OptedOut", annotations: map[string]string{DefaultOptInAnnotation: "false"}},
+		{name: "NotAnnotated"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: tc.name, Annotations: tc.annotations}}
+			_, err := scheduler.Schedule(node)
+			has, _ := scheduler.HasSchedule(node.GetName())
+			if !tc.want {
+				if !IsNotOptedInError(err) {
+					t.Fatalf("DrainSchedules.Schedule(): want NotOptedInError, got %v", err)
+				}
+				if has {
+					t.Error("DrainSchedules.HasSchedule(): want no schedule, got one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DrainSchedules.Schedule(): %v", err)
+			}
+			if !has {
+				t.Error("DrainSchedules.HasSchedule(): want a schedule, got none")
+			}
+			scheduler.DeleteSchedule(node.GetName())
+		})
+	}
+
+	rows, err := view.RetrieveData(v.Name)
+	if err != nil {
+		t.Fatalf("view.RetrieveData(): %v", err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.CountData).Value != 2 {
+		t.Errorf("nodes not opted in: want 2, got %v", rows)
+	}
+}
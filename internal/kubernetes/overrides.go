@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// minPeriodAnnotationKey is the minimum time between the drain scheduled
+	// before that of the annotated node and its own, when longer than the
+	// period between drains.
+	minPeriodAnnotationKey = "draino.kubernetes.io/min-period"
+	// drainTimeoutAnnotationKey bounds how long the drain of the annotated
+	// node may take before it fails, in place of the eviction timeout of the
+	// drainer.
+	drainTimeoutAnnotationKey = "draino.kubernetes.io/drain-timeout"
+)
+
+// nodeDuration returns the supplied annotation of the supplied node as a
+// positive duration. Missing and invalid annotations are ignored.
+func (d *DrainSchedules) nodeDuration(n *v1.Node, key string) (time.Duration, bool) {
+	raw, ok := n.GetAnnotations()[key]
+	if !ok {
+		return 0, false
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil || v <= 0 {
+		d.logger.Warn("Ignoring invalid node annotation", zap.String("node", n.GetName()), zap.String("annotation", key), zap.String("value", raw))
+		return 0, false
+	}
+	return v, true
+}
+
+// periodBefore returns the minimum time between the previous drain and that of
+// the supplied node: the period between drains, or the minimum period of the
+// node if longer.
+func (d *DrainSchedules) periodBefore(n *v1.Node) time.Duration {
+	period := d.effectivePeriod()
+	if p, ok := d.nodeDuration(n, minPeriodAnnotationKey); ok && p > period {
+		d.logger.Info("Applying minimum period override", zap.String("node", n.GetName()), zap.Duration("period", period), zap.Duration("minPeriod", p))
+		period = p
+	}
+	return period
+}
+
+type drainTimeoutKey struct{}
+
+// withDrainTimeout returns a copy of the supplied context that is done once the
+// drain timeout of the supplied node, if any, elapses. The context also carries
+// the timeout so that drainers wait for evictions until it elapses rather than
+// for their own eviction timeout.
+func (d *DrainSchedules) withDrainTimeout(ctx context.Context, n *v1.Node) (context.Context, time.Duration, context.CancelFunc) {
+	timeout, ok := d.nodeDuration(n, drainTimeoutAnnotationKey)
+	if !ok {
+		return ctx, 0, func() {}
+	}
+	d.logger.Info("Applying drain timeout override", zap.String("node", n.GetName()), zap.Duration("timeout", timeout))
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, drainTimeoutKey{}, timeout), timeout)
+	return ctx, timeout, cancel
+}
+
+// drainTimeout returns the drain timeout carried by the supplied context, if
+// any.
+func drainTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(drainTimeoutKey{}).(time.Duration)
+	return timeout, ok
+}
+
+// drainTimedOut returns the supplied drain error as a timeout if the drain
+// timeout of the node elapsed before it completed.
+func drainTimedOut(ctx context.Context, timeout time.Duration, err error) error {
+	if err == nil || ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	return errors.Wrapf(errTimeout{}, "drain did not complete within %s", timeout)
+}
@@ -0,0 +1,105 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_MinPeriodOverride(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithDispatcher(NewManualDispatcher(start))).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Time
+	}{
+		{
+			name: "plain",
+			want: start.Add(time.Minute),
+		},
+		{
+			name:        "slow",
+			annotations: map[string]string{minPeriodAnnotationKey: "10m"},
+			want:        start.Add(11 * time.Minute),
+		},
+		{
+			name:        "shorter",
+			annotations: map[string]string{minPeriodAnnotationKey: "1s"},
+			want:        start.Add(12 * time.Minute),
+		},
+		{
+			name:        "invalid",
+			annotations: map[string]string{minPeriodAnnotationKey: "soon"},
+			want:        start.Add(13 * time.Minute),
+		},
+	}
+	for _, tc := range cases {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: tc.name, Annotations: tc.annotations}}
+		got, err := scheduler.Schedule(node)
+		if err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", tc.name, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("DrainSchedules.Schedule(%s): want %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestDrainSchedules_DrainTimeoutOverride(t *testing.T) {
+	drainer := &cancellableDrainer{started: make(chan struct{}, 1)}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        nodeName,
+		Annotations: map[string]string{drainTimeoutAnnotationKey: "10ms"},
+	}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+
+	scheduler.runDrain(node, sched)
+	reason, failed := scheduler.FailureReason(nodeName)
+	if want := "Timed out waiting for evictions to complete"; !failed || reason != want {
+		t.Errorf("FailureReason(): want %q, true, got %q, %v", want, reason, failed)
+	}
+}
+
+func TestAPICordonDrainer_DrainTimeoutOverride(t *testing.T) {
+	c := newFakeClientSet(
+		reactor{
+			verb:     "list",
+			resource: "pods",
+			ret:      &v1.PodList{Items: []v1.Pod{{ObjectMeta: meta.ObjectMeta{Name: podName}}}},
+		},
+		reactor{
+			verb:        "create",
+			resource:    "pods",
+			subresource: "eviction",
+			err:         apierrors.NewTooManyRequests("nope", 5),
+		},
+	)
+	d := NewAPICordonDrainer(c)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	// The eviction timeout of the drainer is several minutes with the default
+	// grace period; the override of the node must be waited for instead.
+	ctx := context.WithValue(context.Background(), drainTimeoutKey{}, 100*time.Millisecond)
+	started := time.Now()
+	err := d.DrainWithContext(ctx, node)
+	if !IsTimeout(err) {
+		t.Fatalf("d.DrainWithContext(%v): want timeout, got %v", nodeName, err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("d.DrainWithContext(%v): want timeout after 100ms, got %v", nodeName, elapsed)
+	}
+}
@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ownerKindBare is the owner kind of the pods without a controller.
+const ownerKindBare = "bare"
+
+// podOwnerKind returns the kind of the controller of the supplied pod, or bare
+// if it has none. Pods of the ReplicaSets of a Deployment are attributed to the
+// Deployment.
+func podOwnerKind(p core.Pod) string {
+	c := meta.GetControllerOf(&p)
+	if c == nil {
+		return ownerKindBare
+	}
+	if c.Kind == "ReplicaSet" {
+		if _, ok := p.GetLabels()[apps.DefaultDeploymentUniqueLabelKey]; ok {
+			return "Deployment"
+		}
+	}
+	return c.Kind
+}
+
+// podsByOwnerKind counts the supplied pods by the kind of their controller.
+func podsByOwnerKind(pods []core.Pod) map[string]int {
+	kinds := map[string]int{}
+	for _, p := range pods {
+		kinds[podOwnerKind(p)]++
+	}
+	return kinds
+}
+
+// recordPodsByOwnerKind records the number of pods of each owner kind drained
+// from the named node.
+func (d *APICordonDrainer) recordPodsByOwnerKind(node string, pods []core.Pod) {
+	for kind, n := range podsByOwnerKind(pods) {
+		d.metrics.PodsByOwnerKind(node, kind, n)
+	}
+}
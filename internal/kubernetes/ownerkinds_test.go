@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ownerKindMetrics sums the drained pods of each owner kind.
+type ownerKindMetrics struct {
+	OpenCensusMetricsRecorder
+	kinds map[string]int
+}
+
+func (m *ownerKindMetrics) PodsByOwnerKind(_, kind string, n int) {
+	m.kinds[kind] += n
+}
+
+func TestDrainPodsByOwnerKind(t *testing.T) {
+	owned := func(name, kind string, labels map[string]string) core.Pod {
+		isController := true
+		return core.Pod{ObjectMeta: meta.ObjectMeta{
+			Name:            name,
+			Labels:          labels,
+			OwnerReferences: []meta.OwnerReference{{Kind: kind, Name: kind, Controller: &isController}},
+		}}
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			owned("web-1", "ReplicaSet", map[string]string{apps.DefaultDeploymentUniqueLabelKey: "abc"}),
+			owned("web-2", "ReplicaSet", map[string]string{apps.DefaultDeploymentUniqueLabelKey: "abc"}),
+			owned("legacy", "ReplicaSet", nil),
+			owned("db-0", "StatefulSet", nil),
+			owned("backup", "Job", nil),
+			{ObjectMeta: meta.ObjectMeta{Name: "bare"}},
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	m := &ownerKindMetrics{kinds: map[string]int{}}
+	d := NewAPICordonDrainer(c, WithDrainerMetricsRecorder(m))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	want := map[string]int{"Deployment": 2, "ReplicaSet": 1, "StatefulSet": 1, "Job": 1, ownerKindBare: 1}
+	if !reflect.DeepEqual(m.kinds, want) {
+		t.Errorf("pods by owner kind: want %v, got %v", want, m.kinds)
+	}
+}
@@ -0,0 +1,72 @@
+package kubernetes
+
+import (
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PauseGroup pauses the drains of the nodes of the supplied node group, per the
+// node group label. Drains of a paused group are still scheduled, and their
+// nodes marked, but are deferred by DefaultDrainDeferralPeriod when they fire,
+// until the group is resumed. Drains already in progress are not affected.
+func (d *DrainSchedules) PauseGroup(group string) {
+	d.Lock()
+	defer d.Unlock()
+	d.pausedGroups[group] = struct{}{}
+	d.logger.Info("Paused drains of node group", zap.String("group", group))
+}
+
+// ResumeGroup resumes the drains of the nodes of the supplied node group. The
+// drains deferred while the group was paused fire immediately.
+func (d *DrainSchedules) ResumeGroup(group string) {
+	d.Lock()
+	defer d.Unlock()
+	if _, ok := d.pausedGroups[group]; !ok {
+		return
+	}
+	delete(d.pausedGroups, group)
+	d.logger.Info("Resumed drains of node group", zap.String("group", group))
+	for _, s := range d.schedules {
+		if s.group == group && s.paused {
+			s.paused = false
+			s.timer.Reset(0)
+		}
+	}
+}
+
+// PausedGroups returns the sorted node groups whose drains are paused.
+func (d *DrainSchedules) PausedGroups() []string {
+	d.Lock()
+	defer d.Unlock()
+	groups := make([]string, 0, len(d.pausedGroups))
+	for g := range d.pausedGroups {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// deferPaused returns true if the node group of the supplied schedule is
+// paused, in which case the drain is deferred by DefaultDrainDeferralPeriod.
+func (d *DrainSchedules) deferPaused(node *core.Node, sched *schedule) bool {
+	d.Lock()
+	_, paused := d.pausedGroups[sched.group]
+	if !paused || sched.group == "" {
+		d.Unlock()
+		return false
+	}
+	sched.paused = true
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	d.Unlock()
+
+	d.logger.Info("Deferring drain, node group is paused", zap.String("node", node.GetName()), zap.String("group", sched.group))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Drains of node group %s are paused", sched.group)
+	sched.addSpanEvent("deferred", attribute.String("group", sched.group))
+	d.blockDrain(node.GetName(), deferralReasonGroupPaused)
+	return true
+}
@@ -0,0 +1,50 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_PauseGroup(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManualDispatcher(start)
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDispatcher(m), WithNodeGroupLabel("group")).(*DrainSchedules)
+
+	scheduler.PauseGroup("database")
+	if got := scheduler.PausedGroups(); !reflect.DeepEqual(got, []string{"database"}) {
+		t.Errorf("PausedGroups(): want [database], got %v", got)
+	}
+	for name, group := range map[string]string{"db": "database", "web": "web"} {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name, Labels: map[string]string{"group": group}}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		sched := scheduler.schedules[name]
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+	}
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{"web"}) {
+		t.Errorf("drained while paused: want [web], got %v", got)
+	}
+	if has, _ := scheduler.HasSchedule("db"); !has {
+		t.Errorf("schedule of paused group deleted")
+	}
+
+	// Deferred drains fire as soon as their group is resumed.
+	scheduler.ResumeGroup("database")
+	if got := scheduler.PausedGroups(); len(got) != 0 {
+		t.Errorf("PausedGroups() after resume: want none, got %v", got)
+	}
+	m.ProcessDue(start)
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{"web", "db"}) {
+		t.Errorf("drained once resumed: want [web db], got %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"context"
+	"regexp"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// maxPDBTags is the number of distinct PodDisruptionBudgets
+	// MeasurePDBBlocks is tagged with. Further budgets are tagged as
+	// pdbTagOther.
+	maxPDBTags = 100
+
+	pdbTagOther   = "other"
+	pdbTagUnknown = "unknown"
+)
+
+// pdbRejection matches the cause of an eviction refused by a
+// PodDisruptionBudget, as returned by the API server.
+var pdbRejection = regexp.MustCompile(`^The disruption budget (\S+) needs`)
+
+// blockingPDB returns the name of the PodDisruptionBudget that refused the
+// eviction of the supplied pod with the supplied error. The budget is read
+// from the cause of the error, or else found by matching the labels of the pod
+// against the budgets of its namespace. It returns pdbTagUnknown if neither
+// identifies a budget.
+func (d *APICordonDrainer) blockingPDB(ctx context.Context, p core.Pod, err error) string {
+	if status, ok := err.(apierrors.APIStatus); ok && status.Status().Details != nil {
+		for _, c := range status.Status().Details.Causes {
+			if c.Type != policyv1.DisruptionBudgetCause {
+				continue
+			}
+			if m := pdbRejection.FindStringSubmatch(c.Message); m != nil {
+				return m[1]
+			}
+		}
+	}
+	pdbs, lerr := d.c.PolicyV1().PodDisruptionBudgets(p.GetNamespace()).List(ctx, meta.ListOptions{})
+	if lerr != nil {
+		d.l.Info("Cannot list PodDisruptionBudgets", zap.String("namespace", p.GetNamespace()), zap.Error(lerr))
+		return pdbTagUnknown
+	}
+	for _, pdb := range pdbs.Items {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, serr := meta.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if serr != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(p.GetLabels())) {
+			return pdb.GetName()
+		}
+	}
+	return pdbTagUnknown
+}
+
+// pdbTag returns the namespace and name MeasurePDBBlocks is tagged with for
+// the supplied PodDisruptionBudget, bounded to maxPDBTags distinct budgets.
+func (d *APICordonDrainer) pdbTag(namespace, name string) (string, string) {
+	d.pdbTagsMu.Lock()
+	defer d.pdbTagsMu.Unlock()
+	key := namespace + "/" + name
+	if _, ok := d.pdbTags[key]; ok {
+		return namespace, name
+	}
+	if len(d.pdbTags) >= maxPDBTags {
+		return pdbTagOther, pdbTagOther
+	}
+	if d.pdbTags == nil {
+		d.pdbTags = map[string]struct{}{}
+	}
+	d.pdbTags[key] = struct{}{}
+	return namespace, name
+}
+
+// recordPDBBlock records the eviction of the supplied pod as refused by the
+// named PodDisruptionBudget.
+func (d *APICordonDrainer) recordPDBBlock(p core.Pod, name string) {
+	d.metrics.PDBBlocked(d.pdbTag(p.GetNamespace(), name))
+}
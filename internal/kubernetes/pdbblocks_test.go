@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// pdbBlockMetrics records the PodDisruptionBudgets that refused evictions.
+type pdbBlockMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	blocks []string
+}
+
+func (m *pdbBlockMetrics) PDBBlocked(namespace, name string) {
+	m.Lock()
+	defer m.Unlock()
+	m.blocks = append(m.blocks, namespace+"/"+name)
+}
+
+func TestDrainRecordsPDBBlocks(t *testing.T) {
+	refused := apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+	withCause := apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+	withCause.ErrStatus.Details.Causes = []meta.StatusCause{{
+		Type:    policyv1.DisruptionBudgetCause,
+		Message: "The disruption budget web-cause needs 2 healthy pods and has 2 currently",
+	}}
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "Cause", err: withCause, want: "web-cause"},
+		{name: "Selector", err: refused, want: "web"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(
+				&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+				&policyv1.PodDisruptionBudget{
+					ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "web"},
+					Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &meta.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+				},
+			)
+			c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, &core.PodList{Items: []core.Pod{{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName, Labels: map[string]string{"app": "web"}}}}}, nil
+			})
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if a.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				return true, nil, tc.err
+			})
+			c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+			})
+
+			// The refused pod is force deleted right away so that the
+			// drain ends.
+			m := &pdbBlockMetrics{}
+			d := NewAPICordonDrainer(c, WithEvictionEscalation(time.Nanosecond), WithDrainerMetricsRecorder(m))
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			if want := []string{"default/" + tc.want}; !reflect.DeepEqual(m.blocks, want) {
+				t.Errorf("PDB blocks: want %v, got %v", want, m.blocks)
+			}
+		})
+	}
+}
+
+func TestPDBTagCardinality(t *testing.T) {
+	d := &APICordonDrainer{}
+	for i := 0; i < maxPDBTags; i++ {
+		if ns, name := d.pdbTag("default", fmt.Sprintf("pdb-%d", i)); name != fmt.Sprintf("pdb-%d", i) || ns != "default" {
+			t.Fatalf("pdbTag(default, pdb-%d): got %s/%s", i, ns, name)
+		}
+	}
+	if ns, name := d.pdbTag("default", "one-too-many"); ns != pdbTagOther || name != pdbTagOther {
+		t.Errorf("pdbTag() past the limit: want %s/%s, got %s/%s", pdbTagOther, pdbTagOther, ns, name)
+	}
+	if ns, name := d.pdbTag("default", "pdb-0"); ns != "default" || name != "pdb-0" {
+		t.Errorf("pdbTag() of a known budget: want default/pdb-0, got %s/%s", ns, name)
+	}
+}
@@ -0,0 +1,115 @@
+package kubernetes
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const deferralReasonPDBConflict = "pdb-conflict"
+
+// A DisruptionPreviewer previews the PodDisruptionBudgets covering the pods a
+// drain would evict.
+type DisruptionPreviewer interface {
+	// DisruptionPreview returns the PodDisruptionBudgets covering the pods
+	// that would be evicted from the supplied node.
+	DisruptionPreview(n *core.Node) ([]PDBImpact, error)
+}
+
+// WithPDBAwareOrder orders drains so that the pods of the same
+// PodDisruptionBudget are not evicted from several nodes at once. The budgets
+// covering the pods of each node are mapped when it is scheduled, if the
+// drainer is a DisruptionPreviewer. When a drain fires while a drain in
+// progress evicts pods of a budget they share, and their pods together exceed
+// the disruptions it allows, the drain yields to the next schedules and is
+// deferred by DefaultDrainDeferralPeriod. Deferred drains fire regardless once
+// the maximum deferral elapses.
+func WithPDBAwareOrder(enabled bool) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.pdbAwareOrder = enabled
+	}
+}
+
+// mapPDBs records the PodDisruptionBudgets covering the pods of the supplied
+// node on its schedule.
+func (d *DrainSchedules) mapPDBs(node *core.Node, sched *schedule) {
+	if !d.pdbAwareOrder {
+		return
+	}
+	p, ok := d.drainer.(DisruptionPreviewer)
+	if !ok {
+		return
+	}
+	impacts, err := p.DisruptionPreview(node)
+	if err != nil {
+		d.logger.Info("Cannot map the PodDisruptionBudgets of node", zap.String("node", node.GetName()), zap.Error(err))
+		return
+	}
+	d.Lock()
+	sched.pdbs = impacts
+	d.Unlock()
+}
+
+// pdbConflictLocked returns the drain in progress and the PodDisruptionBudget
+// the supplied schedule conflicts with, if any. It must be called with the
+// lock held.
+func (d *DrainSchedules) pdbConflictLocked(name string, sched *schedule) (string, PDBImpact, bool) {
+	for _, impact := range sched.pdbs {
+		for other := range d.inProgress {
+			s, ok := d.schedules[other]
+			if !ok || other == name {
+				continue
+			}
+			for _, o := range s.pdbs {
+				if o.Namespace != impact.Namespace || o.Name != impact.Name {
+					continue
+				}
+				// The budget allowed more disruptions before one of
+				// the drains started, so the larger snapshot is used.
+				allowed := impact.DisruptionsAllowed
+				if o.DisruptionsAllowed > allowed {
+					allowed = o.DisruptionsAllowed
+				}
+				if int32(len(impact.Pods)+len(o.Pods)) > allowed {
+					return other, impact, true
+				}
+			}
+		}
+	}
+	return "", PDBImpact{}, false
+}
+
+// deferPDBConflict returns true if the drain of the supplied schedule is
+// deferred because a drain in progress evicts pods of a PodDisruptionBudget
+// they share.
+func (d *DrainSchedules) deferPDBConflict(node *core.Node, sched *schedule) bool {
+	if !d.pdbAwareOrder {
+		return false
+	}
+	log := d.logger.With(zap.String("node", node.GetName()))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.Lock()
+	defer d.Unlock()
+	other, pdb, conflict := d.pdbConflictLocked(node.GetName(), sched)
+	if !conflict {
+		return false
+	}
+	budget := pdb.Namespace + "/" + pdb.Name
+	if d.maxDeferral > 0 && !d.now().Before(sched.created.Add(d.maxDeferral)) {
+		log.Info("Force firing drain deferred for too long", zap.String("reason", deferralReasonPDBConflict), zap.String("pdb", budget))
+		d.metrics.DrainForceFired(node.GetName())
+		sched.addSpanEvent("force fired", attribute.String("reason", deferralReasonPDBConflict))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for %s to drain pods of PodDisruptionBudget %s", sched.created.Format(time.RFC3339), other, budget)
+		return false
+	}
+	log.Info("Deferring drain, a drain in progress evicts pods of the same PodDisruptionBudget", zap.String("drain", other), zap.String("pdb", budget))
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Waiting for %s to drain pods of PodDisruptionBudget %s", other, budget)
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonPDBConflict))
+	d.recordDeferral(node.GetName(), deferralReasonPDBConflict)
+	d.metrics.PDBConflictAvoided(node.GetName())
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	return true
+}
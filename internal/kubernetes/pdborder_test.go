@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type previewDrainer struct {
+	*recordingDrainer
+	impacts map[string][]PDBImpact
+}
+
+func (d *previewDrainer) DisruptionPreview(n *v1.Node) ([]PDBImpact, error) {
+	return d.impacts[n.GetName()], nil
+}
+
+type pdbConflictMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	avoided []string
+}
+
+func (m *pdbConflictMetrics) PDBConflictAvoided(node string) {
+	m.Lock()
+	defer m.Unlock()
+	m.avoided = append(m.avoided, node)
+}
+
+func TestDrainSchedules_PDBAwareOrder(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	drainer := &previewDrainer{
+		recordingDrainer: newRecordingDrainer(),
+		impacts: map[string][]PDBImpact{
+			"a": {{Namespace: "default", Name: "web", DisruptionsAllowed: 1, Pods: []string{"web-a"}}},
+			"b": {{Namespace: "default", Name: "web", DisruptionsAllowed: 1, Pods: []string{"web-b"}}},
+			"c": {{Namespace: "default", Name: "batch", DisruptionsAllowed: 1, Pods: []string{"batch-c"}}},
+		},
+	}
+	m := &pdbConflictMetrics{}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Minute, zap.NewNop(),
+		WithDispatcher(NewManualDispatcher(start)),
+		WithMetricsRecorder(m),
+		WithPDBAwareOrder(true),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	nodes := map[string]*v1.Node{}
+	for _, name := range []string{"a", "b", "c"} {
+		nodes[name] = &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+		if _, err := scheduler.Schedule(nodes[name]); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+	}
+
+	// The drain of a is in progress, so b, which shares its tight budget,
+	// yields to c.
+	scheduler.Lock()
+	scheduler.inProgress["a"] = struct{}{}
+	scheduler.Unlock()
+	scheduler.runDrain(nodes["b"], scheduler.schedules["b"])
+	scheduler.runDrain(nodes["c"], scheduler.schedules["c"])
+	if got := drainer.nodes(); len(got) != 1 || got[0] != "c" {
+		t.Errorf("drained nodes: want [c], got %v", got)
+	}
+	if len(m.avoided) != 1 || m.avoided[0] != "b" {
+		t.Errorf("PDB conflicts avoided: want [b], got %v", m.avoided)
+	}
+
+	scheduler.Lock()
+	delete(scheduler.inProgress, "a")
+	scheduler.Unlock()
+	scheduler.runDrain(nodes["b"], scheduler.schedules["b"])
+	if got := drainer.nodes(); len(got) != 2 || got[1] != "b" {
+		t.Errorf("drained nodes: want [c b], got %v", got)
+	}
+}
+
+func TestDrainSchedules_PDBConflict(t *testing.T) {
+	web := func(allowed int32, pods ...string) []PDBImpact {
+		return []PDBImpact{{Namespace: "default", Name: "web", DisruptionsAllowed: allowed, Pods: pods}}
+	}
+	cases := []struct {
+		name     string
+		draining []PDBImpact
+		pending  []PDBImpact
+		conflict bool
+	}{
+		{name: "Tight", draining: web(1, "web-a"), pending: web(1, "web-b"), conflict: true},
+		{name: "Loose", draining: web(2, "web-a"), pending: web(1, "web-b")},
+		{name: "OtherBudget", draining: []PDBImpact{{Namespace: "default", Name: "batch", Pods: []string{"batch-a"}}}, pending: web(0, "web-b")},
+		{name: "OtherNamespace", draining: []PDBImpact{{Namespace: "kube-system", Name: "web", Pods: []string{"web-a"}}}, pending: web(0, "web-b")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &DrainSchedules{
+				schedules:  map[string]*schedule{"a": {pdbs: tc.draining}},
+				inProgress: map[string]struct{}{"a": {}},
+			}
+			other, _, conflict := d.pdbConflictLocked("b", &schedule{pdbs: tc.pending})
+			if conflict != tc.conflict {
+				t.Errorf("pdbConflictLocked(): want conflict %v, got %v", tc.conflict, conflict)
+			}
+			if conflict && other != "a" {
+				t.Errorf("pdbConflictLocked(): want a, got %s", other)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+)
+
+// WithPDBPrecheck determines whether Schedule refuses, with a
+// PDBWouldBlockError, to schedule the drain of nodes with pods covered by a
+// PodDisruptionBudget that currently allows no disruptions, since their drain
+// would only be blocked. Such nodes are scheduled once they are handled again
+// and their budgets allow disruptions. Budgets are previewed if the drainer is
+// a DisruptionPreviewer; drains are scheduled regardless if they cannot be.
+func WithPDBPrecheck(enabled bool) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.pdbPrecheck = enabled
+	}
+}
+
+// checkPDBs returns a PDBWouldBlockError if the PodDisruptionBudgets covering
+// the pods of the supplied node, whose drain is not scheduled yet, would block
+// its drain.
+func (d *DrainSchedules) checkPDBs(node *core.Node) error {
+	if !d.pdbPrecheck {
+		return nil
+	}
+	if has, _ := d.HasSchedule(node.GetName()); has {
+		return nil
+	}
+	p, ok := d.drainer.(DisruptionPreviewer)
+	if !ok {
+		return nil
+	}
+	impacts, err := p.DisruptionPreview(node)
+	if err != nil {
+		d.logger.Info("Cannot preview the PodDisruptionBudgets of node, scheduling anyway", zap.String("node", node.GetName()), zap.Error(err))
+		return nil
+	}
+	for _, impact := range impacts {
+		if impact.DisruptionsAllowed <= 0 {
+			return NewPDBWouldBlockError(node.GetName(), impact)
+		}
+	}
+	return nil
+}
+
+type PDBWouldBlockError struct {
+	error
+}
+
+func NewPDBWouldBlockError(name string, impact PDBImpact) error {
+	return &PDBWouldBlockError{
+		fmt.Errorf("PodDisruptionBudget %s/%s allows no disruptions, it would block evicting %d pods from node %s", impact.Namespace, impact.Name, len(impact.Pods), name),
+	}
+}
+
+func IsPDBWouldBlockError(err error) bool {
+	_, ok := err.(*PDBWouldBlockError)
+	return ok
+}
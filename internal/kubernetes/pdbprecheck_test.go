@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_PDBPrecheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		precheck bool
+		allowed  int32
+		want     func(error) bool
+	}{
+		{name: "NoDisruptionsAllowed", precheck: true, allowed: 0, want: IsPDBWouldBlockError},
+		{name: "DisruptionsAllowed", precheck: true, allowed: 1},
+		{name: "Disabled", allowed: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(
+				&core.Pod{
+					ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "web-1", Labels: map[string]string{"app": "web"}},
+					Spec:       core.PodSpec{NodeName: nodeName},
+				},
+				&policyv1.PodDisruptionBudget{
+					ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "web"},
+					Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &meta.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+					Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: tc.allowed},
+				},
+			)
+			scheduler := NewDrainSchedules(NewAPICordonDrainer(c), &record.FakeRecorder{}, 0, zap.NewNop(), WithPDBPrecheck(tc.precheck)).(*DrainSchedules)
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			_, err := scheduler.Schedule(node)
+			has, _ := scheduler.HasSchedule(nodeName)
+			if tc.want != nil {
+				if !tc.want(err) {
+					t.Fatalf("DrainSchedules.Schedule(): want PDBWouldBlockError, got %v", err)
+				}
+				if has {
+					t.Error("DrainSchedules.HasSchedule(): want no schedule, got one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DrainSchedules.Schedule(): %v", err)
+			}
+			if !has {
+				t.Error("DrainSchedules.HasSchedule(): want a schedule, got none")
+			}
+			scheduler.DeleteSchedule(nodeName)
+		})
+	}
+}
+
+func TestDrainSchedules_PDBPrecheckAlreadyScheduled(t *testing.T) {
+	drainer := &previewDrainer{
+		recordingDrainer: newRecordingDrainer(),
+		impacts:          map[string][]PDBImpact{},
+	}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithPDBPrecheck(true)).(*DrainSchedules)
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(): %v", err)
+	}
+	defer scheduler.DeleteSchedule(nodeName)
+
+	// Nodes already scheduled are not checked again.
+	drainer.impacts[nodeName] = []PDBImpact{{Namespace: "default", Name: "web", Pods: []string{"web-1"}}}
+	if _, err := scheduler.Schedule(node); !IsAlreadyScheduledError(err) {
+		t.Errorf("DrainSchedules.Schedule(): want AlreadyScheduledError, got %v", err)
+	}
+}
+
+func TestDrainSchedules_PDBPrecheckWithKey(t *testing.T) {
+	drainer := &previewDrainer{
+		recordingDrainer: newRecordingDrainer(),
+		impacts: map[string][]PDBImpact{
+			nodeName: {{Namespace: "default", Name: "web", Pods: []string{"web-1"}}},
+		},
+	}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithPDBPrecheck(true)).(*DrainSchedules)
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.ScheduleWithKey(node, "mykey"); !IsPDBWouldBlockError(err) {
+		t.Fatalf("DrainSchedules.ScheduleWithKey(): want PDBWouldBlockError, got %v", err)
+	}
+	if has, _ := scheduler.HasSchedule(nodeName); has {
+		t.Error("DrainSchedules.HasSchedule(): want no schedule, got one")
+	}
+}
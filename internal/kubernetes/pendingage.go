@@ -0,0 +1,48 @@
+package kubernetes
+
+import "time"
+
+// DefaultPendingAgeInterval is how often the age of the oldest pending schedule
+// is recorded.
+const DefaultPendingAgeInterval = 30 * time.Second
+
+// WithPendingAgeInterval records the age of the oldest pending schedule every
+// supplied interval, so that the gauge keeps growing while constraints hold
+// drains back. Zero disables the gauge.
+func WithPendingAgeInterval(interval time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.pendingAgeInterval = interval
+	}
+}
+
+// OldestPendingScheduleAge returns how long ago the oldest schedule whose
+// drain has not started yet was created, or zero if there is none. Schedules
+// whose drain is in progress, finished or failed are not pending.
+func (d *DrainSchedules) OldestPendingScheduleAge() time.Duration {
+	d.Lock()
+	defer d.Unlock()
+	return d.oldestPendingAgeLocked()
+}
+
+func (d *DrainSchedules) oldestPendingAgeLocked() time.Duration {
+	var oldest time.Time
+	for name, sched := range d.schedules {
+		if _, ok := d.inProgress[name]; ok || !sched.finish.IsZero() || sched.isFailed() {
+			continue
+		}
+		if oldest.IsZero() || sched.created.Before(oldest) {
+			oldest = sched.created
+		}
+	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return d.now().Sub(oldest)
+}
+
+// reportPendingAge records the age of the oldest pending schedule, then again
+// every pending age interval.
+func (d *DrainSchedules) reportPendingAge() {
+	d.metrics.OldestPendingScheduleAge(d.OldestPendingScheduleAge())
+	d.dispatcher.AfterFunc(d.pendingAgeInterval, d.reportPendingAge)
+}
@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type pendingAgeMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	ages []time.Duration
+}
+
+func (m *pendingAgeMetrics) OldestPendingScheduleAge(age time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.ages = append(m.ages, age)
+}
+
+func (m *pendingAgeMetrics) last() time.Duration {
+	m.Lock()
+	defer m.Unlock()
+	if len(m.ages) == 0 {
+		return -1
+	}
+	return m.ages[len(m.ages)-1]
+}
+
+func TestDrainSchedules_OldestPendingScheduleAge(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	m := &pendingAgeMetrics{}
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Hour, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithMetricsRecorder(m),
+		WithPendingAgeInterval(time.Minute),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+	if got := m.last(); got != 0 {
+		t.Errorf("OldestPendingScheduleAge: want 0 without schedules, got %s", got)
+	}
+
+	a := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	if _, err := scheduler.Schedule(a); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(a) error = %v", err)
+	}
+	dispatcher.ProcessDue(start.Add(time.Minute))
+	b := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "b"}}
+	if _, err := scheduler.Schedule(b); err != nil {
+		t.Fatalf("DrainSchedules.Schedule(b) error = %v", err)
+	}
+	dispatcher.ProcessDue(start.Add(3 * time.Minute))
+	if got, want := m.last(), 3*time.Minute; got != want {
+		t.Errorf("OldestPendingScheduleAge: want %s, got %s", want, got)
+	}
+
+	// Schedules whose drain started are no longer pending.
+	scheduler.Lock()
+	scheduler.inProgress["a"] = struct{}{}
+	scheduler.Unlock()
+	if got, want := scheduler.OldestPendingScheduleAge(), 2*time.Minute; got != want {
+		t.Errorf("OldestPendingScheduleAge(): want %s, got %s", want, got)
+	}
+	scheduler.DeleteSchedule("b")
+	if got := scheduler.OldestPendingScheduleAge(); got != 0 {
+		t.Errorf("OldestPendingScheduleAge(): want 0, got %s", got)
+	}
+}
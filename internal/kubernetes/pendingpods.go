@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultPendingPodsTimeout bounds each count of the pending pods of the
+	// cluster.
+	DefaultPendingPodsTimeout = 10 * time.Second
+
+	deferralReasonPendingPods = "pending-pods"
+)
+
+// A PendingPodCounter returns the number of pods of the cluster waiting to be
+// scheduled.
+type PendingPodCounter func(ctx context.Context) (int, error)
+
+// NewAPIPendingPodCounter returns a PendingPodCounter that counts the Pending
+// pods of the cluster not yet bound to a node.
+func NewAPIPendingPodCounter(c kubernetes.Interface) PendingPodCounter {
+	selector := fields.AndSelectors(
+		fields.OneTermEqualSelector("status.phase", string(core.PodPending)),
+		fields.OneTermEqualSelector("spec.nodeName", ""),
+	).String()
+	return func(ctx context.Context) (int, error) {
+		pods, err := c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{FieldSelector: selector})
+		if err != nil {
+			return 0, errors.Wrap(err, "cannot list pending pods")
+		}
+		n := 0
+		for _, p := range pods.Items {
+			if p.Status.Phase == core.PodPending && p.Spec.NodeName == "" {
+				n++
+			}
+		}
+		return n, nil
+	}
+}
+
+// WithPendingPodsGate counts the pending pods of the cluster when drains fire,
+// and defers them by DefaultDrainDeferralPeriod while there are more than the
+// supplied threshold, or they cannot be counted, so that drains do not add to
+// the pods the scheduler is already struggling to place. Deferred drains fire
+// regardless once the maximum deferral elapses. The count is recorded each time
+// it is taken. A threshold that is not positive disables the gate.
+func WithPendingPodsGate(count PendingPodCounter, threshold int) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.countPending = count
+		d.maxPending = threshold
+	}
+}
+
+// deferPendingPods returns true if the drain of the supplied schedule is
+// deferred because the cluster has too many pending pods.
+func (d *DrainSchedules) deferPendingPods(node *core.Node, sched *schedule) bool {
+	if d.countPending == nil || d.maxPending <= 0 {
+		return false
+	}
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	ctx, cancel := context.WithTimeout(sched.spanContext(), DefaultPendingPodsTimeout)
+	pending, err := d.countPending(ctx)
+	cancel()
+	if err != nil {
+		log.Info("Cannot count pending pods, assuming too many", zap.Error(err))
+	} else {
+		d.metrics.PendingPods(pending)
+		if pending <= d.maxPending {
+			return false
+		}
+	}
+	d.Lock()
+	defer d.Unlock()
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	if d.maxDeferral > 0 && !d.now().Before(sched.created.Add(d.maxDeferral)) {
+		log.Info("Force firing drain deferred for too long", zap.String("reason", deferralReasonPendingPods))
+		d.metrics.DrainForceFired(node.GetName())
+		sched.addSpanEvent("force fired", attribute.String("reason", deferralReasonPendingPods))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for pending pods to be scheduled", sched.created.Format(time.RFC3339))
+		return false
+	}
+	log.Info("Deferring drain, too many pods are pending", zap.Int("pending", pending), zap.Int("maxPending", d.maxPending))
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Too many pods are pending, more than %d, retrying in %s", d.maxPending, DefaultDrainDeferralPeriod)
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonPendingPods))
+	d.recordDeferral(node.GetName(), deferralReasonPendingPods)
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	return true
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+type pendingMetrics struct {
+	deferralMetrics
+	pending []int
+}
+
+func (m *pendingMetrics) PendingPods(count int) {
+	m.pending = append(m.pending, count)
+}
+
+func TestDrainSchedules_PendingPodsGate(t *testing.T) {
+	pending := 20
+	var countErr error
+	m := &pendingMetrics{}
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithPendingPodsGate(func(context.Context) (int, error) { return pending, countErr }, 10),
+		WithMetricsRecorder(m),
+	).(*DrainSchedules)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+
+	// Drains are deferred while too many pods are pending, or they cannot be
+	// counted.
+	for _, err := range []error{nil, errors.New("unavailable")} {
+		countErr = err
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+		if got := drainer.nodes(); len(got) != 0 {
+			t.Fatalf("drained %v while too many pods are pending", got)
+		}
+	}
+	if want := []string{nodeName + "=" + deferralReasonPendingPods, nodeName + "=" + deferralReasonPendingPods}; !reflect.DeepEqual(m.deferred, want) {
+		t.Errorf("DrainDeferred: want %v, got %v", want, m.deferred)
+	}
+
+	pending, countErr = 10, nil
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{nodeName}) {
+		t.Errorf("drained nodes once pending pods subsided: want %v, got %v", []string{nodeName}, got)
+	}
+	if want := []int{20, 10}; !reflect.DeepEqual(m.pending, want) {
+		t.Errorf("PendingPods: want %v, got %v", want, m.pending)
+	}
+}
+
+func TestAPIPendingPodCounter(t *testing.T) {
+	pod := func(name, node string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       v1.PodSpec{NodeName: node},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+	}
+	c := fake.NewSimpleClientset(
+		pod("unscheduled", "", v1.PodPending),
+		pod("unscheduled-too", "", v1.PodPending),
+		pod("pulling", "node", v1.PodPending),
+		pod("running", "node", v1.PodRunning),
+	)
+	got, err := NewAPIPendingPodCounter(c)(context.Background())
+	if err != nil {
+		t.Fatalf("count(): %v", err)
+	}
+	if got != 2 {
+		t.Errorf("count(): want 2, got %d", got)
+	}
+}
@@ -0,0 +1,128 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// A PodCounter counts the pods a drain of a node would evict.
+type PodCounter interface {
+	CountPods(ctx context.Context, n *core.Node) (int, error)
+}
+
+// CountPods returns the number of pods of the supplied node that would be
+// rescheduled elsewhere if the node was drained. Mirror and DaemonSet pods are
+// not counted.
+func (s *ClusterCapacityScorer) CountPods(ctx context.Context, n *core.Node) (int, error) {
+	pods, err := s.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{
+		FieldSelector: "spec.nodeName=" + n.GetName(),
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot list pods")
+	}
+	count := 0
+	for _, p := range pods.Items {
+		if p.Spec.NodeName != n.GetName() || !rescheduled(p) {
+			continue
+		}
+		if p.Status.Phase == core.PodSucceeded || p.Status.Phase == core.PodFailed {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// podEviction is a drain counted against the pod eviction budget.
+type podEviction struct {
+	at   time.Time
+	pods int
+}
+
+// WithPodEvictionBudget limits the pods evicted by drains to max per sliding
+// window, counting the pods of each node with the supplied counter when its
+// drain fires. Drains that would exceed the remaining budget are deferred
+// until enough pods leave the window, or until the maximum deferral elapses.
+// The drain of a node with more pods than the whole budget proceeds once no
+// other drain is in the window. Zero disables the limit.
+func WithPodEvictionBudget(max int, window time.Duration, c PodCounter) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.podBudget = max
+		d.podBudgetWindow = window
+		d.podCounter = c
+	}
+}
+
+// windowPodEvictionsLocked returns the drains counted against the pod eviction
+// budget that are still in the window, oldest first, forgetting older ones. It
+// must be called with the lock held.
+func (d *DrainSchedules) windowPodEvictionsLocked() ([]podEviction, int) {
+	cutoff := d.now().Add(-d.podBudgetWindow)
+	recent := d.podEvictions[:0]
+	total := 0
+	for _, e := range d.podEvictions {
+		if e.at.After(cutoff) {
+			recent = append(recent, e)
+			total += e.pods
+		}
+	}
+	d.podEvictions = recent
+	return recent, total
+}
+
+// checkPodBudget returns true if the drain of the supplied schedule may proceed
+// according to the pod eviction budget, if any, in which case its pods are
+// counted against the budget. Otherwise the drain is deferred until enough pods
+// leave the window.
+func (d *DrainSchedules) checkPodBudget(node *core.Node, sched *schedule) bool {
+	if d.podBudget <= 0 || d.podCounter == nil {
+		return true
+	}
+	log := d.logger.With(zap.String("node", node.GetName()))
+	pods, err := d.podCounter.CountPods(context.Background(), node)
+	if err != nil {
+		log.Info("Cannot count pods to evict, draining anyway", zap.Error(err))
+		return true
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	recent, total := d.windowPodEvictionsLocked()
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	if total > 0 && total+pods > d.podBudget {
+		if d.maxDeferral <= 0 || d.now().Before(sched.created.Add(d.maxDeferral)) {
+			// Wait for the oldest drains to leave the window until the
+			// pods of this one fit in the budget.
+			wait := DefaultDrainDeferralPeriod
+			remaining := total
+			for _, e := range recent {
+				remaining -= e.pods
+				if remaining == 0 || remaining+pods <= d.podBudget {
+					wait = e.at.Add(d.podBudgetWindow).Sub(d.now())
+					break
+				}
+			}
+			log.Info("Deferring drain, pod eviction budget exhausted", zap.Int("pods", pods), zap.Int("windowPods", total))
+			d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Evicting %d pods would exceed the budget of %d pods per %s, %d already evicted", pods, d.podBudget, d.podBudgetWindow, total)
+			sched.addSpanEvent("deferred", attribute.Int("pods", pods), attribute.Int("windowPods", total))
+			d.blockDrain(node.GetName(), deferralReasonPodBudget)
+			sched.timer.Reset(wait)
+			d.metrics.WindowPodEvictions(total)
+			return false
+		}
+		log.Info("Force firing drain deferred for too long", zap.Int("pods", pods), zap.Int("windowPods", total))
+		d.metrics.DrainForceFired(node.GetName())
+		sched.addSpanEvent("force fired", attribute.Int("pods", pods), attribute.Int("windowPods", total))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainForceFired, "Drain deferred since %s, no longer waiting for the pod eviction budget", sched.created.Format(time.RFC3339))
+	}
+	d.podEvictions = append(d.podEvictions, podEviction{at: d.now(), pods: pods})
+	d.metrics.WindowPodEvictions(total + pods)
+	return true
+}
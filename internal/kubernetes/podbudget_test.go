@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+// podCounts is a PodCounter backed by a map of node names to pod counts.
+type podCounts map[string]int
+
+func (c podCounts) CountPods(_ context.Context, n *v1.Node) (int, error) {
+	return c[n.GetName()], nil
+}
+
+func TestDrainSchedules_PodEvictionBudget(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManualDispatcher(start)
+	drainer := newRecordingDrainer()
+	counts := podCounts{"small": 4, "large": 8}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDispatcher(m), WithPodEvictionBudget(10, time.Hour, counts)).(*DrainSchedules)
+
+	for _, name := range []string{"small", "large"} {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		sched := scheduler.schedules[name]
+		sched.timer.Stop()
+		scheduler.runDrain(node, sched)
+	}
+	// The large node would blow the budget until the small one leaves the
+	// window.
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{"small"}) {
+		t.Errorf("drained: want [small], got %v", got)
+	}
+	m.ProcessDue(start.Add(59 * time.Minute))
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{"small"}) {
+		t.Errorf("drained before the window passed: want [small], got %v", got)
+	}
+	m.ProcessDue(start.Add(time.Hour))
+	if got := drainer.nodes(); !reflect.DeepEqual(got, []string{"small", "large"}) {
+		t.Errorf("drained once the window passed: want [small large], got %v", got)
+	}
+}
+
+func TestClusterCapacityScorerCountPods(t *testing.T) {
+	c := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: meta.ObjectMeta{Name: "a"}, Spec: v1.PodSpec{NodeName: nodeName}},
+		&v1.Pod{ObjectMeta: meta.ObjectMeta{Name: "b"}, Spec: v1.PodSpec{NodeName: nodeName}},
+		&v1.Pod{ObjectMeta: meta.ObjectMeta{Name: "done"}, Spec: v1.PodSpec{NodeName: nodeName}, Status: v1.PodStatus{Phase: v1.PodSucceeded}},
+		&v1.Pod{ObjectMeta: meta.ObjectMeta{Name: "elsewhere"}, Spec: v1.PodSpec{NodeName: "other"}},
+	)
+	got, err := NewClusterCapacityScorer(c).CountPods(context.Background(), &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if err != nil {
+		t.Fatalf("CountPods(): %v", err)
+	}
+	if got != 2 {
+		t.Errorf("CountPods(): want 2, got %d", got)
+	}
+}
@@ -30,6 +30,10 @@ import (
 // A PodFilterFunc returns true if the supplied pod passes the filter.
 type PodFilterFunc func(p core.Pod) (bool, error)
 
+// An EvictionFilter returns true if the supplied pod should be evicted, or false
+// and the reason it should not be.
+type EvictionFilter func(p *core.Pod) (evict bool, reason string)
+
 // MirrorPodFilter returns true if the supplied pod is not a mirror pod, i.e. a
 // pod created by a manifest on the node rather than the API server.
 func MirrorPodFilter(p core.Pod) (bool, error) {
@@ -0,0 +1,149 @@
+package kubernetes
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// A PostDrainAction is what to do with a node once it was successfully
+// drained.
+type PostDrainAction string
+
+// Post drain actions.
+const (
+	// PostDrainKeepCordoned leaves the node cordoned, typically because it is
+	// about to be replaced. This is the default.
+	PostDrainKeepCordoned PostDrainAction = "keepCordoned"
+	// PostDrainUncordon returns the node to service, typically because the
+	// condition that caused the drain is transient.
+	PostDrainUncordon PostDrainAction = "uncordon"
+)
+
+// A FailedDrainAction is what to do with a cordoned node whose pods could not
+// all be evicted.
+type FailedDrainAction string
+
+// Failed drain actions.
+const (
+	// FailedDrainKeepCordoned leaves the node cordoned and half drained until
+	// an operator intervenes. This is the default.
+	FailedDrainKeepCordoned FailedDrainAction = "keepCordoned"
+	// FailedDrainRollbackCordon uncordons the node so that it returns to
+	// service.
+	FailedDrainRollbackCordon FailedDrainAction = "rollbackCordon"
+	// FailedDrainRetainForRetry leaves the node cordoned and retries its
+	// drain as soon as the period between drains allows.
+	FailedDrainRetainForRetry FailedDrainAction = "retainForRetry"
+)
+
+// WithPostDrainPolicy configures what to do with successfully drained nodes,
+// depending on the node conditions that caused the drain. Nodes are kept
+// cordoned unless all the conditions that caused their drain map to
+// PostDrainUncordon. Uncordoning requires the drainer to also be a Cordoner.
+func WithPostDrainPolicy(policy map[string]PostDrainAction) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.postDrainPolicy = policy
+	}
+}
+
+// WithFailedDrainPolicy configures what to do with nodes whose drain failed
+// once they were cordoned, after all their attempts. Rolling back the cordon
+// requires the drainer to also be a Cordoner. Nodes are kept cordoned by
+// default.
+func WithFailedDrainPolicy(a FailedDrainAction) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.failedDrainAction = a
+	}
+}
+
+// drainReasons returns the types of the node conditions that caused the drain
+// of the supplied node. These are the conditions recorded on the node when it
+// was cordoned or, failing that, the conditions that are currently true.
+func drainReasons(n *core.Node) []string {
+	var reasons []string
+	for _, c := range parseConditionsFromAnnotation(n) {
+		reasons = append(reasons, string(c.Type))
+	}
+	if len(reasons) == 0 {
+		for _, c := range n.Status.Conditions {
+			if c.Status == core.ConditionTrue && !isDrainCondition(c.Type) {
+				reasons = append(reasons, string(c.Type))
+			}
+		}
+	}
+	sort.Strings(reasons)
+	return reasons
+}
+
+// postDrainAction returns what to do with a node drained because of the
+// supplied conditions.
+func (d *DrainSchedules) postDrainAction(reasons []string) PostDrainAction {
+	if len(d.postDrainPolicy) == 0 || len(reasons) == 0 {
+		return PostDrainKeepCordoned
+	}
+	for _, r := range reasons {
+		if d.postDrainPolicy[r] != PostDrainUncordon {
+			return PostDrainKeepCordoned
+		}
+	}
+	return PostDrainUncordon
+}
+
+// afterDrain applies the post drain policy to the supplied, successfully
+// drained node.
+func (d *DrainSchedules) afterDrain(node *core.Node, sched *schedule) {
+	if d.postDrainAction(sched.reasons) != PostDrainUncordon {
+		return
+	}
+	c, ok := d.drainer.(Cordoner)
+	if !ok {
+		d.logger.Warn("Cannot uncordon drained node, drainer cannot uncordon", zap.String("node", node.GetName()))
+		return
+	}
+	log := d.logger.With(zap.String("node", node.GetName()), zap.Strings("reasons", sched.reasons))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.UncordonStarting, "Uncordoning drained node")
+	if err := c.Uncordon(node, removeAnnotationMutator); err != nil {
+		log.Info("Failed to uncordon drained node", zap.Error(err))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.UncordonFailed, "Uncordoning failed: %v", err)
+		return
+	}
+	log.Info("Uncordoned drained node")
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.UncordonSucceeded, "Uncordoned drained node")
+}
+
+// afterFailedDrain applies the failed drain policy to the supplied node, whose
+// drain failed.
+func (d *DrainSchedules) afterFailedDrain(node *core.Node, sched *schedule) {
+	action := d.failedDrainAction
+	if action == "" {
+		action = FailedDrainKeepCordoned
+	}
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID), zap.String("action", string(action)))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	switch action {
+	case FailedDrainRollbackCordon:
+		c, ok := d.drainer.(Cordoner)
+		if !ok {
+			log.Warn("Cannot roll back the cordon of the node, drainer cannot uncordon")
+			return
+		}
+		d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.UncordonStarting, "Rolling back cordon after failed drain")
+		if err := c.Uncordon(node, removeAnnotationMutator); err != nil {
+			log.Info("Failed to roll back cordon", zap.Error(err))
+			d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.UncordonFailed, "Uncordoning failed: %v", err)
+			return
+		}
+		d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.UncordonSucceeded, "Rolled back cordon after failed drain")
+	case FailedDrainRetainForRetry:
+		if err := d.RetryFailed(node.GetName()); err != nil {
+			log.Info("Failed to retry failed drain", zap.Error(err))
+			return
+		}
+	}
+	log.Info("Applied failed drain policy")
+	d.metrics.FailedDrainAction(node.GetName(), string(action))
+}
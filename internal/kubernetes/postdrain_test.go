@@ -0,0 +1,148 @@
+package kubernetes
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type uncordonRecordingDrainer struct {
+	NoopCordonDrainer
+	uncordoned []string
+}
+
+func (d *uncordonRecordingDrainer) Uncordon(n *v1.Node, mutators ...nodeMutatorFn) error {
+	d.uncordoned = append(d.uncordoned, n.GetName())
+	return nil
+}
+
+func TestDrainSchedules_PostDrainPolicy(t *testing.T) {
+	policy := map[string]PostDrainAction{
+		"TransientProblem": PostDrainUncordon,
+		"HardwareFailure":  PostDrainKeepCordoned,
+	}
+	cases := []struct {
+		name         string
+		annotation   string
+		conditions   []v1.NodeCondition
+		wantUncordon bool
+	}{
+		{
+			name:         "TransientCondition",
+			annotation:   "TransientProblem=True,0s",
+			wantUncordon: true,
+		},
+		{
+			name:       "ReplacementCondition",
+			annotation: "HardwareFailure=True,0s",
+		},
+		{
+			name:       "TransientAndReplacementConditions",
+			annotation: "TransientProblem=True,0s;HardwareFailure=True,0s",
+		},
+		{
+			name:         "TrueConditionWithoutAnnotation",
+			conditions:   []v1.NodeCondition{{Type: "TransientProblem", Status: v1.ConditionTrue}},
+			wantUncordon: true,
+		},
+		{
+			name:       "UnknownCondition",
+			annotation: "Other=True,0s",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := &uncordonRecordingDrainer{}
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithPostDrainPolicy(policy)).(*DrainSchedules)
+			node := &v1.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName},
+				Status:     v1.NodeStatus{Conditions: tc.conditions},
+			}
+			if tc.annotation != "" {
+				node.Annotations = map[string]string{drainoConditionsAnnotationKey: tc.annotation}
+			}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			if got := len(drainer.uncordoned) == 1; got != tc.wantUncordon {
+				t.Errorf("uncordoned: want %v, got %v", tc.wantUncordon, drainer.uncordoned)
+			}
+		})
+	}
+}
+
+// evictionFailingDrainer cordons nodes, then fails to evict their pods.
+type evictionFailingDrainer struct {
+	uncordonRecordingDrainer
+	cordoned []string
+}
+
+func (d *evictionFailingDrainer) Cordon(n *v1.Node, mutators ...nodeMutatorFn) error {
+	d.cordoned = append(d.cordoned, n.GetName())
+	return nil
+}
+
+func (d *evictionFailingDrainer) Drain(n *v1.Node) error { return errors.New("eviction failed") }
+
+type failedDrainActionMetrics struct {
+	OpenCensusMetricsRecorder
+	actions []string
+}
+
+func (m *failedDrainActionMetrics) FailedDrainAction(node, action string) {
+	m.actions = append(m.actions, node+"="+action)
+}
+
+func TestDrainSchedules_FailedDrainPolicy(t *testing.T) {
+	cases := []struct {
+		name         string
+		action       FailedDrainAction
+		want         FailedDrainAction
+		wantUncordon bool
+		wantFailed   bool
+	}{
+		{name: "Default", want: FailedDrainKeepCordoned, wantFailed: true},
+		{name: "KeepCordoned", action: FailedDrainKeepCordoned, want: FailedDrainKeepCordoned, wantFailed: true},
+		{name: "RollbackCordon", action: FailedDrainRollbackCordon, want: FailedDrainRollbackCordon, wantUncordon: true, wantFailed: true},
+		{name: "RetainForRetry", action: FailedDrainRetainForRetry, want: FailedDrainRetainForRetry},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := &evictionFailingDrainer{}
+			m := &failedDrainActionMetrics{}
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+				WithStageConcurrency(0, 0), WithFailedDrainPolicy(tc.action), WithMetricsRecorder(m)).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+			defer scheduler.DeleteSchedule(nodeName)
+
+			if want := []string{nodeName}; !reflect.DeepEqual(drainer.cordoned, want) {
+				t.Errorf("cordoned: want %v, got %v", want, drainer.cordoned)
+			}
+			if got := len(drainer.uncordoned) == 1; got != tc.wantUncordon {
+				t.Errorf("uncordoned: want %v, got %v", tc.wantUncordon, drainer.uncordoned)
+			}
+			if _, failed := scheduler.HasSchedule(nodeName); failed != tc.wantFailed {
+				t.Errorf("HasSchedule(): want failed %v, got %v", tc.wantFailed, failed)
+			}
+			if want := []string{nodeName + "=" + string(tc.want)}; !reflect.DeepEqual(m.actions, want) {
+				t.Errorf("FailedDrainAction: want %v, got %v", want, m.actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// A PreCordonHook runs before a node is cordoned, while it still accepts new
+// pods, for example to deregister it from an external load balancer pool.
+type PreCordonHook func(ctx context.Context, n *core.Node) error
+
+// WithPreCordonHooks configures hooks run one after the other before cordoning
+// nodes, each bounded by the supplied timeout unless zero. When a hook fails
+// the remaining hooks are not run, and the node is neither cordoned nor
+// scheduled for drain. This is tried again the next time the node is handled.
+// Hooks do not run for nodes that are already cordoned.
+func WithPreCordonHooks(timeout time.Duration, hooks ...PreCordonHook) DrainingResourceEventHandlerOption {
+	return func(h *DrainingResourceEventHandler) {
+		h.preCordonHooks = append(h.preCordonHooks, hooks...)
+		h.preCordonTimeout = timeout
+	}
+}
+
+// runPreCordonHooks runs the pre-cordon hooks against the supplied node, and
+// returns true if they all succeeded.
+func (h *DrainingResourceEventHandler) runPreCordonHooks(n *core.Node) bool {
+	if len(h.preCordonHooks) == 0 {
+		return true
+	}
+	for _, hook := range h.preCordonHooks {
+		if err := h.runPreCordonHook(hook, n); err != nil {
+			h.logger.Info("Pre-cordon hook failed, deferring cordon and drain", zap.String("node", n.GetName()), zap.Error(err))
+			nr := &core.ObjectReference{Kind: "Node", Name: n.GetName(), UID: types.UID(n.GetName())}
+			h.eventRecorder.Eventf(nr, core.EventTypeWarning, h.eventReasons.DrainDeferred, "Pre-cordon hook failed: %v", err)
+			return false
+		}
+	}
+	return true
+}
+
+func (h *DrainingResourceEventHandler) runPreCordonHook(hook PreCordonHook, n *core.Node) error {
+	ctx := context.Background()
+	if h.preCordonTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.preCordonTimeout)
+		defer cancel()
+	}
+	return hook(ctx, n)
+}
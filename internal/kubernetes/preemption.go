@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// A terminationCheck finds the nodes being terminated by their provider, as
+// marked by node termination handlers.
+type terminationCheck struct {
+	nodes       NodeStore
+	taints      []string
+	annotations []string
+}
+
+// WithNodeTerminationCheck skips the drains of nodes being terminated by their
+// provider, typically because a spot or preemptible instance is reclaimed, so
+// that draino does not fight the node termination handler. A node is being
+// terminated if it carries a taint, or any annotation, whose key is one of the
+// supplied keys. Nodes are got from the supplied store when their drain fires;
+// the node as it was scheduled is checked if the store fails. The schedule of
+// such nodes is deleted, and their drain recorded with result preempted.
+func WithNodeTerminationCheck(nodes NodeStore, taints, annotations []string) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if len(taints) == 0 && len(annotations) == 0 {
+			d.termination = nil
+			return
+		}
+		d.termination = &terminationCheck{nodes: nodes, taints: taints, annotations: annotations}
+	}
+}
+
+// terminating returns the taint or annotation key marking the supplied node as
+// being terminated, if any.
+func (c *terminationCheck) terminating(n *core.Node) (string, bool) {
+	for _, key := range c.taints {
+		for _, t := range n.Spec.Taints {
+			if t.Key == key {
+				return key, true
+			}
+		}
+	}
+	for _, key := range c.annotations {
+		if _, ok := n.GetAnnotations()[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// skipPreempted returns true if the drain of the supplied schedule is skipped
+// because its node is being terminated by its provider. The schedule is then
+// deleted.
+func (d *DrainSchedules) skipPreempted(node *core.Node, sched *schedule) bool {
+	if d.termination == nil {
+		return false
+	}
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	current := node
+	if d.termination.nodes != nil {
+		fresh, err := d.termination.nodes.Get(node.GetName())
+		if err != nil {
+			log.Info("Cannot get node to check whether it is being terminated", zap.Error(err))
+		} else {
+			current = fresh
+		}
+	}
+	key, ok := d.termination.terminating(current)
+	if !ok {
+		return false
+	}
+	log.Info("Skipping drain of node being terminated by its provider", zap.String("key", key))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainPreempted, "Drain skipped because the node is being terminated by its provider (%s)", key)
+	sched.addSpanEvent("node preempted")
+	d.Lock()
+	sched.finish = d.now()
+	if c, ok := d.schedules[node.GetName()]; ok && c == sched {
+		d.deleteScheduleLocked(node.GetName(), sched)
+	}
+	d.Unlock()
+	d.saveState()
+	d.metrics.NodeDrained(node.GetName(), d.instanceType(node), kubeletVersion(node), tagResultPreempted)
+	d.recordWaveOutcome(node.GetName(), sched, false)
+	return true
+}
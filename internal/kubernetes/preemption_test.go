@@ -0,0 +1,93 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const spotTaint = "aws-node-termination-handler/spot-itn"
+
+func TestDrainSchedules_NodeTermination(t *testing.T) {
+	cases := []struct {
+		name        string
+		fresh       *v1.Node
+		wantDrained bool
+	}{
+		{
+			name:        "Healthy",
+			fresh:       &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+			wantDrained: true,
+		},
+		{
+			name: "Tainted",
+			fresh: &v1.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName},
+				Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: spotTaint, Effect: v1.TaintEffectNoSchedule}}},
+			},
+		},
+		{
+			name: "Annotated",
+			fresh: &v1.Node{ObjectMeta: meta.ObjectMeta{
+				Name:        nodeName,
+				Annotations: map[string]string{"example.com/terminating": "true"},
+			}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := newRecordingDrainer()
+			m := &recordingMetrics{}
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+				WithMetricsRecorder(m),
+				WithNodeTerminationCheck(nodeStore{nodeName: tc.fresh}, []string{spotTaint}, []string{"example.com/terminating"}),
+			).(*DrainSchedules)
+			// The node was healthy when scheduled.
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+
+			if got := len(drainer.nodes()) == 1; got != tc.wantDrained {
+				t.Errorf("drained: want %v, got %v", tc.wantDrained, got)
+			}
+			if tc.wantDrained {
+				return
+			}
+			if want := []string{nodeName + "=" + tagResultPreempted}; !reflect.DeepEqual(m.drained, want) {
+				t.Errorf("NodeDrained: want %v, got %v", want, m.drained)
+			}
+			if has, _ := scheduler.HasSchedule(nodeName); has {
+				t.Error("HasSchedule(): want schedule deleted, got scheduled")
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_NodeTerminationStoreFailure(t *testing.T) {
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Minute, zap.NewNop(),
+		WithNodeTerminationCheck(nodeStore{}, []string{spotTaint}, nil),
+	).(*DrainSchedules)
+	node := &v1.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: spotTaint, Effect: v1.TaintEffectNoSchedule}}},
+	}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Errorf("want no drains of the scheduled tainted node, got %v", got)
+	}
+}
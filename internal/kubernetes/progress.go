@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultProgressAnnotation is the node annotation the default progress
+	// reporter records drain progress to, e.g. "37/120".
+	DefaultProgressAnnotation = "draino.kubernetes.io/drain-progress"
+
+	// DefaultProgressInterval is the default minimum interval between
+	// progress reports.
+	DefaultProgressInterval = 10 * time.Second
+)
+
+// A ProgressReporter is told how many of the pods of a node being drained were
+// evicted so far.
+type ProgressReporter interface {
+	ReportProgress(ctx context.Context, n *core.Node, evicted, total int) error
+}
+
+// WithProgressReporter configures Drain to report its progress to the supplied
+// reporter at most once per interval, and once more when all pods are evicted.
+// Progress is only reported when it changed since the last report. A nil
+// reporter disables progress reporting.
+func WithProgressReporter(r ProgressReporter, interval time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.progress = r
+		d.progressInterval = interval
+	}
+}
+
+// AnnotationProgressReporter records drain progress to a node annotation.
+type AnnotationProgressReporter struct {
+	c          kubernetes.Interface
+	annotation string
+}
+
+// NewAnnotationProgressReporter returns a ProgressReporter recording drain
+// progress to the supplied annotation of the node being drained.
+func NewAnnotationProgressReporter(c kubernetes.Interface, annotation string) *AnnotationProgressReporter {
+	return &AnnotationProgressReporter{c: c, annotation: annotation}
+}
+
+// ReportProgress patches the annotation of the supplied node with the supplied
+// progress, leaving its other annotations untouched.
+func (r *AnnotationProgressReporter) ReportProgress(ctx context.Context, n *core.Node, evicted, total int) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]string{
+			r.annotation: fmt.Sprintf("%d/%d", evicted, total),
+		}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot encode drain progress annotation")
+	}
+	if _, err := r.c.CoreV1().Nodes().Patch(ctx, n.GetName(), types.MergePatchType, patch, meta.PatchOptions{}); err != nil {
+		return errors.Wrapf(err, "cannot annotate drain progress of node %s", n.GetName())
+	}
+	return nil
+}
+
+// progressTracker throttles the progress reported for a drain.
+type progressTracker struct {
+	d     *APICordonDrainer
+	n     *core.Node
+	total int
+	// reported is the number of evicted pods last reported, or -1.
+	reported int
+	ticker   *time.Ticker
+}
+
+// trackProgress returns a tracker of the progress of the drain of the
+// supplied number of pods of the supplied node, having reported that none of
+// them were evicted yet. It returns nil when progress is not reported.
+func (d *APICordonDrainer) trackProgress(ctx context.Context, n *core.Node, total int) *progressTracker {
+	if d.progress == nil {
+		return nil
+	}
+	interval := d.progressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	t := &progressTracker{d: d, n: n, total: total, reported: -1, ticker: time.NewTicker(interval)}
+	t.report(ctx, 0)
+	return t
+}
+
+// tick returns a channel receiving when progress may be reported again, or nil
+// if progress is not reported.
+func (t *progressTracker) tick() <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.ticker.C
+}
+
+// report reports the supplied number of evicted pods, unless already reported.
+// Failing to report progress does not fail the drain.
+func (t *progressTracker) report(ctx context.Context, evicted int) {
+	if t == nil || evicted == t.reported {
+		return
+	}
+	t.reported = evicted
+	if err := t.d.progress.ReportProgress(ctx, t.n, evicted, t.total); err != nil {
+		t.d.l.Info("Failed to report drain progress", zap.String("node", t.n.GetName()), zap.Error(err))
+	}
+}
+
+func (t *progressTracker) stop() {
+	if t != nil {
+		t.ticker.Stop()
+	}
+}
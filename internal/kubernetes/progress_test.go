@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+type recordingProgress struct {
+	sync.Mutex
+	evicted []int
+	totals  []int
+}
+
+func (r *recordingProgress) ReportProgress(ctx context.Context, n *core.Node, evicted, total int) error {
+	r.Lock()
+	defer r.Unlock()
+	r.evicted = append(r.evicted, evicted)
+	r.totals = append(r.totals, total)
+	return nil
+}
+
+func TestDrainProgress(t *testing.T) {
+	const pods = 5
+	objects := []runtime.Object{&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}}
+	for i := 0; i < pods; i++ {
+		objects = append(objects, &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	c := fake.NewSimpleClientset(objects...)
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		time.Sleep(50 * time.Millisecond)
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+	})
+
+	r := &recordingProgress{}
+	d := NewAPICordonDrainer(c, WithDeterministicOrder(true), WithProgressReporter(r, 10*time.Millisecond))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if len(r.evicted) < 3 {
+		t.Fatalf("ReportProgress(): want progress reported during the drain, got %v", r.evicted)
+	}
+	if r.evicted[0] != 0 || r.evicted[len(r.evicted)-1] != pods {
+		t.Errorf("ReportProgress(): want progress from 0 to %d, got %v", pods, r.evicted)
+	}
+	for i := 1; i < len(r.evicted); i++ {
+		if r.evicted[i] <= r.evicted[i-1] {
+			t.Errorf("ReportProgress(): want only changed progress reported, got %v", r.evicted)
+			break
+		}
+	}
+	for _, total := range r.totals {
+		if total != pods {
+			t.Errorf("ReportProgress(): want a total of %d pods, got %v", pods, r.totals)
+			break
+		}
+	}
+}
+
+func TestAnnotationProgressReporter(t *testing.T) {
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{
+		Name:        nodeName,
+		Annotations: map[string]string{"other": "kept"},
+	}})
+	r := NewAnnotationProgressReporter(c, DefaultProgressAnnotation)
+	if err := r.ReportProgress(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, 37, 120); err != nil {
+		t.Fatalf("ReportProgress(): %v", err)
+	}
+	n, err := c.CoreV1().Nodes().Get(context.Background(), nodeName, meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get(%v): %v", nodeName, err)
+	}
+	if got := n.GetAnnotations()[DefaultProgressAnnotation]; got != "37/120" {
+		t.Errorf("annotation %s: want 37/120, got %q", DefaultProgressAnnotation, got)
+	}
+	if got := n.GetAnnotations()["other"]; got != "kept" {
+		t.Errorf("annotation other: want it kept, got %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"go.uber.org/zap"
+)
+
+// DefaultPushInterval is the default interval between metrics pushes to a
+// Pushgateway.
+const DefaultPushInterval = time.Minute
+
+// A MetricsPusher pushes the metrics of a gatherer to a Prometheus Pushgateway,
+// periodically and a final time when stopped, so that the metrics of draino
+// runs that exit before being scraped are kept. A nil MetricsPusher does
+// nothing.
+type MetricsPusher struct {
+	pusher   *push.Pusher
+	interval time.Duration
+	l        *zap.Logger
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	stopped bool
+}
+
+// NewMetricsPusher returns a MetricsPusher pushing the metrics of the supplied
+// gatherer, as the supplied job, to the Pushgateway at the supplied URL every
+// supplied interval once started. It returns nil if the URL is empty.
+func NewMetricsPusher(url, job string, g prometheus.Gatherer, interval time.Duration, l *zap.Logger) *MetricsPusher {
+	if url == "" {
+		return nil
+	}
+	return &MetricsPusher{
+		pusher:   push.New(url, job).Gatherer(g),
+		interval: interval,
+		l:        l,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start pushes metrics every interval, in the background, until stopped. Zero
+// only pushes metrics when stopped.
+func (p *MetricsPusher) Start() {
+	if p == nil || p.interval <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done != nil || p.stopped {
+		return
+	}
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.push(); err != nil {
+					p.l.Info("Cannot push metrics", zap.Error(err))
+				}
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic pushes, then pushes the metrics a final time. Only
+// the first call pushes.
+func (p *MetricsPusher) Stop() error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return nil
+	}
+	p.stopped = true
+	close(p.stop)
+	done := p.done
+	p.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	return p.push()
+}
+
+func (p *MetricsPusher) push() error {
+	return errors.Wrap(p.pusher.Push(), "cannot push metrics to the Pushgateway")
+}
@@ -0,0 +1,75 @@
+package kubernetes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+)
+
+func TestMetricsPusherStop(t *testing.T) {
+	var mu sync.Mutex
+	var pushes []map[string]*dto.MetricFamily
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close() // nolint:errcheck
+		families := map[string]*dto.MetricFamily{}
+		dec := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+		for {
+			mf := &dto.MetricFamily{}
+			if err := dec.Decode(mf); err != nil {
+				break
+			}
+			families[mf.GetName()] = mf
+		}
+		mu.Lock()
+		pushes = append(pushes, families)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	registry := prometheus.NewRegistry()
+	drained := prometheus.NewCounter(prometheus.CounterOpts{Name: "draino_drained_nodes_total"})
+	registry.MustRegister(drained)
+
+	// Periodic pushes are disabled, so that only Stop pushes.
+	p := NewMetricsPusher(gateway.URL, "draino", registry, 0, zap.NewNop())
+	p.Start()
+	drained.Add(3)
+	if err := p.Stop(); err != nil {
+		t.Fatalf("p.Stop(): %v", err)
+	}
+	// Only the first Stop pushes.
+	if err := p.Stop(); err != nil {
+		t.Fatalf("p.Stop(): %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushes) != 1 {
+		t.Fatalf("pushes: want 1, got %d", len(pushes))
+	}
+	mf, ok := pushes[0]["draino_drained_nodes_total"]
+	if !ok || len(mf.GetMetric()) != 1 {
+		t.Fatalf("push: want draino_drained_nodes_total, got %v", pushes[0])
+	}
+	if got := mf.GetMetric()[0].GetCounter().GetValue(); got != 3 {
+		t.Errorf("draino_drained_nodes_total: want 3, got %v", got)
+	}
+}
+
+func TestMetricsPusherUnconfigured(t *testing.T) {
+	p := NewMetricsPusher("", "draino", prometheus.NewRegistry(), DefaultPushInterval, zap.NewNop())
+	if p != nil {
+		t.Fatalf("NewMetricsPusher(): want nil without a URL, got %v", p)
+	}
+	p.Start()
+	if err := p.Stop(); err != nil {
+		t.Errorf("p.Stop(): want no error, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultQuorumGroupLabel is the label of the pods of consensus based
+// workloads, such as etcd or ZooKeeper, whose value identifies their quorum
+// group within their namespace.
+const DefaultQuorumGroupLabel = "draino.kubernetes.io/quorum-group"
+
+// A QuorumSafetyValidator is a SafetyValidator that considers unsafe the pods
+// of a quorum group whose eviction would leave fewer healthy members than a
+// quorum, a strict majority of its members. Members are the pods of the same
+// namespace and group that did not complete, and healthy members those that
+// are ready and not terminating.
+type QuorumSafetyValidator struct {
+	c     kubernetes.Interface
+	label string
+}
+
+// NewQuorumSafetyValidator returns a QuorumSafetyValidator that identifies
+// quorum groups by the supplied pod label, and lists pods using the supplied
+// client.
+func NewQuorumSafetyValidator(c kubernetes.Interface, label string) *QuorumSafetyValidator {
+	return &QuorumSafetyValidator{c: c, label: label}
+}
+
+// UnsafePods returns the sorted namespaced names of the pods of the supplied
+// node whose eviction would break the quorum of their group.
+func (v *QuorumSafetyValidator) UnsafePods(ctx context.Context, n *core.Node) ([]string, error) {
+	pods, err := v.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{
+		FieldSelector: "spec.nodeName=" + n.GetName(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list pods")
+	}
+	// Group the members of each quorum group that run on the node, since
+	// evicting them all at once removes them all from the quorum.
+	groups := map[quorumGroup][]core.Pod{}
+	for _, p := range pods.Items {
+		group, ok := p.GetLabels()[v.label]
+		if !ok || p.Spec.NodeName != n.GetName() || completed(p) {
+			continue
+		}
+		g := quorumGroup{namespace: p.GetNamespace(), name: group}
+		groups[g] = append(groups[g], p)
+	}
+	var unsafe []string
+	for g, local := range groups {
+		ok, err := v.safe(ctx, g, local)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+		for _, p := range local {
+			unsafe = append(unsafe, p.GetNamespace()+"/"+p.GetName())
+		}
+	}
+	sort.Strings(unsafe)
+	return unsafe, nil
+}
+
+type quorumGroup struct {
+	namespace string
+	name      string
+}
+
+// safe returns true if the supplied group keeps a quorum of healthy members
+// once the supplied members are evicted.
+func (v *QuorumSafetyValidator) safe(ctx context.Context, g quorumGroup, evicted []core.Pod) (bool, error) {
+	selector := labels.SelectorFromSet(labels.Set{v.label: g.name})
+	pods, err := v.c.CoreV1().Pods(g.namespace).List(ctx, meta.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot list pods of quorum group %s/%s", g.namespace, g.name)
+	}
+	members, healthy := 0, 0
+	for _, p := range pods.Items {
+		if !selector.Matches(labels.Set(p.GetLabels())) || completed(p) {
+			continue
+		}
+		members++
+		if healthyMember(p) {
+			healthy++
+		}
+	}
+	for _, p := range evicted {
+		if healthyMember(p) {
+			healthy--
+		}
+	}
+	return healthy >= members/2+1, nil
+}
+
+// completed returns true if the supplied pod succeeded or failed.
+func completed(p core.Pod) bool {
+	return p.Status.Phase == core.PodSucceeded || p.Status.Phase == core.PodFailed
+}
+
+// healthyMember returns true if the supplied pod counts towards the quorum of
+// its group.
+func healthyMember(p core.Pod) bool {
+	return p.GetDeletionTimestamp() == nil && podReady(p)
+}
+
+// SafetyValidators is a SafetyValidator that considers unsafe the pods any of
+// its validators considers unsafe.
+type SafetyValidators []SafetyValidator
+
+// UnsafePods returns the sorted namespaced names of the pods of the supplied
+// node that any validator considers unsafe to reschedule.
+func (vs SafetyValidators) UnsafePods(ctx context.Context, n *core.Node) ([]string, error) {
+	seen := map[string]bool{}
+	var unsafe []string
+	for _, v := range vs {
+		pods, err := v.UnsafePods(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pods {
+			if !seen[p] {
+				seen[p] = true
+				unsafe = append(unsafe, p)
+			}
+		}
+	}
+	sort.Strings(unsafe)
+	return unsafe, nil
+}
@@ -0,0 +1,142 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func quorumMember(name, node string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{DefaultQuorumGroupLabel: "etcd"}},
+		Spec:       v1.PodSpec{NodeName: node},
+		Status:     v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}}},
+	}
+}
+
+func TestQuorumSafetyValidator(t *testing.T) {
+	cases := []struct {
+		name    string
+		members []*v1.Pod
+		want    []string
+	}{
+		{
+			name: "AllHealthy",
+			members: []*v1.Pod{
+				quorumMember("etcd-0", nodeName, true),
+				quorumMember("etcd-1", "other-1", true),
+				quorumMember("etcd-2", "other-2", true),
+			},
+		},
+		{
+			name: "OneDown",
+			members: []*v1.Pod{
+				quorumMember("etcd-0", nodeName, true),
+				quorumMember("etcd-1", "other-1", true),
+				quorumMember("etcd-2", "other-2", false),
+			},
+			want: []string{"default/etcd-0"},
+		},
+		{
+			name: "LocalMemberDown",
+			members: []*v1.Pod{
+				quorumMember("etcd-0", nodeName, false),
+				quorumMember("etcd-1", "other-1", true),
+				quorumMember("etcd-2", "other-2", true),
+			},
+		},
+		{
+			name: "TwoLocalMembers",
+			members: []*v1.Pod{
+				quorumMember("etcd-0", nodeName, true),
+				quorumMember("etcd-1", nodeName, true),
+				quorumMember("etcd-2", "other-2", true),
+			},
+			want: []string{"default/etcd-0", "default/etcd-1"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset()
+			for _, p := range tc.members {
+				if err := c.Tracker().Add(p); err != nil {
+					t.Fatalf("Tracker().Add(%s) error = %v", p.GetName(), err)
+				}
+			}
+			got, err := NewQuorumSafetyValidator(c, DefaultQuorumGroupLabel).UnsafePods(context.Background(), &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if err != nil {
+				t.Fatalf("UnsafePods() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("UnsafePods(): want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDrainSchedules_Quorum(t *testing.T) {
+	cases := []struct {
+		name      string
+		ready     bool
+		wantDrain bool
+	}{
+		{name: "Allowed", ready: true, wantDrain: true},
+		{name: "Deferred", ready: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(
+				quorumMember("etcd-0", nodeName, true),
+				quorumMember("etcd-1", "other-1", true),
+				quorumMember("etcd-2", "other-2", tc.ready),
+			)
+			drainer := newRecordingDrainer()
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+				WithSafeMode(NewQuorumSafetyValidator(c, DefaultQuorumGroupLabel), SafeModeDefer),
+			).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+			sched.timer.Stop()
+
+			if got := drainer.nodes(); (len(got) == 1) != tc.wantDrain {
+				t.Errorf("drained: want %v, got %v", tc.wantDrain, got)
+			}
+			if _, failed := scheduler.HasSchedule(nodeName); failed {
+				t.Error("want drain not failed, got failed")
+			}
+		})
+	}
+}
+
+func TestSafetyValidators(t *testing.T) {
+	c := fake.NewSimpleClientset(
+		quorumMember("etcd-0", nodeName, true),
+		quorumMember("etcd-1", "other-1", false),
+		ownedPod("bare", "", ""),
+	)
+	validator := SafetyValidators{NewControllerSafetyValidator(c), NewQuorumSafetyValidator(c, DefaultQuorumGroupLabel)}
+	got, err := validator.UnsafePods(context.Background(), &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if err != nil {
+		t.Fatalf("UnsafePods() error = %v", err)
+	}
+	// etcd-0 is both a bare pod and needed for quorum.
+	want := []string{"default/bare", "default/etcd-0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnsafePods(): want %v, got %v", want, got)
+	}
+}
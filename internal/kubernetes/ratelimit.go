@@ -0,0 +1,117 @@
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// An evictionPacer paces the removal of the pods of a node to a maximum rate,
+// and a minimum delay between removals, and observes the rate pods were
+// actually removed at.
+type evictionPacer struct {
+	limiter  *rate.Limiter
+	minDelay time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mu          sync.Mutex
+	removed     int
+	first, last time.Time
+	// turn serializes the removals waiting for the minimum delay. next is the
+	// earliest time the next removal may start, and delayed the total time
+	// removals waited for it.
+	turn    sync.Mutex
+	next    time.Time
+	delayed time.Duration
+}
+
+// newEvictionPacer returns a pacer allowing the supplied number of removals
+// per second, or any number if zero, each at least the supplied delay after
+// the previous one. Waits end once the supplied context is done or the pacer
+// is stopped.
+func newEvictionPacer(ctx context.Context, perSecond float64, minDelay time.Duration) *evictionPacer {
+	p := &evictionPacer{minDelay: minDelay}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	if perSecond > 0 {
+		p.limiter = rate.NewLimiter(rate.Limit(perSecond), 1)
+	}
+	return p
+}
+
+// wait blocks until a pod may be removed.
+func (p *evictionPacer) wait() error {
+	if p.limiter != nil {
+		if err := p.limiter.Wait(p.ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.removed == 0 {
+		p.first = now
+	}
+	p.last = now
+	p.removed++
+	return nil
+}
+
+// space blocks until the minimum delay elapsed since the previous removal
+// started. Removals wait for their turn one at a time.
+func (p *evictionPacer) space() error {
+	if p.minDelay <= 0 {
+		return nil
+	}
+	p.turn.Lock()
+	defer p.turn.Unlock()
+	p.mu.Lock()
+	wait := time.Until(p.next)
+	p.mu.Unlock()
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-p.ctx.Done():
+			return p.ctx.Err()
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if wait > 0 {
+		p.delayed += wait
+	}
+	p.next = time.Now().Add(p.minDelay)
+	return nil
+}
+
+// addedDelay returns the total time removals waited for the minimum delay
+// between them.
+func (p *evictionPacer) addedDelay() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.delayed
+}
+
+// stop ends the pending waits.
+func (p *evictionPacer) stop() {
+	p.cancel()
+}
+
+// observed returns the number of removals per second between the first and
+// the last one, if there were at least two.
+func (p *evictionPacer) observed() (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	elapsed := p.last.Sub(p.first)
+	if p.removed < 2 || elapsed <= 0 {
+		return 0, false
+	}
+	return float64(p.removed-1) / elapsed.Seconds(), true
+}
@@ -0,0 +1,68 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// A ReasonScheduler schedules drains on behalf of the reason that triggered
+// them, typically the type of the offending condition of the node.
+type ReasonScheduler interface {
+	// ScheduleWithReason schedules the drain of the supplied node, triggered
+	// by the supplied reason, like ScheduleWithTransition.
+	ScheduleWithReason(node *v1.Node, reason string, transitionTime time.Time) (time.Time, error)
+}
+
+// WithReasonAllowlist configures the only reasons ScheduleWithReason schedules
+// drains for. Drains triggered by other reasons are refused with a
+// DisallowedReasonError, as a guard against misconfigured condition matching.
+// Drains scheduled by Schedule, without a reason, are not checked. No reasons
+// disables the allowlist.
+func WithReasonAllowlist(reasons ...string) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if len(reasons) == 0 {
+			d.allowedReasons = nil
+			return
+		}
+		d.allowedReasons = make(map[string]bool, len(reasons))
+		for _, r := range reasons {
+			d.allowedReasons[r] = true
+		}
+	}
+}
+
+func (d *DrainSchedules) ScheduleWithReason(node *v1.Node, reason string, transitionTime time.Time) (time.Time, error) {
+	if err := d.checkReason(node.GetName(), reason); err != nil {
+		return time.Time{}, err
+	}
+	return d.ScheduleWithTransition(node, transitionTime)
+}
+
+// checkReason returns a DisallowedReasonError if the supplied reason for the
+// drain of the named node is not allowed.
+func (d *DrainSchedules) checkReason(name, reason string) error {
+	if d.allowedReasons == nil || d.allowedReasons[reason] {
+		return nil
+	}
+	d.logger.Warn("Refusing to schedule drain for a disallowed reason", zap.String("node", name), zap.String("reason", reason))
+	d.metrics.ReasonDisallowed(name, reason)
+	return NewDisallowedReasonError(name, reason)
+}
+
+type DisallowedReasonError struct {
+	error
+}
+
+func NewDisallowedReasonError(name, reason string) error {
+	return &DisallowedReasonError{
+		fmt.Errorf("drain of node %s triggered by %q, which is not an allowed reason", name, reason),
+	}
+}
+
+func IsDisallowedReasonError(err error) bool {
+	_, ok := err.(*DisallowedReasonError)
+	return ok
+}
@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_ReasonAllowlist(t *testing.T) {
+	v := &view.View{
+		Name:        "test_disallowed_reasons",
+		Measure:     MeasureDisallowedReasons,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{TagReason},
+	}
+	if err := view.Register(v); err != nil {
+		t.Fatalf("view.Register(): %v", err)
+	}
+	defer view.Unregister(v)
+
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, 0, zap.NewNop(), WithReasonAllowlist("KernelDeadlock")).(*DrainSchedules)
+
+	refused := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "refused"}}
+	if _, err := scheduler.ScheduleWithReason(refused, "Ready", time.Time{}); !IsDisallowedReasonError(err) {
+		t.Fatalf("DrainSchedules.ScheduleWithReason(): want DisallowedReasonError, got %v", err)
+	}
+	if has, _ := scheduler.HasSchedule(refused.GetName()); has {
+		t.Error("DrainSchedules.HasSchedule(): want no schedule for a disallowed reason, got one")
+	}
+
+	allowed := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "allowed"}}
+	if _, err := scheduler.ScheduleWithReason(allowed, "KernelDeadlock", time.Time{}); err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWithReason(): %v", err)
+	}
+	defer scheduler.DeleteSchedule(allowed.GetName())
+	if has, _ := scheduler.HasSchedule(allowed.GetName()); !has {
+		t.Error("DrainSchedules.HasSchedule(): want a schedule for an allowed reason, got none")
+	}
+
+	rows, err := view.RetrieveData(v.Name)
+	if err != nil {
+		t.Fatalf("view.RetrieveData(): %v", err)
+	}
+	if len(rows) != 1 || rows[0].Tags[0].Value != "Ready" || rows[0].Data.(*view.CountData).Value != 1 {
+		t.Errorf("disallowed reasons: want 1 Ready, got %v", rows)
+	}
+}
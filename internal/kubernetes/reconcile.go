@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// scheduledConditionPrefix prefixes the message of the DrainScheduled
+// condition, followed by the time the drain is scheduled for.
+const scheduledConditionPrefix = "Drain activity scheduled "
+
+// WithLegacyConditionTypes configures the condition types previous versions
+// of draino recorded drains with. ReconcileFromNodes treats them as
+// equivalent to the DrainScheduled condition, so that nodes handled before an
+// upgrade are not drained again. The DrainScheduled condition takes precedence
+// when a node carries both.
+func WithLegacyConditionTypes(types []string) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.legacyConditionTypes = types
+	}
+}
+
+// scheduledFor returns the time the drain of the supplied node is scheduled
+// for, according to its DrainScheduled condition, or else the first of the
+// supplied legacy conditions it carries. It returns false if the node has no
+// pending drain, i.e. the condition is absent, unparseable, or records a
+// finished drain.
+func scheduledFor(n *v1.Node, legacy ...string) (time.Time, bool) {
+	for _, t := range append([]string{ConditionDrainedScheduled}, legacy...) {
+		for _, c := range n.Status.Conditions {
+			if string(c.Type) == t {
+				return scheduledByCondition(c)
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// scheduledByCondition returns the time the supplied drain condition records
+// a pending drain for.
+func scheduledByCondition(c v1.NodeCondition) (time.Time, bool) {
+	if c.Status != v1.ConditionTrue {
+		return time.Time{}, false
+	}
+	if !strings.HasPrefix(c.Message, scheduledConditionPrefix) {
+		return time.Time{}, false
+	}
+	ts := strings.TrimPrefix(c.Message, scheduledConditionPrefix)
+	if i := strings.Index(ts, " |"); i >= 0 {
+		ts = ts[:i]
+	}
+	when, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return when, true
+}
+
+// ReconcileFromNodes recreates the in-memory schedules of the supplied nodes
+// that carry a DrainScheduled condition, or a legacy condition configured by
+// WithLegacyConditionTypes, for a pending drain, typically after a restart.
+// Drains scheduled in the future are re-armed, while those whose time passed
+// fire immediately. Failed drains being retried resume their attempts and
+// backoff from the state store, if any, and drains scheduled with a key keep
+// it. Nodes that already have a schedule are left untouched.
+func (d *DrainSchedules) ReconcileFromNodes(nodes []*v1.Node) {
+	d.Lock()
+	reconciled := 0
+	for _, n := range nodes {
+		when, ok := scheduledFor(n, d.legacyConditionTypes...)
+		if !ok {
+			continue
+		}
+		if _, ok := d.schedules[n.GetName()]; ok {
+			continue
+		}
+		if when.After(d.lastDrainScheduledFor) {
+			d.lastDrainScheduledFor = when
+		}
+		group := d.nodeGroup(n)
+		if group != "" && when.After(d.groupLastDrain[group]) {
+			d.groupLastDrain[group] = when
+		}
+		sched := d.newSchedule(n, when)
+		sched.group = group
+		sched.zone = nodeZone(n)
+		sched.dependsOn = parseDrainAfter(n)
+		sched.reasons = drainReasons(n)
+		d.restoreRetryLocked(n.GetName(), sched)
+		d.restoreKeyLocked(n.GetName(), sched)
+		d.startDrainSpan(n, sched)
+		d.schedules[n.GetName()] = sched
+		reconciled++
+		d.logger.Info("Reconciled drain schedule from node condition", zap.String("node", n.GetName()), zap.Time("when", when), zap.Bool("overdue", !when.After(d.now())))
+	}
+	d.Unlock()
+	if reconciled > 0 {
+		d.saveState()
+	}
+}
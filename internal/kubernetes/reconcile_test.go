@@ -0,0 +1,104 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func scheduledNode(name string, status v1.ConditionStatus, message string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: meta.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{
+			Type:    v1.NodeConditionType(ConditionDrainedScheduled),
+			Status:  status,
+			Message: message,
+		}}},
+	}
+}
+
+func TestDrainSchedules_ReconcileFromNodes(t *testing.T) {
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	scheduler.ReconcileFromNodes([]*v1.Node{
+		scheduledNode("future", v1.ConditionTrue, scheduledConditionPrefix+future.Format(time.RFC3339)),
+		scheduledNode("past", v1.ConditionTrue, scheduledConditionPrefix+past.Format(time.RFC3339)),
+		scheduledNode("completed", v1.ConditionFalse, scheduledConditionPrefix+past.Format(time.RFC3339)+" | Completed: "+past.Format(time.RFC3339)),
+		scheduledNode("garbage", v1.ConditionTrue, "Something else"),
+		{ObjectMeta: meta.ObjectMeta{Name: "unmarked"}},
+	})
+
+	select {
+	case name := <-drainer.drained:
+		if name != "past" {
+			t.Fatalf("want past drained immediately, got %v", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the overdue drain")
+	}
+
+	statuses := scheduler.HasSchedules([]string{"future", "past", "completed", "garbage", "unmarked"})
+	if s := statuses["future"]; !s.Has || !s.When.Equal(future) {
+		t.Errorf("future: want schedule for %v, got %+v", future, s)
+	}
+	if !statuses["past"].Has {
+		t.Errorf("past: want schedule")
+	}
+	for _, name := range []string{"completed", "garbage", "unmarked"} {
+		if statuses[name].Has {
+			t.Errorf("%s: want no schedule, got %+v", name, statuses[name])
+		}
+	}
+	if !scheduler.lastDrainScheduledFor.Equal(future) {
+		t.Errorf("lastDrainScheduledFor: want %v, got %v", future, scheduler.lastDrainScheduledFor)
+	}
+
+	// Reconciling again leaves existing schedules untouched.
+	sched := scheduler.schedules["future"]
+	scheduler.ReconcileFromNodes([]*v1.Node{
+		scheduledNode("future", v1.ConditionTrue, scheduledConditionPrefix+past.Format(time.RFC3339)),
+	})
+	if scheduler.schedules["future"] != sched {
+		t.Errorf("future: schedule replaced by second reconciliation")
+	}
+	if !sched.timer.Stop() {
+		t.Errorf("future: want timer armed")
+	}
+}
+
+func TestDrainSchedules_ReconcileLegacyConditions(t *testing.T) {
+	const legacy = "DrainoScheduled"
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithLegacyConditionTypes([]string{legacy}),
+	).(*DrainSchedules)
+
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	pending := scheduledConditionPrefix + future.Format(time.RFC3339)
+	completed := scheduledConditionPrefix + past.Format(time.RFC3339) + " | Completed: " + past.Format(time.RFC3339)
+	upgraded := scheduledNode("upgraded", v1.ConditionTrue, pending)
+	upgraded.Status.Conditions[0].Type = legacy
+	// The current condition takes precedence over the legacy one.
+	both := scheduledNode("both", v1.ConditionFalse, completed)
+	both.Status.Conditions = append(both.Status.Conditions, v1.NodeCondition{Type: legacy, Status: v1.ConditionTrue, Message: pending})
+	unknown := scheduledNode("unknown", v1.ConditionTrue, pending)
+	unknown.Status.Conditions[0].Type = "SomethingElse"
+	scheduler.ReconcileFromNodes([]*v1.Node{upgraded, both, unknown})
+
+	statuses := scheduler.HasSchedules([]string{"upgraded", "both", "unknown"})
+	if s := statuses["upgraded"]; !s.Has || !s.When.Equal(future) {
+		t.Errorf("upgraded: want schedule for %v, got %+v", future, s)
+	}
+	for _, name := range []string{"both", "unknown"} {
+		if statuses[name].Has {
+			t.Errorf("%s: want no schedule, got %+v", name, statuses[name])
+		}
+	}
+}
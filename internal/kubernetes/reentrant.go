@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// An AlreadyCordonedPolicy is what becomes of a drain that fires for a node
+// that draino had already cordoned, e.g. by a prior drain that failed or that
+// was in progress when draino restarted.
+type AlreadyCordonedPolicy string
+
+// Already cordoned policies.
+const (
+	// AlreadyCordonedProceed drains the node as usual. This is the default.
+	AlreadyCordonedProceed AlreadyCordonedPolicy = "proceed"
+	// AlreadyCordonedSkip deletes the schedule of the node without draining
+	// it, leaving it cordoned.
+	AlreadyCordonedSkip AlreadyCordonedPolicy = "skip"
+)
+
+// WithAlreadyCordonedPolicy distinguishes the drains that fire for nodes
+// draino had already cordoned from fresh drains. A node was cordoned by draino
+// if it was unschedulable when its drain was scheduled, and the supplied owner
+// annotation names draino, as set by CordonWithReason. Such drains are recorded
+// with result already-cordoned-by-us, then proceed or are skipped per the
+// supplied policy. An empty annotation disables the check.
+func WithAlreadyCordonedPolicy(ownerAnnotation string, p AlreadyCordonedPolicy) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.cordonOwnerAnnotation = ownerAnnotation
+		d.alreadyCordoned = p
+	}
+}
+
+// cordonedByUs returns true if the supplied node was cordoned by draino.
+func (d *DrainSchedules) cordonedByUs(n *core.Node) bool {
+	return d.cordonOwnerAnnotation != "" && n.Spec.Unschedulable && n.GetAnnotations()[d.cordonOwnerAnnotation] == Component
+}
+
+// skipAlreadyCordoned records the drain of the supplied schedule if its node
+// was already cordoned by draino, and returns true if the drain is skipped per
+// the already cordoned policy. The schedule is then deleted.
+func (d *DrainSchedules) skipAlreadyCordoned(node *core.Node, sched *schedule) bool {
+	if !d.cordonedByUs(node) {
+		return false
+	}
+	d.metrics.DrainReentered(node.GetName())
+	log := d.logger.With(zap.String("node", node.GetName()), zap.String("drainID", sched.drainID))
+	if d.alreadyCordoned != AlreadyCordonedSkip {
+		log.Info("Draining node already cordoned by draino")
+		return false
+	}
+	log.Info("Skipping drain of node already cordoned by draino")
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainSkippedCordoned, "Drain skipped because the node was already cordoned by draino")
+	sched.addSpanEvent("node already cordoned")
+	d.Lock()
+	sched.finish = d.now()
+	if c, ok := d.schedules[node.GetName()]; ok && c == sched {
+		d.deleteScheduleLocked(node.GetName(), sched)
+	}
+	d.Unlock()
+	d.saveState()
+	return true
+}
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type reentrantMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	reentered []string
+}
+
+func (m *reentrantMetrics) DrainReentered(node string) {
+	m.Lock()
+	defer m.Unlock()
+	m.reentered = append(m.reentered, node)
+}
+
+func TestDrainSchedules_AlreadyCordoned(t *testing.T) {
+	cordonedBy := func(owner string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{DefaultCordonOwnerAnnotation: owner}},
+			Spec:       v1.NodeSpec{Unschedulable: true},
+		}
+	}
+	cases := []struct {
+		name          string
+		node          *v1.Node
+		policy        AlreadyCordonedPolicy
+		wantReentered bool
+		wantDrained   bool
+	}{
+		{
+			name:        "Fresh",
+			node:        &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+			policy:      AlreadyCordonedSkip,
+			wantDrained: true,
+		},
+		{
+			name:        "CordonedByOthers",
+			node:        cordonedBy("kubectl"),
+			policy:      AlreadyCordonedSkip,
+			wantDrained: true,
+		},
+		{
+			name:          "Proceed",
+			node:          cordonedBy(Component),
+			policy:        AlreadyCordonedProceed,
+			wantReentered: true,
+			wantDrained:   true,
+		},
+		{
+			name:          "Skip",
+			node:          cordonedBy(Component),
+			policy:        AlreadyCordonedSkip,
+			wantReentered: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := newRecordingDrainer()
+			m := &reentrantMetrics{}
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+				WithMetricsRecorder(m),
+				WithAlreadyCordonedPolicy(DefaultCordonOwnerAnnotation, tc.policy),
+			).(*DrainSchedules)
+			if _, err := scheduler.Schedule(tc.node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(tc.node, sched)
+
+			if got := len(drainer.nodes()) == 1; got != tc.wantDrained {
+				t.Errorf("drained: want %v, got %v", tc.wantDrained, got)
+			}
+			if got := len(m.reentered) == 1; got != tc.wantReentered {
+				t.Errorf("DrainReentered(): want called %v, got %v", tc.wantReentered, m.reentered)
+			}
+			if has, _ := scheduler.HasSchedule(nodeName); has != tc.wantDrained {
+				t.Errorf("HasSchedule(): want %v, got %v", tc.wantDrained, has)
+			}
+		})
+	}
+}
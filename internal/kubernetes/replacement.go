@@ -0,0 +1,107 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultReplacementTimeout is how long Drain defers the eviction of the
+	// last ready replica of a workload when WithReplacementRoomCheck is
+	// configured without a timeout.
+	DefaultReplacementTimeout = 10 * time.Minute
+
+	replacementPollPeriod = 10 * time.Second
+)
+
+// WithReplacementRoomCheck configures Drain to defer the eviction of the last
+// ready replica of a workload while its replacement would not fit in the free
+// capacity of the other ready, schedulable nodes of the cluster, so that drains
+// do not leave workloads with nothing but a forever Pending pod. The check is
+// polled for up to the supplied timeout, or DefaultReplacementTimeout when
+// zero, after which the pod is evicted nonetheless. Like WithFeasibilityScorer
+// the check only accounts for resource requests. It lists the nodes and pods of
+// the cluster for each last replica, and is thus disabled by default.
+func WithReplacementRoomCheck(timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		if timeout <= 0 {
+			timeout = DefaultReplacementTimeout
+		}
+		d.replacementTimeout = timeout
+	}
+}
+
+// awaitReplacementRoom waits for the replacement of the supplied pod to fit in
+// the cluster, if it is the last ready replica of its workload and the
+// replacement check is configured.
+func (d *APICordonDrainer) awaitReplacementRoom(ctx context.Context, p core.Pod) error {
+	if d.replacementTimeout <= 0 || meta.GetControllerOf(&p) == nil || !rescheduled(p) {
+		return nil
+	}
+	period := d.replacementPollPeriod
+	if period <= 0 {
+		period = replacementPollPeriod
+	}
+	stop, cancel := context.WithTimeout(ctx, d.replacementTimeout)
+	defer cancel()
+	deferred := false
+	err := wait.PollImmediateUntil(period, func() (bool, error) {
+		ok, err := d.replacementFits(stop, p)
+		if err != nil {
+			d.l.Info("Cannot check whether the replacement of the pod fits, evicting it", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Error(err))
+			return true, nil
+		}
+		if !ok && !deferred {
+			deferred = true
+			d.l.Info("Deferring eviction of the last ready replica, its replacement would not fit", zap.String("pod", p.GetNamespace()+"/"+p.GetName()))
+			drainSummaryFrom(ctx).warn("%s/%s eviction deferred, the replacement of the last ready replica would not fit", p.GetNamespace(), p.GetName())
+			d.metrics.EvictionDeferred(p.Spec.NodeName)
+		}
+		return ok, nil
+	}, stop.Done())
+	if err == wait.ErrWaitTimeout {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "pod eviction cancelled")
+		}
+		d.l.Info("Replacement of the last ready replica still does not fit, evicting it", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Duration("timeout", d.replacementTimeout))
+		drainSummaryFrom(ctx).warn("%s/%s evicted after %s, although its replacement would not fit", p.GetNamespace(), p.GetName(), d.replacementTimeout)
+		return nil
+	}
+	return err
+}
+
+// replacementFits returns true unless the supplied pod is the last ready
+// replica of its workload, outside of its node, and its replacement does not
+// fit in the free capacity of any other schedulable node.
+func (d *APICordonDrainer) replacementFits(ctx context.Context, p core.Pod) (bool, error) {
+	pods, err := d.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return false, errors.Wrap(err, "cannot list pods")
+	}
+	owner := meta.GetControllerOf(&p).UID
+	for _, o := range pods.Items {
+		if o.GetNamespace() != p.GetNamespace() || o.Spec.NodeName == p.Spec.NodeName || !podReady(o) {
+			continue
+		}
+		if c := meta.GetControllerOf(&o); c != nil && c.UID == owner {
+			return true, nil
+		}
+	}
+	nodes, err := NewAPINodeStore(d.c).ListNodes(ctx)
+	if err != nil {
+		return false, err
+	}
+	cpu, memory := podRequests(p)
+	for _, f := range freeCapacities(nodes, pods.Items, p.Spec.NodeName) {
+		if f.cpu >= cpu && f.memory >= memory {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newReplicaPod returns a ready pod of the supplied controller, scheduled to
+// the supplied node.
+func newReplicaPod(name, node, cpu, controller string) *core.Pod {
+	p := newScheduledPod(name, node, cpu)
+	p.OwnerReferences = []meta.OwnerReference{{Kind: "ReplicaSet", Name: controller, UID: types.UID(controller), Controller: &isController}}
+	p.Status.Conditions = []core.PodCondition{{Type: core.PodReady, Status: core.ConditionTrue}}
+	return p
+}
+
+// newEvictingClientset returns a clientset holding the supplied objects, whose
+// evictions delete pods at once, and whose pod listings honor the node field
+// selector of Drain.
+func newEvictingClientset(objs ...runtime.Object) *fake.Clientset {
+	c := fake.NewSimpleClientset(objs...)
+	pods := core.SchemeGroupVersion.WithResource("pods")
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		node, ok := a.(clienttesting.ListAction).GetListRestrictions().Fields.RequiresExactMatch("spec.nodeName")
+		if !ok {
+			return false, nil, nil
+		}
+		o, err := c.Tracker().List(pods, core.SchemeGroupVersion.WithKind("Pod"), a.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		list := &core.PodList{}
+		for _, p := range o.(*core.PodList).Items {
+			if p.Spec.NodeName == node {
+				list.Items = append(list.Items, p)
+			}
+		}
+		return true, list, nil
+	})
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		e := a.(clienttesting.CreateAction).GetObject().(meta.Object)
+		return true, nil, c.Tracker().Delete(pods, e.GetNamespace(), e.GetName())
+	})
+	return c
+}
+
+// deferredEvictionMetrics counts the deferred evictions.
+type deferredEvictionMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	deferred int
+}
+
+func (m *deferredEvictionMetrics) EvictionDeferred(string) {
+	m.Lock()
+	defer m.Unlock()
+	m.deferred++
+}
+
+func TestDrainReplacementRoomCheck(t *testing.T) {
+	cases := []struct {
+		name         string
+		otherCPU     string
+		want         []string
+		wantDeferred int
+	}{
+		{
+			name:     "Fits",
+			otherCPU: "4",
+			want:     []string{"api", "web"},
+		},
+		{
+			// The eviction of the last replica of web is deferred, then
+			// goes ahead once the replacement timeout elapsed.
+			name:         "NoRoom",
+			otherCPU:     "2",
+			want:         []string{"api", "web"},
+			wantDeferred: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newEvictingClientset(
+				newCapacityNode(nodeName, "4", true),
+				newCapacityNode("other", tc.otherCPU, true),
+				// The other replica of api is still ready elsewhere.
+				newReplicaPod("api", nodeName, "3", "api"),
+				newReplicaPod("api-other", "other", "1", "api"),
+				newReplicaPod("web", nodeName, "2", "web"),
+			)
+			m := &deferredEvictionMetrics{}
+			d := NewAPICordonDrainer(c, WithReplacementRoomCheck(200*time.Millisecond), WithDeterministicOrder(true), WithDrainerMetricsRecorder(m))
+			d.replacementPollPeriod = 10 * time.Millisecond
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+			if got := evictedPods(c); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("evictions: want %v, got %v", tc.want, got)
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			if m.deferred != tc.wantDeferred {
+				t.Errorf("deferred evictions: want %d, got %d", tc.wantDeferred, m.deferred)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WithReplacementSpreadCheck verifies, once the pods of a drained node are
+// gone, that the replacements of the pods of each controller did not land on
+// nodes about to be drained too, i.e. cordoned or marked for drain, where they
+// would be evicted again. Replacements are the pods of the same controller
+// created since the drain started, looked up the supplied delay after the pods
+// are gone to leave them time to be scheduled. Replacements that landed on
+// such nodes are recorded as warnings of the drain summary; the drain still
+// succeeds. Zero delay disables the check.
+func WithReplacementSpreadCheck(delay time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.spreadCheckDelay = delay
+	}
+}
+
+// checkReplacementSpread warns about the replacements of the supplied pods
+// evicted from the supplied node since the supplied time that landed on nodes
+// also being drained.
+func (d *APICordonDrainer) checkReplacementSpread(ctx context.Context, n *core.Node, evicted []core.Pod, since time.Time) {
+	if d.spreadCheckDelay <= 0 {
+		return
+	}
+	// owners maps the UID of the controllers of the evicted pods to their
+	// namespaced name.
+	owners := map[types.UID]string{}
+	namespaces := map[string]bool{}
+	for i := range evicted {
+		if c := meta.GetControllerOf(&evicted[i]); c != nil {
+			owners[c.UID] = c.Kind + " " + evicted[i].GetNamespace() + "/" + c.Name
+			namespaces[evicted[i].GetNamespace()] = true
+		}
+	}
+	if len(owners) == 0 {
+		return
+	}
+	select {
+	case <-time.After(d.spreadCheckDelay):
+	case <-ctx.Done():
+		return
+	}
+
+	nodes, err := NewAPINodeStore(d.c).ListNodes(ctx)
+	if err != nil {
+		d.l.Info("Cannot check where replacements landed", zap.String("node", n.GetName()), zap.Error(err))
+		return
+	}
+	draining := map[string]bool{}
+	for _, o := range nodes {
+		if o.GetName() != n.GetName() && (o.Spec.Unschedulable || IsMarkedForDrain(o)) {
+			draining[o.GetName()] = true
+		}
+	}
+	// landed maps the controllers whose replacements landed on nodes being
+	// drained to these nodes.
+	landed := map[types.UID]map[string]bool{}
+	for ns := range namespaces {
+		pods, err := d.c.CoreV1().Pods(ns).List(ctx, meta.ListOptions{})
+		if err != nil {
+			d.l.Info("Cannot check where replacements landed", zap.String("node", n.GetName()), zap.String("namespace", ns), zap.Error(err))
+			return
+		}
+		for i := range pods.Items {
+			p := &pods.Items[i]
+			c := meta.GetControllerOf(p)
+			if c == nil || owners[c.UID] == "" || !draining[p.Spec.NodeName] || p.GetCreationTimestamp().Time.Before(since.Truncate(time.Second)) {
+				continue
+			}
+			d.l.Info("Replacement landed on a node being drained", zap.String("node", n.GetName()), zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.String("replacementNode", p.Spec.NodeName))
+			d.metrics.ReplicaMisplaced(n.GetName())
+			if landed[c.UID] == nil {
+				landed[c.UID] = map[string]bool{}
+			}
+			landed[c.UID][p.Spec.NodeName] = true
+		}
+	}
+	uids := make([]types.UID, 0, len(landed))
+	for uid := range landed {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return owners[uids[i]] < owners[uids[j]] })
+	for _, uid := range uids {
+		names := make([]string, 0, len(landed[uid]))
+		for name := range landed[uid] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		drainSummaryFrom(ctx).warn("replacements of %s landed on nodes also being drained: %s", owners[uid], strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,93 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// misplacedMetrics counts the replacements that landed on draining nodes.
+type misplacedMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	misplaced int
+}
+
+func (m *misplacedMetrics) ReplicaMisplaced(string) {
+	m.Lock()
+	defer m.Unlock()
+	m.misplaced++
+}
+
+func TestDrainReplacementSpreadCheck(t *testing.T) {
+	replacement := func(name, node, controller string) *core.Pod {
+		p := newReplicaPod(name, node, "1", controller)
+		p.CreationTimestamp = meta.NewTime(time.Now().Add(time.Minute))
+		return p
+	}
+	cordoned := newCapacityNode("cordoned", "4", true)
+	cordoned.Spec.Unschedulable = true
+	marked := newCapacityNode("marked", "4", true)
+	marked.Status.Conditions = append(marked.Status.Conditions, core.NodeCondition{Type: ConditionDrainedScheduled, Status: core.ConditionTrue})
+
+	cases := []struct {
+		name          string
+		replacements  []*core.Pod
+		want          []string
+		wantMisplaced int
+	}{
+		{
+			name:         "Spread",
+			replacements: []*core.Pod{replacement("web-2", "other", "web"), replacement("api-2", "other", "api")},
+		},
+		{
+			name: "LandedOnDrainingNodes",
+			replacements: []*core.Pod{
+				replacement("web-2", "cordoned", "web"),
+				replacement("web-3", "marked", "web"),
+				replacement("api-2", "other", "api"),
+			},
+			want:          []string{"replacements of ReplicaSet default/web landed on nodes also being drained: cordoned, marked"},
+			wantMisplaced: 2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The earlier replica of api on the cordoned node is not a
+			// replacement.
+			old := newReplicaPod("api-old", "cordoned", "1", "api")
+			c := newEvictingClientset(
+				newCapacityNode(nodeName, "4", true),
+				newCapacityNode("other", "4", true),
+				cordoned,
+				marked,
+				old,
+				newReplicaPod("web", nodeName, "1", "web"),
+				newReplicaPod("api", nodeName, "1", "api"),
+			)
+			for _, p := range tc.replacements {
+				if err := c.Tracker().Add(p); err != nil {
+					t.Fatalf("Tracker().Add(%s): %v", p.GetName(), err)
+				}
+			}
+			m := &misplacedMetrics{}
+			d := NewAPICordonDrainer(c, WithReplacementSpreadCheck(time.Millisecond), WithDrainerMetricsRecorder(m))
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+			summary, _ := d.DrainSummary(nodeName)
+			if !reflect.DeepEqual(summary.Warnings, tc.want) {
+				t.Errorf("summary warnings: want %v, got %v", tc.want, summary.Warnings)
+			}
+			m.Lock()
+			defer m.Unlock()
+			if m.misplaced != tc.wantMisplaced {
+				t.Errorf("misplaced replicas: want %d, got %d", tc.wantMisplaced, m.misplaced)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package kubernetes
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const deferralReasonNoPermits = "no-permits"
+
+// drainPermits grants a batch of drain permits at the start of each interval.
+// Permits left unused at the end of an interval do not carry over.
+type drainPermits struct {
+	batch    int
+	interval time.Duration
+
+	// remaining is the number of permits left in the current interval, which
+	// started at granted.
+	remaining int
+	granted   time.Time
+}
+
+// WithDrainPermits limits drains to batch per interval, granting a fresh batch
+// of drain permits at the start of each interval, e.g. 2 drains every 30
+// minutes. Intervals start when the scheduler is created. Each drain that fires
+// consumes a permit, and drains that fire once the permits of the current
+// interval are exhausted are deferred until the next interval starts, and
+// never force fired. Retries consume a permit too. Zero disables the limit.
+func WithDrainPermits(batch int, interval time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		if batch <= 0 || interval <= 0 {
+			d.permits = nil
+			return
+		}
+		d.permits = &drainPermits{batch: batch, interval: interval, remaining: batch}
+	}
+}
+
+// replenishLocked grants a fresh batch of permits if a new interval started by
+// the supplied time. It returns when the next interval starts.
+func (p *drainPermits) replenishLocked(now time.Time) time.Time {
+	if elapsed := now.Sub(p.granted); elapsed >= p.interval {
+		p.granted = p.granted.Add(elapsed.Truncate(p.interval))
+		p.remaining = p.batch
+	}
+	return p.granted.Add(p.interval)
+}
+
+// RemainingDrainPermits returns the number of drains the current interval still
+// permits, or -1 when drains are not limited by permits.
+func (d *DrainSchedules) RemainingDrainPermits() int {
+	d.Lock()
+	defer d.Unlock()
+	if d.permits == nil {
+		return -1
+	}
+	d.permits.replenishLocked(d.now())
+	return d.permits.remaining
+}
+
+// deferNoPermit returns true if the drain of the supplied schedule is deferred
+// because the permits of the current interval are exhausted. Otherwise the
+// drain consumes a permit.
+func (d *DrainSchedules) deferNoPermit(node *core.Node, sched *schedule) bool {
+	if d.permits == nil {
+		return false
+	}
+	d.Lock()
+	p := d.permits
+	next := p.replenishLocked(d.now())
+	if p.remaining > 0 {
+		p.remaining--
+		remaining := p.remaining
+		d.Unlock()
+		d.metrics.DrainPermits(remaining)
+		return false
+	}
+	wait := next.Sub(d.now())
+	sched.timer.Reset(wait)
+	d.Unlock()
+
+	d.logger.Info("Deferring drain, no drain permits left", zap.String("node", node.GetName()), zap.Time("replenished", next))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "No drain permits left, %d more are granted at %s", p.batch, next.Format(time.RFC3339))
+	sched.addSpanEvent("deferred", attribute.String("reason", deferralReasonNoPermits))
+	d.recordDeferral(node.GetName(), deferralReasonNoPermits)
+	d.metrics.DrainPermits(0)
+	return true
+}
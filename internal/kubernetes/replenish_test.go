@@ -0,0 +1,94 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type permitMetrics struct {
+	OpenCensusMetricsRecorder
+	sync.Mutex
+	permits  []int
+	deferred []string
+}
+
+func (m *permitMetrics) DrainPermits(remaining int) {
+	m.Lock()
+	defer m.Unlock()
+	m.permits = append(m.permits, remaining)
+}
+
+func (m *permitMetrics) DrainDeferred(node, reason string) {
+	m.Lock()
+	defer m.Unlock()
+	m.deferred = append(m.deferred, node+"="+reason)
+}
+
+func TestDrainSchedules_DrainPermits(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := newRecordingDrainer()
+	m := &permitMetrics{}
+	scheduler := NewDrainSchedules(drainer, record.NewFakeRecorder(100), time.Minute, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithMetricsRecorder(m),
+		WithDrainPermits(2, 30*time.Minute),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	// Drains fire a minute apart, starting a minute from now.
+	for i := 0; i < 3; i++ {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", node.GetName(), err)
+		}
+	}
+	if got := scheduler.RemainingDrainPermits(); got != 2 {
+		t.Errorf("RemainingDrainPermits(): want 2, got %d", got)
+	}
+
+	// The first two drains consume the permits of the interval, and the
+	// third is deferred to the next interval.
+	dispatcher.ProcessDue(start.Add(29 * time.Minute))
+	if want, got := []string{"node-0", "node-1"}, drainer.nodes(); !reflect.DeepEqual(want, got) {
+		t.Fatalf("drained nodes: want %v, got %v", want, got)
+	}
+	if got := scheduler.RemainingDrainPermits(); got != 0 {
+		t.Errorf("RemainingDrainPermits(): want 0, got %d", got)
+	}
+	if want := []string{"node-2=" + deferralReasonNoPermits}; !reflect.DeepEqual(want, m.deferred) {
+		t.Errorf("DrainDeferred(): want %v, got %v", want, m.deferred)
+	}
+
+	// A fresh batch is granted once the next interval starts.
+	dispatcher.ProcessDue(start.Add(30 * time.Minute))
+	if want, got := []string{"node-0", "node-1", "node-2"}, drainer.nodes(); !reflect.DeepEqual(want, got) {
+		t.Fatalf("drained nodes: want %v, got %v", want, got)
+	}
+	if want := []int{1, 0, 0, 1}; !reflect.DeepEqual(want, m.permits) {
+		t.Errorf("DrainPermits(): want %v, got %v", want, m.permits)
+	}
+
+	// Unused permits do not carry over.
+	dispatcher.ProcessDue(start.Add(95 * time.Minute))
+	if got := scheduler.RemainingDrainPermits(); got != 2 {
+		t.Errorf("RemainingDrainPermits(): want 2, got %d", got)
+	}
+}
+
+func TestDrainSchedules_DrainPermitsDisabled(t *testing.T) {
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Minute, zap.NewNop(),
+		WithDrainPermits(0, 30*time.Minute),
+	).(*DrainSchedules)
+	if got := scheduler.RemainingDrainPermits(); got != -1 {
+		t.Errorf("RemainingDrainPermits(): want -1, got %d", got)
+	}
+}
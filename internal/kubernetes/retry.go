@@ -0,0 +1,113 @@
+package kubernetes
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WithDrainRetries allows failed drains to be attempted up to the supplied
+// number of times before they are marked failed. The first retry waits the
+// supplied backoff, which doubles after each failed attempt. One attempt, the
+// default, disables retries.
+func WithDrainRetries(maxAttempts int, backoff time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.maxAttempts = maxAttempts
+		d.retryBackoff = backoff
+	}
+}
+
+// WithForceOnFinalAttempt escalates the final attempt of drains that are
+// retried to a force drain, deleting pods without a grace period, so that nodes
+// whose pods repeatedly refuse to be evicted eventually clear. Force drains
+// require a drainer that is a ForceDrainer; other drainers drain gracefully.
+func WithForceOnFinalAttempt(force bool) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.forceOnFinalAttempt = force
+	}
+}
+
+// escalateDrain returns true if the current attempt of the supplied schedule
+// must be a force drain, i.e. it is the final attempt of a retried drain.
+func (d *DrainSchedules) escalateDrain(node *core.Node, sched *schedule) bool {
+	if !d.forceOnFinalAttempt || d.maxAttempts < 2 || sched.attempt < d.maxAttempts {
+		return false
+	}
+	d.logger.Info("Escalating to force drain on final attempt", zap.String("node", node.GetName()), zap.String("drainID", sched.drainID), zap.Int("attempt", sched.attempt))
+	d.metrics.DrainEscalated(node.GetName())
+	sched.addSpanEvent("escalated to force drain", attribute.Int("attempt", sched.attempt))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainEscalated, "Escalating to force drain on final attempt %d of %d", sched.attempt, d.maxAttempts)
+	return true
+}
+
+// retryDrain returns true if the failed drain of the supplied schedule has
+// attempts left, in which case it is retried once the backoff elapses.
+func (d *DrainSchedules) retryDrain(node *core.Node, sched *schedule, started time.Time, err error) bool {
+	if sched.attempt >= d.maxAttempts {
+		return false
+	}
+	backoff := d.retryBackoff << uint(sched.attempt-1)
+	d.logger.Info("Failed to drain, retrying", zap.String("node", node.GetName()), zap.String("drainID", sched.drainID), zap.Int("attempt", sched.attempt), zap.Duration("backoff", backoff), zap.Error(err))
+	d.history.add(node.GetName(), DrainRecord{
+		DrainID:   sched.drainID,
+		Scheduled: sched.when,
+		Started:   started,
+		Finished:  d.now(),
+		Failed:    true,
+		Error:     DrainFailureReason(err),
+	})
+	sched.addSpanEvent("retrying", attribute.Int("attempt", sched.attempt))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainRetrying, "Drain attempt %d of %d failed, retrying in %s: %v", sched.attempt, d.maxAttempts, backoff, err)
+	if err := d.markDrain(node, DrainStateScheduled, sched.when, time.Time{}, ""); err != nil {
+		d.logger.Info("Failed to mark drain scheduled for retry", zap.String("node", node.GetName()), zap.Error(err))
+	}
+	d.setDrainState(node, DrainStateScheduled, d.now().Add(backoff), time.Time{}, "")
+	d.Lock()
+	sched.lastFailure = d.now()
+	sched.backoff = backoff
+	d.Unlock()
+	sched.timer.Reset(backoff)
+	d.saveState()
+	return true
+}
+
+// RetryFailed retries the failed drain of the named node. Its schedule is no
+// longer failed, its attempts are reset, and its drain fires as soon as the
+// period between drains allows, as if newly scheduled. The node is marked
+// scheduled again. It returns a NotFailedError if the node has no schedule, or
+// its schedule is not failed.
+func (d *DrainSchedules) RetryFailed(name string) error {
+	d.Lock()
+	sched, ok := d.schedules[name]
+	if !ok || !sched.isFailed() {
+		d.Unlock()
+		return NewNotFailedError(name)
+	}
+	when := d.WhenNextSchedule()
+	d.lastDrainScheduledFor = when
+	atomic.StoreInt32(&sched.failed, 0)
+	sched.attempt = 0
+	sched.lastFailure = time.Time{}
+	sched.backoff = 0
+	sched.failureReason = ""
+	sched.finish = time.Time{}
+	sched.when = when
+	sched.timer.Reset(when.Sub(d.now()))
+	d.Unlock()
+
+	d.logger.Info("Retrying failed drain", zap.String("node", name), zap.String("drainID", sched.drainID), zap.Time("when", when))
+	nr := &core.ObjectReference{Kind: "Node", Name: name, UID: types.UID(name)}
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainRetrying, "Retrying failed drain after %s", when.Format(time.RFC3339))
+	if err := d.markDrain(sched.node, DrainStateScheduled, when, time.Time{}, ""); err != nil {
+		d.logger.Info("Failed to mark drain scheduled for retry", zap.String("node", name), zap.Error(err))
+	}
+	d.setDrainState(sched.node, DrainStateScheduled, when, time.Time{}, "")
+	d.saveState()
+	return nil
+}
@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// gracefulFailDrainer is a ForceDrainer whose graceful drains always fail. It
+// records the kind of each drain.
+type gracefulFailDrainer struct {
+	NoopCordonDrainer
+	sync.Mutex
+	drains []string
+}
+
+func (d *gracefulFailDrainer) Drain(n *v1.Node) error {
+	d.Lock()
+	defer d.Unlock()
+	d.drains = append(d.drains, "graceful")
+	return errors.New("pod disruption budget")
+}
+
+func (d *gracefulFailDrainer) ForceDrain(_ context.Context, n *v1.Node) error {
+	d.Lock()
+	defer d.Unlock()
+	d.drains = append(d.drains, "force")
+	return nil
+}
+
+type escalationMetrics struct {
+	OpenCensusMetricsRecorder
+	escalated []string
+}
+
+func (m *escalationMetrics) DrainEscalated(node string) {
+	m.escalated = append(m.escalated, node)
+}
+
+func TestDrainSchedules_ForceOnFinalAttempt(t *testing.T) {
+	cases := []struct {
+		name       string
+		force      bool
+		wantDrains []string
+		wantFailed bool
+	}{
+		{
+			name:       "Escalated",
+			force:      true,
+			wantDrains: []string{"graceful", "graceful", "force"},
+		},
+		{
+			name:       "NotEscalated",
+			wantDrains: []string{"graceful", "graceful", "graceful"},
+			wantFailed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drainer := &gracefulFailDrainer{}
+			metrics := &escalationMetrics{}
+			recorder := record.NewFakeRecorder(100)
+			scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop(),
+				WithDrainRetries(3, time.Hour),
+				WithForceOnFinalAttempt(tc.force),
+				WithMetricsRecorder(metrics),
+			).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			for i := 0; i < 3; i++ {
+				sched.timer.Stop()
+				scheduler.runDrain(node, sched)
+			}
+			sched.timer.Stop()
+
+			if strings.Join(drainer.drains, ",") != strings.Join(tc.wantDrains, ",") {
+				t.Errorf("drains: want %v, got %v", tc.wantDrains, drainer.drains)
+			}
+			if _, failed := scheduler.HasSchedule(nodeName); failed != tc.wantFailed {
+				t.Errorf("failed: want %v, got %v", tc.wantFailed, failed)
+			}
+
+			var retries, escalations int
+			close(recorder.Events)
+			for e := range recorder.Events {
+				switch {
+				case strings.Contains(e, eventReasonDrainRetrying):
+					retries++
+				case strings.Contains(e, eventReasonDrainEscalated):
+					escalations++
+				}
+			}
+			wantEscalations := 0
+			if tc.force {
+				wantEscalations = 1
+			}
+			if retries != 2 {
+				t.Errorf("want 2 %s events, got %d", eventReasonDrainRetrying, retries)
+			}
+			if escalations != wantEscalations || len(metrics.escalated) != wantEscalations {
+				t.Errorf("escalations: want %d, got %d events and %d metrics", wantEscalations, escalations, len(metrics.escalated))
+			}
+			if got := len(scheduler.History(nodeName)); got != 3 {
+				t.Errorf("history: want 3 attempts, got %d", got)
+			}
+		})
+	}
+}
+
+// flakyDrainer fails its drains while fail is set, and records the drain
+// states it marks.
+type flakyDrainer struct {
+	NoopCordonDrainer
+	sync.Mutex
+	fail   bool
+	states []DrainState
+}
+
+func (d *flakyDrainer) Drain(n *v1.Node) error {
+	d.Lock()
+	defer d.Unlock()
+	if d.fail {
+		return errors.New("pod disruption budget")
+	}
+	return nil
+}
+
+func (d *flakyDrainer) MarkDrainState(n *v1.Node, state DrainState, when, finish time.Time, reason string) error {
+	d.Lock()
+	defer d.Unlock()
+	d.states = append(d.states, state)
+	return nil
+}
+
+func TestDrainSchedules_RetryFailed(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManualDispatcher(start)
+	drainer := &flakyDrainer{fail: true}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithDispatcher(m)).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	if err := scheduler.RetryFailed(nodeName); !IsNotFailedError(err) {
+		t.Errorf("RetryFailed() of an unknown schedule: want NotFailedError, got %v", err)
+	}
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	if err := scheduler.RetryFailed(nodeName); !IsNotFailedError(err) {
+		t.Errorf("RetryFailed() of a pending schedule: want NotFailedError, got %v", err)
+	}
+
+	m.ProcessDue(start.Add(time.Hour))
+	if _, failed := scheduler.HasSchedule(nodeName); !failed {
+		t.Fatalf("HasSchedule(): want a failed schedule")
+	}
+
+	drainer.Lock()
+	drainer.fail = false
+	drainer.states = nil
+	drainer.Unlock()
+	if err := scheduler.RetryFailed(nodeName); err != nil {
+		t.Fatalf("RetryFailed(): %v", err)
+	}
+	if has, failed := scheduler.HasSchedule(nodeName); !has || failed {
+		t.Errorf("HasSchedule() after RetryFailed(): want a pending schedule, got has %v, failed %v", has, failed)
+	}
+	if got := scheduler.schedules[nodeName].attempt; got != 0 {
+		t.Errorf("attempts after RetryFailed(): want 0, got %d", got)
+	}
+
+	m.ProcessDue(start.Add(2 * time.Hour))
+	if _, failed := scheduler.HasSchedule(nodeName); failed {
+		t.Errorf("HasSchedule(): want the retried drain to succeed")
+	}
+	want := []DrainState{DrainStateScheduled, DrainStateInProgress, DrainStateSucceeded}
+	if !reflect.DeepEqual(drainer.states, want) {
+		t.Errorf("drain states: want %v, got %v", want, drainer.states)
+	}
+}
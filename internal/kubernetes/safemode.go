@@ -0,0 +1,157 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// A SafeModePolicy determines what happens to drains of nodes whose pods
+// cannot be safely rescheduled.
+type SafeModePolicy string
+
+// Safe mode policies.
+const (
+	// SafeModeDefer defers the drain by DefaultDrainDeferralPeriod.
+	SafeModeDefer SafeModePolicy = "defer"
+	// SafeModeFail fails the drain.
+	SafeModeFail SafeModePolicy = "fail"
+)
+
+// A SafetyValidator finds the pods of a node that could not be safely
+// rescheduled if the node was drained.
+type SafetyValidator interface {
+	UnsafePods(ctx context.Context, n *core.Node) ([]string, error)
+}
+
+// A ControllerSafetyValidator is a conservative SafetyValidator that considers
+// unsafe the pods without a controller, and the pods of StatefulSets with a
+// single replica, which are unavailable until rescheduled.
+type ControllerSafetyValidator struct {
+	c kubernetes.Interface
+}
+
+// NewControllerSafetyValidator returns a ControllerSafetyValidator that lists
+// pods and gets their controllers using the supplied client.
+func NewControllerSafetyValidator(c kubernetes.Interface) *ControllerSafetyValidator {
+	return &ControllerSafetyValidator{c: c}
+}
+
+// UnsafePods returns the sorted namespaced names of the pods of the supplied
+// node that are unsafe to reschedule. Mirror and DaemonSet pods are never
+// unsafe, since they are not rescheduled elsewhere.
+func (v *ControllerSafetyValidator) UnsafePods(ctx context.Context, n *core.Node) ([]string, error) {
+	pods, err := v.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{
+		FieldSelector: "spec.nodeName=" + n.GetName(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list pods")
+	}
+	var unsafe []string
+	for _, p := range pods.Items {
+		if p.Spec.NodeName != n.GetName() || !rescheduled(p) {
+			continue
+		}
+		if p.Status.Phase == core.PodSucceeded || p.Status.Phase == core.PodFailed {
+			continue
+		}
+		ok, err := v.safe(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			unsafe = append(unsafe, p.GetNamespace()+"/"+p.GetName())
+		}
+	}
+	sort.Strings(unsafe)
+	return unsafe, nil
+}
+
+func (v *ControllerSafetyValidator) safe(ctx context.Context, p core.Pod) (bool, error) {
+	c := meta.GetControllerOf(&p)
+	if c == nil {
+		return false, nil
+	}
+	if c.Kind != kindStatefulSet {
+		return true, nil
+	}
+	ss, err := v.c.AppsV1().StatefulSets(p.GetNamespace()).Get(ctx, c.Name, meta.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot get StatefulSet %s/%s", p.GetNamespace(), c.Name)
+	}
+	replicas := int32(1)
+	if ss.Spec.Replicas != nil {
+		replicas = *ss.Spec.Replicas
+	}
+	return replicas > 1, nil
+}
+
+// WithSafeMode configures a validator run when a drain fires. Drains of nodes
+// with pods the validator finds unsafe to reschedule are deferred or failed per
+// the supplied policy. Drains are deferred if the validator fails.
+func WithSafeMode(v SafetyValidator, policy SafeModePolicy) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.safetyValidator = v
+		d.safeModePolicy = policy
+	}
+}
+
+// checkSafety returns true if the drain of the supplied schedule may proceed
+// according to the safety validator, if any. Otherwise the drain is deferred by
+// DefaultDrainDeferralPeriod, or failed, per the safe mode policy.
+func (d *DrainSchedules) checkSafety(node *core.Node, sched *schedule) bool {
+	if d.safetyValidator == nil {
+		return true
+	}
+	log := d.logger.With(zap.String("node", node.GetName()))
+	nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+	unsafe, err := d.safetyValidator.UnsafePods(context.Background(), node)
+	if err != nil {
+		log.Info("Cannot check whether pods can be safely rescheduled, deferring drain", zap.Error(err))
+		d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Cannot check whether pods can be safely rescheduled: %v", err)
+		sched.addSpanEvent("deferred", attribute.String("reason", "cannot check pod safety"))
+		d.blockDrain(node.GetName(), deferralReasonUnsafePods)
+		sched.timer.Reset(DefaultDrainDeferralPeriod)
+		return false
+	}
+	if len(unsafe) == 0 {
+		return true
+	}
+
+	if d.safeModePolicy == SafeModeFail {
+		log.Info("Refusing to drain, pods cannot be safely rescheduled", zap.Strings("pods", unsafe))
+		d.failDrain(node, sched, d.now(), NewUnsafePodsError(node.GetName(), unsafe))
+		sched.endSpan("unsafe pods")
+		return false
+	}
+	log.Info("Deferring drain, pods cannot be safely rescheduled", zap.Strings("pods", unsafe))
+	d.eventRecorder.Eventf(nr, core.EventTypeWarning, d.eventReasons.DrainDeferred, "Pods cannot be safely rescheduled: %s", strings.Join(unsafe, ", "))
+	sched.addSpanEvent("deferred", attribute.StringSlice("unsafePods", unsafe))
+	d.blockDrain(node.GetName(), deferralReasonUnsafePods)
+	sched.timer.Reset(DefaultDrainDeferralPeriod)
+	return false
+}
+
+type UnsafePodsError struct {
+	error
+}
+
+func NewUnsafePodsError(name string, pods []string) error {
+	return &UnsafePodsError{
+		fmt.Errorf("node %s has pods that cannot be safely rescheduled: %s", name, strings.Join(pods, ", ")),
+	}
+}
+
+func IsUnsafePodsError(err error) bool {
+	_, ok := err.(*UnsafePodsError)
+	return ok
+}
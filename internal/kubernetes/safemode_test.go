@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func ownedPod(name, kind, owner string) *v1.Pod {
+	p := &v1.Pod{
+		ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       v1.PodSpec{NodeName: nodeName},
+	}
+	if kind != "" {
+		p.OwnerReferences = []meta.OwnerReference{{Kind: kind, Name: owner, Controller: &isController}}
+	}
+	return p
+}
+
+func statefulSet(name string, replicas int32) *apps.StatefulSet {
+	return &apps.StatefulSet{
+		ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       apps.StatefulSetSpec{Replicas: &replicas},
+	}
+}
+
+func TestControllerSafetyValidator(t *testing.T) {
+	c := fake.NewSimpleClientset(
+		ownedPod("bare", "", ""),
+		ownedPod("db-0", kindStatefulSet, "db"),
+		ownedPod("cache-0", kindStatefulSet, "cache"),
+		ownedPod("web-1234", "ReplicaSet", "web-12"),
+		ownedPod("agent", kindDaemonSet, "agent"),
+		statefulSet("db", 1),
+		statefulSet("cache", 3),
+	)
+	got, err := NewControllerSafetyValidator(c).UnsafePods(context.Background(), &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if err != nil {
+		t.Fatalf("UnsafePods() error = %v", err)
+	}
+	want := []string{"default/bare", "default/db-0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnsafePods(): want %v, got %v", want, got)
+	}
+}
+
+func TestDrainSchedules_SafeMode(t *testing.T) {
+	cases := []struct {
+		name       string
+		policy     SafeModePolicy
+		wantFailed bool
+	}{
+		{name: "Defer", policy: SafeModeDefer},
+		{name: "Fail", policy: SafeModeFail, wantFailed: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(ownedPod("db-0", kindStatefulSet, "db"), statefulSet("db", 1))
+			drainer := newRecordingDrainer()
+			scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithSafeMode(NewControllerSafetyValidator(c), tc.policy)).(*DrainSchedules)
+			node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+			if _, err := scheduler.Schedule(node); err != nil {
+				t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+			}
+			sched := scheduler.schedules[nodeName]
+			sched.timer.Stop()
+			scheduler.runDrain(node, sched)
+			sched.timer.Stop()
+
+			if got := drainer.nodes(); len(got) != 0 {
+				t.Errorf("want no drains, got %v", got)
+			}
+			if _, failed := scheduler.HasSchedule(nodeName); failed != tc.wantFailed {
+				t.Errorf("failed: want %v, got %v", tc.wantFailed, failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// A PrepareHook asks an external system, such as a change management service,
+// to prepare for the drain of the supplied node at the supplied time. The drain
+// is not scheduled if it returns an error.
+type PrepareHook func(ctx context.Context, n *v1.Node, when time.Time) error
+
+// A CommitHook confirms to an external system that the drain of the supplied
+// node, prepared by a PrepareHook, is scheduled for the supplied time.
+type CommitHook func(ctx context.Context, n *v1.Node, when time.Time)
+
+// WithScheduleHooks configures Schedule to run the supplied prepare hook before
+// the drain condition of a node is written and the timer of its schedule is
+// armed, and the supplied commit hook once the condition is written. Each hook
+// is bounded by the supplied timeout unless zero. When the prepare hook fails
+// the schedule is rolled back and Schedule returns a PrepareFailedError. Either
+// hook may be nil.
+func WithScheduleHooks(prepare PrepareHook, commit CommitHook, timeout time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.prepareHook = prepare
+		d.commitHook = commit
+		d.scheduleHookTimeout = timeout
+	}
+}
+
+// scheduleHookContext returns the context schedule hooks run with.
+func (d *DrainSchedules) scheduleHookContext() (context.Context, context.CancelFunc) {
+	if d.scheduleHookTimeout > 0 {
+		return context.WithTimeout(context.Background(), d.scheduleHookTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// prepareSchedule runs the prepare hook for the supplied schedule, whose timer
+// must not be armed yet, and arms it if the hook succeeds. Otherwise the
+// schedule is rolled back, and the reserved drain time released back to the
+// previous one unless a later drain was scheduled meanwhile.
+func (d *DrainSchedules) prepareSchedule(node *v1.Node, sched *schedule, previous, reserved time.Time) error {
+	if d.prepareHook == nil {
+		return nil
+	}
+	ctx, cancel := d.scheduleHookContext()
+	err := d.prepareHook(ctx, node, sched.when)
+	cancel()
+
+	d.Lock()
+	defer d.Unlock()
+	if d.schedules[node.GetName()] != sched {
+		// The schedule was deleted while being prepared.
+		return NewPrepareFailedError(node.GetName(), fmt.Errorf("schedule deleted"))
+	}
+	if err == nil {
+		sched.timer.Reset(sched.when.Sub(d.now()))
+		return nil
+	}
+	d.logger.Info("Prepare hook failed, rolling back schedule", zap.String("node", node.GetName()), zap.Error(err))
+	d.deleteScheduleLocked(node.GetName(), sched)
+	if d.lastDrainScheduledFor.Equal(reserved) {
+		d.lastDrainScheduledFor = previous
+	}
+	return NewPrepareFailedError(node.GetName(), err)
+}
+
+// commitSchedule runs the commit hook for the supplied schedule, whose drain
+// condition was written.
+func (d *DrainSchedules) commitSchedule(node *v1.Node, sched *schedule) {
+	if d.commitHook == nil {
+		return
+	}
+	ctx, cancel := d.scheduleHookContext()
+	defer cancel()
+	d.commitHook(ctx, node, sched.when)
+}
+
+type PrepareFailedError struct {
+	error
+}
+
+func NewPrepareFailedError(name string, err error) error {
+	return &PrepareFailedError{
+		fmt.Errorf("cannot prepare the drain of node %s: %v", name, err),
+	}
+}
+
+func IsPrepareFailedError(err error) bool {
+	_, ok := err.(*PrepareFailedError)
+	return ok
+}
@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_ScheduleHooks(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := &markingDrainer{now: dispatcher.Now}
+	var prepared, committed []int
+	prepare := func(ctx context.Context, n *v1.Node, when time.Time) error {
+		prepared = append(prepared, len(drainer.marks()))
+		return nil
+	}
+	commit := func(ctx context.Context, n *v1.Node, when time.Time) {
+		committed = append(committed, len(drainer.marks()))
+	}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithScheduleHooks(prepare, commit, time.Second),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	when, err := scheduler.Schedule(node)
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	// The drain is prepared before the condition is written, and committed
+	// after.
+	if len(prepared) != 1 || prepared[0] != 0 {
+		t.Errorf("prepare hook: want one call before the condition is written, got %v", prepared)
+	}
+	if len(committed) != 1 || committed[0] != 1 {
+		t.Errorf("commit hook: want one call after the condition is written, got %v", committed)
+	}
+	if has, _ := scheduler.HasSchedule(node.GetName()); !has {
+		t.Fatal("DrainSchedules.HasSchedule(): want a schedule, got none")
+	}
+	if got := dispatcher.ProcessDue(when); got != 1 {
+		t.Errorf("ProcessDue(): want the drain timer to fire, got %d timers fired", got)
+	}
+}
+
+func TestDrainSchedules_PrepareHookRollback(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewManualDispatcher(start)
+	drainer := &markingDrainer{now: dispatcher.Now}
+	committed := 0
+	prepare := func(ctx context.Context, n *v1.Node, when time.Time) error {
+		return errors.New("change rejected")
+	}
+	commit := func(ctx context.Context, n *v1.Node, when time.Time) { committed++ }
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Hour, zap.NewNop(),
+		WithDispatcher(dispatcher),
+		WithScheduleHooks(prepare, commit, 0),
+	).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	if _, err := scheduler.Schedule(node); !IsPrepareFailedError(err) {
+		t.Fatalf("DrainSchedules.Schedule(): want PrepareFailedError, got %v", err)
+	}
+	if has, _ := scheduler.HasSchedule(node.GetName()); has {
+		t.Error("DrainSchedules.HasSchedule(): want the schedule rolled back, got one")
+	}
+	if got := drainer.marks(); len(got) != 0 {
+		t.Errorf("MarkDrain(): want no call, got %v", got)
+	}
+	if committed != 0 {
+		t.Errorf("commit hook: want no call, got %d", committed)
+	}
+	if !scheduler.lastDrainScheduledFor.Equal(start) {
+		t.Errorf("lastDrainScheduledFor: want the reserved drain time released to %s, got %s", start, scheduler.lastDrainScheduledFor)
+	}
+	if got := dispatcher.ProcessDue(start.Add(24 * time.Hour)); got != 0 {
+		t.Errorf("ProcessDue(): want no timer armed, got %d timers fired", got)
+	}
+}
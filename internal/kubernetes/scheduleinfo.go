@@ -0,0 +1,70 @@
+package kubernetes
+
+import (
+	"sort"
+	"time"
+)
+
+// A ScheduleInfo describes the drain schedule of a node.
+type ScheduleInfo struct {
+	Node    string     `json:"node"`
+	DrainID string     `json:"drainID"`
+	State   DrainState `json:"state"`
+	// When is the time the drain is scheduled for.
+	When time.Time `json:"when"`
+	// Created is the time the schedule was created.
+	Created time.Time `json:"created"`
+	// Finished is the time the drain succeeded or failed, zero until then.
+	Finished time.Time `json:"finished"`
+	// Attempt counts the times the drain started.
+	Attempt       int      `json:"attempt"`
+	FailureReason string   `json:"failureReason,omitempty"`
+	Group         string   `json:"group,omitempty"`
+	Zone          string   `json:"zone,omitempty"`
+	Reasons       []string `json:"reasons,omitempty"`
+	DependsOn     []string `json:"dependsOn,omitempty"`
+}
+
+// ListSchedules describes the drain schedules of all nodes, sorted by node
+// name.
+func (d *DrainSchedules) ListSchedules() []ScheduleInfo {
+	d.Lock()
+	defer d.Unlock()
+	infos := make([]ScheduleInfo, 0, len(d.schedules))
+	for name, s := range d.schedules {
+		infos = append(infos, d.scheduleInfoLocked(name, s))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Node < infos[j].Node })
+	return infos
+}
+
+// ScheduleInfo describes the drain schedule of the named node. It returns
+// false if the node has no schedule.
+func (d *DrainSchedules) ScheduleInfo(name string) (ScheduleInfo, bool) {
+	d.Lock()
+	defer d.Unlock()
+	s, ok := d.schedules[name]
+	if !ok {
+		return ScheduleInfo{}, false
+	}
+	return d.scheduleInfoLocked(name, s), true
+}
+
+// scheduleInfoLocked describes the supplied schedule of the named node. It
+// must be called with the lock held.
+func (d *DrainSchedules) scheduleInfoLocked(name string, s *schedule) ScheduleInfo {
+	return ScheduleInfo{
+		Node:          name,
+		DrainID:       s.drainID,
+		State:         d.drainStateLocked(name, s),
+		When:          s.when,
+		Created:       s.created,
+		Finished:      s.finish,
+		Attempt:       s.attempt,
+		FailureReason: s.failureReason,
+		Group:         s.group,
+		Zone:          s.zone,
+		Reasons:       append([]string(nil), s.reasons...),
+		DependsOn:     append([]string(nil), s.dependsOn...),
+	}
+}
@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+)
+
+// Default sidecar shutdown settings, those of the Istio proxy agent.
+const (
+	DefaultSidecarQuitPort = 15020
+	DefaultSidecarQuitPath = "/quitquitquit"
+
+	sidecarShutdownTimeout = 10 * time.Second
+)
+
+// A SidecarShutdownHook asks the sidecars of the supplied pod, such as a service
+// mesh proxy, to exit so that the pod terminates once its main containers did.
+type SidecarShutdownHook func(ctx context.Context, p *core.Pod) error
+
+// WithSidecarShutdown configures Drain to run the supplied hook against each
+// evicted pod carrying the supplied label, whatever its value, once its
+// eviction was accepted, so that pods kept alive by sidecars that do not exit
+// on their own do not hang the drain. A failing hook is recorded as a warning
+// of the drain summary, and the drain carries on. An empty label disables the
+// hook.
+func WithSidecarShutdown(label string, hook SidecarShutdownHook) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.sidecarLabel = label
+		d.sidecarShutdown = hook
+	}
+}
+
+// NewHTTPSidecarShutdownHook returns a SidecarShutdownHook that POSTs to the
+// supplied path, on the supplied port of the IP of the pod, like the quit
+// endpoint of the Istio proxy agent.
+func NewHTTPSidecarShutdownHook(port int, path string) SidecarShutdownHook {
+	c := &http.Client{Timeout: sidecarShutdownTimeout}
+	return func(ctx context.Context, p *core.Pod) error {
+		if p.Status.PodIP == "" {
+			return errors.Errorf("pod %s/%s has no IP", p.GetNamespace(), p.GetName())
+		}
+		url := fmt.Sprintf("http://%s%s", net.JoinHostPort(p.Status.PodIP, strconv.Itoa(port)), path)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+		if err != nil {
+			return errors.Wrap(err, "cannot create sidecar shutdown request")
+		}
+		rsp, err := c.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "cannot shut down the sidecars of pod %s/%s", p.GetNamespace(), p.GetName())
+		}
+		rsp.Body.Close() // nolint:errcheck,gosec
+		if rsp.StatusCode >= http.StatusBadRequest {
+			return errors.Errorf("cannot shut down the sidecars of pod %s/%s: %s", p.GetNamespace(), p.GetName(), rsp.Status)
+		}
+		return nil
+	}
+}
+
+// shutdownSidecars runs the sidecar shutdown hook against the supplied pod, if
+// it carries the sidecar label.
+func (d *APICordonDrainer) shutdownSidecars(ctx context.Context, p core.Pod) {
+	if d.sidecarLabel == "" || d.sidecarShutdown == nil {
+		return
+	}
+	if _, ok := p.GetLabels()[d.sidecarLabel]; !ok {
+		return
+	}
+	if err := d.sidecarShutdown(ctx, &p); err != nil {
+		d.l.Info("Cannot shut down sidecars", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Error(err))
+		drainSummaryFrom(ctx).warn("%s/%s sidecars not shut down: %v", p.GetNamespace(), p.GetName(), err)
+		return
+	}
+	d.l.Info("Shut down sidecars", zap.String("pod", p.GetNamespace()+"/"+p.GetName()))
+}
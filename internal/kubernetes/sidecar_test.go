@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDrainSidecarShutdown(t *testing.T) {
+	const label = "sidecar.istio.io/inject"
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "meshed", Labels: map[string]string{label: "true"}}},
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "plain"}},
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	var mu sync.Mutex
+	var shutdown []string
+	hook := func(ctx context.Context, p *core.Pod) error {
+		mu.Lock()
+		defer mu.Unlock()
+		shutdown = append(shutdown, p.GetName())
+		return nil
+	}
+	d := NewAPICordonDrainer(c, WithSidecarShutdown(label, hook))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"meshed"}; !reflect.DeepEqual(shutdown, want) {
+		t.Errorf("sidecar shutdowns: want %v, got %v", want, shutdown)
+	}
+}
+
+func TestHTTPSidecarShutdownHook(t *testing.T) {
+	var method, path string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+	}))
+	defer s.Close()
+	host, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.SplitHostPort(): %v", err)
+	}
+	p, _ := strconv.Atoi(port)
+
+	hook := NewHTTPSidecarShutdownHook(p, DefaultSidecarQuitPath)
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "meshed"}, Status: core.PodStatus{PodIP: host}}
+	if err := hook(context.Background(), pod); err != nil {
+		t.Fatalf("hook(): %v", err)
+	}
+	if method != http.MethodPost || path != DefaultSidecarQuitPath {
+		t.Errorf("request: want POST %s, got %s %s", DefaultSidecarQuitPath, method, path)
+	}
+}
@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+)
+
+// WithStageConcurrency splits each drain into a cordon stage and an eviction
+// stage, each bounded by its own limit on the drains in that stage at once.
+// The cordon stage makes sure the node is still cordoned once its drain fires,
+// which is cheap; the eviction stage evicts its pods, which is not. Cordons can
+// then outrun evictions, so that all the nodes due for drain stop receiving
+// pods while few are evicted at a time. Zero means no limit for a stage. The
+// cordon stage requires a drainer that is a Cordoner.
+func WithStageConcurrency(maxCordons, maxEvictions int) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.stages = true
+		if maxCordons > 0 {
+			d.cordonSlots = NewLocalRateBudget(maxCordons)
+		}
+		if maxEvictions > 0 {
+			d.evictionSlots = NewLocalRateBudget(maxEvictions)
+		}
+	}
+}
+
+// StageInFlight returns how many drains are currently in their cordon stage,
+// and in their eviction stage. Both are zero unless drains are split in stages.
+func (d *DrainSchedules) StageInFlight() (cordons, evictions int) {
+	return int(atomic.LoadInt32(&d.cordonsInFlight)), int(atomic.LoadInt32(&d.evictionsInFlight))
+}
+
+// cordonStage cordons the supplied node, if drains are split in stages, once
+// the cordon limit allows it. It returns false if the supplied context is done
+// first. Failures to cordon are logged but do not fail the drain, since nodes
+// are cordoned when their drain is scheduled.
+func (d *DrainSchedules) cordonStage(ctx context.Context, node *core.Node) bool {
+	c, ok := d.drainer.(Cordoner)
+	if !d.stages || !ok {
+		return true
+	}
+	if d.cordonSlots != nil {
+		if err := d.acquireStage(ctx, d.cordonSlots, node.GetName()); err != nil {
+			return false
+		}
+		defer d.cordonSlots.Release()
+	}
+	atomic.AddInt32(&d.cordonsInFlight, 1)
+	defer atomic.AddInt32(&d.cordonsInFlight, -1)
+	if err := c.Cordon(node); err != nil {
+		d.logger.Info("Cannot cordon node before evicting its pods", zap.String("node", node.GetName()), zap.Error(err))
+	}
+	return true
+}
+
+// evictionStage waits until the eviction limit allows the eviction of the pods
+// of the supplied node. It returns a function ending the eviction stage, or
+// false if the supplied context is done first.
+func (d *DrainSchedules) evictionStage(ctx context.Context, node *core.Node) (func(), bool) {
+	if !d.stages {
+		return func() {}, true
+	}
+	if d.evictionSlots != nil {
+		if err := d.acquireStage(ctx, d.evictionSlots, node.GetName()); err != nil {
+			return nil, false
+		}
+	}
+	atomic.AddInt32(&d.evictionsInFlight, 1)
+	return func() {
+		atomic.AddInt32(&d.evictionsInFlight, -1)
+		if d.evictionSlots != nil {
+			d.evictionSlots.Release()
+		}
+	}, true
+}
+
+// acquireStage acquires a slot of the supplied stage for the drain of the named
+// node, reporting the drain blocked on the stage limit while it waits for one.
+func (d *DrainSchedules) acquireStage(ctx context.Context, slots *LocalRateBudget, node string) error {
+	if slots.tryAcquire() {
+		return nil
+	}
+	d.blockDrain(node, deferralReasonStageLimit)
+	defer d.unblockDrain(node)
+	return slots.Acquire(ctx)
+}
@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// gatedDrainer records the nodes it cordons, and blocks its drains until
+// released.
+type gatedDrainer struct {
+	NoopCordonDrainer
+	sync.Mutex
+	cordoned []string
+	release  chan struct{}
+	drained  chan string
+}
+
+func (d *gatedDrainer) Cordon(n *v1.Node, mutators ...nodeMutatorFn) error {
+	d.Lock()
+	defer d.Unlock()
+	d.cordoned = append(d.cordoned, n.GetName())
+	return nil
+}
+
+func (d *gatedDrainer) Drain(n *v1.Node) error {
+	<-d.release
+	d.drained <- n.GetName()
+	return nil
+}
+
+func (d *gatedDrainer) cordons() int {
+	d.Lock()
+	defer d.Unlock()
+	return len(d.cordoned)
+}
+
+func TestDrainSchedules_StageConcurrency(t *testing.T) {
+	const nodes = 3
+	drainer := &gatedDrainer{release: make(chan struct{}), drained: make(chan string, nodes)}
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithStageConcurrency(0, 1)).(*DrainSchedules)
+	for _, name := range []string{"a", "b", "c"} {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		sched := scheduler.schedules[name]
+		sched.timer.Stop()
+		go scheduler.runDrain(node, sched)
+	}
+
+	// All nodes are cordoned while a single one is evicting.
+	deadline := time.Now().Add(5 * time.Second)
+	for drainer.cordons() < nodes && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := drainer.cordons(); got != nodes {
+		t.Fatalf("cordoned nodes: want %d, got %d", nodes, got)
+	}
+	if cordons, evictions := scheduler.StageInFlight(); cordons != 0 || evictions != 1 {
+		t.Errorf("StageInFlight(): want 0 cordons and 1 eviction, got %d and %d", cordons, evictions)
+	}
+
+	close(drainer.release)
+	for i := 0; i < nodes; i++ {
+		select {
+		case <-drainer.drained:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("drained %d of %d nodes", i, nodes)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// stateConfigMapKey is the ConfigMap data key holding the scheduler state.
+const stateConfigMapKey = "state.json"
+
+// SchedulerState is the scheduler state that survives restarts.
+type SchedulerState struct {
+	// LastDrainScheduledFor is the time the last drain was scheduled for.
+	LastDrainScheduledFor time.Time `json:"lastDrainScheduledFor,omitempty"`
+	// GroupLastDrain is the time the last drain of each node group was
+	// scheduled for.
+	GroupLastDrain map[string]time.Time `json:"groupLastDrain,omitempty"`
+	// DailyAttempts counts the drains of each node scheduled during the
+	// current day.
+	DailyAttempts map[string]DailyAttempts `json:"dailyAttempts,omitempty"`
+	// Retries are the retries of the failed drains of each node that are
+	// still pending.
+	Retries map[string]DrainRetryState `json:"retries,omitempty"`
+	// Keys are the keys the pending drains of each node were scheduled with,
+	// so that they are not rescheduled when requested again with the same key.
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+// DrainRetryState is the state of the retries of the failed drain of a node.
+type DrainRetryState struct {
+	// Attempt is the number of times the drain was attempted.
+	Attempt int `json:"attempt"`
+	// LastFailure is when the last failed attempt ended.
+	LastFailure time.Time `json:"lastFailure"`
+	// Backoff is how long after the last failure the drain is retried.
+	Backoff time.Duration `json:"backoff"`
+}
+
+// A StateStore persists the scheduler state.
+type StateStore interface {
+	// Load returns the saved state, or an empty state if none was saved.
+	Load(ctx context.Context) (SchedulerState, error)
+	// Save replaces the saved state.
+	Save(ctx context.Context, s SchedulerState) error
+}
+
+// A MemoryStateStore keeps the scheduler state in memory. It is mostly useful
+// for tests.
+type MemoryStateStore struct {
+	sync.Mutex
+	state SchedulerState
+}
+
+// Load returns the saved state.
+func (m *MemoryStateStore) Load(_ context.Context) (SchedulerState, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.state.copy(), nil
+}
+
+// Save replaces the saved state.
+func (m *MemoryStateStore) Save(_ context.Context, s SchedulerState) error {
+	m.Lock()
+	defer m.Unlock()
+	m.state = s.copy()
+	return nil
+}
+
+// A ConfigMapStateStore persists the scheduler state in a ConfigMap.
+type ConfigMapStateStore struct {
+	c         kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMapStateStore returns a StateStore that persists the scheduler state
+// in the named ConfigMap, creating it if necessary.
+func NewConfigMapStateStore(c kubernetes.Interface, namespace, name string) *ConfigMapStateStore {
+	return &ConfigMapStateStore{c: c, namespace: namespace, name: name}
+}
+
+// Load returns the state saved in the ConfigMap.
+func (s *ConfigMapStateStore) Load(ctx context.Context) (SchedulerState, error) {
+	var state SchedulerState
+	cm, err := s.c.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, errors.Wrapf(err, "cannot get ConfigMap %s/%s", s.namespace, s.name)
+	}
+	raw, ok := cm.Data[stateConfigMapKey]
+	if !ok {
+		return state, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return state, errors.Wrapf(err, "cannot decode state from ConfigMap %s/%s", s.namespace, s.name)
+	}
+	return state, nil
+}
+
+// Save writes the supplied state to the ConfigMap.
+func (s *ConfigMapStateStore) Save(ctx context.Context, state SchedulerState) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode state")
+	}
+	cms := s.c.CoreV1().ConfigMaps(s.namespace)
+	cm, err := cms.Get(ctx, s.name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &core.ConfigMap{
+			ObjectMeta: meta.ObjectMeta{Namespace: s.namespace, Name: s.name},
+			Data:       map[string]string{stateConfigMapKey: string(raw)},
+		}
+		if _, err := cms.Create(ctx, cm, meta.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "cannot create ConfigMap %s/%s", s.namespace, s.name)
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot get ConfigMap %s/%s", s.namespace, s.name)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[stateConfigMapKey] = string(raw)
+	if _, err := cms.Update(ctx, cm, meta.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "cannot update ConfigMap %s/%s", s.namespace, s.name)
+	}
+	return nil
+}
+
+func (s SchedulerState) copy() SchedulerState {
+	c := SchedulerState{LastDrainScheduledFor: s.LastDrainScheduledFor}
+	if s.GroupLastDrain != nil {
+		c.GroupLastDrain = make(map[string]time.Time, len(s.GroupLastDrain))
+		for g, t := range s.GroupLastDrain {
+			c.GroupLastDrain[g] = t
+		}
+	}
+	if s.DailyAttempts != nil {
+		c.DailyAttempts = make(map[string]DailyAttempts, len(s.DailyAttempts))
+		for n, a := range s.DailyAttempts {
+			c.DailyAttempts[n] = a
+		}
+	}
+	if s.Retries != nil {
+		c.Retries = make(map[string]DrainRetryState, len(s.Retries))
+		for n, r := range s.Retries {
+			c.Retries[n] = r
+		}
+	}
+	if s.Keys != nil {
+		c.Keys = make(map[string]string, len(s.Keys))
+		for n, k := range s.Keys {
+			c.Keys[n] = k
+		}
+	}
+	return c
+}
+
+// WithStateStore configures a store persisting the scheduler state, such as
+// the drain cooldowns, across restarts. The saved state is loaded when the
+// scheduler is created.
+func WithStateStore(s StateStore) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.stateStore = s
+	}
+}
+
+// WithGroupCooldown configures the minimum time between the drains of nodes of
+// the same node group. Zero disables the cooldown.
+func WithGroupCooldown(c time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.groupCooldown = c
+	}
+}
+
+// loadState rehydrates the scheduler from its state store, if any.
+func (d *DrainSchedules) loadState() {
+	if d.stateStore == nil {
+		return
+	}
+	s, err := d.stateStore.Load(context.Background())
+	if err != nil {
+		d.logger.Warn("Cannot load scheduler state", zap.Error(err))
+		return
+	}
+	d.lastDrainScheduledFor = s.LastDrainScheduledFor
+	for g, t := range s.GroupLastDrain {
+		d.groupLastDrain[g] = t
+	}
+	for n, a := range s.DailyAttempts {
+		d.dailyAttempts[n] = a
+	}
+	for n, r := range s.Retries {
+		d.restoredRetries[n] = r
+	}
+	for n, k := range s.Keys {
+		d.restoredKeys[n] = k
+	}
+}
+
+// stateLocked returns a snapshot of the scheduler state. The caller must hold
+// the lock.
+func (d *DrainSchedules) stateLocked() SchedulerState {
+	return SchedulerState{
+		LastDrainScheduledFor: d.lastDrainScheduledFor,
+		GroupLastDrain:        d.groupLastDrain,
+		DailyAttempts:         d.dailyAttempts,
+		Retries:               d.retriesLocked(),
+		Keys:                  d.keysLocked(),
+	}.copy()
+}
+
+// keysLocked returns the keys the pending drains were scheduled with,
+// including those restored from the state store whose schedule is not
+// reconciled yet. The caller must hold the lock.
+func (d *DrainSchedules) keysLocked() map[string]string {
+	keys := map[string]string{}
+	for n, k := range d.restoredKeys {
+		keys[n] = k
+	}
+	for n, sched := range d.schedules {
+		if sched.key == "" || !sched.finish.IsZero() {
+			continue
+		}
+		keys[n] = sched.key
+	}
+	return keys
+}
+
+// restoreKeyLocked restores the key the drain of the supplied schedule,
+// recreated for the named node, was scheduled with before a restart. The
+// caller must hold the lock.
+func (d *DrainSchedules) restoreKeyLocked(name string, sched *schedule) {
+	if k, ok := d.restoredKeys[name]; ok {
+		delete(d.restoredKeys, name)
+		sched.key = k
+	}
+}
+
+// retriesLocked returns the retries of the failed drains that are pending,
+// including those restored from the state store whose schedule is not
+// reconciled yet. The caller must hold the lock.
+func (d *DrainSchedules) retriesLocked() map[string]DrainRetryState {
+	retries := map[string]DrainRetryState{}
+	for n, r := range d.restoredRetries {
+		retries[n] = r
+	}
+	for n, sched := range d.schedules {
+		if sched.lastFailure.IsZero() || !sched.finish.IsZero() || sched.isFailed() {
+			continue
+		}
+		retries[n] = DrainRetryState{Attempt: sched.attempt, LastFailure: sched.lastFailure, Backoff: sched.backoff}
+	}
+	return retries
+}
+
+// restoreRetryLocked resumes the retries of the failed drain of the supplied
+// schedule, recreated for the named node, where they stood before a restart:
+// its attempts are restored, and its drain retried once the backoff of its
+// last failure elapsed. The caller must hold the lock.
+func (d *DrainSchedules) restoreRetryLocked(name string, sched *schedule) {
+	r, ok := d.restoredRetries[name]
+	if !ok {
+		return
+	}
+	delete(d.restoredRetries, name)
+	sched.attempt = r.Attempt
+	sched.lastFailure = r.LastFailure
+	sched.backoff = r.Backoff
+	retry := r.LastFailure.Add(r.Backoff)
+	if retry.After(sched.when) {
+		sched.timer.Reset(retry.Sub(d.now()))
+	}
+	d.logger.Info("Restored drain retries", zap.String("node", name), zap.Int("attempt", r.Attempt), zap.Time("retry", retry))
+}
+
+// saveState persists the current scheduler state to the state store, if any.
+// Failures are logged.
+func (d *DrainSchedules) saveState() {
+	if d.stateStore == nil {
+		return
+	}
+	// Serialize saves so that the latest state is always saved last.
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	d.Lock()
+	s := d.stateLocked()
+	d.Unlock()
+	if err := d.stateStore.Save(context.Background(), s); err != nil {
+		d.logger.Warn("Cannot save scheduler state", zap.Error(err))
+	}
+}
@@ -0,0 +1,184 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_GroupCooldownSurvivesRestart(t *testing.T) {
+	store := &MemoryStateStore{}
+	newScheduler := func() *DrainSchedules {
+		return NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(),
+			WithNodeGroupLabel("group"),
+			WithGroupCooldown(time.Hour),
+			WithStateStore(store),
+		).(*DrainSchedules)
+	}
+	nodeInGroup := func(name string) *v1.Node {
+		return &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name, Labels: map[string]string{"group": "a"}}}
+	}
+
+	first := newScheduler()
+	firstWhen, err := first.Schedule(nodeInGroup("first"))
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	first.DeleteSchedule("first")
+
+	// A restarted scheduler honors the cooldown of the group.
+	restarted := newScheduler()
+	secondWhen, err := restarted.Schedule(nodeInGroup("second"))
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	defer restarted.DeleteSchedule("second")
+	if got := secondWhen.Sub(firstWhen); got < time.Hour {
+		t.Errorf("second drain scheduled %v after the first, want at least %v", got, time.Hour)
+	}
+
+	// Nodes of other groups are not delayed.
+	other, err := restarted.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: "other", Labels: map[string]string{"group": "b"}}})
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	defer restarted.DeleteSchedule("other")
+	if !other.Before(secondWhen) {
+		t.Errorf("drain of another group scheduled at %v, want before %v", other, secondWhen)
+	}
+}
+
+func TestConfigMapStateStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewConfigMapStateStore(fake.NewSimpleClientset(), "kube-system", "draino-state")
+
+	got, err := s.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() of a missing ConfigMap: %v", err)
+	}
+	if !reflect.DeepEqual(got, SchedulerState{}) {
+		t.Errorf("Load() of a missing ConfigMap: want empty state, got %+v", got)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for _, want := range []SchedulerState{
+		{LastDrainScheduledFor: now, GroupLastDrain: map[string]time.Time{"a": now}},
+		{LastDrainScheduledFor: now.Add(time.Minute), GroupLastDrain: map[string]time.Time{"a": now, "b": now.Add(time.Hour)}},
+	} {
+		if err := s.Save(ctx, want); err != nil {
+			t.Fatalf("Save(): %v", err)
+		}
+		got, err := s.Load(ctx)
+		if err != nil {
+			t.Fatalf("Load(): %v", err)
+		}
+		if !got.LastDrainScheduledFor.Equal(want.LastDrainScheduledFor) || len(got.GroupLastDrain) != len(want.GroupLastDrain) {
+			t.Errorf("Load(): want %+v, got %+v", want, got)
+		}
+		for g, w := range want.GroupLastDrain {
+			if !got.GroupLastDrain[g].Equal(w) {
+				t.Errorf("Load(): group %s: want %v, got %v", g, w, got.GroupLastDrain[g])
+			}
+		}
+	}
+}
+
+func TestDrainSchedules_RetriesSurviveRestart(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &MemoryStateStore{}
+	newScheduler := func(dispatcher *ManualDispatcher) *DrainSchedules {
+		return NewDrainSchedules(&failDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(),
+			WithDispatcher(dispatcher),
+			WithDrainRetries(3, time.Hour),
+			WithStateStore(store),
+		).(*DrainSchedules)
+	}
+
+	dispatcher := NewManualDispatcher(start)
+	first := newScheduler(dispatcher)
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	when, err := first.Schedule(node)
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	dispatcher.ProcessDue(when)
+	saved, _ := store.Load(context.Background())
+	want := DrainRetryState{Attempt: 1, LastFailure: when, Backoff: time.Hour}
+	if got := saved.Retries[nodeName]; got != want {
+		t.Fatalf("saved retries: want %+v, got %+v", want, got)
+	}
+
+	// The restarted scheduler recreates the schedule from the condition of
+	// the node, and resumes its retries.
+	node = scheduledNode(nodeName, v1.ConditionTrue, scheduledConditionPrefix+when.Format(time.RFC3339))
+	dispatcher = NewManualDispatcher(when.Add(30 * time.Minute))
+	restarted := newScheduler(dispatcher)
+	restarted.ReconcileFromNodes([]*v1.Node{node})
+	defer restarted.DeleteSchedule(nodeName)
+	sched := restarted.schedules[nodeName]
+	if sched.attempt != 1 {
+		t.Errorf("attempt: want 1 restored, got %d", sched.attempt)
+	}
+
+	// The overdue drain waits for the backoff of its last failure.
+	if fired := dispatcher.ProcessDue(when.Add(59 * time.Minute)); fired != 0 {
+		t.Errorf("drain fired before its backoff elapsed")
+	}
+	if fired := dispatcher.ProcessDue(when.Add(time.Hour)); fired != 1 {
+		t.Fatalf("drain: want retried once its backoff elapsed, fired %d", fired)
+	}
+	saved, _ = store.Load(context.Background())
+	want = DrainRetryState{Attempt: 2, LastFailure: when.Add(time.Hour), Backoff: 2 * time.Hour}
+	if got := saved.Retries[nodeName]; got != want {
+		t.Errorf("saved retries: want %+v, got %+v", want, got)
+	}
+}
+
+func TestDrainSchedules_KeysSurviveRestart(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &MemoryStateStore{}
+	drainer := newRecordingDrainer()
+	newScheduler := func() *DrainSchedules {
+		return NewDrainSchedules(drainer, &record.FakeRecorder{}, time.Minute, zap.NewNop(),
+			WithDispatcher(NewManualDispatcher(start)),
+			WithStateStore(store),
+		).(*DrainSchedules)
+	}
+
+	first := newScheduler()
+	first.lastDrainScheduledFor = start
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	when, err := first.ScheduleWithKey(node, "mykey")
+	if err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWithKey() error = %v", err)
+	}
+	saved, _ := store.Load(context.Background())
+	if want := map[string]string{nodeName: "mykey"}; !reflect.DeepEqual(saved.Keys, want) {
+		t.Fatalf("saved keys: want %v, got %v", want, saved.Keys)
+	}
+
+	// The restarted scheduler recreates the schedule with its key, so that
+	// scheduling it again with the same key does nothing.
+	node = scheduledNode(nodeName, v1.ConditionTrue, scheduledConditionPrefix+when.Format(time.RFC3339))
+	restarted := newScheduler()
+	restarted.ReconcileFromNodes([]*v1.Node{node})
+	defer restarted.DeleteSchedule(nodeName)
+	sched := restarted.schedules[nodeName]
+	got, err := restarted.ScheduleWithKey(node, "mykey")
+	if err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWithKey() after restart error = %v", err)
+	}
+	if !got.Equal(when) {
+		t.Errorf("DrainSchedules.ScheduleWithKey() after restart: want %v, got %v", when, got)
+	}
+	if restarted.schedules[nodeName] != sched {
+		t.Error("DrainSchedules.ScheduleWithKey() after restart: want the reconciled schedule kept, got it rescheduled")
+	}
+}
@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+)
+
+// WithStuckTerminatingForceDelete configures Drain to force delete, without a
+// grace period, the pods still terminating once the supplied threshold elapsed
+// since their deletion started, either because they were already terminating
+// when the drain started or because they were evicted, typically because of
+// finalizers or an unresponsive kubelet. This is equivalent to running kubectl
+// delete --force --grace-period=0, and a warning is recorded for each pod force
+// deleted. Zero, the default, waits for stuck pods until the drain times out.
+func WithStuckTerminatingForceDelete(threshold time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.stuckTerminatingThreshold = threshold
+	}
+}
+
+// stuckTerminatingWait returns how long to wait for the supplied pod, which
+// started terminating at the supplied time, before deeming it stuck.
+func (d *APICordonDrainer) stuckTerminatingWait(since time.Time) time.Duration {
+	if wait := time.Until(since.Add(d.stuckTerminatingThreshold)); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// forceDeleteStuck force deletes the supplied pod, stuck terminating, warning
+// that it did.
+func (d *APICordonDrainer) forceDeleteStuck(ctx context.Context, p core.Pod) error {
+	d.l.Info("Pod stuck terminating, force deleting it", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Duration("threshold", d.stuckTerminatingThreshold))
+	drainSummaryFrom(ctx).warn("%s/%s force deleted, still terminating after %s", p.GetNamespace(), p.GetName(), d.stuckTerminatingThreshold)
+	if d.eventRecorder != nil {
+		pr := &core.ObjectReference{Kind: "Pod", Namespace: p.GetNamespace(), Name: p.GetName(), UID: p.GetUID()}
+		d.eventRecorder.Eventf(pr, core.EventTypeWarning, d.eventReasons.PodForceDeleted, "Force deleting pod, still terminating after %s", d.stuckTerminatingThreshold)
+	}
+	return d.forceDelete(ctx, p)
+}
@@ -0,0 +1,75 @@
+package kubernetes
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainStuckTerminating(t *testing.T) {
+	cases := []struct {
+		name        string
+		terminating bool
+	}{
+		// The pod was already terminating when the drain started.
+		{name: "AlreadyTerminating", terminating: true},
+		// The pod never leaves Terminating once evicted.
+		{name: "Evicted"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "stuck", UID: "stuck"}}
+			if tc.terminating {
+				pod.DeletionTimestamp = &meta.Time{Time: time.Now()}
+			}
+			c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, pod)
+			var mu sync.Mutex
+			var grace []int64
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				if a.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				return true, nil, nil
+			})
+			// The pod keeps being listed until force deleted.
+			c.PrependReactor("delete", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				grace = append(grace, *a.(clienttesting.DeleteActionImpl).DeleteOptions.GracePeriodSeconds)
+				return false, nil, nil
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			d := NewAPICordonDrainer(c, WithStuckTerminatingForceDelete(50*time.Millisecond), WithDrainerEventRecorder(recorder), WithDrainerEventReasons(EventReasons{PodForceDeleted: "MyPodForceDeleted"}))
+			if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+				t.Fatalf("d.Drain(%v): %v", nodeName, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(grace) != 1 || grace[0] != 0 {
+				t.Errorf("pod deletions: want one without a grace period, got grace periods %v", grace)
+			}
+			summary, _ := d.DrainSummary(nodeName)
+			if summary.Forced != 1 {
+				t.Errorf("summary: want 1 pod forced, got %+v", summary)
+			}
+			if len(summary.Warnings) != 1 || !strings.Contains(summary.Warnings[0], "still terminating") {
+				t.Errorf("summary warnings: want the pod stuck terminating, got %v", summary.Warnings)
+			}
+			if len(recorder.Events) != 1 {
+				t.Fatalf("events: want 1, got %d", len(recorder.Events))
+			}
+			if e := <-recorder.Events; !strings.HasPrefix(e, "Warning MyPodForceDeleted ") {
+				t.Errorf("event: want a MyPodForceDeleted warning, got %q", e)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultDrainSuccessTimeout is how long Drain polls a
+	// DrainSuccessValidator when WithDrainSuccessValidator is configured
+	// without a timeout.
+	DefaultDrainSuccessTimeout = 5 * time.Minute
+
+	drainSuccessPollPeriod = 1 * time.Second
+)
+
+// A DrainSuccessValidator returns true once the drain of the supplied node,
+// whose pods were evicted, is complete. Drains are by default complete once
+// the evicted pods are gone; validators may encode further criteria, e.g. a
+// node condition set by a node agent once it flushed its local state.
+type DrainSuccessValidator func(ctx context.Context, n *core.Node) (done bool, err error)
+
+// WithDrainSuccessValidator configures Drain to succeed only once the supplied
+// validator returns true, polling it for up to the supplied timeout after the
+// pods of the node are gone. DefaultDrainSuccessTimeout applies when the
+// timeout is zero. A drain whose validator does not return true in time fails
+// with a DrainNotSucceededError.
+func WithDrainSuccessValidator(v DrainSuccessValidator, timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		if timeout <= 0 {
+			timeout = DefaultDrainSuccessTimeout
+		}
+		d.successValidator = v
+		d.successTimeout = timeout
+	}
+}
+
+// awaitDrainSuccess polls the DrainSuccessValidator, if any, until it reports
+// the drain of the supplied node complete.
+func (d *APICordonDrainer) awaitDrainSuccess(ctx context.Context, n *core.Node) error {
+	if d.successValidator == nil {
+		return nil
+	}
+	period := d.successPollPeriod
+	if period <= 0 {
+		period = drainSuccessPollPeriod
+	}
+	stop, cancel := context.WithTimeout(ctx, d.successTimeout)
+	defer cancel()
+	err := wait.PollImmediateUntil(period, func() (bool, error) {
+		done, err := d.successValidator(stop, n)
+		if err != nil {
+			return false, errors.Wrap(err, "cannot validate drain success")
+		}
+		return done, nil
+	}, stop.Done())
+	if err == wait.ErrWaitTimeout {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "drain cancelled")
+		}
+		d.l.Info("Drain success criteria not met", zap.String("node", n.GetName()), zap.Duration("timeout", d.successTimeout))
+		return NewDrainNotSucceededError(n.GetName(), d.successTimeout)
+	}
+	return err
+}
+
+type DrainNotSucceededError struct {
+	error
+}
+
+func NewDrainNotSucceededError(node string, timeout time.Duration) error {
+	return &DrainNotSucceededError{
+		fmt.Errorf("drain of node %s did not meet its success criteria within %s", node, timeout),
+	}
+}
+
+func IsDrainNotSucceededError(err error) bool {
+	_, ok := errors.Cause(err).(*DrainNotSucceededError)
+	return ok
+}
@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const conditionStateFlushed core.NodeConditionType = "LocalStateFlushed"
+
+// stateFlushed returns a DrainSuccessValidator that requires the node to
+// report its local state flushed.
+func stateFlushed(c kubernetes.Interface) DrainSuccessValidator {
+	return func(ctx context.Context, n *core.Node) (bool, error) {
+		n, err := c.CoreV1().Nodes().Get(ctx, n.GetName(), meta.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range n.Status.Conditions {
+			if cond.Type == conditionStateFlushed && cond.Status == core.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+func TestDrainSuccessValidator(t *testing.T) {
+	cases := []struct {
+		name    string
+		flushed bool
+		wantErr bool
+	}{
+		{name: "ConditionSet", flushed: true},
+		{name: "ConditionNeverSet", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(
+				&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+				&core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}},
+			)
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return a.GetSubresource() == "eviction", nil, nil
+			})
+			c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+			})
+			d := NewAPICordonDrainer(c, WithDrainSuccessValidator(stateFlushed(c), 200*time.Millisecond))
+			d.successPollPeriod = 10 * time.Millisecond
+
+			if tc.flushed {
+				// The node agent reports its state flushed a little after
+				// the pods are gone.
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					n := &core.Node{
+						ObjectMeta: meta.ObjectMeta{Name: nodeName},
+						Status: core.NodeStatus{Conditions: []core.NodeCondition{
+							{Type: conditionStateFlushed, Status: core.ConditionTrue},
+						}},
+					}
+					if _, err := c.CoreV1().Nodes().UpdateStatus(context.Background(), n, meta.UpdateOptions{}); err != nil {
+						t.Errorf("UpdateStatus(%v): %v", nodeName, err)
+					}
+				}()
+			}
+
+			err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if tc.wantErr {
+				if !IsDrainNotSucceededError(err) {
+					t.Errorf("d.Drain(%v): want DrainNotSucceededError, got %v", nodeName, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("d.Drain(%v): %v", nodeName, err)
+			}
+		})
+	}
+}
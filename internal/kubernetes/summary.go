@@ -0,0 +1,255 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MaxEventMessageLength caps the message of drain summary events, per the
+// limit the API server enforces on event messages.
+const MaxEventMessageLength = 1024
+
+// maxRetriedPodsInSummary caps the number of pods whose eviction attempts are
+// listed in drain summary events.
+const maxRetriedPodsInSummary = 3
+
+// A DrainSummary tallies what the last drain attempt of a node did.
+type DrainSummary struct {
+	// Evicted, Forced and Terminated count the pods removed by eviction, by
+	// force deletion, and by waiting for pods that were already terminating.
+	Evicted    int
+	Forced     int
+	Terminated int
+	// NamespaceTerminating counts the pods assumed gone because their
+	// namespace was being deleted.
+	NamespaceTerminating int
+	// Skipped counts the pods skipped by the eviction filter, or because an
+	// admission webhook denied their eviction.
+	Skipped int
+	// Denied are the namespaced names of the pods left on the node because
+	// an admission webhook denied their eviction.
+	Denied []string
+	// VolumeBacked counts the pods to evict that use PersistentVolumeClaims.
+	VolumeBacked int
+	// Retries counts the evictions refused and retried.
+	Retries int
+	// Attempts holds the number of eviction attempts of each pod, by
+	// namespaced name, whose eviction was retried.
+	Attempts map[string]int
+	// PeakTerminating is the largest number of pods being removed at once.
+	PeakTerminating int
+	// InterPodDelay is the total time removals waited for the minimum delay
+	// between them.
+	InterPodDelay time.Duration
+	// Warnings describe the pods that did not go as planned.
+	Warnings []string
+}
+
+// podsMoved returns the number of pods the drain removed from the node.
+func (s DrainSummary) podsMoved() int {
+	return s.Evicted + s.Forced + s.Terminated
+}
+
+// A DrainSummarizer tallies what its drains did.
+type DrainSummarizer interface {
+	// DrainSummary returns, and forgets, the summary of the last drain of the
+	// named node.
+	DrainSummary(node string) (DrainSummary, bool)
+}
+
+// drainSummary accumulates the summary of a drain while it runs.
+type drainSummary struct {
+	sync.Mutex
+	DrainSummary
+}
+
+type drainSummaryKey struct{}
+
+// withDrainSummary returns a copy of the supplied context that carries the
+// supplied summary.
+func withDrainSummary(ctx context.Context, s *drainSummary) context.Context {
+	return context.WithValue(ctx, drainSummaryKey{}, s)
+}
+
+// drainSummaryFrom returns the summary carried by the supplied context, if any.
+// Updating a nil summary does nothing.
+func drainSummaryFrom(ctx context.Context) *drainSummary {
+	s, _ := ctx.Value(drainSummaryKey{}).(*drainSummary)
+	return s
+}
+
+func (s *drainSummary) update(fn func(s *DrainSummary)) {
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	fn(&s.DrainSummary)
+}
+
+func (s *drainSummary) removed(phase string) {
+	s.update(func(s *DrainSummary) {
+		switch phase {
+		case evictionPhaseEvicted:
+			s.Evicted++
+		case evictionPhaseForced:
+			s.Forced++
+		case evictionPhaseTerminating:
+			s.Terminated++
+		}
+	})
+}
+
+// denied returns the set of the pods whose eviction was denied and skipped.
+func (s *drainSummary) denied() map[string]bool {
+	if s == nil {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	denied := make(map[string]bool, len(s.Denied))
+	for _, pod := range s.Denied {
+		denied[pod] = true
+	}
+	return denied
+}
+
+// retried counts a retried eviction of the supplied namespaced pod.
+func (s *drainSummary) retried(pod string) {
+	s.update(func(s *DrainSummary) {
+		s.Retries++
+		if s.Attempts == nil {
+			s.Attempts = map[string]int{}
+		}
+		if s.Attempts[pod] == 0 {
+			s.Attempts[pod] = 1
+		}
+		s.Attempts[pod]++
+	})
+}
+
+// mostRetried returns the namespaced names of, at most, the supplied number of
+// pods with the most eviction attempts, by decreasing attempts then name.
+func (s DrainSummary) mostRetried(max int) []string {
+	pods := make([]string, 0, len(s.Attempts))
+	for pod := range s.Attempts {
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if s.Attempts[pods[i]] != s.Attempts[pods[j]] {
+			return s.Attempts[pods[i]] > s.Attempts[pods[j]]
+		}
+		return pods[i] < pods[j]
+	})
+	if len(pods) > max {
+		pods = pods[:max]
+	}
+	return pods
+}
+
+func (s *drainSummary) warn(format string, args ...interface{}) {
+	s.update(func(s *DrainSummary) {
+		s.Warnings = append(s.Warnings, fmt.Sprintf(format, args...))
+	})
+}
+
+// startDrainSummary starts the summary of a new drain of the named node,
+// replacing that of its previous drain.
+func (d *APICordonDrainer) startDrainSummary(node string) *drainSummary {
+	s := &drainSummary{}
+	d.summaryMu.Lock()
+	defer d.summaryMu.Unlock()
+	if d.summaries == nil {
+		d.summaries = map[string]*drainSummary{}
+	}
+	d.summaries[node] = s
+	return s
+}
+
+// DrainSummary returns, and forgets, the summary of the last drain of the
+// named node.
+func (d *APICordonDrainer) DrainSummary(node string) (DrainSummary, bool) {
+	d.summaryMu.Lock()
+	s, ok := d.summaries[node]
+	delete(d.summaries, node)
+	d.summaryMu.Unlock()
+	if !ok {
+		return DrainSummary{}, false
+	}
+	s.Lock()
+	defer s.Unlock()
+	summary := s.DrainSummary
+	summary.Warnings = append([]string(nil), s.Warnings...)
+	summary.Denied = append([]string(nil), s.Denied...)
+	if s.Attempts != nil {
+		summary.Attempts = make(map[string]int, len(s.Attempts))
+		for pod, n := range s.Attempts {
+			summary.Attempts[pod] = n
+		}
+	}
+	return summary, true
+}
+
+// summaryMessage describes the supplied drain summary in a single line of at
+// most MaxEventMessageLength bytes.
+func summaryMessage(result string, took time.Duration, s DrainSummary) string {
+	msg := fmt.Sprintf("Drain %s in %s: %d pods evicted, %d force deleted, %d already terminating, %d skipped",
+		result, took.Round(time.Second), s.Evicted, s.Forced, s.Terminated, s.Skipped)
+	if s.NamespaceTerminating > 0 {
+		msg += fmt.Sprintf(", %d in terminating namespaces", s.NamespaceTerminating)
+	}
+	if s.VolumeBacked > 0 {
+		msg += fmt.Sprintf(", %d with persistent volumes", s.VolumeBacked)
+	}
+	if s.Retries > 0 {
+		msg += fmt.Sprintf(", %d evictions retried", s.Retries)
+		if pods := s.mostRetried(maxRetriedPodsInSummary); len(pods) > 0 {
+			attempts := make([]string, 0, len(pods))
+			for _, pod := range pods {
+				attempts = append(attempts, fmt.Sprintf("%s took %d attempts", pod, s.Attempts[pod]))
+			}
+			msg += " (" + strings.Join(attempts, ", ") + ")"
+		}
+	}
+	if s.InterPodDelay > 0 {
+		msg += fmt.Sprintf(", %s spent spacing evictions", s.InterPodDelay.Round(time.Second))
+	}
+	if len(s.Warnings) > 0 {
+		msg += "; warnings: " + strings.Join(s.Warnings, "; ")
+	}
+	return truncate(msg, MaxEventMessageLength)
+}
+
+// summarizeDrain records a single event summarizing the drain of the supplied
+// schedule, if the drainer is a DrainSummarizer, and reports its stats.
+func (d *DrainSchedules) summarizeDrain(node *core.Node, sched *schedule, result string, started, finish time.Time) {
+	summary, ok := d.takeDrainSummary(node)
+	d.recordDrainSummary(node, sched, result, started, finish, summary, ok)
+}
+
+// takeDrainSummary returns, and forgets, the summary of the last drain of the
+// supplied node, if the drainer is a DrainSummarizer.
+func (d *DrainSchedules) takeDrainSummary(node *core.Node) (DrainSummary, bool) {
+	s, ok := d.drainer.(DrainSummarizer)
+	if !ok {
+		return DrainSummary{}, false
+	}
+	return s.DrainSummary(node.GetName())
+}
+
+// recordDrainSummary records a single event summarizing the drain of the
+// supplied schedule, if it was summarized, and reports its stats.
+func (d *DrainSchedules) recordDrainSummary(node *core.Node, sched *schedule, result string, started, finish time.Time, summary DrainSummary, ok bool) {
+	if ok {
+		nr := &core.ObjectReference{Kind: "Node", Name: node.GetName(), UID: types.UID(node.GetName())}
+		d.eventRecorder.Event(nr, core.EventTypeWarning, d.eventReasons.DrainSummary, summaryMessage(result, finish.Sub(started), summary))
+	}
+	d.reportDrainStats(node, sched, result, started, finish, summary)
+}
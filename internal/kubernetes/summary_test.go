@@ -0,0 +1,163 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_SummarizeDrain(t *testing.T) {
+	deleting := meta.Now()
+	pods := map[string]*core.Pod{
+		"web":   {ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "web"}},
+		"api":   {ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "api"}},
+		"late":  {ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "late", DeletionTimestamp: &deleting}},
+		"local": {ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "local"}},
+	}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	c := fake.NewSimpleClientset(node)
+	var mu sync.Mutex
+	evicted := map[string]bool{}
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		l := &core.PodList{}
+		for _, p := range pods {
+			l.Items = append(l.Items, *p)
+		}
+		return true, l, nil
+	})
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		evicted[a.(clienttesting.CreateAction).GetObject().(*policy.Eviction).GetName()] = true
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		name := a.(clienttesting.GetAction).GetName()
+		mu.Lock()
+		defer mu.Unlock()
+		if evicted[name] {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+		}
+		return true, pods[name], nil
+	})
+
+	drainer := NewAPICordonDrainer(c,
+		WithTerminatingPodTimeout(10*time.Millisecond),
+		WithEvictionFilter(func(p *core.Pod) (bool, string) { return p.GetName() != "local", "local storage" }),
+	)
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewDrainSchedules(drainer, recorder, 0, zap.NewNop()).(*DrainSchedules)
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[nodeName]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	var summary string
+	for len(recorder.Events) > 0 {
+		if e := <-recorder.Events; strings.HasPrefix(e, "Warning DrainSummary ") {
+			summary = strings.TrimPrefix(e, "Warning DrainSummary ")
+		}
+	}
+	want := "Drain succeeded in 0s: 3 pods evicted, 0 force deleted, 0 already terminating, 1 skipped; warnings: default/late was still terminating after 10ms"
+	if summary != want {
+		t.Errorf("summary event:\nwant %q\ngot  %q", want, summary)
+	}
+	if _, ok := drainer.DrainSummary(nodeName); ok {
+		t.Errorf("DrainSummary(): summary not forgotten once recorded")
+	}
+}
+
+func TestSummaryMessageLength(t *testing.T) {
+	s := DrainSummary{Warnings: []string{strings.Repeat("x", MaxEventMessageLength)}}
+	if got := summaryMessage(tagResultFailed, time.Minute, s); len(got) != MaxEventMessageLength || !strings.HasSuffix(got, "...") {
+		t.Errorf("summaryMessage(): want %d characters ending with ..., got %d", MaxEventMessageLength, len(got))
+	}
+}
+
+func TestSummaryMessageLengthMultibyte(t *testing.T) {
+	// Shift the three byte runes so that the message is cut at every offset.
+	for _, prefix := range []string{"", "x", "xx"} {
+		s := DrainSummary{Warnings: []string{prefix + strings.Repeat("€", MaxEventMessageLength)}}
+		got := summaryMessage(tagResultFailed, time.Minute, s)
+		if len(got) > MaxEventMessageLength || !strings.HasSuffix(got, "...") {
+			t.Errorf("summaryMessage(): want at most %d bytes ending with ..., got %d", MaxEventMessageLength, len(got))
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("summaryMessage(): want valid UTF-8, got %q", got)
+		}
+	}
+}
+
+func TestDrainSummaryRetriedPods(t *testing.T) {
+	c := fake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	c.PrependReactor("list", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &core.PodList{Items: []core.Pod{
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "flaky"}},
+			{ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "steady"}},
+		}}, nil
+	})
+	// The eviction calls of the flaky pod hang past the call timeout thrice.
+	var mu sync.Mutex
+	hangs := 3
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		mu.Lock()
+		hang := a.(clienttesting.CreateAction).GetObject().(*policy.Eviction).GetName() == "flaky" && hangs > 0
+		if hang {
+			hangs--
+		}
+		mu.Unlock()
+		if hang {
+			time.Sleep(20 * time.Millisecond)
+			return true, nil, context.DeadlineExceeded
+		}
+		return true, nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, a.(clienttesting.GetAction).GetName())
+	})
+
+	d := NewAPICordonDrainer(c, WithEvictionCallTimeout(5*time.Millisecond))
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	s, _ := d.DrainSummary(nodeName)
+	if want := map[string]int{"default/flaky": 4}; !reflect.DeepEqual(s.Attempts, want) {
+		t.Errorf("DrainSummary().Attempts: want %v, got %v", want, s.Attempts)
+	}
+	want := "Drain succeeded in 0s: 2 pods evicted, 0 force deleted, 0 already terminating, 0 skipped, 3 evictions retried (default/flaky took 4 attempts)"
+	if got := summaryMessage(tagResultSucceeded, 0, s); got != want {
+		t.Errorf("summaryMessage():\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestSummaryMessageMostRetried(t *testing.T) {
+	s := DrainSummary{Retries: 10, Attempts: map[string]int{"default/a": 2, "default/b": 5, "default/c": 3, "default/d": 3}}
+	got := summaryMessage(tagResultFailed, time.Minute, s)
+	// Only the pods with the most attempts are listed.
+	if want := "(default/b took 5 attempts, default/c took 3 attempts, default/d took 3 attempts)"; !strings.HasSuffix(got, want) {
+		t.Errorf("summaryMessage(): want suffix %q, got %q", want, got)
+	}
+}
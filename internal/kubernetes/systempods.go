@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	"strings"
+
+	core "k8s.io/api/core/v1"
+)
+
+// WithSystemPodsLast determines which pods Drain evicts in a final phase, after
+// all others, because they provide node local services such as networking,
+// storage or monitoring that the other pods may still need while they leave the
+// node, for example a CSI driver detaching their volumes. System pods are those
+// of the supplied namespaces, and those with one of the supplied labels, either
+// KEY to match any value or KEY=VALUE. The evict first pods still precede them.
+// No namespaces and labels disables this phase.
+func WithSystemPodsLast(namespaces, labels []string) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.systemNamespaces = map[string]bool{}
+		for _, ns := range namespaces {
+			d.systemNamespaces[ns] = true
+		}
+		d.systemLabels = labels
+	}
+}
+
+// isSystemPod returns true if the supplied pod provides node local services,
+// and is evicted after all others.
+func (d *APICordonDrainer) isSystemPod(p core.Pod) bool {
+	if d.systemNamespaces[p.GetNamespace()] {
+		return true
+	}
+	for _, l := range d.systemLabels {
+		key, value, exact := strings.Cut(l, "=")
+		v, ok := p.GetLabels()[key]
+		if ok && (!exact || v == value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDrainSystemPodsLast(t *testing.T) {
+	labelled := func(name string, labels map[string]string) core.Pod {
+		p := newTestPod(name, 0, "1")
+		p.SetLabels(labels)
+		return p
+	}
+	c := newFakeClientSet(
+		reactor{verb: "list", resource: "pods", ret: &core.PodList{Items: []core.Pod{
+			func() core.Pod {
+				p := newTestPod("a-kube-proxy", 0, "1")
+				p.SetNamespace("kube-system")
+				return p
+			}(),
+			labelled("b-csi", map[string]string{"app": "csi-driver"}),
+			labelled("c-monitoring", map[string]string{"node-agent": "true"}),
+			// Only the configured value of app is a system pod.
+			labelled("d-web", map[string]string{"app": "web"}),
+			newTestPod("e", 0, "1"),
+		}}},
+		reactor{verb: "create", resource: "pods", subresource: "eviction"},
+		reactor{verb: "get", resource: "pods", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)},
+		reactor{verb: "delete", resource: "nodes"},
+	)
+	d := NewAPICordonDrainer(c,
+		WithSystemPodsLast([]string{"kube-system"}, []string{"app=csi-driver", "node-agent"}),
+		WithDeterministicOrder(true),
+	)
+	if err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	// System pods are sorted by namespace, then name, like the others.
+	if want, got := []string{"d-web", "e", "b-csi", "c-monitoring", "a-kube-proxy"}, evictedPods(c.(*fake.Clientset)); !reflect.DeepEqual(want, got) {
+		t.Errorf("evictions: want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,171 @@
+package kubernetes
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Default latency throttle settings.
+const (
+	// DefaultLatencyThrottleSmoothing is the weight given to each new latency
+	// sample in the moving average maintained by a LatencyThrottle.
+	DefaultLatencyThrottleSmoothing = 0.2
+
+	// minThrottledPeriod is the first period used when backing off from a
+	// zero period.
+	minThrottledPeriod = 1 * time.Second
+)
+
+// A LatencyThrottle backs off the period between drains while the observed
+// latency of API server calls is too high. The period is doubled, up to the
+// maximum period, for each call observed while the moving average of the
+// latency exceeds the threshold. It is halved back towards the base period
+// for each call observed while the average is below the threshold.
+type LatencyThrottle struct {
+	sync.Mutex
+	period    time.Duration
+	threshold time.Duration
+	maxPeriod time.Duration
+	smoothing float64
+
+	average time.Duration
+	current time.Duration
+}
+
+// NewLatencyThrottle returns a LatencyThrottle for the supplied base period
+// that backs off while the average API server latency exceeds the supplied
+// threshold, without ever spacing drains by more than maxPeriod.
+func NewLatencyThrottle(period, threshold, maxPeriod time.Duration) *LatencyThrottle {
+	if maxPeriod < period {
+		maxPeriod = period
+	}
+	return &LatencyThrottle{
+		period:    period,
+		threshold: threshold,
+		maxPeriod: maxPeriod,
+		smoothing: DefaultLatencyThrottleSmoothing,
+		current:   period,
+	}
+}
+
+// Observe records the latency of an API server call.
+func (t *LatencyThrottle) Observe(latency time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+	if t.average == 0 {
+		t.average = latency
+	} else {
+		t.average = time.Duration(t.smoothing*float64(latency) + (1-t.smoothing)*float64(t.average))
+	}
+
+	if t.average > t.threshold {
+		next := t.current * 2
+		if next < minThrottledPeriod {
+			next = minThrottledPeriod
+		}
+		if next > t.maxPeriod {
+			next = t.maxPeriod
+		}
+		t.current = next
+		return
+	}
+	t.current /= 2
+	if t.current < t.period || t.current < minThrottledPeriod {
+		t.current = t.period
+	}
+}
+
+// Period returns the current period between drains.
+func (t *LatencyThrottle) Period() time.Duration {
+	t.Lock()
+	defer t.Unlock()
+	return t.current
+}
+
+// SetPeriod changes the base period of the throttle. A throttle that is not
+// backing off switches to the new period right away.
+func (t *LatencyThrottle) SetPeriod(p time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+	if t.current <= t.period || t.current < p {
+		t.current = p
+	}
+	t.period = p
+	if t.maxPeriod < p {
+		t.maxPeriod = p
+	}
+}
+
+// SetPeriod changes the period between drains at runtime, for example to
+// evacuate nodes faster for a while. Drains scheduled from then on are spaced
+// by the new period; drains already scheduled keep their time.
+func (d *DrainSchedules) SetPeriod(p time.Duration) {
+	d.Lock()
+	defer d.Unlock()
+	p = d.floorPeriod(p)
+	d.period = p
+	if d.throttle != nil {
+		d.throttle.SetPeriod(p)
+	}
+	d.metrics.EffectiveDrainPeriod(d.effectivePeriod())
+}
+
+// floorPeriod returns the supplied period between drains, raised to the
+// minimum period if shorter.
+func (d *DrainSchedules) floorPeriod(p time.Duration) time.Duration {
+	if p >= d.minPeriod {
+		return p
+	}
+	d.logger.Warn("Period between drains is below the minimum, using the minimum", zap.Duration("period", p), zap.Duration("minPeriod", d.minPeriod))
+	return d.minPeriod
+}
+
+// Period returns the period between drains, before throttling.
+func (d *DrainSchedules) Period() time.Duration {
+	d.Lock()
+	defer d.Unlock()
+	return d.period
+}
+
+// markInProgress marks the supplied node as being drained if the drainer is a
+// DrainStateMarker. Other drainers do not distinguish in progress drains from
+// scheduled ones. Failures are logged but do not fail the drain.
+func (d *DrainSchedules) markInProgress(n *v1.Node, when time.Time) {
+	m, ok := d.drainer.(DrainStateMarker)
+	if !ok {
+		return
+	}
+	if err := m.MarkDrainState(n, DrainStateInProgress, when, time.Time{}, ""); err != nil {
+		d.logger.Info("Failed to mark drain in progress", zap.String("node", n.GetName()), zap.Error(err))
+	}
+}
+
+// effectivePeriod returns the period between drains, accounting for the
+// latency throttle if any.
+func (d *DrainSchedules) effectivePeriod() time.Duration {
+	if d.throttle == nil {
+		return d.period
+	}
+	p := d.throttle.Period()
+	d.metrics.EffectiveDrainPeriod(p)
+	return p
+}
+
+// markDrain marks the supplied node with the supplied drain state, feeding the
+// latency of the call to the latency throttle if any.
+func (d *DrainSchedules) markDrain(n *v1.Node, state DrainState, when, finish time.Time, reason string) error {
+	start := d.now()
+	var err error
+	if m, ok := d.drainer.(DrainStateMarker); ok {
+		err = m.MarkDrainState(n, state, when, finish, reason)
+	} else {
+		err = d.drainer.MarkDrain(n, when, finish, state == DrainStateFailed, reason)
+	}
+	if d.throttle != nil {
+		d.throttle.Observe(d.now().Sub(start))
+	}
+	return err
+}
@@ -0,0 +1,186 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestLatencyThrottle(t *testing.T) {
+	throttle := NewLatencyThrottle(time.Minute, 100*time.Millisecond, 5*time.Minute)
+
+	steps := []struct {
+		latency time.Duration
+		want    time.Duration
+	}{
+		{latency: 10 * time.Millisecond, want: time.Minute},
+		{latency: time.Second, want: 2 * time.Minute},
+		{latency: time.Second, want: 4 * time.Minute},
+		{latency: time.Second, want: 5 * time.Minute},
+		{latency: time.Second, want: 5 * time.Minute},
+	}
+	for i, s := range steps {
+		throttle.Observe(s.latency)
+		if got := throttle.Period(); got != s.want {
+			t.Fatalf("step %d: Period(): want %v, got %v", i, s.want, got)
+		}
+	}
+
+	// The period recovers once the average latency drops below the threshold.
+	for i := 0; i < 20; i++ {
+		throttle.Observe(time.Millisecond)
+	}
+	if got := throttle.Period(); got != time.Minute {
+		t.Errorf("Period() after recovery: want %v, got %v", time.Minute, got)
+	}
+}
+
+func TestLatencyThrottleZeroPeriod(t *testing.T) {
+	throttle := NewLatencyThrottle(0, 100*time.Millisecond, time.Minute)
+	throttle.Observe(time.Second)
+	if got := throttle.Period(); got != minThrottledPeriod {
+		t.Fatalf("Period(): want %v, got %v", minThrottledPeriod, got)
+	}
+	for i := 0; i < 20; i++ {
+		throttle.Observe(time.Millisecond)
+	}
+	if got := throttle.Period(); got != 0 {
+		t.Errorf("Period() after recovery: want 0, got %v", got)
+	}
+}
+
+type slowDrainer struct {
+	NoopCordonDrainer
+}
+
+func (d *slowDrainer) MarkDrain(n *v1.Node, when, finish time.Time, failed bool, reason string) error {
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func TestLatencyThrottleSetPeriod(t *testing.T) {
+	throttle := NewLatencyThrottle(time.Hour, time.Millisecond, time.Hour)
+	throttle.SetPeriod(time.Minute)
+	if got := throttle.Period(); got != time.Minute {
+		t.Errorf("Period() after lowering the period: want %v, got %v", time.Minute, got)
+	}
+	throttle.Observe(time.Second)
+	backedOff := throttle.Period()
+	throttle.SetPeriod(time.Second)
+	if got := throttle.Period(); got != backedOff {
+		t.Errorf("Period() while backing off: want %v, got %v", backedOff, got)
+	}
+}
+
+func TestDrainSchedules_LatencyThrottle(t *testing.T) {
+	period := time.Minute
+	throttle := NewLatencyThrottle(period, time.Millisecond, time.Hour)
+	scheduler := NewDrainSchedules(&slowDrainer{}, &record.FakeRecorder{}, period, zap.NewNop(), WithLatencyThrottle(throttle)).(*DrainSchedules)
+
+	first, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: "first"}})
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	second, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: "second"}})
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	defer scheduler.DeleteSchedule("first")
+	defer scheduler.DeleteSchedule("second")
+
+	if got := second.Sub(first); got != 2*period {
+		t.Errorf("second drain scheduled %v after the first, want %v", got, 2*period)
+	}
+}
+
+func TestDrainSchedules_SetPeriod(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, 10*time.Minute, zap.NewNop(), WithDispatcher(NewManualDispatcher(start))).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	schedule := func(name string) time.Time {
+		when, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}})
+		if err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+		return when
+	}
+	first := schedule("first")
+	scheduler.SetPeriod(time.Minute)
+	if got := scheduler.Period(); got != time.Minute {
+		t.Errorf("Period(): want %v, got %v", time.Minute, got)
+	}
+	second := schedule("second")
+	third := schedule("third")
+
+	if !first.Equal(start.Add(10 * time.Minute)) {
+		t.Errorf("first drain: want %v, got %v", start.Add(10*time.Minute), first)
+	}
+	if got := scheduler.schedules["first"].when; !got.Equal(first) {
+		t.Errorf("first drain moved to %v by the new period", got)
+	}
+	if got := second.Sub(first); got != time.Minute {
+		t.Errorf("second drain scheduled %v after the first, want %v", got, time.Minute)
+	}
+	if got := third.Sub(second); got != time.Minute {
+		t.Errorf("third drain scheduled %v after the second, want %v", got, time.Minute)
+	}
+}
+
+func TestDrainSchedules_MinPeriod(t *testing.T) {
+	cases := []struct {
+		name       string
+		period     time.Duration
+		setPeriod  time.Duration
+		opts       []DrainSchedulesOption
+		wantPeriod time.Duration
+		wantSet    time.Duration
+	}{
+		{
+			name:       "DefaultFloor",
+			period:     0,
+			setPeriod:  time.Second,
+			wantPeriod: DefaultMinDrainPeriod,
+			wantSet:    DefaultMinDrainPeriod,
+		},
+		{
+			name:       "AboveFloor",
+			period:     time.Minute,
+			setPeriod:  time.Hour,
+			wantPeriod: time.Minute,
+			wantSet:    time.Hour,
+		},
+		{
+			name:       "ExplicitFloor",
+			period:     time.Second,
+			setPeriod:  time.Minute,
+			opts:       []DrainSchedulesOption{WithMinPeriod(2 * time.Minute)},
+			wantPeriod: 2 * time.Minute,
+			wantSet:    2 * time.Minute,
+		},
+		{
+			name:       "FloorDisabled",
+			period:     0,
+			setPeriod:  time.Millisecond,
+			opts:       []DrainSchedulesOption{WithMinPeriod(0)},
+			wantPeriod: 0,
+			wantSet:    time.Millisecond,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, tc.period, zap.NewNop(), tc.opts...).(*DrainSchedules)
+			if got := scheduler.Period(); got != tc.wantPeriod {
+				t.Errorf("Period(): want %v, got %v", tc.wantPeriod, got)
+			}
+			scheduler.SetPeriod(tc.setPeriod)
+			if got := scheduler.Period(); got != tc.wantSet {
+				t.Errorf("Period() after SetPeriod(%v): want %v, got %v", tc.setPeriod, tc.wantSet, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"sync"
+	"time"
+)
+
+// Default drain throughput settings.
+const (
+	// DefaultThroughputWindow is the window over which the drain throughput
+	// metric is computed.
+	DefaultThroughputWindow = 1 * time.Hour
+
+	throughputBucket    = 1 * time.Minute
+	throughputRetention = 24 * time.Hour
+)
+
+// throughputBucketCount is the number of drains completed within the bucket
+// starting at start.
+type throughputBucketCount struct {
+	start time.Time
+	count int
+}
+
+// drainThroughput counts completed drains in fixed size time buckets, oldest
+// first, forgetting the buckets older than its retention.
+type drainThroughput struct {
+	sync.Mutex
+	bucket    time.Duration
+	retention time.Duration
+	buckets   []throughputBucketCount
+}
+
+func newDrainThroughput(bucket, retention time.Duration) *drainThroughput {
+	return &drainThroughput{bucket: bucket, retention: retention}
+}
+
+// evictLocked forgets the buckets that ended before the retention. It must be
+// called with the lock held.
+func (t *drainThroughput) evictLocked(now time.Time) {
+	cutoff := now.Add(-t.retention)
+	i := 0
+	for i < len(t.buckets) && !t.buckets[i].start.Add(t.bucket).After(cutoff) {
+		i++
+	}
+	t.buckets = t.buckets[i:]
+}
+
+// add counts a drain completed at the supplied time.
+func (t *drainThroughput) add(at time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	t.evictLocked(at)
+	start := at.Truncate(t.bucket)
+	if n := len(t.buckets); n > 0 && !t.buckets[n-1].start.Before(start) {
+		t.buckets[n-1].count++
+		return
+	}
+	t.buckets = append(t.buckets, throughputBucketCount{start: start, count: 1})
+}
+
+// perMinute returns the number of drains completed per minute within the
+// supplied window ending at the supplied time. Buckets that overlap the window
+// are counted whole. Windows longer than the retention are capped.
+func (t *drainThroughput) perMinute(now time.Time, window time.Duration) float64 {
+	if window <= 0 {
+		return 0
+	}
+	if window > t.retention {
+		window = t.retention
+	}
+	t.Lock()
+	defer t.Unlock()
+	t.evictLocked(now)
+	cutoff := now.Add(-window)
+	count := 0
+	for _, b := range t.buckets {
+		if b.start.Add(t.bucket).After(cutoff) {
+			count += b.count
+		}
+	}
+	return float64(count) / window.Minutes()
+}
+
+// Throughput returns the number of drains completed successfully per minute
+// within the supplied window, up to a day, ending now. Drains are counted in one
+// minute buckets, which are counted whole when they overlap the window.
+func (d *DrainSchedules) Throughput(window time.Duration) float64 {
+	return d.throughput.perMinute(d.now(), window)
+}
+
+// recordThroughput counts a drain completed at the supplied time, and records
+// the drain throughput over DefaultThroughputWindow.
+func (d *DrainSchedules) recordThroughput(at time.Time) {
+	d.throughput.add(at)
+	d.metrics.DrainThroughput(d.Throughput(DefaultThroughputWindow))
+}
@@ -0,0 +1,85 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainThroughput(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := newDrainThroughput(time.Minute, time.Hour)
+	for _, at := range []time.Duration{0, 10 * time.Second, 90 * time.Second, 5 * time.Minute, 30 * time.Minute} {
+		th.add(start.Add(at))
+	}
+	if got := len(th.buckets); got != 4 {
+		t.Errorf("buckets: want 4, got %d", got)
+	}
+
+	cases := []struct {
+		name   string
+		now    time.Duration
+		window time.Duration
+		want   float64
+	}{
+		{name: "LastHour", now: 30 * time.Minute, window: time.Hour, want: 5.0 / 60},
+		{name: "LastTenMinutes", now: 30 * time.Minute, window: 10 * time.Minute, want: 1.0 / 10},
+		{name: "PartialBucket", now: 30*time.Minute + 30*time.Second, window: 30 * time.Second, want: 1.0 / 0.5},
+		{name: "LongerThanRetention", now: 30 * time.Minute, window: 2 * time.Hour, want: 5.0 / 60},
+		{name: "Idle", now: 50 * time.Minute, window: 10 * time.Minute},
+		{name: "NoWindow", now: 30 * time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := th.perMinute(start.Add(tc.now), tc.window); got != tc.want {
+				t.Errorf("perMinute(%v, %v): want %v, got %v", tc.now, tc.window, tc.want, got)
+			}
+		})
+	}
+
+	// Buckets older than the retention are evicted as time advances.
+	th.add(start.Add(70 * time.Minute))
+	if got := len(th.buckets); got != 2 {
+		t.Errorf("buckets after an hour: want 2, got %d", got)
+	}
+}
+
+func TestDrainSchedules_Throughput(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &recordingMetrics{}
+	dispatcher := NewManualDispatcher(start)
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithMetricsRecorder(m), WithDispatcher(dispatcher)).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+	for i := 0; i < 3; i++ {
+		node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}}
+		if _, err := scheduler.Schedule(node); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", node.GetName(), err)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		if fired := dispatcher.ProcessDue(start.Add(time.Duration(i) * time.Minute)); fired != 1 {
+			t.Fatalf("ProcessDue(%dm): want 1 drain fired, got %d", i, fired)
+		}
+	}
+
+	if got, want := scheduler.Throughput(time.Hour), 3.0/60; got != want {
+		t.Errorf("Throughput(1h): want %v, got %v", want, got)
+	}
+	if got, want := scheduler.Throughput(time.Minute), 2.0; got != want {
+		t.Errorf("Throughput(1m): want %v, got %v", want, got)
+	}
+	if want := []float64{1.0 / 60, 2.0 / 60, 3.0 / 60}; !reflect.DeepEqual(m.rates, want) {
+		t.Errorf("DrainThroughput: want %v, got %v", want, m.rates)
+	}
+
+	dispatcher.ProcessDue(start.Add(2 * time.Hour))
+	if got := scheduler.Throughput(time.Hour); got != 0 {
+		t.Errorf("Throughput(1h) two hours later: want 0, got %v", got)
+	}
+}
@@ -0,0 +1,141 @@
+package kubernetes
+
+import (
+	"sort"
+	"time"
+)
+
+// A ProjectedDrain is the time a pending drain is projected to fire.
+type ProjectedDrain struct {
+	Node string
+	// Scheduled is the time the drain is currently scheduled for.
+	Scheduled time.Time
+	// Projected is the time the drain is expected to fire, once the drains
+	// scheduled before it fired.
+	Projected time.Time
+}
+
+// ScheduleWindow returns the earliest and latest times pending drains are
+// scheduled for, and how many drains are pending. Drains in progress, done or
+// failed are not pending. It returns zero times when no drain is pending.
+func (d *DrainSchedules) ScheduleWindow() (earliest, latest time.Time, count int) {
+	d.Lock()
+	defer d.Unlock()
+	for name, s := range d.schedules {
+		if _, draining := d.inProgress[name]; draining || !s.finish.IsZero() || s.isFailed() {
+			continue
+		}
+		if count == 0 || s.when.Before(earliest) {
+			earliest = s.when
+		}
+		if count == 0 || s.when.After(latest) {
+			latest = s.when
+		}
+		count++
+	}
+	return earliest, latest, count
+}
+
+// ProjectTimeline returns when each pending drain is projected to fire, in fire
+// order. Pending drains are projected in the order they are scheduled in, no
+// sooner than now, spaced by the period between drains, and pushed later by
+// the group cooldown, the group drain schedules and the zone drain limit.
+// Projections are what-ifs: they do not account for dependencies between
+// drains, drains that fail, or drains deferred when they fire. ProjectTimeline
+// does not alter any schedule.
+func (d *DrainSchedules) ProjectTimeline() []ProjectedDrain {
+	d.Lock()
+	defer d.Unlock()
+
+	now := d.now()
+	period := d.effectivePeriod()
+	var pending []ProjectedDrain
+	var prev time.Time
+	for name, s := range d.schedules {
+		if _, draining := d.inProgress[name]; draining {
+			if s.when.After(prev) {
+				prev = s.when
+			}
+			continue
+		}
+		if !s.finish.IsZero() || s.isFailed() {
+			continue
+		}
+		pending = append(pending, ProjectedDrain{Node: name, Scheduled: s.when})
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Scheduled.Equal(pending[j].Scheduled) {
+			return pending[i].Node < pending[j].Node
+		}
+		return pending[i].Scheduled.Before(pending[j].Scheduled)
+	})
+
+	// The group and zone drains only account for the drains that already
+	// happened, and the projected ones.
+	groupLast := make(map[string]time.Time)
+	for g, t := range d.groupLastDrain {
+		if !t.After(now) {
+			groupLast[g] = t
+		}
+	}
+	zoneDrains := make(map[string][]time.Time)
+	for z, drains := range d.zoneDrains {
+		for _, t := range drains {
+			if t.After(now.Add(-d.zoneWindow)) {
+				zoneDrains[z] = append(zoneDrains[z], t)
+			}
+		}
+	}
+
+	for i := range pending {
+		s := d.schedules[pending[i].Node]
+		when := pending[i].Scheduled
+		if when.Before(now) {
+			when = now
+		}
+		if !prev.IsZero() && when.Before(prev.Add(period)) {
+			when = prev.Add(period)
+		}
+		for {
+			next := d.projectSlot(s, groupLast, zoneDrains[s.zone], when)
+			if next.Equal(when) {
+				break
+			}
+			when = next
+		}
+		pending[i].Projected = when
+		prev = when
+		if s.group != "" {
+			groupLast[s.group] = when
+		}
+		if d.zoneLimit > 0 && s.zone != "" {
+			zoneDrains[s.zone] = append(zoneDrains[s.zone], when)
+			sort.Slice(zoneDrains[s.zone], func(i, j int) bool { return zoneDrains[s.zone][i].Before(zoneDrains[s.zone][j]) })
+		}
+	}
+	return pending
+}
+
+// projectSlot returns the earliest time, no sooner than the supplied time,
+// allowed for the drain of the supplied schedule by the group cooldown, the
+// group drain schedules and the zone drain limit, given the supplied last drain
+// of each group and drains of the zone of the schedule. It must be called with
+// the lock held.
+func (d *DrainSchedules) projectSlot(s *schedule, groupLast map[string]time.Time, zoneDrains []time.Time, when time.Time) time.Time {
+	last, drained := groupLast[s.group]
+	if s.group != "" && drained && d.groupCooldown > 0 && when.Before(last.Add(d.groupCooldown)) {
+		when = last.Add(d.groupCooldown)
+	}
+	if cron, ok := d.groupSchedules[s.group]; ok && s.group != "" {
+		if drained && when.Before(last.Add(d.period)) {
+			when = last.Add(d.period)
+		}
+		if next := cron.Next(when); !next.IsZero() {
+			when = next
+		}
+	}
+	if d.zoneLimit > 0 && s.zone != "" {
+		when = d.zoneSlot(zoneDrains, when)
+	}
+	return when
+}
@@ -0,0 +1,85 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_ProjectTimeline(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManualDispatcher(start)
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithDispatcher(m)).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}); err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", name, err)
+		}
+	}
+
+	// Pending drains keep the times they were scheduled for, but are projected
+	// per the new period.
+	scheduler.SetPeriod(10 * time.Minute)
+	want := []ProjectedDrain{
+		{Node: "a", Scheduled: start.Add(time.Minute), Projected: start.Add(time.Minute)},
+		{Node: "b", Scheduled: start.Add(2 * time.Minute), Projected: start.Add(11 * time.Minute)},
+		{Node: "c", Scheduled: start.Add(3 * time.Minute), Projected: start.Add(21 * time.Minute)},
+	}
+	if got := scheduler.ProjectTimeline(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProjectTimeline(): want %v, got %v", want, got)
+	}
+	if got := scheduler.schedules["c"].when; !got.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("ProjectTimeline() altered schedule: want %v, got %v", start.Add(3*time.Minute), got)
+	}
+
+	// Drains already done are no longer projected.
+	m.ProcessDue(start.Add(time.Minute))
+	want = []ProjectedDrain{
+		{Node: "b", Scheduled: start.Add(2 * time.Minute), Projected: start.Add(2 * time.Minute)},
+		{Node: "c", Scheduled: start.Add(3 * time.Minute), Projected: start.Add(12 * time.Minute)},
+	}
+	if got := scheduler.ProjectTimeline(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProjectTimeline() after a drain: want %v, got %v", want, got)
+	}
+}
+
+func TestDrainSchedules_ScheduleWindow(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManualDispatcher(start)
+	scheduler := NewDrainSchedules(newRecordingDrainer(), &record.FakeRecorder{}, time.Minute, zap.NewNop(), WithDispatcher(m)).(*DrainSchedules)
+	scheduler.lastDrainScheduledFor = start
+
+	steps := []struct {
+		name         string
+		schedule     string
+		wantEarliest time.Time
+		wantLatest   time.Time
+		wantCount    int
+		fireUntil    time.Time
+	}{
+		{name: "Empty"},
+		{name: "Single", schedule: "a", wantEarliest: start.Add(time.Minute), wantLatest: start.Add(time.Minute), wantCount: 1},
+		{name: "Multiple", schedule: "b", wantEarliest: start.Add(time.Minute), wantLatest: start.Add(2 * time.Minute), wantCount: 2},
+		{name: "Fired", fireUntil: start.Add(time.Minute), wantEarliest: start.Add(2 * time.Minute), wantLatest: start.Add(2 * time.Minute), wantCount: 1},
+	}
+	for _, s := range steps {
+		if s.schedule != "" {
+			if _, err := scheduler.Schedule(&v1.Node{ObjectMeta: meta.ObjectMeta{Name: s.schedule}}); err != nil {
+				t.Fatalf("%s: DrainSchedules.Schedule(%s) error = %v", s.name, s.schedule, err)
+			}
+		}
+		if !s.fireUntil.IsZero() {
+			m.ProcessDue(s.fireUntil)
+		}
+		earliest, latest, count := scheduler.ScheduleWindow()
+		if !earliest.Equal(s.wantEarliest) || !latest.Equal(s.wantLatest) || count != s.wantCount {
+			t.Errorf("%s: ScheduleWindow(): want (%v, %v, %d), got (%v, %v, %d)", s.name, s.wantEarliest, s.wantLatest, s.wantCount, earliest, latest, count)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WithTopologySpreadOrder determines whether Drain evicts pods one at a time
+// in an order that preserves the topology spread of their workloads, typically
+// with topology.kubernetes.io/zone as key. The topology domains are the values
+// of the supplied node label. Pods whose controller is the most over
+// represented in the domain of the node, compared to its least populated
+// domain, are evicted first, then by namespace and name. Pods without a
+// controller come last. This order takes precedence over those configured by
+// WithLargestFirstOrder, WithOwnerAwareOrder and WithDeterministicOrder, but
+// still applies after the pods evicted first and within each startup cost.
+// Pods are evicted in the configured order if the node has no such label, or
+// the pods and nodes of the cluster cannot be listed. An empty key disables
+// this order.
+func WithTopologySpreadOrder(key string) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.topologySpreadKey = key
+	}
+}
+
+// topologySpread returns, for each of the supplied pods of the supplied node,
+// by namespaced name,
+// how many more pods of its controller run in the topology domain of the node
+// than in the least populated domain of the cluster. It returns nil if pods are
+// not evicted in topology spread order.
+func (d *APICordonDrainer) topologySpread(ctx context.Context, n *core.Node, pods []core.Pod) map[string]int {
+	if d.topologySpreadKey == "" {
+		return nil
+	}
+	domain, ok := n.GetLabels()[d.topologySpreadKey]
+	if !ok {
+		return nil
+	}
+	spread, err := d.surplus(ctx, domain, pods)
+	if err != nil {
+		d.l.Info("Cannot determine topology spread, evicting pods in the configured order", zap.String("node", n.GetName()), zap.Error(err))
+		return nil
+	}
+	return spread
+}
+
+// surplus returns, for each of the supplied pods, how many more pods of its
+// controller run in the supplied domain than in its least populated domain.
+func (d *APICordonDrainer) surplus(ctx context.Context, domain string, pods []core.Pod) (map[string]int, error) {
+	nodes, err := NewAPINodeStore(d.c).ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	domains := map[string]string{}
+	for _, n := range nodes {
+		if v, ok := n.GetLabels()[d.topologySpreadKey]; ok {
+			domains[n.GetName()] = v
+		}
+	}
+	all, err := d.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list pods")
+	}
+	// counts holds the number of pods of each controller in each domain,
+	// including the domains where it has none.
+	counts := map[types.UID]map[string]int{}
+	for _, p := range pods {
+		if c := meta.GetControllerOf(&p); c != nil && counts[c.UID] == nil {
+			counts[c.UID] = map[string]int{}
+			for _, v := range domains {
+				counts[c.UID][v] = 0
+			}
+		}
+	}
+	for _, p := range all.Items {
+		c := meta.GetControllerOf(&p)
+		if c == nil || counts[c.UID] == nil {
+			continue
+		}
+		if v, ok := domains[p.Spec.NodeName]; ok {
+			counts[c.UID][v]++
+		}
+	}
+	spread := make(map[string]int, len(pods))
+	for _, p := range pods {
+		c := meta.GetControllerOf(&p)
+		if c == nil {
+			spread[p.GetNamespace()+"/"+p.GetName()] = -1
+			continue
+		}
+		least := counts[c.UID][domain]
+		for _, count := range counts[c.UID] {
+			if count < least {
+				least = count
+			}
+		}
+		spread[p.GetNamespace()+"/"+p.GetName()] = counts[c.UID][domain] - least
+	}
+	return spread, nil
+}
+
+// spreadBatches splits the supplied pods into batches of one pod each, by
+// decreasing topology spread surplus.
+func spreadBatches(pods []core.Pod, spread map[string]int) [][]core.Pod {
+	sortPods(pods)
+	sort.SliceStable(pods, func(i, j int) bool {
+		return spread[pods[i].GetNamespace()+"/"+pods[i].GetName()] > spread[pods[j].GetNamespace()+"/"+pods[j].GetName()]
+	})
+	batches := make([][]core.Pod, 0, len(pods))
+	for _, p := range pods {
+		batches = append(batches, []core.Pod{p})
+	}
+	return batches
+}
@@ -0,0 +1,38 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+)
+
+func TestDrainTopologySpreadOrder(t *testing.T) {
+	zoned := func(name, zone string) *core.Node {
+		n := newCapacityNode(name, "4", true)
+		n.Labels = map[string]string{core.LabelTopologyZone: zone}
+		return n
+	}
+	draining := zoned(nodeName, "a")
+	c := newEvictingClientset(
+		draining,
+		zoned("peer-a", "a"),
+		zoned("peer-b", "b"),
+		newScheduledPod("bare", nodeName, "1"),
+		// api is under represented in zone a, with one pod against two in
+		// zone b, while web has both of its pods in zone a.
+		newReplicaPod("api-0", nodeName, "1", "api"),
+		newReplicaPod("api-1", "peer-b", "1", "api"),
+		newReplicaPod("api-2", "peer-b", "1", "api"),
+		newReplicaPod("web-0", nodeName, "1", "web"),
+		newReplicaPod("web-1", "peer-a", "1", "web"),
+	)
+	d := NewAPICordonDrainer(c, WithTopologySpreadOrder(core.LabelTopologyZone), WithDeterministicOrder(true))
+	if err := d.Drain(draining); err != nil {
+		t.Fatalf("d.Drain(%v): %v", nodeName, err)
+	}
+	// Pods without a controller come last.
+	if want, got := []string{"web-0", "api-0", "bare"}, evictedPods(c); !reflect.DeepEqual(want, got) {
+		t.Errorf("evictions: want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// startDrainSpan starts the span covering the whole lifecycle of the supplied
+// schedule. It does nothing when tracing is disabled.
+func (d *DrainSchedules) startDrainSpan(node *v1.Node, sched *schedule) {
+	if d.tracer == nil {
+		return
+	}
+	_, sched.span = d.tracer.Start(context.Background(), "draino.drain", trace.WithAttributes(
+		attribute.String("node", node.GetName()),
+		attribute.String("drain_id", sched.drainID),
+	))
+}
+
+// startSpan starts a child span of the supplied context. The returned span is
+// nil when tracing is disabled.
+func (d *DrainSchedules) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	if d.tracer == nil {
+		return ctx, nil
+	}
+	return d.tracer.Start(ctx, name)
+}
+
+// endSpan ends the supplied span, recording the error if any.
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// drain drains the supplied node, propagating the context to drainers that
+// support it. Force drains fall back to graceful drains with drainers that do
+// not support them.
+func (d *DrainSchedules) drain(ctx context.Context, node *v1.Node, force bool) error {
+	if force {
+		if fd, ok := d.drainer.(ForceDrainer); ok {
+			return fd.ForceDrain(ctx, node)
+		}
+		d.logger.Info("Drainer cannot force drain, draining gracefully", zap.String("node", node.GetName()))
+	}
+	if cd, ok := d.drainer.(ContextDrainer); ok {
+		return cd.DrainWithContext(ctx, node)
+	}
+	return d.drainer.Drain(node)
+}
+
+// spanContext returns a context carrying the drain span of the schedule.
+func (s *schedule) spanContext() context.Context {
+	if s.span == nil {
+		return context.Background()
+	}
+	return trace.ContextWithSpan(context.Background(), s.span)
+}
+
+// addSpanEvent adds an event to the drain span of the schedule.
+func (s *schedule) addSpanEvent(name string, attrs ...attribute.KeyValue) {
+	if s.span == nil {
+		return
+	}
+	s.span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the drain span of the schedule. A non empty event is added to
+// the span before it ends.
+func (s *schedule) endSpan(event string) {
+	if s.span == nil {
+		return
+	}
+	if event != "" {
+		s.span.AddEvent(event)
+	}
+	s.span.End()
+}
@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("draino")
+
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	c := newFakeClientSet(
+		reactor{
+			verb:     "list",
+			resource: "pods",
+			ret: &core.PodList{Items: []core.Pod{
+				{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "default"}},
+			}},
+		},
+		reactor{
+			verb:        "create",
+			resource:    "pods",
+			subresource: "eviction",
+		},
+		reactor{
+			verb:     "get",
+			resource: "pods",
+			err:      apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName),
+		},
+	)
+	drainer := NewAPICordonDrainer(c, WithAPICordonDrainerTracer(tracer))
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop(), WithTracer(tracer)).(*DrainSchedules)
+
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[node.Name]
+	sched.timer.Stop()
+	scheduler.runDrain(node, sched)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	root, ok := spans["draino.drain"]
+	if !ok {
+		t.Fatalf("missing drain span, got %v", spans)
+	}
+	drainID := ""
+	for _, a := range root.Attributes() {
+		if a.Key == "drain_id" {
+			drainID = a.Value.AsString()
+		}
+	}
+	if drainID != sched.drainID {
+		t.Errorf("drain_id attribute: want %v, got %v", sched.drainID, drainID)
+	}
+	parents := map[string]string{
+		"draino.drain.mark_scheduled": "draino.drain",
+		"draino.drain.evict":          "draino.drain",
+		"draino.evict_pod":            "draino.drain.evict",
+		"draino.drain.mark_succeeded": "draino.drain",
+	}
+	for name, parent := range parents {
+		s, ok := spans[name]
+		if !ok {
+			t.Errorf("missing span %v", name)
+			continue
+		}
+		if s.Parent().SpanID() != spans[parent].SpanContext().SpanID() {
+			t.Errorf("span %v: want parent %v", name, parent)
+		}
+	}
+}
+
+func TestDrainSchedules_NoTracer(t *testing.T) {
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	if _, err := scheduler.Schedule(node); err != nil {
+		t.Fatalf("DrainSchedules.Schedule() error = %v", err)
+	}
+	sched := scheduler.schedules[node.Name]
+	sched.timer.Stop()
+	if sched.span != nil {
+		t.Errorf("no span should be started without a tracer")
+	}
+	scheduler.runDrain(node, sched)
+}
@@ -0,0 +1,122 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// A UtilizationDrainResult describes the outcome of a partial drain.
+type UtilizationDrainResult struct {
+	PodsEvicted int
+
+	// CPUUtilization and MemoryUtilization are the fractions of the node's
+	// allocatable resources requested by its pods once the drain completed.
+	CPUUtilization    float64
+	MemoryUtilization float64
+}
+
+// DrainToUtilization evicts the evictable pods of the supplied node, lowest
+// priority first, until the CPU and memory requested by the pods of the node
+// are below the supplied fraction of its allocatable resources. The node is
+// expected to be cordoned, and is left cordoned.
+func (d *APICordonDrainer) DrainToUtilization(n *core.Node, targetFraction float64) (UtilizationDrainResult, error) {
+	ctx := context.Background()
+	if d.skipDrain {
+		d.l.Debug("Skipping drain because draining is disabled")
+		return UtilizationDrainResult{}, nil
+	}
+
+	l, err := d.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": n.GetName()}).String(),
+	})
+	if err != nil {
+		return UtilizationDrainResult{}, errors.Wrapf(err, "cannot get pods for node %s", n.GetName())
+	}
+	var cpu, memory int64
+	for _, p := range l.Items {
+		if p.Status.Phase == core.PodSucceeded || p.Status.Phase == core.PodFailed {
+			continue
+		}
+		c, m := podRequests(p)
+		cpu += c
+		memory += m
+	}
+
+	pods, err := d.getPods(n.GetName())
+	if err != nil {
+		return UtilizationDrainResult{}, errors.Wrapf(err, "cannot get pods for node %s", n.GetName())
+	}
+	sort.SliceStable(pods, func(i, j int) bool { return podPriority(pods[i]) < podPriority(pods[j]) })
+
+	allocatableCPU := n.Status.Allocatable.Cpu().MilliValue()
+	allocatableMemory := n.Status.Allocatable.Memory().Value()
+	result := UtilizationDrainResult{}
+	utilization := func() {
+		result.CPUUtilization = fraction(cpu, allocatableCPU)
+		result.MemoryUtilization = fraction(memory, allocatableMemory)
+	}
+	utilization()
+
+	abort := make(chan struct{})
+	defer close(abort)
+	deadline := time.After(d.deleteTimeout())
+	for _, pod := range pods {
+		if result.CPUUtilization < targetFraction && result.MemoryUtilization < targetFraction {
+			break
+		}
+		errs := make(chan error, 1)
+		var blocked int32
+		go d.evict(ctx, pod, abort, errs, &blocked)
+		select {
+		case err := <-errs:
+			if err != nil {
+				return result, errors.Wrap(err, "cannot evict pod")
+			}
+		case <-deadline:
+			return result, errors.Wrap(errTimeout{}, "timed out waiting for evictions to complete")
+		}
+		c, m := podRequests(pod)
+		cpu -= c
+		memory -= m
+		result.PodsEvicted++
+		utilization()
+	}
+
+	d.l.Info("Drained node to target utilization",
+		zap.String("node", n.GetName()),
+		zap.Int("podsEvicted", result.PodsEvicted),
+		zap.Float64("cpuUtilization", result.CPUUtilization),
+		zap.Float64("memoryUtilization", result.MemoryUtilization))
+	return result, nil
+}
+
+// podRequests returns the CPU, in millicores, and memory, in bytes, requested
+// by the containers of the supplied pod.
+func podRequests(p core.Pod) (cpu, memory int64) {
+	for _, c := range p.Spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		memory += c.Resources.Requests.Memory().Value()
+	}
+	return cpu, memory
+}
+
+func podPriority(p core.Pod) int32 {
+	if p.Spec.Priority == nil {
+		return 0
+	}
+	return *p.Spec.Priority
+}
+
+func fraction(used, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(used) / float64(total)
+}
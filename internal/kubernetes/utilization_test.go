@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newTestPod(name string, priority int32, cpu string) core.Pod {
+	return core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: core.PodSpec{
+			Priority: &priority,
+			Containers: []core.Container{{
+				Resources: core.ResourceRequirements{Requests: core.ResourceList{
+					core.ResourceCPU:    resource.MustParse(cpu),
+					core.ResourceMemory: resource.MustParse("1Gi"),
+				}},
+			}},
+		},
+	}
+}
+
+// evictedPods returns the names of the pods evicted through the supplied client,
+// in order.
+func evictedPods(c *fake.Clientset) []string {
+	var names []string
+	for _, a := range c.Actions() {
+		if a.GetVerb() == "create" && a.GetSubresource() == "eviction" {
+			names = append(names, a.(clienttesting.CreateAction).GetObject().(meta.Object).GetName())
+		}
+	}
+	return names
+}
+
+func TestDrainToUtilization(t *testing.T) {
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Status: core.NodeStatus{Allocatable: core.ResourceList{
+			core.ResourceCPU:    resource.MustParse("4"),
+			core.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	c := newFakeClientSet(
+		reactor{
+			verb:     "list",
+			resource: "pods",
+			ret: &core.PodList{Items: []core.Pod{
+				newTestPod("important", 100, "1"),
+				newTestPod("batch", 0, "2"),
+			}},
+		},
+		reactor{
+			verb:        "create",
+			resource:    "pods",
+			subresource: "eviction",
+		},
+		reactor{
+			verb:     "get",
+			resource: "pods",
+			err:      apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName),
+		},
+	)
+	d := NewAPICordonDrainer(c)
+
+	result, err := d.DrainToUtilization(node, 0.5)
+	if err != nil {
+		t.Fatalf("d.DrainToUtilization(%v): %v", node.Name, err)
+	}
+	want := UtilizationDrainResult{PodsEvicted: 1, CPUUtilization: 0.25, MemoryUtilization: 0.125}
+	if !reflect.DeepEqual(want, result) {
+		t.Errorf("d.DrainToUtilization(%v): want %#v, got %#v", node.Name, want, result)
+	}
+	if want, got := []string{"batch"}, evictedPods(c.(*fake.Clientset)); !reflect.DeepEqual(want, got) {
+		t.Errorf("evicted pods: want %v, got %v", want, got)
+	}
+}
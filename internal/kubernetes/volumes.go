@@ -0,0 +1,136 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultVolumeDetachTimeout is how long a PersistentVolume aware drain
+	// waits for the volumes of an evicted pod to be detached from the node.
+	DefaultVolumeDetachTimeout = 2 * time.Minute
+
+	volumeDetachPollPeriod = 1 * time.Second
+)
+
+// WithPVAwareDrain determines whether Drain waits, once each pod using
+// PersistentVolumeClaims is gone, for the PersistentVolumes bound to these
+// claims to be detached from the node, for up to the supplied timeout. The
+// drain fails with a VolumesNotDetachedError if they are not. Evictions of
+// these pods are never escalated to force deletion, which could strand their
+// volumes. Detachment is observed through VolumeAttachments, so it only
+// applies to volumes attached by a CSI driver or the attach detach
+// controller.
+func WithPVAwareDrain(b bool, timeout time.Duration) APICordonDrainerOption {
+	return func(d *APICordonDrainer) {
+		d.pvAwareDrain = b
+		d.volumeDetachTimeout = timeout
+	}
+}
+
+// claimNames returns the names of the PersistentVolumeClaims used by the
+// supplied pod, including those of its generic ephemeral volumes.
+func claimNames(p core.Pod) []string {
+	var claims []string
+	for _, v := range p.Spec.Volumes {
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			claims = append(claims, v.PersistentVolumeClaim.ClaimName)
+		case v.Ephemeral != nil:
+			claims = append(claims, p.GetName()+"-"+v.Name)
+		}
+	}
+	return claims
+}
+
+// keepsVolumes returns true if the supplied pod must be drained with care for
+// its PersistentVolumes.
+func (d *APICordonDrainer) keepsVolumes(p core.Pod) bool {
+	return d.pvAwareDrain && len(claimNames(p)) > 0
+}
+
+// detachTimeout returns how long Drain may wait for the PersistentVolumes of
+// an evicted pod to be detached.
+func (d *APICordonDrainer) detachTimeout() time.Duration {
+	if d.volumeDetachTimeout <= 0 {
+		return DefaultVolumeDetachTimeout
+	}
+	return d.volumeDetachTimeout
+}
+
+// awaitVolumeDetach waits for the PersistentVolumes of the supplied pod, which
+// is gone, to be detached from its node. It returns immediately unless the
+// drain is PersistentVolume aware.
+func (d *APICordonDrainer) awaitVolumeDetach(ctx context.Context, p core.Pod) error {
+	if !d.keepsVolumes(p) {
+		return nil
+	}
+	volumes := map[string]bool{}
+	for _, claim := range claimNames(p) {
+		pvc, err := d.c.CoreV1().PersistentVolumeClaims(p.GetNamespace()).Get(ctx, claim, meta.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "cannot get PersistentVolumeClaim %s/%s", p.GetNamespace(), claim)
+		}
+		if pvc.Spec.VolumeName != "" {
+			volumes[pvc.Spec.VolumeName] = true
+		}
+	}
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	stop, cancel := context.WithTimeout(ctx, d.detachTimeout())
+	defer cancel()
+	var attached []string
+	err := wait.PollImmediateUntil(volumeDetachPollPeriod, func() (bool, error) {
+		vas, err := d.c.StorageV1().VolumeAttachments().List(ctx, meta.ListOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, "cannot list VolumeAttachments")
+		}
+		attached = nil
+		for _, va := range vas.Items {
+			pv := va.Spec.Source.PersistentVolumeName
+			if va.Spec.NodeName == p.Spec.NodeName && pv != nil && volumes[*pv] {
+				attached = append(attached, *pv)
+			}
+		}
+		return len(attached) == 0, nil
+	}, stop.Done())
+	if err == wait.ErrWaitTimeout {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "volume detach cancelled")
+		}
+		sort.Strings(attached)
+		d.l.Info("Volumes still attached", zap.String("pod", p.GetNamespace()+"/"+p.GetName()), zap.Strings("volumes", attached))
+		return NewVolumesNotDetachedError(p.GetNamespace()+"/"+p.GetName(), attached)
+	}
+	return err
+}
+
+type VolumesNotDetachedError struct {
+	error
+}
+
+func NewVolumesNotDetachedError(pod string, volumes []string) error {
+	return &VolumesNotDetachedError{
+		fmt.Errorf("volumes of pod %s are still attached: %s", pod, strings.Join(volumes, ", ")),
+	}
+}
+
+func IsVolumesNotDetachedError(err error) bool {
+	_, ok := errors.Cause(err).(*VolumesNotDetachedError)
+	return ok
+}
@@ -0,0 +1,123 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	storage "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDrainPVAware(t *testing.T) {
+	pv := "pv-data"
+	attachment := &storage.VolumeAttachment{
+		ObjectMeta: meta.ObjectMeta{Name: "csi-data"},
+		Spec: storage.VolumeAttachmentSpec{
+			NodeName: nodeName,
+			Source:   storage.VolumeAttachmentSource{PersistentVolumeName: &pv},
+		},
+	}
+	cases := []struct {
+		name     string
+		pvAware  bool
+		objects  []runtime.Object
+		detached bool
+	}{
+		{name: "Detached", pvAware: true, detached: true},
+		{name: "StillAttached", pvAware: true, objects: []runtime.Object{attachment}},
+		{name: "Disabled", objects: []runtime.Object{attachment}, detached: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := append([]runtime.Object{
+				&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+				&core.Pod{
+					ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName},
+					Spec: core.PodSpec{
+						NodeName: nodeName,
+						Volumes: []core.Volume{{
+							Name:         "data",
+							VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+						}},
+					},
+				},
+				&core.PersistentVolumeClaim{
+					ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "data"},
+					Spec:       core.PersistentVolumeClaimSpec{VolumeName: pv},
+				},
+			}, tc.objects...)
+			c := fake.NewSimpleClientset(objects...)
+			c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return a.GetSubresource() == "eviction", nil, nil
+			})
+			c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+			})
+
+			d := NewAPICordonDrainer(c, WithPVAwareDrain(tc.pvAware, 10*time.Millisecond))
+			err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+			if tc.detached && err != nil {
+				t.Errorf("d.Drain(%v): %v", nodeName, err)
+			}
+			if !tc.detached && !IsVolumesNotDetachedError(err) {
+				t.Errorf("d.Drain(%v): want VolumesNotDetachedError, got %v", nodeName, err)
+			}
+			if s, _ := d.DrainSummary(nodeName); s.VolumeBacked != 1 {
+				t.Errorf("DrainSummary(): want 1 pod with persistent volumes, got %d", s.VolumeBacked)
+			}
+		})
+	}
+}
+
+func TestDrainPVAwareOutlastsDeleteTimeout(t *testing.T) {
+	pv := "pv-data"
+	c := fake.NewSimpleClientset(
+		&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}},
+		&core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: podName},
+			Spec: core.PodSpec{
+				NodeName: nodeName,
+				Volumes: []core.Volume{{
+					Name:         "data",
+					VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+				}},
+			},
+		},
+		&core.PersistentVolumeClaim{
+			ObjectMeta: meta.ObjectMeta{Namespace: "default", Name: "data"},
+			Spec:       core.PersistentVolumeClaimSpec{VolumeName: pv},
+		},
+		&storage.VolumeAttachment{
+			ObjectMeta: meta.ObjectMeta{Name: "csi-data"},
+			Spec: storage.VolumeAttachmentSpec{
+				NodeName: nodeName,
+				Source:   storage.VolumeAttachmentSource{PersistentVolumeName: &pv},
+			},
+		},
+	)
+	c.PrependReactor("create", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return a.GetSubresource() == "eviction", nil, nil
+	})
+	c.PrependReactor("get", "pods", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName)
+	})
+
+	// The volume detach wait outlasts the time allowed to delete pods, so
+	// the drain must report the volumes rather than a generic timeout.
+	d := NewAPICordonDrainer(c,
+		MaxGracePeriod(0),
+		EvictionHeadroom(10*time.Millisecond),
+		WithPVAwareDrain(true, 200*time.Millisecond),
+	)
+	err := d.Drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}})
+	if !IsVolumesNotDetachedError(err) {
+		t.Errorf("d.Drain(%v): want VolumesNotDetachedError, got %v", nodeName, err)
+	}
+}
@@ -35,6 +35,27 @@ type NodeStore interface {
 	Get(name string) (*core.Node, error)
 }
 
+// An APINodeStore gets nodes directly from the API server, bypassing any
+// cache.
+type APINodeStore struct {
+	c kubernetes.Interface
+}
+
+// NewAPINodeStore returns a NodeStore that gets nodes using the supplied
+// client.
+func NewAPINodeStore(c kubernetes.Interface) *APINodeStore {
+	return &APINodeStore{c: c}
+}
+
+// Get an node by name. Returns an error if the node does not exist.
+func (s *APINodeStore) Get(name string) (*core.Node, error) {
+	n, err := s.c.CoreV1().Nodes().Get(context.Background(), name, meta.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get node %s", name)
+	}
+	return n, nil
+}
+
 // An NodeWatch is a cache of node resources that notifies registered
 // handlers when its contents change.
 type NodeWatch struct {
@@ -0,0 +1,134 @@
+package kubernetes
+
+import (
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// A WaveNodeState is the state of the drain of a node of a wave.
+type WaveNodeState string
+
+// Wave node states.
+const (
+	WaveNodePending   WaveNodeState = "Pending"
+	WaveNodeSucceeded WaveNodeState = "Succeeded"
+	WaveNodeFailed    WaveNodeState = "Failed"
+	// WaveNodeCancelled denotes a node whose schedule was deleted before it
+	// finished draining.
+	WaveNodeCancelled WaveNodeState = "Cancelled"
+)
+
+// WaveStatus describes the progress of a wave of drains.
+type WaveStatus struct {
+	Name  string
+	Nodes map[string]WaveNodeState
+	// Complete is true once none of the nodes of the wave is pending.
+	Complete bool
+}
+
+// drainWave is a named set of nodes drained together. The drains of a wave
+// depend on the nodes of the previous wave.
+type drainWave struct {
+	name  string
+	nodes []string
+	after []string
+	// drainIDs holds the drain ID of the schedule of each node of the wave,
+	// and outcomes the state of the finished ones.
+	drainIDs map[string]string
+	outcomes map[string]WaveNodeState
+}
+
+// ScheduleWave schedules the drain of the supplied nodes as a named wave. The
+// nodes of a wave are not drained until all the drains of the previously
+// scheduled wave finished, whether they succeeded or failed. Like any drain
+// dependency, a wave is no longer held once its maximum deferral elapses.
+func (d *DrainSchedules) ScheduleWave(name string, nodes []*v1.Node) error {
+	d.Lock()
+	if d.waveLocked(name) != nil {
+		d.Unlock()
+		return errors.Errorf("wave %s is already scheduled", name)
+	}
+	for _, n := range nodes {
+		if _, ok := d.schedules[n.GetName()]; ok {
+			d.Unlock()
+			return errors.Wrapf(NewAlreadyScheduledError(), "cannot schedule wave %s: node %s", name, n.GetName())
+		}
+	}
+	if err := d.checkCapacityLocked(len(nodes)); err != nil {
+		d.Unlock()
+		return errors.Wrapf(err, "cannot schedule wave %s", name)
+	}
+	w := &drainWave{
+		name:     name,
+		drainIDs: map[string]string{},
+		outcomes: map[string]WaveNodeState{},
+	}
+	if len(d.waves) > 0 {
+		w.after = d.waves[len(d.waves)-1].nodes
+	}
+	for _, n := range nodes {
+		w.nodes = append(w.nodes, n.GetName())
+	}
+	d.waves = append(d.waves, w)
+	d.Unlock()
+
+	for _, n := range nodes {
+		d.Lock()
+		if _, err := d.scheduleLocked(n, "", w); err != nil {
+			return errors.Wrapf(err, "cannot schedule wave %s: node %s", name, n.GetName())
+		}
+	}
+	return nil
+}
+
+// WaveStatus returns the progress of the named wave, and false if there is no
+// such wave.
+func (d *DrainSchedules) WaveStatus(name string) (WaveStatus, bool) {
+	d.Lock()
+	defer d.Unlock()
+	w := d.waveLocked(name)
+	if w == nil {
+		return WaveStatus{}, false
+	}
+	status := WaveStatus{Name: name, Nodes: map[string]WaveNodeState{}, Complete: true}
+	for _, n := range w.nodes {
+		state := WaveNodeCancelled
+		if s, ok := d.schedules[n]; ok && s.drainID == w.drainIDs[n] {
+			state = WaveNodePending
+		}
+		if outcome, ok := w.outcomes[n]; ok {
+			state = outcome
+		}
+		if state == WaveNodePending {
+			status.Complete = false
+		}
+		status.Nodes[n] = state
+	}
+	return status, true
+}
+
+func (d *DrainSchedules) waveLocked(name string) *drainWave {
+	for _, w := range d.waves {
+		if w.name == name {
+			return w
+		}
+	}
+	return nil
+}
+
+// recordWaveOutcome records the outcome of the drain of the supplied schedule
+// in its wave, if any.
+func (d *DrainSchedules) recordWaveOutcome(name string, sched *schedule, failed bool) {
+	if sched.wave == nil {
+		return
+	}
+	d.Lock()
+	defer d.Unlock()
+	if sched.wave.drainIDs[name] != sched.drainID {
+		return
+	}
+	sched.wave.outcomes[name] = WaveNodeSucceeded
+	if failed {
+		sched.wave.outcomes[name] = WaveNodeFailed
+	}
+}
@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDrainSchedules_ScheduleWave(t *testing.T) {
+	drainer := newRecordingDrainer()
+	scheduler := NewDrainSchedules(drainer, &record.FakeRecorder{}, 0, zap.NewNop()).(*DrainSchedules)
+
+	nodes := map[string]*v1.Node{}
+	for _, name := range []string{"a1", "a2", "b1"} {
+		nodes[name] = &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+	}
+	if err := scheduler.ScheduleWave("a", []*v1.Node{nodes["a1"], nodes["a2"]}); err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWave(a) error = %v", err)
+	}
+	if err := scheduler.ScheduleWave("b", []*v1.Node{nodes["b1"]}); err != nil {
+		t.Fatalf("DrainSchedules.ScheduleWave(b) error = %v", err)
+	}
+	if err := scheduler.ScheduleWave("b", nil); err == nil {
+		t.Errorf("DrainSchedules.ScheduleWave(b) again: want error")
+	}
+	// Timers are driven by hand below.
+	for name := range nodes {
+		scheduler.schedules[name].timer.Stop()
+	}
+
+	assertStatus := func(wave string, want WaveStatus) {
+		t.Helper()
+		got, ok := scheduler.WaveStatus(wave)
+		if !ok {
+			t.Fatalf("WaveStatus(%s): wave not found", wave)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("WaveStatus(%s): want %+v, got %+v", wave, want, got)
+		}
+	}
+
+	scheduler.runDrain(nodes["b1"], scheduler.schedules["b1"])
+	if got := drainer.nodes(); len(got) != 0 {
+		t.Fatalf("wave b drained before wave a: %v", got)
+	}
+
+	scheduler.runDrain(nodes["a1"], scheduler.schedules["a1"])
+	<-drainer.drained
+	assertStatus("a", WaveStatus{Name: "a", Nodes: map[string]WaveNodeState{"a1": WaveNodeSucceeded, "a2": WaveNodePending}})
+	select {
+	case name := <-drainer.drained:
+		t.Fatalf("%s drained before wave a completed", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	scheduler.runDrain(nodes["a2"], scheduler.schedules["a2"])
+	<-drainer.drained
+	assertStatus("a", WaveStatus{Name: "a", Nodes: map[string]WaveNodeState{"a1": WaveNodeSucceeded, "a2": WaveNodeSucceeded}, Complete: true})
+
+	select {
+	case name := <-drainer.drained:
+		if name != "b1" {
+			t.Fatalf("want b1 drained once wave a completed, got %v", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for wave b to be released")
+	}
+	// The outcome is recorded once the drain returns.
+	deadline := time.Now().Add(time.Second)
+	for {
+		status, _ := scheduler.WaveStatus("b")
+		if status.Complete || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertStatus("b", WaveStatus{Name: "b", Nodes: map[string]WaveNodeState{"b1": WaveNodeSucceeded}, Complete: true})
+
+	scheduler.DeleteSchedule("a1")
+	assertStatus("a", WaveStatus{Name: "a", Nodes: map[string]WaveNodeState{"a1": WaveNodeSucceeded, "a2": WaveNodeSucceeded}, Complete: true})
+	if _, ok := scheduler.WaveStatus("c"); ok {
+		t.Errorf("WaveStatus(c): want no such wave")
+	}
+}
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// WithZoneDrainLimit limits the drains of the nodes of each availability zone
+// to max per sliding window. Drains that would exceed the limit are scheduled
+// once the oldest drain of the zone leaves the window. The limit applies on top
+// of the period between drains. Zero disables the limit.
+func WithZoneDrainLimit(max int, window time.Duration) DrainSchedulesOption {
+	return func(d *DrainSchedules) {
+		d.zoneLimit = max
+		d.zoneWindow = window
+	}
+}
+
+// nodeZone returns the availability zone of the supplied node, or an empty
+// string if it has none.
+func nodeZone(n *v1.Node) string {
+	if z, ok := n.GetLabels()[v1.LabelTopologyZone]; ok {
+		return z
+	}
+	return n.GetLabels()[v1.LabelFailureDomainBetaZone]
+}
+
+// zoneDrainsLocked returns the sorted times of the drains of the supplied zone
+// that may still be in the window: the completion times of the recent drains
+// and the times the pending drains are scheduled for. It must be called with
+// the lock held.
+func (d *DrainSchedules) zoneDrainsLocked(zone string) []time.Time {
+	cutoff := d.now().Add(-d.zoneWindow)
+	var recent []time.Time
+	for _, t := range d.zoneDrains[zone] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) > 0 {
+		d.zoneDrains[zone] = recent
+	} else {
+		delete(d.zoneDrains, zone)
+	}
+	d.metrics.ZoneWindowDrains(zone, len(recent))
+
+	drains := append([]time.Time{}, recent...)
+	for _, s := range d.schedules {
+		if s.zone == zone && s.finish.IsZero() {
+			drains = append(drains, s.when)
+		}
+	}
+	sort.Slice(drains, func(i, j int) bool { return drains[i].Before(drains[j]) })
+	return drains
+}
+
+// zoneSlotLocked returns the earliest time, no sooner than the supplied time,
+// at which a drain of the supplied zone does not exceed the zone drain limit.
+// It must be called with the lock held.
+func (d *DrainSchedules) zoneSlotLocked(zone string, when time.Time) time.Time {
+	if d.zoneLimit <= 0 || zone == "" {
+		return when
+	}
+	return d.zoneSlot(d.zoneDrainsLocked(zone), when)
+}
+
+// zoneSlot returns the earliest time, no sooner than the supplied time, at
+// which a drain does not exceed the zone drain limit given the supplied sorted
+// drains of its zone.
+func (d *DrainSchedules) zoneSlot(drains []time.Time, when time.Time) time.Time {
+	for {
+		var window []time.Time
+		for _, t := range drains {
+			if t.After(when.Add(-d.zoneWindow)) && !t.After(when) {
+				window = append(window, t)
+			}
+		}
+		if len(window) < d.zoneLimit {
+			return when
+		}
+		when = window[len(window)-d.zoneLimit].Add(d.zoneWindow)
+	}
+}
+
+// recordZoneDrainLocked records the completion of the drain of the supplied
+// schedule in the window of its zone. It must be called with the lock held.
+func (d *DrainSchedules) recordZoneDrainLocked(sched *schedule) {
+	if d.zoneLimit <= 0 || sched.zone == "" {
+		return
+	}
+	d.zoneDrains[sched.zone] = append(d.zoneDrains[sched.zone], sched.finish)
+	d.metrics.ZoneWindowDrains(sched.zone, len(d.zoneDrains[sched.zone]))
+}
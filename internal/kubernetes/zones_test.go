@@ -0,0 +1,63 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type zoneMetrics struct {
+	OpenCensusMetricsRecorder
+	counts map[string]int
+}
+
+func (m *zoneMetrics) ZoneWindowDrains(zone string, n int) {
+	m.counts[zone] = n
+}
+
+func TestDrainSchedules_ZoneDrainLimit(t *testing.T) {
+	m := &zoneMetrics{counts: map[string]int{}}
+	scheduler := NewDrainSchedules(&NoopCordonDrainer{}, &record.FakeRecorder{}, 0, zap.NewNop(),
+		WithZoneDrainLimit(2, time.Hour), WithMetricsRecorder(m)).(*DrainSchedules)
+
+	zoned := func(name, zone string) *v1.Node {
+		return &v1.Node{ObjectMeta: meta.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}}}
+	}
+	whens := map[string]time.Time{}
+	for _, n := range []*v1.Node{zoned("a1", "a"), zoned("a2", "a"), zoned("a3", "a"), zoned("b1", "b")} {
+		when, err := scheduler.Schedule(n)
+		if err != nil {
+			t.Fatalf("DrainSchedules.Schedule(%s) error = %v", n.GetName(), err)
+		}
+		scheduler.schedules[n.GetName()].timer.Stop()
+		defer scheduler.DeleteSchedule(n.GetName())
+		whens[n.GetName()] = when
+	}
+
+	if want := whens["a1"].Add(time.Hour); whens["a3"].Before(want) {
+		t.Errorf("a3: want drain no sooner than %v, got %v", want, whens["a3"])
+	}
+	if whens["b1"].After(whens["a2"].Add(time.Minute)) {
+		t.Errorf("b1: drain deferred by the limit of another zone to %v", whens["b1"])
+	}
+
+	// Completed drains keep counting towards the limit.
+	node := zoned("a1", "a")
+	scheduler.runDrain(node, scheduler.schedules["a1"])
+	if got := m.counts["a"]; got != 1 {
+		t.Errorf("zone a window drains: want 1, got %d", got)
+	}
+	scheduler.DeleteSchedule("a3")
+	when, err := scheduler.Schedule(zoned("a3", "a"))
+	if err != nil {
+		t.Fatalf("DrainSchedules.Schedule(a3) error = %v", err)
+	}
+	scheduler.schedules["a3"].timer.Stop()
+	if want := scheduler.schedules["a1"].finish.Add(time.Hour); when.Before(want) {
+		t.Errorf("a3 rescheduled: want drain no sooner than %v, got %v", want, when)
+	}
+}